@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// projectBackendCmd 查看或设置项目的 Terraform 远程状态后端
+func projectBackendCmd(projectSvc service.ProjectService) *cobra.Command {
+	var (
+		backendType string
+		options     []string
+		unset       bool
+		migrate     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backend <name>",
+		Short: "查看或设置项目的 Terraform 远程状态后端",
+		Long: `查看或设置项目的 Terraform 远程状态后端（记录在项目的 project.ini 中），用于团队共享场景状态。
+每个场景的状态路径由项目名和场景 ID 生成，初始化场景时自动写入 cloudbot_backend.tf。
+
+支持的后端类型:
+  s3    S3 兼容存储（AWS S3 / 阿里云 OSS / 腾讯云 COS / MinIO），需要 bucket 和 region
+        endpoint=<url>     S3 兼容存储的访问地址
+        credentials=<云服务商>  使用凭据管理器中该云服务商的凭据访问存储（通过 AWS_* 环境变量传递；
+                           场景使用 AWS provider 时只能设置为 aws）
+        key_prefix=<前缀>   状态路径前缀，默认 cloudbot
+  http  HTTP 后端（如 GitLab），需要 address（状态地址为 address/<项目>/<场景ID>）
+        lock=true          启用状态锁（address/<项目>/<场景ID>/lock）
+  pg    PostgreSQL，conn_str 可通过参数或 PG_CONN_STR 环境变量指定
+        key_prefix=<前缀>   schema 名称前缀，默认 cloudbot
+
+其他参数原样写入 backend 块。修改后端后，已有状态需要使用 --migrate 迁移。`,
+		Example: `  # 查看项目的后端配置
+  cloudbot project backend my-project
+
+  # 使用阿里云 OSS 保存状态
+  cloudbot project backend my-project --type s3 -o bucket=tf-state -o region=cn-hangzhou \
+    -o endpoint=https://oss-cn-hangzhou.aliyuncs.com -o credentials=aliyun
+
+  # 将已有的本地状态迁移到远程后端
+  cloudbot project backend my-project --migrate
+
+  # 取消远程后端并将状态迁移回本地
+  cloudbot project backend my-project --unset --migrate`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ctx := context.Background()
+
+			if backendType != "" && unset {
+				return fmt.Errorf("--type 和 --unset 不能同时使用")
+			}
+			if backendType == "" && len(options) > 0 {
+				return fmt.Errorf("设置后端参数时需要指定 --type")
+			}
+
+			switch {
+			case backendType != "":
+				backend := &domain.BackendConfig{Type: backendType, Options: make(map[string]string)}
+				for _, option := range options {
+					key, value, ok := strings.Cut(option, "=")
+					if !ok || key == "" {
+						return fmt.Errorf("无效的后端参数: %s（格式: key=value）", option)
+					}
+					backend.Options[key] = value
+				}
+				if err := projectSvc.SetProjectBackend(ctx, name, backend); err != nil {
+					return err
+				}
+				fmt.Printf("项目 %s 的状态后端已设置为 %s\n", name, backendType)
+			case unset:
+				if err := projectSvc.SetProjectBackend(ctx, name, nil); err != nil {
+					return err
+				}
+				fmt.Printf("项目 %s 已取消远程状态后端\n", name)
+			case !migrate:
+				project, err := projectSvc.GetProject(ctx, name)
+				if err != nil {
+					return err
+				}
+				printProjectBackend(project)
+				return nil
+			}
+
+			if !migrate {
+				fmt.Printf("如果项目已有部署的场景，请运行 'cloudbot project backend %s --migrate' 迁移状态\n", name)
+				return nil
+			}
+			if err := projectSvc.MigrateProjectState(ctx, name); err != nil {
+				return err
+			}
+			fmt.Printf("项目 %s 的状态迁移完成\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&backendType, "type", "", "后端类型 (s3, http, pg)")
+	cmd.Flags().StringArrayVarP(&options, "option", "o", nil, "后端参数 key=value（可重复指定）")
+	cmd.Flags().BoolVar(&unset, "unset", false, "取消远程后端，使用本地状态")
+	cmd.Flags().BoolVar(&migrate, "migrate", false, "将项目下所有场景的已有状态迁移到当前后端")
+
+	return cmd
+}
+
+// printProjectBackend 显示项目的后端配置（隐藏疑似敏感的参数）
+func printProjectBackend(project *domain.Project) {
+	if project.Backend == nil {
+		fmt.Printf("项目 %s 使用本地状态\n", project.Name)
+		return
+	}
+
+	fmt.Printf("项目 %s 的状态后端: %s\n", project.Name, project.Backend.Type)
+	keys := make([]string, 0, len(project.Backend.Options))
+	for key := range project.Backend.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := project.Backend.Options[key]
+		if isSecretOption(key) {
+			value = "******"
+		}
+		fmt.Printf("  %s = %s\n", key, value)
+	}
+}
+
+// isSecretOption 判断后端参数是否可能包含敏感信息
+func isSecretOption(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"secret", "password", "token", "conn_str", "access_key"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -56,6 +56,28 @@ func completeScenarios(projectSvc service.ProjectService) func(cmd *cobra.Comman
 	}
 }
 
+// completeScenarioResources 补全场景 Terraform 状态中的资源地址（前两个参数为项目名和场景 ID）
+func completeScenarioResources(projectSvc service.ProjectService) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) < 2 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		resources, err := projectSvc.ListScenarioResources(context.Background(), args[0], args[1])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		var completions []string
+		for _, address := range resources {
+			if strings.HasPrefix(address, toComplete) {
+				completions = append(completions, address)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
 // completeProviders 补全云服务商列表
 func completeProviders(templateRepo repository.TemplateRepository) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
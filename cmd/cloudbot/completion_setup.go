@@ -138,6 +138,39 @@ func setupScenarioCompletion(rootCmd *cobra.Command, projectSvc service.ProjectS
 			}
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
+		// --target 补全场景中的资源地址
+		destroyCmd.RegisterFlagCompletionFunc("target", completeScenarioResources(projectSvc))
+	}
+
+	// scenario replace/taint/untaint 命令：项目名、场景ID，之后补全资源地址
+	for _, name := range []string{"replace", "taint", "untaint"} {
+		resourceCmd := findCommand(scenarioCmd, name)
+		if resourceCmd == nil {
+			continue
+		}
+		resourceCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return completeProjects(projectSvc)(cmd, args, toComplete)
+			case 1:
+				return completeScenarios(projectSvc)(cmd, args, toComplete)
+			}
+			return completeScenarioResources(projectSvc)(cmd, args, toComplete)
+		}
+	}
+
+	// scenario scale 命令：项目名、场景ID，节点数不补全
+	scaleCmd := findCommand(scenarioCmd, "scale")
+	if scaleCmd != nil {
+		scaleCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return completeProjects(projectSvc)(cmd, args, toComplete)
+			case 1:
+				return completeScenarios(projectSvc)(cmd, args, toComplete)
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
 	}
 }
 
@@ -849,7 +849,7 @@ func (c *console) cmdProjectList() error {
 
 	fmt.Println("项目列表:")
 	for _, p := range projects {
-		fmt.Printf("  - %s (%d 个场景)\n", p.Name, len(p.Scenarios))
+		fmt.Printf("  - %s (%d 个场景%s)\n", p.Name, len(p.Scenarios), projectDriftSummary(p))
 	}
 	return nil
 }
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/service"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // credentialCmd 凭据管理命令组
@@ -23,34 +30,267 @@ func credentialCmd() *cobra.Command {
   - vultr: Vultr
 
 凭据可以存储在配置文件中，也可以从环境变量读取。
-环境变量优先级低于配置文件。`,
+环境变量优先级低于配置文件。
+
+支持命名 profile（如团队账号、个人账号、客户账号）:
+  - 通过 --profile 指定操作的 profile，默认 profile 名为 default
+  - 通过环境变量 CLOUDBOT_PROFILE 或 'credential use <profile>' 选择当前 profile
+  - 通过 'project profile <project> <profile>' 为项目绑定 profile
+
+支持加密存储凭据（scrypt + AES-256-GCM，完全离线）:
+  - 'credential migrate' 将配置文件中的明文凭据迁移到加密文件 .redc.credentials
+  - 'credential unlock' / 'credential lock' 解锁或锁定，解锁后在会话超时前无需重复输入口令
+  - 无交互环境可通过环境变量 CLOUDBOT_CREDENTIAL_PASSPHRASE 提供口令
+
+支持 AssumeRole 类型的 profile（阿里云、腾讯云、AWS）:
+  - 'credential set-role' 配置角色 ARN 及基础身份所在 profile
+  - 执行 Terraform 前自动通过 STS 获取或刷新临时凭据，无需在本机保存长期密钥
+
+支持通过外部命令获取凭据（credential_process，语义与 AWS 相同）:
+  - 'credential set-process' 配置辅助程序，例如从团队保险库读取凭据
+  - 辅助程序输出包含 AccessKey、SecretKey、SessionToken、Expiration 的 JSON，结果缓存至过期前`,
 	}
 
 	cmd.AddCommand(listCredentialsCmd())
 	cmd.AddCommand(setCredentialCmd())
 	cmd.AddCommand(getCredentialCmd())
 	cmd.AddCommand(removeCredentialCmd())
+	cmd.AddCommand(listProfilesCmd())
+	cmd.AddCommand(useProfileCmd())
+	cmd.AddCommand(unlockCredentialCmd())
+	cmd.AddCommand(lockCredentialCmd())
+	cmd.AddCommand(migrateCredentialCmd())
+	cmd.AddCommand(setRoleCredentialCmd())
+	cmd.AddCommand(setProcessCredentialCmd())
+	cmd.AddCommand(verifyCredentialCmd())
+
+	return cmd
+}
+
+// secureStore 返回默认凭据管理器的加密存储接口
+func secureStore() (credentials.SecureStore, error) {
+	store, ok := credentials.GetDefaultManager().(credentials.SecureStore)
+	if !ok {
+		return nil, fmt.Errorf("当前凭据管理器不支持加密存储")
+	}
+	return store, nil
+}
+
+// missingCredentialError 返回未找到凭据时的错误，加密存储未解锁时提示解锁
+func missingCredentialError(provider credentials.Provider) error {
+	if store, err := secureStore(); err == nil && store.IsLocked() {
+		return credentials.ErrStoreLocked
+	}
+	return fmt.Errorf("未配置 %s 的凭据", provider.DisplayName())
+}
+
+// readPassphrase 读取加密存储口令
+// 优先使用环境变量 CLOUDBOT_CREDENTIAL_PASSPHRASE，否则从终端读取
+func readPassphrase(prompt string) (string, error) {
+	if passphrase := os.Getenv(credentials.PassphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+
+	fmt.Print(prompt)
+	passphrase, err := readPassword()
+	if err != nil {
+		return "", fmt.Errorf("读取口令失败: %w", err)
+	}
+	if len(passphrase) == 0 {
+		return "", fmt.Errorf("口令不能为空")
+	}
+	return string(passphrase), nil
+}
+
+// unlockCredentialCmd 解锁加密凭据存储
+func unlockCredentialCmd() *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "解锁加密凭据存储",
+		Long: `使用口令解锁加密凭据存储。
+解锁后派生密钥缓存在当前用户的临时目录中，超时前的命令无需再次输入口令。`,
+		Example: `  # 解锁 15 分钟（默认）
+  cloudbot credential unlock
+
+  # 解锁 1 小时
+  cloudbot credential unlock --timeout 1h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := secureStore()
+			if err != nil {
+				return err
+			}
+			if !store.IsEncrypted() {
+				return fmt.Errorf("未启用加密凭据存储，请先运行 'cloudbot credential migrate'")
+			}
+
+			passphrase, err := readPassphrase("请输入凭据存储口令: ")
+			if err != nil {
+				return err
+			}
+			if err := store.Unlock(passphrase, timeout); err != nil {
+				return fmt.Errorf("解锁失败: %w", err)
+			}
+
+			fmt.Printf("凭据存储已解锁，%s 后自动锁定\n", timeout)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", credentials.DefaultSessionTimeout, "会话缓存有效期")
+
+	return cmd
+}
+
+// lockCredentialCmd 锁定加密凭据存储
+func lockCredentialCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "锁定加密凭据存储",
+		Long:  "清除解锁会话缓存，之后的命令需要重新输入口令。",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := secureStore()
+			if err != nil {
+				return err
+			}
+			if err := store.Lock(); err != nil {
+				return fmt.Errorf("锁定失败: %w", err)
+			}
+
+			fmt.Println("凭据存储已锁定")
+			return nil
+		},
+	}
+	return cmd
+}
+
+// migrateCredentialCmd 将明文凭据迁移到加密存储
+func migrateCredentialCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "将明文凭据迁移到加密存储",
+		Long: `将配置文件中的明文凭据（所有 profile）加密保存到配置文件同目录下的 .redc.credentials，
+并从配置文件中删除明文凭据。环境变量中的凭据不会被迁移。迁移后新设置的凭据也只会保存到加密存储。`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := secureStore()
+			if err != nil {
+				return err
+			}
+			if store.IsEncrypted() {
+				return fmt.Errorf("已启用加密凭据存储")
+			}
+
+			passphrase, err := readPassphrase("请设置凭据存储口令: ")
+			if err != nil {
+				return err
+			}
+			if os.Getenv(credentials.PassphraseEnvVar) == "" {
+				confirm, err := readPassphrase("请再次输入口令: ")
+				if err != nil {
+					return err
+				}
+				if confirm != passphrase {
+					return fmt.Errorf("两次输入的口令不一致")
+				}
+			}
 
+			count, err := store.MigrateToEncrypted(passphrase)
+			if err != nil {
+				return fmt.Errorf("迁移失败: %w", err)
+			}
+
+			fmt.Printf("已将 %d 组凭据迁移到加密存储，配置文件中的明文凭据已删除\n", count)
+			fmt.Println("提示: 请妥善保管口令，口令丢失后无法恢复凭据")
+			return nil
+		},
+	}
+	return cmd
+}
+
+// credentialManagerForProfile 返回指定 profile 的凭据管理器，profile 为空时使用当前 profile
+func credentialManagerForProfile(profile string) credentials.CredentialManager {
+	return credentials.GetDefaultManager().WithProfile(profile)
+}
+
+// listProfilesCmd 列出所有凭据 profile
+func listProfilesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "列出所有凭据 profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager := credentials.GetDefaultManager()
+			active := manager.ActiveProfile()
+
+			fmt.Println("凭据 profile 列表:")
+			for _, profile := range manager.ListProfiles() {
+				marker := " "
+				if profile == active {
+					marker = "*"
+				}
+
+				var names []string
+				for _, provider := range manager.WithProfile(profile).ListProviders() {
+					names = append(names, string(provider))
+				}
+				fmt.Printf("  %s %s: %s\n", marker, profile, strings.Join(names, ", "))
+			}
+			fmt.Println("\n* 表示当前使用的 profile")
+			return nil
+		},
+	}
+	return cmd
+}
+
+// useProfileCmd 设置默认凭据 profile
+func useProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use <profile>",
+		Short: "设置默认凭据 profile",
+		Long:  "设置默认使用的凭据 profile（写入配置文件）。环境变量 CLOUDBOT_PROFILE 的优先级高于此设置。",
+		Example: `  # 切换到客户账号
+  cloudbot credential use client-a
+
+  # 切换回默认账号
+  cloudbot credential use default`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := credentials.GetDefaultManager().SetDefaultProfile(args[0]); err != nil {
+				return fmt.Errorf("设置默认 profile 失败: %w", err)
+			}
+			fmt.Printf("默认凭据 profile 已设置为 %s\n", args[0])
+			return nil
+		},
+	}
 	return cmd
 }
 
 // listCredentialsCmd 列出所有已配置的凭据
 func listCredentialsCmd() *cobra.Command {
+	var profile string
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "列出所有已配置的凭据",
 		Long:  "显示所有已配置凭据的云服务商列表。",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			manager := credentials.GetDefaultManager()
+			manager := credentialManagerForProfile(profile)
 			providers := manager.ListProviders()
 
+			if store, err := secureStore(); err == nil && store.IsLocked() {
+				fmt.Println("凭据存储已加密且未解锁，请运行 'cloudbot credential unlock' 查看加密存储中的凭据")
+			}
+
 			if len(providers) == 0 {
-				fmt.Println("未配置任何凭据")
+				fmt.Printf("profile %s 未配置任何凭据\n", manager.ActiveProfile())
 				fmt.Println("\n提示: 使用 'cloudbot credential set <provider>' 配置凭据")
 				return nil
 			}
 
-			fmt.Println("已配置的云服务商凭据:")
+			fmt.Printf("已配置的云服务商凭据 (profile: %s):\n", manager.ActiveProfile())
+			if store, err := secureStore(); err == nil && store.IsEncrypted() {
+				fmt.Println("凭据存储: 已加密")
+			}
 			fmt.Println()
 
 			for _, provider := range providers {
@@ -68,18 +308,23 @@ func listCredentialsCmd() *cobra.Command {
 				if creds.Region != "" {
 					fmt.Printf("    Region: %s\n", creds.Region)
 				}
+				printCredentialSource(manager, provider, creds, "    ")
 				fmt.Println()
 			}
 
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "凭据 profile（默认使用当前 profile）")
+
 	return cmd
 }
 
 // setCredentialCmd 设置凭据
 func setCredentialCmd() *cobra.Command {
-	var accessKey, secretKey, region string
+	var accessKey, secretKey, region, profile string
+	var noVerify bool
 
 	cmd := &cobra.Command{
 		Use:   "set <provider>",
@@ -93,7 +338,12 @@ func setCredentialCmd() *cobra.Command {
   cloudbot credential set aliyun
   
   # 通过参数设置
-  cloudbot credential set aliyun --access-key <key> --secret-key <key> --region <region>`,
+  cloudbot credential set aliyun --access-key <key> --secret-key <key> --region <region>
+
+  # 设置命名 profile 的凭据
+  cloudbot credential set aliyun --profile client-a
+
+保存前会调用只读 API 验证凭据，验证失败时不保存；离线环境可使用 --no-verify 跳过。`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			providerStr := args[0]
@@ -103,7 +353,7 @@ func setCredentialCmd() *cobra.Command {
 				return fmt.Errorf("无效的云服务商: %s。支持的云服务商: aliyun, tencent, huaweicloud, aws, vultr", providerStr)
 			}
 
-			manager := credentials.GetDefaultManager()
+			manager := credentialManagerForProfile(profile)
 
 			// 如果没有通过参数提供，交互式输入
 			if accessKey == "" {
@@ -131,11 +381,18 @@ func setCredentialCmd() *cobra.Command {
 				Region:    region,
 			}
 
+			if !noVerify {
+				err := verifyProviderCredentials(cmd.Context(), provider, creds)
+				if err != nil && !errors.Is(err, service.ErrVerifyUnsupported) {
+					return fmt.Errorf("%w，凭据未保存（使用 --no-verify 跳过验证）", err)
+				}
+			}
+
 			if err := manager.SetCredentials(provider, creds); err != nil {
 				return fmt.Errorf("设置凭据失败: %w", err)
 			}
 
-			fmt.Printf("%s 凭据设置成功\n", provider.DisplayName())
+			fmt.Printf("%s 凭据设置成功 (profile: %s)\n", provider.DisplayName(), manager.ActiveProfile())
 			return nil
 		},
 	}
@@ -143,12 +400,122 @@ func setCredentialCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&accessKey, "access-key", "a", "", "AccessKey (或 Secret ID)")
 	cmd.Flags().StringVarP(&secretKey, "secret-key", "s", "", "SecretKey (或 Secret Key)")
 	cmd.Flags().StringVarP(&region, "region", "r", "", "默认区域（可选）")
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "凭据 profile（默认使用当前 profile）")
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, "保存前不验证凭据")
 
 	return cmd
 }
 
+// verifyCredentialCmd 验证凭据
+func verifyCredentialCmd() *cobra.Command {
+	var profile string
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "verify [provider]",
+		Short: "验证云服务商凭据",
+		Long: `调用云服务商的只读 API（GetCallerIdentity、DescribeRegions 等）验证凭据是否有效，
+并显示凭据对应的账号及各项权限检查结果。
+
+示例:
+  cloudbot credential verify aliyun
+  cloudbot credential verify --all --profile client-a`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager := credentialManagerForProfile(profile)
+
+			var providers []credentials.Provider
+			switch {
+			case len(args) == 1 && all:
+				return fmt.Errorf("不能同时指定 provider 和 --all")
+			case len(args) == 1:
+				provider := credentials.Provider(args[0])
+				if !provider.IsValid() {
+					return fmt.Errorf("无效的云服务商: %s", args[0])
+				}
+				if !manager.HasCredentials(provider) {
+					return missingCredentialError(provider)
+				}
+				providers = []credentials.Provider{provider}
+			case all:
+				providers = manager.ListProviders()
+				if len(providers) == 0 {
+					return fmt.Errorf("profile %s 未配置任何凭据", manager.ActiveProfile())
+				}
+			default:
+				return fmt.Errorf("请指定 provider 或使用 --all")
+			}
+
+			fmt.Printf("验证凭据 (profile: %s):\n\n", manager.ActiveProfile())
+			failed := 0
+			for _, provider := range providers {
+				creds, err := manager.GetCredentials(provider)
+				if err != nil {
+					fmt.Printf("%s (%s): 获取凭据失败 - %v\n\n", provider.DisplayName(), provider, err)
+					failed++
+					continue
+				}
+				err = verifyProviderCredentials(cmd.Context(), provider, creds)
+				if err != nil && !errors.Is(err, service.ErrVerifyUnsupported) {
+					failed++
+				}
+				fmt.Println()
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d 个云服务商的凭据验证未通过", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "凭据 profile（默认使用当前 profile）")
+	cmd.Flags().BoolVar(&all, "all", false, "验证当前 profile 下所有已配置的凭据")
+
+	return cmd
+}
+
+// verifyProviderCredentials 验证凭据并输出账号及权限检查结果，未全部通过时返回错误
+func verifyProviderCredentials(ctx context.Context, provider credentials.Provider, creds *credentials.Credentials) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := service.VerifyCredentials(ctx, string(provider), creds)
+	if err != nil {
+		fmt.Printf("%s (%s): 无法验证 - %v\n", provider.DisplayName(), provider, err)
+		return fmt.Errorf("%s 凭据验证失败: %w", provider.DisplayName(), err)
+	}
+
+	status := "验证通过"
+	if !result.OK() {
+		status = "验证未通过"
+	}
+	fmt.Printf("%s (%s): %s\n", provider.DisplayName(), provider, status)
+	if result.AccountID != "" {
+		fmt.Printf("  账号: %s\n", result.AccountID)
+	}
+	if result.Identity != "" {
+		fmt.Printf("  身份: %s\n", result.Identity)
+	}
+	for _, check := range result.Checks {
+		if check.Passed {
+			fmt.Printf("  ✓ %s\n", check.Action)
+		} else {
+			fmt.Printf("  ✗ %s: %s\n", check.Action, check.Error)
+		}
+	}
+
+	if !result.OK() {
+		return fmt.Errorf("%s 凭据验证未通过", provider.DisplayName())
+	}
+	return nil
+}
+
 // getCredentialCmd 获取凭据
 func getCredentialCmd() *cobra.Command {
+	var profile string
+
 	cmd := &cobra.Command{
 		Use:   "get <provider>",
 		Short: "获取云服务商凭据",
@@ -162,10 +529,10 @@ func getCredentialCmd() *cobra.Command {
 				return fmt.Errorf("无效的云服务商: %s", providerStr)
 			}
 
-			manager := credentials.GetDefaultManager()
+			manager := credentialManagerForProfile(profile)
 
 			if !manager.HasCredentials(provider) {
-				return fmt.Errorf("未配置 %s 的凭据", provider.DisplayName())
+				return missingCredentialError(provider)
 			}
 
 			creds, err := manager.GetCredentials(provider)
@@ -175,21 +542,27 @@ func getCredentialCmd() *cobra.Command {
 
 			maskedSK := maskSecretForCLI(creds.SecretKey)
 
-			fmt.Printf("%s 凭据信息:\n", provider.DisplayName())
+			fmt.Printf("%s 凭据信息 (profile: %s):\n", provider.DisplayName(), manager.ActiveProfile())
 			fmt.Printf("  AccessKey: %s\n", creds.AccessKey)
 			fmt.Printf("  SecretKey: %s\n", maskedSK)
 			if creds.Region != "" {
 				fmt.Printf("  Region: %s\n", creds.Region)
 			}
+			printCredentialSource(manager, provider, creds, "  ")
 
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "凭据 profile（默认使用当前 profile）")
+
 	return cmd
 }
 
 // removeCredentialCmd 删除凭据
 func removeCredentialCmd() *cobra.Command {
+	var profile string
+
 	cmd := &cobra.Command{
 		Use:   "remove <provider>",
 		Short: "删除云服务商凭据",
@@ -203,10 +576,10 @@ func removeCredentialCmd() *cobra.Command {
 				return fmt.Errorf("无效的云服务商: %s", providerStr)
 			}
 
-			manager := credentials.GetDefaultManager()
+			manager := credentialManagerForProfile(profile)
 
 			if !manager.HasCredentials(provider) {
-				return fmt.Errorf("未配置 %s 的凭据", provider.DisplayName())
+				return missingCredentialError(provider)
 			}
 
 			fmt.Printf("确认删除 %s 的凭据? (yes/no): ", provider.DisplayName())
@@ -226,9 +599,138 @@ func removeCredentialCmd() *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "凭据 profile（默认使用当前 profile）")
+
+	return cmd
+}
+
+// setRoleCredentialCmd 配置 AssumeRole 类型的 profile
+func setRoleCredentialCmd() *cobra.Command {
+	var profile string
+	role := &credentials.RoleConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "set-role <provider>",
+		Short: "配置 AssumeRole 类型的凭据",
+		Long: `将 profile 下指定云服务商配置为 AssumeRole 类型。
+
+使用 --source-profile 中的长期凭据调用 STS AssumeRole 获取临时凭据，
+临时凭据在过期前自动刷新，并以 ALICLOUD_SECURITY_TOKEN / TENCENTCLOUD_SECURITY_TOKEN / AWS_SESSION_TOKEN 注入 Terraform。
+
+支持的 provider: aliyun, tencent, aws
+
+示例:
+  cloudbot credential set-role aliyun --profile client-a --role-arn acs:ram::123456789:role/ops --source-profile default
+  cloudbot credential set-role aws --profile prod --role-arn arn:aws:iam::123456789012:role/ops --duration 1800`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			provider := credentials.Provider(args[0])
+			switch provider {
+			case credentials.ProviderAliyun, credentials.ProviderTencent, credentials.ProviderAWS:
+			default:
+				return fmt.Errorf("%s 不支持 AssumeRole，支持的云服务商: aliyun, tencent, aws", args[0])
+			}
+
+			roleStore, ok := credentials.GetDefaultManager().(credentials.RoleStore)
+			if !ok {
+				return fmt.Errorf("当前凭据管理器不支持 AssumeRole")
+			}
+			if profile == "" {
+				profile = credentials.GetDefaultManager().ActiveProfile()
+			}
+
+			if err := roleStore.SetRole(profile, provider, role); err != nil {
+				return fmt.Errorf("配置 AssumeRole 失败: %w", err)
+			}
+
+			fmt.Printf("%s 已配置为 AssumeRole (profile: %s, role: %s, source_profile: %s)\n",
+				provider.DisplayName(), profile, role.RoleARN, role.SourceProfile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "凭据 profile（默认使用当前 profile）")
+	cmd.Flags().StringVar(&role.RoleARN, "role-arn", "", "要扮演的角色 ARN（必填）")
+	cmd.Flags().StringVar(&role.SourceProfile, "source-profile", credentials.DefaultProfile, "基础身份所在的 profile")
+	cmd.Flags().StringVar(&role.SessionName, "session-name", "", "角色会话名称（默认 cloudbot）")
+	cmd.Flags().IntVar(&role.DurationSeconds, "duration", 0, "临时凭据有效期（秒，默认 3600）")
+	cmd.Flags().StringVar(&role.ExternalID, "external-id", "", "外部 ID（可选）")
+	cmd.Flags().StringVarP(&role.Region, "region", "r", "", "默认区域（可选）")
+	cmd.MarkFlagRequired("role-arn")
+
 	return cmd
 }
 
+// setProcessCredentialCmd 配置 credential_process 类型的 profile
+func setProcessCredentialCmd() *cobra.Command {
+	var profile, command string
+
+	cmd := &cobra.Command{
+		Use:   "set-process <provider>",
+		Short: "配置通过外部命令获取凭据",
+		Long: `将 profile 下指定云服务商配置为通过外部命令（credential_process）获取凭据。
+
+命令不经过 shell 执行，需要在标准输出打印如下 JSON（兼容 AWS 的 AccessKeyId / SecretAccessKey 字段名）:
+  {"Version": 1, "AccessKey": "...", "SecretKey": "...", "SessionToken": "...", "Expiration": "2024-01-01T00:00:00Z"}
+
+SessionToken 和 Expiration 可选；返回 Expiration 时凭据缓存至过期前，否则在本次运行中一直缓存。
+
+示例:
+  cloudbot credential set-process aliyun --profile vault --command "/usr/local/bin/vault-helper --provider aliyun"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			provider := credentials.Provider(args[0])
+			if !provider.IsValid() {
+				return fmt.Errorf("无效的云服务商: %s。支持的云服务商: aliyun, tencent, huaweicloud, aws, vultr", args[0])
+			}
+
+			processStore, ok := credentials.GetDefaultManager().(credentials.ProcessStore)
+			if !ok {
+				return fmt.Errorf("当前凭据管理器不支持 credential_process")
+			}
+			if profile == "" {
+				profile = credentials.GetDefaultManager().ActiveProfile()
+			}
+
+			if err := processStore.SetCredentialProcess(profile, provider, command); err != nil {
+				return fmt.Errorf("配置 credential_process 失败: %w", err)
+			}
+
+			fmt.Printf("%s 已配置为通过外部命令获取凭据 (profile: %s)\n", provider.DisplayName(), profile)
+			fmt.Printf("提示: 使用 'cloudbot credential get %s --profile %s' 验证命令输出\n", provider, profile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "凭据 profile（默认使用当前 profile）")
+	cmd.Flags().StringVarP(&command, "command", "c", "", "获取凭据的外部命令（必填）")
+	cmd.MarkFlagRequired("command")
+
+	return cmd
+}
+
+// printCredentialSource 显示 AssumeRole / credential_process 配置及临时凭据的令牌和过期时间
+func printCredentialSource(manager credentials.CredentialManager, provider credentials.Provider, creds *credentials.Credentials, indent string) {
+	if roleStore, ok := credentials.GetDefaultManager().(credentials.RoleStore); ok {
+		if role, ok := roleStore.GetRole(manager.ActiveProfile(), provider); ok {
+			fmt.Printf("%sRoleArn: %s (source_profile: %s)\n", indent, role.RoleARN, role.SourceProfile)
+		}
+	}
+	if processStore, ok := credentials.GetDefaultManager().(credentials.ProcessStore); ok {
+		if command, ok := processStore.GetCredentialProcess(manager.ActiveProfile(), provider); ok {
+			fmt.Printf("%sCredentialProcess: %s\n", indent, command)
+		}
+	}
+	if !creds.IsTemporary() {
+		return
+	}
+	fmt.Printf("%sSessionToken: %s\n", indent, maskSecretForCLI(creds.SessionToken))
+	if !creds.Expiration.IsZero() {
+		fmt.Printf("%sExpiration: %s\n", indent, creds.Expiration.Local().Format("2006-01-02 15:04:05"))
+	}
+}
+
 // maskSecret 隐藏 SecretKey（只显示前4位和后4位）
 // 注意：此函数在 console.go 中也有定义，为了避免重复定义，这里使用不同的包名
 // 但由于都在同一个包中，需要重命名或删除一个
@@ -240,10 +742,18 @@ func maskSecretForCLI(secret string) string {
 	return secret[:4] + "****" + secret[len(secret)-4:]
 }
 
-// readPassword 读取密码（不显示输入）
+// readPassword 读取密码（终端中不显示输入，非终端时读取一行）
 func readPassword() ([]byte, error) {
-	// 简单的实现：直接读取（实际应该使用 golang.org/x/term）
-	var password string
-	fmt.Scanln(&password)
-	return []byte(password), nil
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Println()
+		return password, err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, err
+	}
+	return []byte(strings.TrimRight(line, "\r\n")), nil
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// driftKindNames 漂移类型的显示名称
+var driftKindNames = map[string]string{
+	domain.DriftDeleted:  "已删除",
+	domain.DriftStopped:  "已停止",
+	domain.DriftModified: "已修改",
+}
+
+// driftScenarioCmd 检测场景的 Terraform 状态与云端资源的差异
+func driftScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drift <project> [scenario-id]",
+		Short: "检测场景状态与云端资源的差异（漂移）",
+		Long: `执行 terraform plan -refresh-only，对比 Terraform 状态与云端实际资源，
+发现在控制台手动删除、被回收的抢占式实例、已停止的云主机或在 Terraform 之外修改的资源。
+检测只读取云端状态，不会修改 Terraform 状态和云资源；结果记录在场景元数据中，可在 project list 和 scenario list 中查看。
+不指定场景 ID 时检测项目下所有已部署的场景。`,
+		Example: `  # 检测项目下所有已部署场景
+  cloudbot scenario drift my-project
+
+  # 检测指定场景
+  cloudbot scenario drift my-project <scenario-id>`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectName := args[0]
+
+			if len(args) == 2 {
+				drift, err := projectSvc.DetectScenarioDrift(context.Background(), projectName, args[1])
+				if err != nil {
+					return err
+				}
+				printScenarioDrift(args[1], drift)
+				return nil
+			}
+
+			scenarios, err := projectSvc.ListScenarios(context.Background(), projectName)
+			if err != nil {
+				return err
+			}
+
+			checked, drifted, failed := 0, 0, 0
+			for _, scenario := range scenarios {
+				if scenario.Status != "deployed" {
+					continue
+				}
+				checked++
+				drift, err := projectSvc.DetectScenarioDrift(context.Background(), projectName, scenario.ID)
+				if err != nil {
+					failed++
+					fmt.Printf("场景 %s 检测失败: %v\n", scenario.ID, err)
+					continue
+				}
+				if drift.Drifted {
+					drifted++
+				}
+				printScenarioDrift(scenario.ID, drift)
+			}
+
+			if checked == 0 {
+				fmt.Printf("项目 %s 没有已部署的场景\n", projectName)
+				return nil
+			}
+			fmt.Printf("\n共检测 %d 个场景: %d 个存在漂移, %d 个检测失败\n", checked, drifted, failed)
+			if failed > 0 {
+				return fmt.Errorf("%d 个场景漂移检测失败", failed)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// printScenarioDrift 打印场景的漂移检测结果
+func printScenarioDrift(scenarioID string, drift *domain.ScenarioDrift) {
+	if !drift.Drifted {
+		fmt.Printf("场景 %s: 无漂移\n", scenarioID)
+		return
+	}
+
+	fmt.Printf("场景 %s: 存在漂移（%d 个资源）\n", scenarioID, len(drift.Resources))
+	for _, r := range drift.Resources {
+		fmt.Printf("  - [%s] %s\n", driftKindNames[r.Kind], r.Address)
+	}
+}
+
+// scenarioStatusLabel 场景状态显示文本，最近一次检测存在漂移时附加标记
+func scenarioStatusLabel(scenario *domain.Scenario) string {
+	if scenario.Drift != nil && scenario.Drift.Drifted {
+		return scenario.Status + ", 漂移"
+	}
+	return scenario.Status
+}
+
+// projectDriftSummary 汇总项目中最近一次检测存在漂移的场景数，没有时返回空字符串
+func projectDriftSummary(project *domain.Project) string {
+	drifted := 0
+	for _, scenario := range project.Scenarios {
+		if scenario.Drift != nil && scenario.Drift.Drifted {
+			drifted++
+		}
+	}
+	if drifted == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", %d 个漂移", drifted)
+}
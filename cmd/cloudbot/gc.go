@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// gcCmd 查找并清理孤立的云资源
+func gcCmd(gcSvc service.GCService) *cobra.Command {
+	var providers []string
+	var regions []string
+	var projectName string
+	var profile string
+	var dryRun bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "查找并清理不属于任何场景的孤立云资源",
+		Long: `通过 cloudbot_scenario 标签查找云端的实例、EIP、安全组、子网和 VPC，与已知场景对比，
+列出场景已不存在（如场景目录被手动删除）或已销毁、但仍在云端的资源，并询问是否删除。
+
+只有声明了 cloudbot_project / cloudbot_scenario 变量并将其设置为资源标签的模板创建的资源才能被识别，
+动态生成的模板已包含这些标签。支持阿里云、腾讯云、AWS 和华为云，未指定 --region 时扫描账号的全部区域。
+
+注意: 只与当前元数据存储中的场景对比。使用本地元数据且未指定 --project 时，所属项目在本地不存在的资源
+可能属于其他团队成员，会列为"归属未知"而不会删除；确认后可使用 --project 指定项目清理，或配置共享元数据存储。`,
+		Example: `  # 查找所有已配置凭据的云服务商中的孤立资源
+  cloudbot gc
+
+  # 只查找阿里云北京区域、项目 my-project 的孤立资源
+  cloudbot gc --provider aliyun --region cn-beijing --project my-project
+
+  # 只列出，不删除
+  cloudbot gc --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			if profile != "" {
+				ctx = credentials.ContextWithProfile(ctx, profile)
+			}
+
+			report, err := gcSvc.FindOrphans(ctx, service.GCOptions{
+				Providers: providers,
+				Regions:   regions,
+				Project:   projectName,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, provider := range service.SupportedClientProviders {
+				if _, ok := report.Regions[provider]; !ok {
+					continue
+				}
+				fmt.Printf("扫描 %s 区域: %s\n", provider, strings.Join(report.Regions[provider], ", "))
+			}
+			for _, e := range report.Errors {
+				fmt.Printf("查询失败: %s\n", e)
+			}
+			if len(report.Errors) > 0 {
+				fmt.Printf("部分云服务商或区域查询失败，以下结果可能不完整\n")
+			}
+			if len(report.Unknown) > 0 {
+				fmt.Printf("以下 %d 个资源所属的项目在本地不存在，可能属于其他团队成员，不会删除（确认后可使用 --project 指定项目清理）:\n\n", len(report.Unknown))
+				printUnknownResources(report.Unknown)
+				fmt.Println()
+			}
+			if len(report.Orphans) == 0 {
+				fmt.Printf("共找到 %d 个带有 cloudbot 标签的资源，没有孤立资源\n", report.Scanned)
+				return nil
+			}
+
+			fmt.Printf("共找到 %d 个带有 cloudbot 标签的资源，其中 %d 个孤立资源:\n\n", report.Scanned, len(report.Orphans))
+			printOrphans(report.Orphans)
+
+			if dryRun {
+				return nil
+			}
+			if !yes {
+				fmt.Printf("\n确认删除以上 %d 个孤立资源? (yes/no): ", len(report.Orphans))
+				var confirm string
+				fmt.Scanln(&confirm)
+				if strings.ToLower(confirm) != "yes" && strings.ToLower(confirm) != "y" {
+					fmt.Println("已取消")
+					return nil
+				}
+			}
+
+			failed := 0
+			for _, result := range gcSvc.DeleteOrphans(ctx, report.Orphans) {
+				r := result.Resource
+				if result.Err != nil {
+					failed++
+					fmt.Printf("  ✗ %s %s (%s/%s): %v\n", r.Kind, r.ID, r.Provider, r.Region, result.Err)
+					continue
+				}
+				fmt.Printf("  ✓ %s %s (%s/%s)\n", r.Kind, r.ID, r.Provider, r.Region)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d 个资源删除失败（依赖的资源可能仍在释放中，可稍后重新运行 cloudbot gc）", failed)
+			}
+			fmt.Printf("已删除 %d 个孤立资源\n", len(report.Orphans))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&providers, "provider", nil, "云服务商（可多次指定，默认所有已配置凭据的云服务商）")
+	cmd.Flags().StringSliceVar(&regions, "region", nil, "区域（可多次指定，默认所有可用区域）")
+	cmd.Flags().StringVar(&projectName, "project", "", "只查找标签中为该项目的资源")
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "使用的凭据 profile（默认使用当前 profile）")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "只列出孤立资源，不删除")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "不询问确认，直接删除")
+	return cmd
+}
+
+// printOrphans 打印孤立资源列表
+func printOrphans(orphans []service.OrphanResource) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  云服务商\t区域\t类型\tID\t名称\t项目\t场景\t原因")
+	for _, o := range orphans {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", o.Provider, o.Region, o.Kind, o.ID, o.Name, o.Project, o.Scenario, o.Reason)
+	}
+	tw.Flush()
+}
+
+// printUnknownResources 打印归属未知的资源列表
+func printUnknownResources(resources []service.TaggedResource) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  云服务商\t区域\t类型\tID\t名称\t项目\t场景")
+	for _, r := range resources {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Provider, r.Region, r.Kind, r.ID, r.Name, r.Project, r.Scenario)
+	}
+	tw.Flush()
+}
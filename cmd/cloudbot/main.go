@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -29,6 +30,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	domain.SetUSDToCNY(cfg.USDToCNY)
+
+	// 未配置 exec_path 时自动查找 Terraform/OpenTofu 可执行文件
+	cfg.Terraform.ExecPath = service.ResolveTerraformExecPath(cfg)
+
 	// 初始化日志系统
 	logConfig := &logger.Config{
 		Level:         logger.ParseLevel(cfg.Log.Level),
@@ -48,8 +54,18 @@ func main() {
 	log.Debug("配置加载成功: WorkDir=%s, TemplateDir=%s, ProjectDir=%s",
 		cfg.WorkDir, cfg.TemplateDir, cfg.ProjectDir)
 
+	credManager := credentials.GetDefaultManager()
+	// 注入 STS AssumeRole 实现，AssumeRole 类型的 profile 按需获取临时凭据
+	if roleStore, ok := credManager.(credentials.RoleStore); ok {
+		roleStore.SetRoleAssumer(service.NewRoleAssumer())
+	}
+
 	// 初始化服务
-	projectRepo := repository.NewProjectRepository(cfg)
+	projectRepo, err := newProjectRepository(cfg, credManager)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
 	templateRepo := repository.NewTemplateRepository(cfg)
 	priceRepo := repository.NewPriceRepository(cfg)
 	terraformSvc := service.NewTerraformService(cfg)
@@ -61,20 +77,10 @@ func main() {
 		priceRepoWithFetcher.SetPriceFetcher(priceFetcher)
 	}
 
-	priceSvc := service.NewPriceService(priceRepo)
+	priceSvc := service.NewPriceService(priceRepo, credManager)
 
-	// 创建价格优化器（从凭据管理器获取 AccessKey）
-	credManager := credentials.GetDefaultManager()
-	var priceOptimizer service.AliyunPriceOptimizer
-	if credManager.HasCredentials(credentials.ProviderAliyun) {
-		aliyunCreds, err := credManager.GetCredentials(credentials.ProviderAliyun)
-		if err == nil && aliyunCreds != nil {
-			priceOptimizer = service.NewAliyunPriceOptimizer(cfg, aliyunCreds.AccessKey, aliyunCreds.SecretKey)
-		}
-	}
-
-	// 创建价格优化服务（即使没有 AccessKey 也创建，内部会处理错误）
-	priceOptimizerSvc := service.NewPriceOptimizerService(cfg, priceOptimizer)
+	// 创建价格优化服务（按云服务商从凭据管理器获取 AccessKey，未配置时查询会返回错误）
+	priceOptimizerSvc := service.NewPriceOptimizerService(cfg, credManager)
 
 	// 创建根命令
 	rootCmd := &cobra.Command{
@@ -95,6 +101,10 @@ by:luckone`,
 	projectCmd.AddCommand(listProjectsCmd(projectSvc))
 	projectCmd.AddCommand(deleteProjectCmd(projectSvc))
 	projectCmd.AddCommand(initProjectCmd(projectSvc))
+	projectCmd.AddCommand(projectProfileCmd(projectSvc))
+	projectCmd.AddCommand(projectSetVarCmd(projectSvc))
+	projectCmd.AddCommand(projectBackendCmd(projectSvc))
+	projectCmd.AddCommand(projectTerraformVersionCmd(projectSvc))
 	rootCmd.AddCommand(projectCmd)
 
 	// 添加场景命令组
@@ -106,8 +116,19 @@ by:luckone`,
 	scenarioCmd.AddCommand(createDynamicScenarioCmd(projectSvc, priceSvc, priceOptimizerSvc))
 	scenarioCmd.AddCommand(listScenariosCmd(projectSvc))
 	scenarioCmd.AddCommand(deployScenarioCmd(projectSvc))
+	scenarioCmd.AddCommand(planScenarioCmd(projectSvc))
+	scenarioCmd.AddCommand(applyScenarioCmd(projectSvc))
 	scenarioCmd.AddCommand(destroyScenarioCmd(projectSvc))
+	scenarioCmd.AddCommand(scenarioReplaceCmd(projectSvc))
+	scenarioCmd.AddCommand(scenarioTaintCmd(projectSvc))
+	scenarioCmd.AddCommand(scenarioUntaintCmd(projectSvc))
+	scenarioCmd.AddCommand(scenarioScaleCmd(projectSvc))
 	scenarioCmd.AddCommand(statusScenariosCmd(projectSvc))
+	scenarioCmd.AddCommand(driftScenarioCmd(projectSvc))
+	scenarioCmd.AddCommand(scenarioSetVarCmd(projectSvc))
+	scenarioCmd.AddCommand(scenarioVarsCmd(projectSvc))
+	scenarioCmd.AddCommand(scenarioRunsCmd(projectSvc))
+	scenarioCmd.AddCommand(scenarioLogsCmd(projectSvc))
 	rootCmd.AddCommand(scenarioCmd)
 
 	// 添加模板命令组（模板管理相关）
@@ -125,6 +146,9 @@ by:luckone`,
 	}
 	priceCmd.AddCommand(comparePriceCmd(priceSvc))
 	priceCmd.AddCommand(listPriceCmd(priceSvc))
+	priceCmd.AddCommand(exportPriceCmd(priceSvc))
+	priceCmd.AddCommand(importPriceCmd(priceSvc))
+	priceCmd.AddCommand(refreshPriceCmd(priceSvc))
 	// 添加最优配置查找命令
 	priceCmd.AddCommand(findOptimalCmd(priceOptimizerSvc))
 	priceCmd.AddCommand(listRegionPricesCmd(priceOptimizerSvc))
@@ -136,6 +160,15 @@ by:luckone`,
 	// 添加凭据管理命令组
 	rootCmd.AddCommand(credentialCmd())
 
+	// 添加 Terraform/OpenTofu 管理命令组
+	rootCmd.AddCommand(terraformCmd(cfg))
+
+	// 添加 provider 缓存和镜像管理命令组
+	rootCmd.AddCommand(providersCmd(cfg, terraformSvc))
+
+	// 添加孤立资源清理命令
+	rootCmd.AddCommand(gcCmd(service.NewGCService(projectRepo)))
+
 	// 设置自动补全
 	setupCompletion(rootCmd)
 
@@ -151,24 +184,112 @@ by:luckone`,
 
 // createProjectCmd 创建项目命令
 func createProjectCmd(projectSvc service.ProjectService) *cobra.Command {
+	var profile string
+
 	cmd := &cobra.Command{
 		Use:   "create <name>",
 		Short: "创建新项目",
 		Long:  "创建一个新的项目。项目名称只能包含字母、数字、连字符和下划线。",
 		Example: `  # 创建名为 my-project 的项目
-  cloudbot project create my-project`,
+  cloudbot project create my-project
+
+  # 创建项目并绑定客户账号的凭据 profile
+  cloudbot project create client-a-ops --profile client-a`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
+
+			// 先校验 profile，避免项目创建后才发现 profile 不存在
+			if profile != "" && !containsProfile(credentials.GetDefaultManager().ListProfiles(), profile) {
+				return fmt.Errorf("凭据 profile %s 不存在，请先运行: cloudbot credential set <provider> --profile %s", profile, profile)
+			}
+
 			project, err := projectSvc.CreateProject(context.Background(), name)
 			if err != nil {
 				return err
 			}
 			fmt.Printf("项目 %s 创建成功\n", project.Name)
 			fmt.Printf("路径: %s\n", project.Path)
+
+			if profile != "" {
+				if err := projectSvc.SetProjectProfile(context.Background(), name, profile); err != nil {
+					return err
+				}
+				fmt.Printf("凭据 profile: %s\n", profile)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "绑定的凭据 profile（默认使用当前 profile）")
+
+	return cmd
+}
+
+// containsProfile 检查 profile 列表中是否包含指定 profile
+func containsProfile(profiles []string, profile string) bool {
+	for _, p := range profiles {
+		if p == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// projectProfileCmd 查看或设置项目绑定的凭据 profile
+func projectProfileCmd(projectSvc service.ProjectService) *cobra.Command {
+	var unset bool
+
+	cmd := &cobra.Command{
+		Use:   "profile <name> [profile]",
+		Short: "查看或设置项目绑定的凭据 profile",
+		Long: `查看或设置项目绑定的凭据 profile（记录在项目的 project.ini 中）。
+项目绑定 profile 后，部署、销毁和状态查询都使用该 profile 下的凭据。`,
+		Example: `  # 查看项目绑定的 profile
+  cloudbot project profile my-project
+
+  # 绑定 profile
+  cloudbot project profile my-project client-a
+
+  # 解除绑定
+  cloudbot project profile my-project --unset`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			if len(args) == 1 && !unset {
+				project, err := projectSvc.GetProject(context.Background(), name)
+				if err != nil {
+					return err
+				}
+				if project.CredentialProfile == "" {
+					fmt.Printf("项目 %s 未绑定凭据 profile，使用当前 profile (%s)\n",
+						name, credentials.GetDefaultManager().ActiveProfile())
+				} else {
+					fmt.Printf("项目 %s 绑定的凭据 profile: %s\n", name, project.CredentialProfile)
+				}
+				return nil
+			}
+
+			profile := ""
+			if len(args) == 2 {
+				profile = args[1]
+			}
+			if err := projectSvc.SetProjectProfile(context.Background(), name, profile); err != nil {
+				return err
+			}
+
+			if profile == "" {
+				fmt.Printf("项目 %s 已解除凭据 profile 绑定\n", name)
+			} else {
+				fmt.Printf("项目 %s 已绑定凭据 profile: %s\n", name, profile)
+			}
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&unset, "unset", false, "解除项目的凭据 profile 绑定")
+
 	return cmd
 }
 
@@ -190,7 +311,11 @@ func listProjectsCmd(projectSvc service.ProjectService) *cobra.Command {
 
 			fmt.Println("项目列表:")
 			for _, project := range projects {
-				fmt.Printf("  - %s (%d 个场景)\n", project.Name, len(project.Scenarios))
+				if project.CredentialProfile != "" {
+					fmt.Printf("  - %s (%d 个场景%s, profile: %s)\n", project.Name, len(project.Scenarios), projectDriftSummary(project), project.CredentialProfile)
+				} else {
+					fmt.Printf("  - %s (%d 个场景%s)\n", project.Name, len(project.Scenarios), projectDriftSummary(project))
+				}
 			}
 			return nil
 		},
@@ -265,8 +390,9 @@ func createScenarioCmd(projectSvc service.ProjectService, priceSvc service.Price
   provider  云服务商 (aliyun, tencent, aws, vultr)
   template  模板名称
 
-使用 --optimal 标志可以自动查找并应用最低价格的区域和实例类型配置（仅支持阿里云）。
-需要配置环境变量 ALICLOUD_ACCESS_KEY 和 ALICLOUD_SECRET_KEY。
+使用 --optimal 标志可以自动查找并应用最低价格的区域和实例类型配置
+（支持 aliyun, tencent, aws, huaweicloud，需要先通过 cloudbot credential set 配置对应凭据）。
+模板使用抢占式实例时按抢占式实例价格和库存选择；模板声明了 zone 变量时，有库存的可用区设置为场景变量。
 
 示例:
   # 使用阿里云 ECS 模板创建场景
@@ -299,27 +425,31 @@ func createScenarioCmd(projectSvc service.ProjectService, priceSvc service.Price
 				region = args[3]
 			}
 
-			// 如果启用了价格优化，查找最优配置
+			scenario, err := projectSvc.CreateScenario(context.Background(), projectName, provider, templateName, region)
+			if err != nil {
+				return err
+			}
+
+			// 如果启用了价格优化，查找最优配置（模板使用抢占式实例时按抢占式实例价格和库存选择）
 			var optimalConfig *service.OptimalInstanceConfig
-			if useOptimal && provider == "aliyun" && priceOptimizerSvc != nil {
+			if useOptimal && priceOptimizerSvc != nil {
+				spot := service.TemplateUsesSpot(scenario.Path)
 				fmt.Println("正在查找最优价格配置...")
-				optimal, err := priceOptimizerSvc.FindOptimalConfig(context.Background(), provider, templateName, nil, nil)
+				optimal, err := priceOptimizerSvc.FindOptimalConfig(context.Background(), provider, templateName, nil, nil, spot)
 				if err == nil && optimal != nil {
 					optimalConfig = optimal
 					fmt.Printf("✨ 找到最优配置:\n")
 					fmt.Printf("  区域: %s\n", optimal.Region)
 					fmt.Printf("  实例类型: %s\n", optimal.InstanceType)
-					fmt.Printf("  价格: %.4f CNY/小时 (%.2f CNY/月)\n", optimal.Price, optimal.PricePerMonth)
+					if optimal.Spot {
+						fmt.Printf("  计费方式: 抢占式实例\n")
+					}
+					fmt.Printf("  价格: %.4f %s/小时 (%.2f %s/月)\n", optimal.Price, optimal.Currency, optimal.PricePerMonth, optimal.Currency)
 				} else {
 					fmt.Printf("⚠️  价格优化查询失败: %v，将使用默认配置\n", err)
 				}
 			}
 
-			scenario, err := projectSvc.CreateScenario(context.Background(), projectName, provider, templateName, region)
-			if err != nil {
-				return err
-			}
-
 			fmt.Printf("\n场景创建成功\n")
 			fmt.Printf("ID: %s\n", scenario.ID)
 			fmt.Printf("名称: %s\n", scenario.Name)
@@ -335,15 +465,24 @@ func createScenarioCmd(projectSvc service.ProjectService, priceSvc service.Price
 				tfvarsContent := fmt.Sprintf("# 自动生成的最优价格配置\n")
 				tfvarsContent += fmt.Sprintf("region = \"%s\"\n", optimalConfig.Region)
 				tfvarsContent += fmt.Sprintf("instance_type = \"%s\"\n", optimalConfig.InstanceType)
-				tfvarsContent += fmt.Sprintf("# 价格: %.4f CNY/小时 (%.2f CNY/月)\n",
-					optimalConfig.Price, optimalConfig.PricePerMonth)
+				if optimalConfig.Zone != "" {
+					// 模板声明了 zone 变量时设置为场景变量，否则只能记录在注释中
+					zoneVars := map[string]interface{}{"zone": optimalConfig.Zone}
+					if err := projectSvc.SetScenarioVars(context.Background(), projectName, scenario.ID, zoneVars, nil); err != nil {
+						tfvarsContent += fmt.Sprintf("# 有库存的可用区: %s（模板未声明 zone 变量，由模板自行选择可用区）\n", optimalConfig.Zone)
+					} else {
+						fmt.Printf("  可用区: %s（已设置为场景变量 zone）\n", optimalConfig.Zone)
+					}
+				}
+				tfvarsContent += fmt.Sprintf("# 价格: %.4f %s/小时 (%.2f %s/月)\n",
+					optimalConfig.Price, optimalConfig.Currency, optimalConfig.PricePerMonth, optimalConfig.Currency)
 
 				if err := os.WriteFile(tfvarsPath, []byte(tfvarsContent), 0644); err == nil {
 					fmt.Printf("\n✨ 已自动应用最优价格配置到 %s\n", tfvarsPath)
 					fmt.Printf("  区域: %s\n", optimalConfig.Region)
 					fmt.Printf("  实例类型: %s\n", optimalConfig.InstanceType)
-					fmt.Printf("  价格: %.4f CNY/小时 (%.2f CNY/月)\n",
-						optimalConfig.Price, optimalConfig.PricePerMonth)
+					fmt.Printf("  价格: %.4f %s/小时 (%.2f %s/月)\n",
+						optimalConfig.Price, optimalConfig.Currency, optimalConfig.PricePerMonth, optimalConfig.Currency)
 				} else {
 					fmt.Printf("\n💡 价格优化建议（需要手动应用）:\n")
 					fmt.Printf("  编辑 %s/terraform.tfvars 文件添加:\n", scenario.Path)
@@ -355,10 +494,7 @@ func createScenarioCmd(projectSvc service.ProjectService, priceSvc service.Price
 			// 显示价格信息和建议
 			price, err := priceSvc.GetPrice(context.Background(), provider, templateName)
 			if err == nil {
-				monthPriceCNY := price.PricePerMonth
-				if price.Currency == "USD" {
-					monthPriceCNY = price.PricePerMonth * 7.2 // 简化汇率
-				}
+				monthPriceCNY := domain.ConvertToCNY(price.PricePerMonth, price.Currency)
 				fmt.Printf("\n💰 价格信息:\n")
 				fmt.Printf("  当前方案: %.2f %s/月 (%.4f %s/小时) ≈ %.2f CNY/月\n",
 					price.PricePerMonth, price.Currency,
@@ -371,10 +507,7 @@ func createScenarioCmd(projectSvc service.ProjectService, priceSvc service.Price
 				if templateType != "" {
 					bestOption, err := priceSvc.GetBestOption(context.Background(), templateType)
 					if err == nil && bestOption != nil {
-						bestMonthPriceCNY := bestOption.PricePerMonth
-						if bestOption.Currency == "USD" {
-							bestMonthPriceCNY = bestOption.PricePerMonth * 7.2
-						}
+						bestMonthPriceCNY := domain.ConvertToCNY(bestOption.PricePerMonth, bestOption.Currency)
 						if bestOption.Provider != provider || bestOption.Template != templateName {
 							fmt.Printf("\n💡 价格优化建议:\n")
 							fmt.Printf("  最优方案: %s/%s (%s)\n", bestOption.Provider, bestOption.Template, bestOption.Spec)
@@ -395,7 +528,7 @@ func createScenarioCmd(projectSvc service.ProjectService, priceSvc service.Price
 		},
 	}
 
-	cmd.Flags().BoolVarP(&useOptimal, "optimal", "o", false, "自动查找并应用最低价格配置（仅支持阿里云，需要配置 ALICLOUD_ACCESS_KEY 和 ALICLOUD_SECRET_KEY）")
+	cmd.Flags().BoolVarP(&useOptimal, "optimal", "o", false, "自动查找并应用最低价格配置（支持 aliyun, tencent, aws, huaweicloud，需要配置对应凭据）")
 	return cmd
 }
 
@@ -471,17 +604,20 @@ func createDynamicScenarioCmd(projectSvc service.ProjectService, priceSvc servic
 			// 如果启用了价格优化，查找最优配置
 			var selectedRegion string
 			var selectedInstanceType string
+			var selectedZone string
 
-			if useOptimal && provider == "aliyun" && priceOptimizerSvc != nil {
+			if useOptimal && priceOptimizerSvc != nil {
 				fmt.Println("正在查找最优价格配置...")
-				optimal, err := priceOptimizerSvc.FindOptimalConfig(context.Background(), provider, scenarioType, nil, nil)
+				// 动态生成的代理和任务执行模板都使用抢占式实例
+				optimal, err := priceOptimizerSvc.FindOptimalConfig(context.Background(), provider, scenarioType, nil, nil, true)
 				if err == nil && optimal != nil {
 					selectedRegion = optimal.Region
 					selectedInstanceType = optimal.InstanceType
+					selectedZone = optimal.Zone
 					fmt.Printf("✨ 找到最优配置:\n")
 					fmt.Printf("  区域: %s\n", optimal.Region)
 					fmt.Printf("  实例类型: %s\n", optimal.InstanceType)
-					fmt.Printf("  价格: %.4f CNY/小时 (%.2f CNY/月)\n", optimal.Price, optimal.PricePerMonth)
+					fmt.Printf("  价格: %.4f %s/小时 (%.2f %s/月)\n", optimal.Price, optimal.Currency, optimal.PricePerMonth, optimal.Currency)
 				} else {
 					fmt.Printf("⚠️  价格优化查询失败: %v，将使用默认配置\n", err)
 				}
@@ -550,12 +686,22 @@ func createDynamicScenarioCmd(projectSvc service.ProjectService, priceSvc servic
 				return fmt.Errorf("创建场景失败: %w", err)
 			}
 
+			if selectedZone != "" {
+				zoneVars := map[string]interface{}{"zone": selectedZone}
+				if err := projectSvc.SetScenarioVars(context.Background(), projectName, scenario.ID, zoneVars, nil); err != nil {
+					fmt.Printf("⚠️  设置可用区失败: %v，将由模板自动选择可用区\n", err)
+				}
+			}
+
 			fmt.Printf("\n✨ 动态场景创建成功\n")
 			fmt.Printf("ID: %s\n", scenario.ID)
 			fmt.Printf("名称: %s\n", scenario.Name)
 			fmt.Printf("模板: %s (动态生成)\n", scenario.Template)
 			fmt.Printf("区域: %s\n", selectedRegion)
 			fmt.Printf("实例类型: %s\n", selectedInstanceType)
+			if selectedZone != "" {
+				fmt.Printf("可用区: %s\n", selectedZone)
+			}
 			fmt.Printf("路径: %s\n", scenario.Path)
 			fmt.Printf("\n提示: 模板已动态生成，可以直接使用 terraform init 和 terraform apply 部署\n")
 
@@ -565,7 +711,7 @@ func createDynamicScenarioCmd(projectSvc service.ProjectService, priceSvc servic
 
 	cmd.Flags().StringVar(&instanceType, "instance-type", "", "指定实例类型")
 	cmd.Flags().IntVar(&nodeCount, "node-count", 0, "节点数量（仅对proxy场景有效）")
-	cmd.Flags().BoolVarP(&useOptimal, "optimal", "o", false, "自动查找并应用最低价格配置（仅支持阿里云）")
+	cmd.Flags().BoolVarP(&useOptimal, "optimal", "o", false, "自动查找并应用最低价格配置（支持 aliyun, tencent, aws, huaweicloud）")
 	return cmd
 }
 
@@ -592,7 +738,11 @@ func listScenariosCmd(projectSvc service.ProjectService) *cobra.Command {
 
 			fmt.Printf("项目 %s 的场景列表:\n", projectName)
 			for _, scenario := range scenarios {
-				fmt.Printf("  - %s [%s] - %s\n", scenario.ID, scenario.Status, scenario.Template)
+				if scenario.CreatedBy != "" {
+					fmt.Printf("  - %s [%s] - %s (%s)\n", scenario.ID, scenarioStatusLabel(scenario), scenario.Template, scenario.CreatedBy)
+				} else {
+					fmt.Printf("  - %s [%s] - %s\n", scenario.ID, scenarioStatusLabel(scenario), scenario.Template)
+				}
 			}
 			return nil
 		},
@@ -709,6 +859,7 @@ func printScenarioStatus(st *service.ScenarioStatus) {
 func deployScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
 	var autoApprove bool
 	var nodeCount int
+	var bidStrategy string
 
 	cmd := &cobra.Command{
 		Use:   "deploy <project> <scenario-id> [node-count] [tool-name] [tool-args...]",
@@ -718,8 +869,10 @@ func deployScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
 部署过程:
   1. 初始化 Terraform (terraform init)
   2. 验证配置 (terraform validate)
-  3. 预览变更 (terraform plan)
-  4. 应用变更 (terraform apply)
+  3. 生成计划 (terraform plan -out)
+  4. 应用该计划 (terraform apply <计划文件>)，保证应用的正是预览的变更
+
+如需先审核计划、稍后再应用，请使用 scenario plan 和 scenario apply --plan。
 
 参数说明:
   project      项目名称
@@ -734,11 +887,20 @@ func deployScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
   - 网络连接正常
   - 如需使用工具执行，需配置 OSS 相关变量
 
-注意: 默认会自动批准（--auto-approve），如需交互式确认请使用 --interactive 标志。`,
+抢占式实例出价策略（--bid-strategy，仅对声明了 spot_price_limit 变量的模板生效）:
+  p95[:上浮%]        最近 7 天抢占式实例价格的 P95 再上浮指定比例（默认 10%），以按量付费价格封顶
+  ondemand[:百分比]  按量付费价格的指定百分比（默认 100%）
+  fixed:<价格>       固定出价（每小时）
+计算出的出价上限会作为 spot_price_limit 变量传给 Terraform，并记录到场景元数据中。
+更换部署区域时按新区域的价格重新计算；不指定时清除之前部署记录的出价策略。
+
+注意: 默认会自动批准（--auto-approve），如需交互式确认请使用 --interactive 标志。
+交互式模式会先列出计划中新建、修改、替换和删除的资源，统计云主机数量并估算每小时费用，
+输入 yes 后才会应用该计划。`,
 		Example: `  # 自动部署（默认行为，跳过确认）
   cloudbot scenario deploy my-project <scenario-id>
   
-  # 交互式部署（会显示 plan 并询问确认）
+  # 交互式部署（显示变更摘要和预估费用并询问确认）
   cloudbot scenario deploy my-project <scenario-id> --interactive
 
   # 指定节点数量（覆盖模板中的 node_count）
@@ -752,7 +914,13 @@ func deployScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
   
   # 指定区域（aliyun-proxy 模板）
   cloudbot scenario deploy my-project <scenario-id> --region bj
-  cloudbot scenario deploy my-project <scenario-id> --region sh --node 10`,
+  cloudbot scenario deploy my-project <scenario-id> --region sh --node 10
+
+  # 按最近 7 天 P95 价格上浮 20% 设置抢占式实例出价上限
+  cloudbot scenario deploy my-project <scenario-id> --bid-strategy p95:20
+
+  # 按按量付费价格的 80% 出价
+  cloudbot scenario deploy my-project <scenario-id> --bid-strategy ondemand:80`,
 		Args: cobra.MinimumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectName := args[0]
@@ -797,8 +965,16 @@ func deployScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
 			toolArgsStr := strings.Join(toolArgs, " ")
 
 			// 区域参数传空字符串，因为区域在创建场景时已确定
-			if err := projectSvc.DeployScenario(context.Background(), projectName, scenarioID, autoApprove, parsedNodeCount, toolName, toolArgsStr, ""); err != nil {
-				return err
+			opts := service.DeployOptions{
+				NodeCount:   parsedNodeCount,
+				ToolName:    toolName,
+				ToolArgs:    toolArgsStr,
+				BidStrategy: bidStrategy,
+			}
+			ctx, stop := interruptibleContext()
+			defer stop()
+			if err := projectSvc.DeployScenarioWithOptions(ctx, projectName, scenarioID, autoApprove, opts); err != nil {
+				return withInterruptedHint(err, projectName, scenarioID)
 			}
 
 			fmt.Printf("场景 %s 部署成功", scenarioID)
@@ -812,19 +988,27 @@ func deployScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
 				}
 			}
 			fmt.Println()
+
+			if bidStrategy != "" {
+				if scenario, err := projectSvc.GetScenario(context.Background(), projectName, scenarioID); err == nil && scenario.BidStrategy != "" {
+					fmt.Printf("抢占式实例出价上限: %.4f/小时（策略: %s）\n", scenario.SpotPriceLimit, scenario.BidStrategy)
+				}
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVarP(&autoApprove, "auto-approve", "y", true, "自动批准，跳过确认（默认启用）")
-	cmd.Flags().BoolP("interactive", "i", false, "交互式模式，显示 plan 并询问确认（会覆盖 --auto-approve）")
+	cmd.Flags().BoolP("interactive", "i", false, "交互式模式，显示变更摘要和预估费用并询问确认（会覆盖 --auto-approve）")
 	cmd.Flags().IntVarP(&nodeCount, "node", "n", 0, "指定节点数量（覆盖模板中的 node_count，0 表示使用默认/随机值）")
+	cmd.Flags().StringVar(&bidStrategy, "bid-strategy", "", "抢占式实例出价策略: p95[:上浮%], ondemand[:百分比], fixed:<价格>")
 	return cmd
 }
 
 // destroyScenarioCmd 销毁场景命令
 func destroyScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
 	var autoApprove bool
+	var targets []string
 
 	cmd := &cobra.Command{
 		Use:   "destroy <project> <scenario-id>",
@@ -837,19 +1021,33 @@ func destroyScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
   - 安全组
   - 其他相关资源
 
-此操作不可逆，请谨慎操作。`,
+此操作不可逆，请谨慎操作。
+
+使用 --target 只销毁指定资源（资源地址或节点序号，与 scenario replace 相同），其他资源保持不变。`,
 		Example: `  # 交互式销毁（会询问确认）
   cloudbot scenario destroy my-project <scenario-id>
   
   # 自动销毁（跳过确认）
-  cloudbot scenario destroy my-project <scenario-id> --auto-approve`,
+  cloudbot scenario destroy my-project <scenario-id> --auto-approve
+
+  # 只销毁第 3 个节点
+  cloudbot scenario destroy my-project <scenario-id> --target 2`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectName := args[0]
 			scenarioID := args[1]
 
-			if err := projectSvc.DestroyScenario(context.Background(), projectName, scenarioID, autoApprove); err != nil {
-				return err
+			ctx, stop := interruptibleContext()
+			defer stop()
+			if len(targets) > 0 {
+				if err := projectSvc.DestroyScenarioResources(ctx, projectName, scenarioID, targets, autoApprove); err != nil {
+					return withInterruptedHint(err, projectName, scenarioID)
+				}
+				fmt.Printf("场景 %s 的资源已销毁: %s\n", scenarioID, strings.Join(targets, ", "))
+				return nil
+			}
+			if err := projectSvc.DestroyScenario(ctx, projectName, scenarioID, autoApprove); err != nil {
+				return withInterruptedHint(err, projectName, scenarioID)
 			}
 
 			fmt.Printf("场景 %s 销毁成功\n", scenarioID)
@@ -858,6 +1056,7 @@ func destroyScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&autoApprove, "auto-approve", "y", false, "自动批准，跳过确认")
+	cmd.Flags().StringSliceVar(&targets, "target", nil, "只销毁指定资源（资源地址或节点序号，可重复）")
 	return cmd
 }
 
@@ -902,23 +1101,61 @@ func listTemplatesCmd(templateRepo repository.TemplateRepository) *cobra.Command
 
 // comparePriceCmd 价格比对命令
 func comparePriceCmd(priceSvc service.PriceService) *cobra.Command {
+	var vcpu, maxTypes int
+	var memory float64
+	var spot bool
+	var regions, providers []string
+
 	cmd := &cobra.Command{
-		Use:   "compare <template-type>",
-		Short: "比对指定类型模板的价格",
-		Long: `比对指定类型模板在不同云服务商之间的价格，找出最优方案。
+		Use:   "compare [template-type]",
+		Short: "比对指定规格或模板类型的价格",
+		Long: `比对不同云服务商之间的价格，找出最优方案。
+
+按规格比价（推荐）:
+  通过 --vcpu/--mem 指定规格需求，查询所有已配置凭据的云服务商中满足规格的实例类型，
+  按实时价格统一换算为人民币后排序，输出具体的实例类型和可用区。
+
+按模板类型比价:
+  不指定 --vcpu/--mem 时，按模板类型比对价格表中的模板价格。
 
 支持的模板类型:
   - ecs: ECS 云服务器
   - proxy: 代理服务器
   - ec2: AWS EC2 实例
   - vps: VPS 服务器`,
-		Example: `  # 比对 ECS 类型模板的价格
+		Example: `  # 比对 2核4G 抢占式实例在国内和亚太区域的价格
+  cloudbot price compare --vcpu 2 --mem 4 --spot --regions cn-*,ap-*
+
+  # 只比较阿里云和腾讯云
+  cloudbot price compare --vcpu 1 --mem 2 --providers aliyun,tencent
+
+  # 比对 ECS 类型模板的价格
   cloudbot price compare ecs
   
   # 比对代理服务器类型模板的价格
   cloudbot price compare proxy`,
-		Args: cobra.ExactArgs(1),
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if vcpu > 0 || memory > 0 {
+				req := service.SpecRequirement{
+					VCPU:          vcpu,
+					MemoryGB:      memory,
+					Spot:          spot,
+					Regions:       regions,
+					Providers:     providers,
+					MaxCandidates: maxTypes,
+				}
+				fmt.Println("正在查询各云服务商满足规格的实例价格...")
+				comparison, err := priceSvc.CompareBySpec(context.Background(), req)
+				if comparison != nil {
+					printSpecComparison(comparison)
+				}
+				return err
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("请指定模板类型，或使用 --vcpu/--mem 按规格比价")
+			}
 			templateType := args[0]
 
 			comparison, err := priceSvc.ComparePrices(context.Background(), templateType)
@@ -932,6 +1169,11 @@ func comparePriceCmd(priceSvc service.PriceService) *cobra.Command {
 				comparison.PriceRange.MaxPerMonth,
 				comparison.PriceRange.MinPerHour,
 				comparison.PriceRange.MaxPerHour)
+			var currencies []string
+			for _, option := range comparison.Options {
+				currencies = append(currencies, option.Currency)
+			}
+			printUSDRateNote(currencies)
 			fmt.Println()
 
 			if comparison.BestOption != nil {
@@ -962,9 +1204,78 @@ func comparePriceCmd(priceSvc service.PriceService) *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().IntVar(&vcpu, "vcpu", 0, "最少 vCPU 核数（按规格比价）")
+	cmd.Flags().Float64Var(&memory, "mem", 0, "最少内存大小，单位 GB（按规格比价）")
+	cmd.Flags().BoolVar(&spot, "spot", false, "按抢占式实例价格比较（华为云不提供抢占式实例询价，会被跳过）")
+	cmd.Flags().StringSliceVar(&regions, "regions", nil, "区域过滤，支持通配符（如 cn-*,ap-*）")
+	cmd.Flags().StringSliceVar(&providers, "providers", nil, "云服务商过滤（默认所有已配置凭据的云服务商）")
+	cmd.Flags().IntVar(&maxTypes, "max-types", 0, "每个区域最多比较的实例类型数量（默认 5）")
 	return cmd
 }
 
+// printUSDRateNote 比价结果中有美元价格时注明换算人民币使用的汇率
+func printUSDRateNote(currencies []string) {
+	for _, currency := range currencies {
+		if currency == "USD" {
+			fmt.Printf("美元价格按 1 USD = %g CNY 换算（可在配置文件 [price] 小节的 usd_to_cny 修改）\n", domain.USDToCNY())
+			return
+		}
+	}
+}
+
+// printSpecComparison 打印按规格比价结果
+func printSpecComparison(comparison *service.SpecComparison) {
+	req := comparison.Requirement
+	billing := "按量付费"
+	if req.Spot {
+		billing = "抢占式实例"
+	}
+	fmt.Printf("规格需求: >= %d vCPU, >= %.1f GB 内存 (%s)\n", req.VCPU, req.MemoryGB, billing)
+	var currencies []string
+	for _, option := range comparison.Options {
+		currencies = append(currencies, option.Currency)
+	}
+	printUSDRateNote(currencies)
+	fmt.Println()
+
+	if len(comparison.Options) > 0 {
+		best := comparison.Options[0]
+		fmt.Printf("✨ 最优方案: %s %s\n", best.Provider, best.InstanceType)
+		fmt.Printf("   区域/可用区: %s / %s\n", best.Region, displayZone(best.Zone))
+		fmt.Printf("   价格: %.4f %s/小时 ≈ %.2f CNY/月\n", best.PricePerHour, best.Currency, best.PricePerMonthCNY)
+		fmt.Println()
+
+		fmt.Println("所有可选方案（按人民币价格从低到高）:")
+		for i, option := range comparison.Options {
+			marker := "  "
+			if i == 0 {
+				marker = "⭐ "
+			}
+			fmt.Printf("%s%d. %s %s (%d核%.0fG)\n", marker, i+1, option.Provider, option.InstanceType, option.CPU, option.Memory)
+			fmt.Printf("     区域/可用区: %s / %s\n", option.Region, displayZone(option.Zone))
+			fmt.Printf("     价格: %.4f %s/小时 ≈ %.4f CNY/小时 (%.2f CNY/月)\n",
+				option.PricePerHour, option.Currency,
+				option.PricePerHourCNY, option.PricePerMonthCNY)
+		}
+	}
+
+	if len(comparison.Skipped) > 0 {
+		fmt.Printf("\n以下查询被跳过 (%d):\n", len(comparison.Skipped))
+		for _, reason := range comparison.Skipped {
+			fmt.Printf("  - %s\n", reason)
+		}
+	}
+}
+
+// displayZone 返回可用区的显示名称
+func displayZone(zone string) string {
+	if zone == "" {
+		return "任意可用区"
+	}
+	return zone
+}
+
 // listPriceCmd 列出所有价格信息命令
 func listPriceCmd(priceSvc service.PriceService) *cobra.Command {
 	cmd := &cobra.Command{
@@ -994,10 +1305,7 @@ func listPriceCmd(priceSvc service.PriceService) *cobra.Command {
 			for provider, priceList := range providerMap {
 				fmt.Printf("\n%s:\n", provider)
 				for _, price := range priceList {
-					monthPriceCNY := price.PricePerMonth
-					if price.Currency == "USD" {
-						monthPriceCNY = price.PricePerMonth * 7.2 // 简化汇率
-					}
+					monthPriceCNY := domain.ConvertToCNY(price.PricePerMonth, price.Currency)
 					fmt.Printf("  - %s (%s)\n", price.Template, price.Spec)
 					fmt.Printf("    价格: %.2f %s/月 (%.4f %s/小时) ≈ %.2f CNY/月\n",
 						price.PricePerMonth, price.Currency,
@@ -1013,6 +1321,147 @@ func listPriceCmd(priceSvc service.PriceService) *cobra.Command {
 	return cmd
 }
 
+// exportPriceCmd 导出价格表命令
+func exportPriceCmd(priceSvc service.PriceService) *cobra.Command {
+	var format string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "导出价格表",
+		Long:  "将当前价格表（prices.json）以 json 或 csv 格式导出到标准输出或文件。",
+		Example: `  # 以 CSV 格式导出到标准输出
+  cloudbot price export --format csv
+
+  # 导出到文件
+  cloudbot price export --format json --output prices-backup.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output == "" {
+				return priceSvc.ExportPrices(context.Background(), os.Stdout, format)
+			}
+
+			file, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("创建导出文件失败: %w", err)
+			}
+			defer file.Close()
+
+			if err := priceSvc.ExportPrices(context.Background(), file, format); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ 价格表已导出到 %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", service.PriceFormatJSON, "导出格式（json, csv）")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "导出文件路径（默认输出到标准输出）")
+
+	return cmd
+}
+
+// importPriceCmd 导入价格表命令
+func importPriceCmd(priceSvc service.PriceService) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "导入价格表",
+		Long: `导入 json 或 csv 格式的价格表并与现有数据合并。
+每条记录都会按价格信息格式校验，任意一条无效时不做任何修改；
+相同云服务商/模板/区域的记录保留更新时间（updated_at）较新的一条。`,
+		Example: `  # 导入 CSV 价格表（格式根据扩展名识别）
+  cloudbot price import prices.csv
+
+  # 显式指定格式
+  cloudbot price import prices.txt --format json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+			if format == "" {
+				format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("打开价格表失败: %w", err)
+			}
+			defer file.Close()
+
+			result, err := priceSvc.ImportPrices(context.Background(), file, format)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("✓ 价格表导入完成: 新增 %d 条，更新 %d 条，未变化 %d 条\n",
+				result.Added, result.Updated, result.Unchanged)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "", "价格表格式（json, csv，默认根据文件扩展名识别）")
+
+	return cmd
+}
+
+// refreshPriceCmd 刷新价格表命令
+func refreshPriceCmd(priceSvc service.PriceService) *cobra.Command {
+	var providers string
+
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "从云服务商实时刷新价格表",
+		Long: `按配置文件中的价格刷新矩阵，从云服务商实时价格接口查询按量付费价格并合并到价格表。
+
+配置示例（.redc.ini）:
+  [price_refresh.aliyun]
+  regions = cn-beijing,cn-shanghai
+  instance_types = ecs:ecs.t5-lc1m2.small,ecs.t6-c1m1.large
+
+instance_types 中的 "模板:实例类型" 表示将价格记录为指定模板的价格。`,
+		Example: `  # 刷新所有已配置的云服务商
+  cloudbot price refresh
+
+  # 只刷新阿里云和 AWS
+  cloudbot price refresh --providers aliyun,aws`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			targets := cfg.PriceRefresh
+			if providers != "" {
+				wanted := make(map[string]bool)
+				for _, p := range strings.Split(providers, ",") {
+					wanted[strings.TrimSpace(p)] = true
+				}
+				targets = nil
+				for _, target := range cfg.PriceRefresh {
+					if wanted[target.Provider] {
+						targets = append(targets, target)
+					}
+				}
+			}
+
+			fmt.Println("正在从云服务商刷新价格...")
+			result, err := priceSvc.RefreshPrices(context.Background(), targets)
+			if result != nil && len(result.Skipped) > 0 {
+				fmt.Printf("\n以下组合已跳过（%d 个）:\n", len(result.Skipped))
+				for _, reason := range result.Skipped {
+					fmt.Printf("  - %s\n", reason)
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("\n✓ 价格表刷新完成: 新增 %d 条，更新 %d 条，未变化 %d 条\n",
+				result.Added, result.Updated, result.Unchanged)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&providers, "providers", "", "只刷新指定的云服务商（逗号分隔）")
+
+	return cmd
+}
+
 // getTemplateType 根据模板名称推断模板类型
 func getTemplateType(templateName string) string {
 	// 简单的类型推断逻辑
@@ -1051,17 +1500,28 @@ func findOptimalCmd(priceOptimizerSvc service.PriceOptimizerService) *cobra.Comm
 		Short: "查找最低价格的区域和实例类型配置",
 		Long: `通过调用云服务商 API 查询实时价格，找出最低价格的区域和实例类型配置。
 
+推荐前会校验实例类型在各可用区的库存和配额（阿里云 DescribeAvailableResource、
+腾讯云 DescribeZoneInstanceConfigInfos、AWS DescribeInstanceTypeOfferings），
+只推荐实际可以创建的部署位置，并列出被跳过的组合及原因。
+
 支持的云服务商:
   - aliyun: 使用阿里云 DescribePrice API
+  - tencent: 使用腾讯云 InquiryPriceRunInstances API
+  - aws: 使用 AWS Price List API (GetProducts)
+  - huaweicloud: 使用华为云按需产品询价 API
 
-需要配置环境变量:
-  - ALICLOUD_ACCESS_KEY: 阿里云 AccessKey ID
-  - ALICLOUD_SECRET_KEY: 阿里云 SecretKey`,
+需要先通过 cloudbot credential set <provider> 配置对应云服务商的凭据。`,
 		Example: `  # 查找阿里云 ECS 的最优配置
   cloudbot price optimal aliyun ecs
   
   # 查找指定实例类型的最优配置
-  cloudbot price optimal aliyun ecs --instance-types ecs.t5-lc1m1.small,ecs.t5-lc1m2.small`,
+  cloudbot price optimal aliyun ecs --instance-types ecs.t5-lc1m1.small,ecs.t5-lc1m2.small
+
+  # 查找 AWS 指定区域的最优配置
+  cloudbot price optimal aws ec2 --regions us-east-1,us-west-2
+
+  # 查找有库存的最便宜抢占式实例
+  cloudbot price optimal aliyun ecs --spot`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			provider := args[0]
@@ -1069,19 +1529,42 @@ func findOptimalCmd(priceOptimizerSvc service.PriceOptimizerService) *cobra.Comm
 
 			instanceTypes, _ := cmd.Flags().GetStringSlice("instance-types")
 			regions, _ := cmd.Flags().GetStringSlice("regions")
+			spot, _ := cmd.Flags().GetBool("spot")
 
-			optimal, err := priceOptimizerSvc.FindOptimalConfig(context.Background(), provider, template, instanceTypes, regions)
+			result, err := priceOptimizerSvc.FindPlacements(context.Background(), provider, template, instanceTypes, regions, spot)
 			if err != nil {
+				if result != nil {
+					printSkippedPlacements(result.Skipped)
+				}
 				return fmt.Errorf("查找最优配置失败: %w", err)
 			}
 
+			optimal := result.Placements[0]
 			fmt.Printf("✨ 最优价格配置:\n\n")
 			fmt.Printf("  云服务商: %s\n", provider)
 			fmt.Printf("  模板: %s\n", template)
 			fmt.Printf("  区域: %s\n", optimal.Region)
+			fmt.Printf("  可用区: %s\n", displayZone(optimal.Zone))
 			fmt.Printf("  实例类型: %s\n", optimal.InstanceType)
-			fmt.Printf("  价格: %.4f %s/小时\n", optimal.Price, optimal.Currency)
+			fmt.Printf("  价格: %.4f %s/小时\n", optimal.PricePerHour, optimal.Currency)
 			fmt.Printf("  月价格: %.2f %s/月\n", optimal.PricePerMonth, optimal.Currency)
+
+			fmt.Printf("\n可用部署位置（按价格从低到高）:\n")
+			for i, p := range result.Placements {
+				marker := "  "
+				if i == 0 {
+					marker = "⭐ "
+				}
+				verified := "已校验库存"
+				if !p.Verified {
+					verified = "未校验库存"
+				}
+				fmt.Printf("%s%d. %s / %s / %s  %.4f %s/小时 (%s)\n",
+					marker, i+1, p.Region, displayZone(p.Zone), p.InstanceType, p.PricePerHour, p.Currency, verified)
+			}
+
+			printSkippedPlacements(result.Skipped)
+
 			fmt.Printf("\n使用方式:\n")
 			fmt.Printf("  terraform apply -var=\"region=%s\" -var=\"instance_type=%s\"\n",
 				optimal.Region, optimal.InstanceType)
@@ -1092,9 +1575,21 @@ func findOptimalCmd(priceOptimizerSvc service.PriceOptimizerService) *cobra.Comm
 
 	cmd.Flags().StringSlice("instance-types", nil, "要比较的实例类型列表（逗号分隔）")
 	cmd.Flags().StringSlice("regions", nil, "要比较的区域列表（逗号分隔）")
+	cmd.Flags().Bool("spot", false, "按抢占式实例价格和库存查找")
 	return cmd
 }
 
+// printSkippedPlacements 打印被跳过的部署位置及原因
+func printSkippedPlacements(skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+	fmt.Printf("\n以下组合因无库存或查询失败被跳过 (%d):\n", len(skipped))
+	for _, reason := range skipped {
+		fmt.Printf("  - %s\n", reason)
+	}
+}
+
 // listRegionPricesCmd 列出各区域价格并标注最低价
 func listRegionPricesCmd(priceOptimizerSvc service.PriceOptimizerService) *cobra.Command {
 	cmd := &cobra.Command{
@@ -1104,10 +1599,11 @@ func listRegionPricesCmd(priceOptimizerSvc service.PriceOptimizerService) *cobra
 
 支持的云服务商:
   - aliyun: 使用阿里云 DescribePrice API
+  - tencent: 使用腾讯云 InquiryPriceRunInstances API
+  - aws: 使用 AWS Price List API (GetProducts)
+  - huaweicloud: 使用华为云按需产品询价 API
 
-需要环境变量:
-  - ALICLOUD_ACCESS_KEY: 阿里云 AccessKey ID
-  - ALICLOUD_SECRET_KEY: 阿里云 SecretKey`,
+需要先通过 cloudbot credential set <provider> 配置对应云服务商的凭据。`,
 		Example: `  # 列出阿里云 ECS 在常用区域的价格
   cloudbot price regions aliyun ecs
 
@@ -1124,7 +1620,7 @@ func listRegionPricesCmd(priceOptimizerSvc service.PriceOptimizerService) *cobra
 			regions, _ := cmd.Flags().GetStringSlice("regions")
 
 			if priceOptimizerSvc == nil {
-				return fmt.Errorf("价格优化器未初始化，请配置 ALICLOUD_ACCESS_KEY 和 ALICLOUD_SECRET_KEY")
+				return fmt.Errorf("价格优化器未初始化")
 			}
 
 			prices, err := priceOptimizerSvc.ListRegionPrices(context.Background(), provider, template, instanceTypes, regions)
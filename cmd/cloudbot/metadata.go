@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/repository"
+)
+
+// defaultMetadataPrefix 共享元数据在对象存储中的默认前缀
+const defaultMetadataPrefix = "cloudbot"
+
+// newProjectRepository 按 [metadata] 配置创建项目仓库
+// 默认使用本地项目目录；type = s3 时项目和场景元数据保存在团队共享的对象存储中
+func newProjectRepository(cfg *config.Config, credManager credentials.CredentialManager) (repository.ProjectRepository, error) {
+	metadata := cfg.Metadata
+	switch metadata.Type {
+	case "", config.MetadataLocal:
+		return repository.NewProjectRepository(cfg), nil
+	case config.MetadataS3:
+	default:
+		return nil, fmt.Errorf("不支持的元数据存储类型: %s（支持 local, s3）", metadata.Type)
+	}
+
+	opts := metadata.Options
+	provider := credentials.Provider(opts["credentials"])
+	if opts["access_key"] == "" && !provider.IsValid() {
+		return nil, fmt.Errorf("[metadata] 需要配置 credentials（云服务商）或 access_key / secret_key")
+	}
+
+	store, err := repository.NewS3ObjectStore(repository.S3StoreConfig{
+		Endpoint:  opts["endpoint"],
+		Region:    opts["region"],
+		Bucket:    opts["bucket"],
+		PathStyle: opts["path_style"] == "true",
+		Credentials: func() (*credentials.Credentials, error) {
+			if opts["access_key"] != "" {
+				return &credentials.Credentials{AccessKey: opts["access_key"], SecretKey: opts["secret_key"]}, nil
+			}
+			return credManager.GetCredentials(provider)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("初始化元数据存储失败: %w", err)
+	}
+
+	prefix := opts["prefix"]
+	if prefix == "" {
+		prefix = defaultMetadataPrefix
+	}
+	return repository.NewSharedProjectRepository(cfg, store, prefix), nil
+}
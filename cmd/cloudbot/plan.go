@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lucksec/cloudbot/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// planScenarioCmd 生成并保存场景的部署计划
+func planScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
+	var nodeCount int
+	var bidStrategy string
+
+	cmd := &cobra.Command{
+		Use:   "plan <project> <scenario-id>",
+		Short: "生成并保存场景的部署计划",
+		Long: `执行 terraform plan -out，将部署计划保存到场景目录的 plans 子目录中。
+变量的构建方式与 scenario deploy 相同。审核计划后使用 scenario apply --plan 应用，
+应用的正是审核过的计划；如果场景配置、变量或状态在生成计划后发生了变化，应用会被拒绝。
+aliyun-proxy 场景和腾讯云抢占式实例多节点部署按区域分步部署，不支持保存计划。`,
+		Example: `  # 生成计划
+  cloudbot scenario plan my-project <scenario-id> --node 5
+
+  # 应用计划
+  cloudbot scenario apply my-project <scenario-id> --plan <plan-id>`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := service.DeployOptions{
+				NodeCount:   nodeCount,
+				BidStrategy: bidStrategy,
+			}
+			ctx, stop := interruptibleContext()
+			defer stop()
+			plan, err := projectSvc.PlanScenario(ctx, args[0], args[1], opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("\n计划已保存: %s\n", plan.ID)
+			fmt.Printf("审核后应用: cloudbot scenario apply %s %s --plan %s\n", args[0], args[1], plan.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&nodeCount, "node", "n", 0, "指定节点数量（覆盖模板中的 node_count，0 表示使用默认/随机值）")
+	cmd.Flags().StringVar(&bidStrategy, "bid-strategy", "", "抢占式实例出价策略: p95[:上浮%], ondemand[:百分比], fixed:<价格>")
+	return cmd
+}
+
+// applyScenarioCmd 应用保存的部署计划
+func applyScenarioCmd(projectSvc service.ProjectService) *cobra.Command {
+	var planID string
+
+	cmd := &cobra.Command{
+		Use:   "apply <project> <scenario-id> --plan <plan-id>",
+		Short: "应用 scenario plan 保存的部署计划",
+		Long: `应用 scenario plan 保存的部署计划。
+场景配置、变量或 Terraform 状态在生成计划后发生变化时，计划视为过期，拒绝应用。
+计划应用成功后会被删除，不能重复应用。不指定 --plan 时列出场景保存的计划。`,
+		Example: `  # 查看场景保存的计划
+  cloudbot scenario apply my-project <scenario-id>
+
+  # 应用计划
+  cloudbot scenario apply my-project <scenario-id> --plan 20240101-120000`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectName, scenarioID := args[0], args[1]
+
+			if planID == "" {
+				plans, err := projectSvc.ListScenarioPlans(context.Background(), projectName, scenarioID)
+				if err != nil {
+					return err
+				}
+				if len(plans) == 0 {
+					return fmt.Errorf("场景 %s 没有保存的计划，请先运行 'cloudbot scenario plan %s %s'", scenarioID, projectName, scenarioID)
+				}
+				fmt.Printf("场景 %s 保存的计划:\n", scenarioID)
+				for _, plan := range plans {
+					fmt.Printf("  - %s (生成于 %s)\n", plan.ID, plan.CreatedAt.Format("2006-01-02 15:04:05"))
+				}
+				return fmt.Errorf("请使用 --plan 指定要应用的计划")
+			}
+
+			ctx, stop := interruptibleContext()
+			defer stop()
+			if err := projectSvc.ApplyScenarioPlan(ctx, projectName, scenarioID, planID); err != nil {
+				return withInterruptedHint(err, projectName, scenarioID)
+			}
+
+			fmt.Printf("场景 %s 已按计划 %s 部署\n", scenarioID, planID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&planID, "plan", "", "要应用的计划 ID（scenario plan 输出）")
+	return cmd
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// providersCmd Terraform provider 管理命令组
+func providersCmd(cfg *config.Config, terraformSvc service.TerraformService) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "providers",
+		Short: "Terraform provider 缓存和镜像管理",
+	}
+	cmd.AddCommand(providersListCmd(cfg))
+	cmd.AddCommand(providersMirrorCmd(cfg, terraformSvc))
+	return cmd
+}
+
+// providersListCmd 列出模板使用的 provider 命令
+func providersListCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出模板 required_providers 中声明的 provider 及缓存和镜像配置",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			providers, err := service.CollectTemplateProviders(cfg.TemplateDir)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "名称\t来源\t版本约束")
+			for _, p := range providers {
+				version := p.Version
+				if version == "" {
+					version = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.Source, version)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Printf("\n插件缓存目录: %s\n", cfg.Terraform.PluginCacheDir)
+			if cfg.Terraform.ProviderMirror != "" {
+				fmt.Printf("本地镜像目录: %s\n", cfg.Terraform.ProviderMirror)
+			}
+			if cfg.Terraform.ProviderNetworkMirror != "" {
+				fmt.Printf("网络镜像地址: %s\n", cfg.Terraform.ProviderNetworkMirror)
+			}
+			return nil
+		},
+	}
+}
+
+// providersMirrorCmd 生成 provider 本地镜像命令
+func providersMirrorCmd(cfg *config.Config, terraformSvc service.TerraformService) *cobra.Command {
+	var platforms []string
+
+	cmd := &cobra.Command{
+		Use:   "mirror [dir]",
+		Short: "将模板使用的 provider 下载到本地镜像目录",
+		Long: `收集模板目录和动态模板 required_providers 中声明的 provider，通过 terraform providers mirror 下载到本地镜像目录。
+未指定目录时使用配置项 [terraform] provider_mirror。
+
+在可以联网的机器上生成镜像后，将目录复制到受限网络环境，并在配置文件中设置:
+
+  [terraform]
+  provider_mirror = /path/to/mirror
+
+之后 terraform init 优先从镜像目录安装 provider，镜像中没有的 provider 仍从官方 registry 下载。`,
+		Example: `  # 下载到配置的镜像目录
+  cloudbot providers mirror
+
+  # 下载到指定目录，同时包含 Linux 和 macOS 平台
+  cloudbot providers mirror ./provider-mirror --platform linux_amd64 --platform darwin_arm64`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := cfg.Terraform.ProviderMirror
+			if len(args) == 1 {
+				target = args[0]
+			}
+			if target == "" {
+				return fmt.Errorf("请指定镜像目录，或在配置文件 [terraform] 小节中设置 provider_mirror")
+			}
+
+			providers, err := service.CollectTemplateProviders(cfg.TemplateDir)
+			if err != nil {
+				return err
+			}
+			if len(providers) == 0 {
+				fmt.Println("模板中没有声明 required_providers")
+				return nil
+			}
+			if len(platforms) == 0 {
+				platforms = []string{service.DefaultProviderPlatform()}
+			}
+
+			if err := service.MirrorProviders(context.Background(), terraformSvc, providers, target, platforms); err != nil {
+				return err
+			}
+			fmt.Printf("已将 %d 个 provider 下载到 %s\n", len(providers), target)
+			if len(args) == 1 && args[0] != cfg.Terraform.ProviderMirror {
+				fmt.Printf("在配置文件 [terraform] 小节中设置 provider_mirror = %s 以使用该镜像\n", target)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&platforms, "platform", nil, "目标平台（如 linux_amd64），可指定多次，默认为当前平台")
+
+	return cmd
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// scenarioRunsCmd 列出场景运行记录命令
+func scenarioRunsCmd(projectSvc service.ProjectService) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "runs <project> <scenario-id>",
+		Short: "列出场景的 Terraform 运行记录",
+		Long: `列出场景执行过的 init、plan、apply、destroy、taint、untaint 命令（保存在场景目录的 .runs 子目录，保留最近 100 次）。
+使用 scenario logs 查看某次运行的完整输出。`,
+		Example: `  cloudbot scenario runs my-project <scenario-id>`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runs, err := projectSvc.ListScenarioRuns(context.Background(), args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				fmt.Printf("场景 %s 没有运行记录\n", args[1])
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "运行 ID\t操作\t开始时间\t耗时\t退出码")
+			for _, run := range runs {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", run.ID, run.Op,
+					run.StartedAt.Format("2006-01-02 15:04:05"), formatRunDuration(run), run.ExitCode)
+			}
+			return w.Flush()
+		},
+	}
+
+	return cmd
+}
+
+// scenarioLogsCmd 查看场景运行日志命令
+func scenarioLogsCmd(projectSvc service.ProjectService) *cobra.Command {
+	var tail int
+
+	cmd := &cobra.Command{
+		Use:   "logs <project> <scenario-id> [run-id]",
+		Short: "查看场景某次 Terraform 运行的输出",
+		Long:  "显示运行记录和 Terraform 的完整输出（标准输出和标准错误）。不指定运行 ID 时显示最近一次运行。",
+		Example: `  # 查看最近一次运行
+  cloudbot scenario logs my-project <scenario-id>
+
+  # 查看指定运行的最后 50 行
+  cloudbot scenario logs my-project <scenario-id> 20240101-120000-apply --tail 50`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runID := ""
+			if len(args) == 3 {
+				runID = args[2]
+			}
+
+			run, logPath, err := projectSvc.GetScenarioRun(context.Background(), args[0], args[1], runID)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(logPath)
+			if err != nil {
+				return fmt.Errorf("读取运行日志失败: %w", err)
+			}
+
+			fmt.Printf("运行 ID: %s\n", run.ID)
+			fmt.Printf("命令:    terraform %s\n", strings.Join(run.Args, " "))
+			fmt.Printf("开始:    %s（耗时 %s）\n", run.StartedAt.Format("2006-01-02 15:04:05"), formatRunDuration(run))
+			fmt.Printf("退出码:  %d\n", run.ExitCode)
+			if run.Error != "" {
+				fmt.Printf("错误:    %s\n", run.Error)
+			}
+			fmt.Println(strings.Repeat("-", 60))
+
+			output := string(data)
+			if tail > 0 {
+				lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+				if len(lines) > tail {
+					lines = lines[len(lines)-tail:]
+				}
+				output = strings.Join(lines, "\n") + "\n"
+			}
+			fmt.Print(output)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&tail, "tail", 0, "只显示最后 N 行")
+
+	return cmd
+}
+
+// formatRunDuration 格式化运行耗时
+func formatRunDuration(run *domain.ScenarioRun) string {
+	return (time.Duration(run.DurationMs) * time.Millisecond).Round(100 * time.Millisecond).String()
+}
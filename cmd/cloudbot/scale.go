@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lucksec/cloudbot/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// scenarioScaleCmd 调整场景节点数命令
+func scenarioScaleCmd(projectSvc service.ProjectService) *cobra.Command {
+	var autoApprove bool
+
+	cmd := &cobra.Command{
+		Use:   "scale <project> <scenario-id> <count>",
+		Short: "调整已部署场景的节点数",
+		Long: `以新的 node_count 重新部署场景，增加或减少节点，其他节点保持不变。
+新的节点数记录在场景中，之后的 scenario deploy 沿用该节点数。
+
+缩容时按以下顺序选择要移除的节点：
+  1. 不健康的节点（已停止，或 scenario drift 检测到已在云端删除）
+  2. 价格较高的节点（节点的实例类型或计费方式不同时）
+  3. 序号较大的节点
+选中的节点通过定向销毁移除，剩余节点的序号调整为连续序号（只修改 Terraform 状态，不会重建节点）。
+
+模板需要声明 node_count 变量，节点资源使用 count 创建。`,
+		Example: `  # 扩容到 5 个节点
+  cloudbot scenario scale my-project <scenario-id> 5
+
+  # 缩容到 2 个节点，跳过确认
+  cloudbot scenario scale my-project <scenario-id> 2 -y`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectName, scenarioID := args[0], args[1]
+			count, err := strconv.Atoi(args[2])
+			if err != nil {
+				return fmt.Errorf("无效的节点数: %s", args[2])
+			}
+
+			ctx, stop := interruptibleContext()
+			defer stop()
+			if err := projectSvc.ScaleScenario(ctx, projectName, scenarioID, count, autoApprove); err != nil {
+				return withInterruptedHint(err, projectName, scenarioID)
+			}
+
+			fmt.Printf("场景 %s 已调整为 %d 个节点\n", scenarioID, count)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&autoApprove, "auto-approve", "y", false, "自动批准，跳过确认")
+	return cmd
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lucksec/cloudbot/internal/service"
+)
+
+// interruptibleContext 返回收到 Ctrl-C（SIGINT）或 SIGTERM 时取消的 context，用于部署和销毁等长时间操作
+// 第一次中断通知 Terraform 安全停止（释放状态锁并记录已创建的资源），第二次强制停止，第三次直接退出
+// 返回的 stop 函数在命令结束时调用，恢复默认的信号处理
+func interruptibleContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	force := make(chan struct{})
+	ctx = service.ContextWithForceStop(ctx, force)
+
+	signals := make(chan os.Signal, 3)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		for count := 1; ; count++ {
+			select {
+			case <-done:
+				return
+			case <-signals:
+			}
+			switch count {
+			case 1:
+				fmt.Fprintf(os.Stderr, "\n收到中断信号，正在等待 Terraform 安全停止（最长 %v），再次按 Ctrl-C 强制停止\n", service.TerraformStopTimeout)
+				cancel()
+			case 2:
+				fmt.Fprintln(os.Stderr, "\n强制停止 Terraform，再次按 Ctrl-C 直接退出")
+				close(force)
+			default:
+				os.Exit(130)
+			}
+		}
+	}()
+
+	stop := func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+	return ctx, stop
+}
+
+// withInterruptedHint 操作被中断时提示如何查看输出和继续
+func withInterruptedHint(err error, projectName, scenarioID string) error {
+	if !errors.Is(err, service.ErrInterrupted) {
+		return err
+	}
+	return fmt.Errorf("%w\n场景已标记为 interrupted，查看 Terraform 输出: cloudbot scenario logs %s %s\n重新运行 scenario deploy 或 scenario destroy 继续（遗留的状态锁会自动释放）",
+		err, projectName, scenarioID)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lucksec/cloudbot/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// scenarioReplaceCmd 重建场景中的指定资源命令
+func scenarioReplaceCmd(projectSvc service.ProjectService) *cobra.Command {
+	var autoApprove bool
+
+	cmd := &cobra.Command{
+		Use:   "replace <project> <scenario-id> <address|node-index>...",
+		Short: "重建场景中的指定资源（如单个节点）",
+		Long: `删除并重新创建场景中的指定资源，其他资源保持不变（terraform plan -replace）。
+
+资源可以是 Terraform 资源地址（如 alicloud_instance.instance[1]），也可以是节点序号（从 0 开始）。
+节点序号通过模板目录中 manifest.json 的 node_resource 转换为资源地址；
+模板没有声明时，使用场景中唯一的云主机资源。`,
+		Example: `  # 重建第 2 个节点
+  cloudbot scenario replace my-project <scenario-id> 1
+
+  # 按资源地址重建，跳过确认
+  cloudbot scenario replace my-project <scenario-id> 'alicloud_instance.instance[0]' -y`,
+		Args: cobra.MinimumNArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectName, scenarioID := args[0], args[1]
+
+			ctx, stop := interruptibleContext()
+			defer stop()
+			if err := projectSvc.ReplaceScenarioResources(ctx, projectName, scenarioID, args[2:], autoApprove); err != nil {
+				return withInterruptedHint(err, projectName, scenarioID)
+			}
+
+			fmt.Printf("场景 %s 的资源已重建: %s\n", scenarioID, strings.Join(args[2:], ", "))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&autoApprove, "auto-approve", "y", false, "自动批准，跳过确认")
+	return cmd
+}
+
+// scenarioTaintCmd 标记场景资源需要重建命令
+func scenarioTaintCmd(projectSvc service.ProjectService) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "taint <project> <scenario-id> <address|node-index>...",
+		Short: "将场景中的资源标记为需要重建",
+		Long: `将资源标记为需要重建（terraform taint），下次 scenario deploy 时删除并重新创建该资源。
+只修改 Terraform 状态，不会立即变更云资源。资源的指定方式与 scenario replace 相同。`,
+		Example: `  cloudbot scenario taint my-project <scenario-id> 0`,
+		Args:    cobra.MinimumNArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addresses, err := projectSvc.TaintScenarioResources(context.Background(), args[0], args[1], args[2:])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("已标记为需要重建: %s\n", strings.Join(addresses, ", "))
+			fmt.Println("运行 scenario deploy 重建这些资源")
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// scenarioUntaintCmd 取消场景资源重建标记命令
+func scenarioUntaintCmd(projectSvc service.ProjectService) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "untaint <project> <scenario-id> <address|node-index>...",
+		Short:   "取消场景中资源的重建标记",
+		Long:    "取消 scenario taint 设置的重建标记（terraform untaint）。资源的指定方式与 scenario replace 相同。",
+		Example: `  cloudbot scenario untaint my-project <scenario-id> 0`,
+		Args:    cobra.MinimumNArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addresses, err := projectSvc.UntaintScenarioResources(context.Background(), args[0], args[1], args[2:])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("已取消重建标记: %s\n", strings.Join(addresses, ", "))
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// terraformCmd Terraform/OpenTofu 管理命令组
+func terraformCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "terraform",
+		Short: "Terraform/OpenTofu 安装和版本管理",
+	}
+	cmd.AddCommand(terraformInstallCmd(cfg))
+	cmd.AddCommand(terraformListCmd(cfg))
+	return cmd
+}
+
+// terraformInstallCmd 安装 Terraform/OpenTofu 命令
+func terraformInstallCmd(cfg *config.Config) *cobra.Command {
+	var flavor string
+	var mirror string
+
+	cmd := &cobra.Command{
+		Use:   "install [version]",
+		Short: "下载并安装 Terraform 或 OpenTofu",
+		Long: `下载指定版本的 Terraform 或 OpenTofu 发行包，校验 SHA256SUMS 后安装到 ~/.cloudbot/bin（配置项 [terraform] bin_dir）。
+未指定版本时安装最新版本。安装的版本同时设为默认版本，未配置 exec_path 时优先使用。
+
+SHA256SUMS 的签名使用发布公钥验证：Terraform 使用内置的 HashiCorp 公钥，OpenTofu 使用从
+https://get.opentofu.org/opentofu.asc 下载的公钥；配置项 [terraform] signing_keys 可指定公钥文件代替。
+本地目录镜像中没有签名文件时不验证签名。
+
+网络受限时可以通过 --mirror 或配置项 [terraform] mirror 指定镜像，镜像可以是 URL 或本地目录，
+目录结构为 <mirror>/<terraform|tofu>/<版本>/<发行包和 SHA256SUMS>，与官方发行文件名一致。`,
+		Example: `  # 安装最新版本的 Terraform
+  cloudbot terraform install
+
+  # 安装指定版本
+  cloudbot terraform install 1.9.5
+
+  # 安装 OpenTofu
+  cloudbot terraform install 1.8.3 --flavor tofu
+
+  # 从本地镜像目录离线安装
+  cloudbot terraform install 1.9.5 --mirror /data/terraform-mirror`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version := ""
+			if len(args) == 1 {
+				version = args[0]
+			}
+
+			installer := service.NewTerraformInstaller(cfg, mirror)
+			binary, err := installer.Install(context.Background(), flavor, version)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("已安装 %s: %s\n", binary, binary.Path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flavor, "flavor", service.FlavorTerraform, "发行版: terraform 或 tofu")
+	cmd.Flags().StringVar(&mirror, "mirror", "", "发行包镜像 URL 或本地目录（覆盖配置项 [terraform] mirror）")
+
+	return cmd
+}
+
+// terraformListCmd 列出已安装版本命令
+func terraformListCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出已安装的 Terraform/OpenTofu 版本和当前默认版本",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if current, err := service.CheckTerraformInstalled(cfg.Terraform.ExecPath); err != nil {
+				fmt.Printf("默认: %v\n", err)
+			} else {
+				fmt.Printf("默认: %s (%s)\n", current, current.Path)
+			}
+
+			installed, err := service.NewTerraformInstaller(cfg, "").List()
+			if err != nil {
+				return err
+			}
+			if len(installed) == 0 {
+				fmt.Printf("安装目录 %s 中没有已安装的版本\n", cfg.Terraform.BinDir)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "\n发行版\t版本\t路径")
+			for _, b := range installed {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", b.Flavor, b.Version, b.Path)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// projectTerraformVersionCmd 查看或设置项目要求的 Terraform 版本命令
+func projectTerraformVersionCmd(projectSvc service.ProjectService) *cobra.Command {
+	var unset bool
+
+	cmd := &cobra.Command{
+		Use:   "terraform-version <name> [constraint]",
+		Short: "查看或设置项目要求的 Terraform/OpenTofu 版本",
+		Long: `查看或设置项目要求的 Terraform/OpenTofu 版本（记录在项目 project.ini 的 required_terraform_version）。
+设置后，该项目的 Terraform 命令使用满足要求的最高版本（默认可执行文件或 cloudbot terraform install 安装的版本）。
+
+版本要求支持 =、!=、>、>=、<、<=、~>，多个约束用逗号分隔，可以加 terraform 或 tofu 前缀限定发行版。`,
+		Example: `  # 查看项目要求的版本
+  cloudbot project terraform-version my-project
+
+  # 固定版本
+  cloudbot project terraform-version my-project 1.9.5
+
+  # 版本范围
+  cloudbot project terraform-version my-project ">= 1.5, < 2.0"
+
+  # 使用 OpenTofu
+  cloudbot project terraform-version my-project "tofu ~> 1.8"
+
+  # 取消版本要求
+  cloudbot project terraform-version my-project --unset`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			if len(args) == 1 && !unset {
+				project, err := projectSvc.GetProject(context.Background(), name)
+				if err != nil {
+					return err
+				}
+				if project.RequiredTerraformVersion == "" {
+					fmt.Printf("项目 %s 未设置 Terraform 版本要求\n", name)
+				} else {
+					fmt.Printf("项目 %s 要求的 Terraform 版本: %s\n", name, project.RequiredTerraformVersion)
+				}
+				return nil
+			}
+
+			constraint := ""
+			if len(args) == 2 {
+				constraint = args[1]
+			}
+			if err := projectSvc.SetProjectTerraformVersion(context.Background(), name, constraint); err != nil {
+				return err
+			}
+
+			if constraint == "" {
+				fmt.Printf("项目 %s 已取消 Terraform 版本要求\n", name)
+			} else {
+				fmt.Printf("项目 %s 要求的 Terraform 版本: %s\n", name, constraint)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&unset, "unset", false, "取消项目的 Terraform 版本要求")
+
+	return cmd
+}
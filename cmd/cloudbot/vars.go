@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lucksec/cloudbot/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// projectSetVarCmd 设置项目级 Terraform 变量
+func projectSetVarCmd(projectSvc service.ProjectService) *cobra.Command {
+	var unset []string
+
+	cmd := &cobra.Command{
+		Use:   "set-var <project> [key=value...]",
+		Short: "设置项目级 Terraform 变量",
+		Long: `设置项目级 Terraform 变量，保存在项目目录的 cloudbot.auto.tfvars.json 中。
+项目变量对项目下所有声明了该变量的场景生效，未声明该变量的场景会忽略它。
+
+变量优先级: 部署命令行参数 > 场景变量 > 项目变量 > 模板默认值
+以 [ 或 { 开头的值按 JSON 解析（用于 list / map 类型变量）。`,
+		Example: `  cloudbot project set-var my-project instance_type=ecs.t5-lc1m1.small
+  cloudbot project set-var my-project tags='{"owner":"ops"}'
+  cloudbot project set-var my-project --unset instance_type`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			values, err := parseVarAssignments(args[1:])
+			if err != nil {
+				return err
+			}
+			if len(values) == 0 && len(unset) == 0 {
+				return fmt.Errorf("请指定 key=value 或 --unset <key>")
+			}
+
+			if err := projectSvc.SetProjectVars(context.Background(), args[0], values, unset); err != nil {
+				return err
+			}
+
+			fmt.Printf("项目 %s 的变量已更新（设置 %d 个，删除 %d 个）\n", args[0], len(values), len(unset))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&unset, "unset", nil, "删除变量（可重复指定）")
+
+	return cmd
+}
+
+// scenarioSetVarCmd 设置场景级 Terraform 变量
+func scenarioSetVarCmd(projectSvc service.ProjectService) *cobra.Command {
+	var unset []string
+
+	cmd := &cobra.Command{
+		Use:   "set-var <project> <scenario-id> [key=value...]",
+		Short: "设置场景级 Terraform 变量",
+		Long: `设置场景级 Terraform 变量，保存在场景目录的 cloudbot.auto.tfvars.json 中。
+变量必须在场景模板中声明，且值需符合声明的类型。
+
+变量优先级: 部署命令行参数 > 场景变量 > 项目变量 > 模板默认值
+以 [ 或 { 开头的值按 JSON 解析（用于 list / map 类型变量）。`,
+		Example: `  cloudbot scenario set-var my-project <scenario-id> node_count=5
+  cloudbot scenario set-var my-project <scenario-id> --unset node_count
+
+  # 查看生效的变量值
+  cloudbot scenario vars my-project <scenario-id>`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			values, err := parseVarAssignments(args[2:])
+			if err != nil {
+				return err
+			}
+			if len(values) == 0 && len(unset) == 0 {
+				return fmt.Errorf("请指定 key=value 或 --unset <key>")
+			}
+
+			if err := projectSvc.SetScenarioVars(context.Background(), args[0], args[1], values, unset); err != nil {
+				return err
+			}
+
+			fmt.Printf("场景 %s 的变量已更新（设置 %d 个，删除 %d 个）\n", args[1], len(values), len(unset))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&unset, "unset", nil, "删除变量（可重复指定）")
+
+	return cmd
+}
+
+// scenarioVarsCmd 查看场景变量的生效值
+func scenarioVarsCmd(projectSvc service.ProjectService) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vars <project> <scenario-id>",
+		Short: "查看场景变量的生效值",
+		Long: `列出场景模板声明的所有变量及其生效值和来源（scenario / project / default）。
+部署时通过命令行参数（如 --node-count）指定的值优先级最高，不在此列出。`,
+		Example: `  cloudbot scenario vars my-project <scenario-id>`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vars, err := projectSvc.GetScenarioVars(context.Background(), args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if len(vars) == 0 {
+				fmt.Println("场景模板未声明任何变量")
+				return nil
+			}
+
+			fmt.Printf("场景 %s 的变量:\n\n", args[1])
+			for _, v := range vars {
+				typ := v.Type
+				if typ == "" {
+					typ = "any"
+				}
+				switch v.Source {
+				case "":
+					fmt.Printf("  %s (%s) = <未设置，必填>\n", v.Name, typ)
+				default:
+					fmt.Printf("  %s (%s) = %s  [%s]\n", v.Name, typ, v.Value, v.Source)
+				}
+				if v.Description != "" {
+					fmt.Printf("      %s\n", v.Description)
+				}
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// parseVarAssignments 解析 key=value 形式的变量列表
+func parseVarAssignments(assignments []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, assignment := range assignments {
+		key, value, err := service.ParseVarAssignment(assignment)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
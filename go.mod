@@ -3,13 +3,17 @@ module github.com/lucksec/cloudbot
 go 1.21
 
 require (
+	github.com/ProtonMail/go-crypto v1.1.6
 	github.com/c-bata/go-prompt v0.2.5
 	github.com/google/uuid v1.6.0
 	github.com/spf13/cobra v1.8.0
+	golang.org/x/crypto v0.17.0
+	golang.org/x/term v0.15.0
 	gopkg.in/ini.v1 v1.67.0
 )
 
 require (
+	github.com/cloudflare/circl v1.3.7 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/mattn/go-colorable v0.1.7 // indirect
 	github.com/mattn/go-isatty v0.0.12 // indirect
@@ -18,5 +22,5 @@ require (
 	github.com/pkg/term v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/stretchr/testify v1.11.1 // indirect
-	golang.org/x/sys v0.0.0-20200918174421-af09f7315aff // indirect
+	golang.org/x/sys v0.16.0 // indirect
 )
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"gopkg.in/ini.v1"
 )
@@ -27,15 +29,88 @@ type Config struct {
 	
 	// 凭据配置文件路径
 	CredentialConfigPath string
+	
+	// 价格刷新矩阵（price refresh 使用）
+	PriceRefresh []PriceRefreshTarget
+	
+	// 美元兑人民币汇率（[price] 小节的 usd_to_cny，跨云服务商比价时换算 USD 价格），为 0 时使用默认汇率
+	USDToCNY float64
+	
+	// 项目元数据存储配置
+	Metadata MetadataConfig
+}
+
+// 项目元数据存储类型
+const (
+	MetadataLocal = "local" // 本地项目目录（默认）
+	MetadataS3    = "s3"    // S3 兼容对象存储，团队共享
+)
+
+// MetadataConfig 项目元数据存储配置
+// 在配置文件中以 [metadata] 小节配置，例如:
+//
+//	[metadata]
+//	type = s3
+//	bucket = team-cloudbot
+//	region = cn-hangzhou
+//	endpoint = https://oss-cn-hangzhou.aliyuncs.com
+//	credentials = aliyun
+//
+// 除 type 外的参数记录在 Options 中，由存储实现解析
+type MetadataConfig struct {
+	Type    string
+	Options map[string]string
+}
+
+// PriceRefreshTarget 价格刷新目标
+// 在配置文件中以 [price_refresh.<provider>] 小节配置，例如:
+//
+//	[price_refresh.aliyun]
+//	regions = cn-beijing,cn-shanghai
+//	instance_types = ecs:ecs.t5-lc1m2.small,ecs.t6-c1m1.large
+//
+// instance_types 中的 "模板:实例类型" 表示将该实例类型的价格记录为指定模板的价格，
+// 只写实例类型时以实例类型作为模板名称
+type PriceRefreshTarget struct {
+	Provider      string
+	Regions       []string
+	InstanceTypes []string
 }
 
 // TerraformConfig Terraform 相关配置
 type TerraformConfig struct {
-	// Terraform 可执行文件路径
+	// Terraform 可执行文件路径（也可以是 OpenTofu 的 tofu），为空时自动查找:
+	// BinDir 中安装的 terraform、tofu，再到 PATH 中查找
 	ExecPath string
 	
 	// 默认工作目录
 	WorkDir string
+
+	// cloudbot terraform install 的安装目录（默认 ~/.cloudbot/bin）
+	BinDir string
+
+	// Terraform/OpenTofu 发行包镜像，可以是 URL 或本地目录，
+	// 目录结构为 <mirror>/<terraform|tofu>/<版本>/<发行包和 SHA256SUMS>，为空时从官方地址下载
+	Mirror string
+
+	// 验证 SHA256SUMS 签名使用的发布公钥文件（OpenPGP 公钥环），为空时使用内置的 HashiCorp 公钥
+	// 和从 get.opentofu.org 下载的 OpenTofu 公钥
+	SigningKeys string
+
+	// Provider 插件缓存目录（TF_PLUGIN_CACHE_DIR，默认 ~/.cloudbot/plugin-cache），各场景共享已下载的 provider
+	PluginCacheDir string
+
+	// Provider 本地镜像目录（provider_installation 的 filesystem_mirror），可由 cloudbot providers mirror 生成
+	ProviderMirror string
+
+	// Provider 网络镜像地址（provider_installation 的 network_mirror），如内网的 provider 镜像站
+	ProviderNetworkMirror string
+
+	// 配置了网络镜像时，镜像中没有的 provider 是否仍从官方 registry 下载（默认不下载）
+	ProviderDirect bool
+
+	// cloudbot 生成的 Terraform CLI 配置文件（TF_CLI_CONFIG_FILE，默认 ~/.cloudbot/terraform.rc）
+	CLIConfigFile string
 }
 
 // LogConfig 日志配置
@@ -83,8 +158,7 @@ func LoadConfig() (*Config, error) {
 		TemplateDir: "./redc-templates",
 		ProjectDir:  "./projects",
 		Terraform: TerraformConfig{
-			ExecPath: "terraform",
-			WorkDir:  ".",
+			WorkDir: ".",
 		},
 		Log: LogConfig{
 			Level:         "INFO",
@@ -94,6 +168,10 @@ func LoadConfig() (*Config, error) {
 			LogFile:      "",
 		},
 		CredentialConfigPath: configPath,
+		Metadata: MetadataConfig{
+			Type:    MetadataLocal,
+			Options: map[string]string{},
+		},
 	}
 	
 	// 尝试读取配置文件
@@ -141,6 +219,27 @@ func LoadConfig() (*Config, error) {
 			if workDir := section.Key("work_dir").String(); workDir != "" {
 				config.Terraform.WorkDir = workDir
 			}
+			if binDir := section.Key("bin_dir").String(); binDir != "" {
+				config.Terraform.BinDir = binDir
+			}
+			if mirror := section.Key("mirror").String(); mirror != "" {
+				config.Terraform.Mirror = mirror
+			}
+			if signingKeys := section.Key("signing_keys").String(); signingKeys != "" {
+				config.Terraform.SigningKeys = signingKeys
+			}
+			if cacheDir := section.Key("plugin_cache_dir").String(); cacheDir != "" {
+				config.Terraform.PluginCacheDir = cacheDir
+			}
+			if providerMirror := section.Key("provider_mirror").String(); providerMirror != "" {
+				config.Terraform.ProviderMirror = providerMirror
+			}
+			if networkMirror := section.Key("provider_network_mirror").String(); networkMirror != "" {
+				config.Terraform.ProviderNetworkMirror = networkMirror
+			}
+			if direct := section.Key("provider_direct").String(); direct != "" {
+				config.Terraform.ProviderDirect = direct == "true" || direct == "1"
+			}
 		}
 		
 		if section := cfgFile.Section("log"); section != nil {
@@ -160,6 +259,52 @@ func LoadConfig() (*Config, error) {
 				config.Log.LogFile = logFile
 			}
 		}
+		
+		if section, err := cfgFile.GetSection("metadata"); err == nil {
+			for _, key := range section.Keys() {
+				if key.Name() == "type" {
+					config.Metadata.Type = key.String()
+				} else {
+					config.Metadata.Options[key.Name()] = key.String()
+				}
+			}
+		}
+		
+		if section, err := cfgFile.GetSection("price"); err == nil {
+			if value := section.Key("usd_to_cny").String(); value != "" {
+				rate, err := strconv.ParseFloat(value, 64)
+				if err != nil || rate <= 0 {
+					return nil, fmt.Errorf("无效的汇率 usd_to_cny: %s", value)
+				}
+				config.USDToCNY = rate
+			}
+		}
+		
+		for _, section := range cfgFile.Sections() {
+			provider, ok := strings.CutPrefix(section.Name(), "price_refresh.")
+			if !ok || provider == "" {
+				continue
+			}
+			config.PriceRefresh = append(config.PriceRefresh, PriceRefreshTarget{
+				Provider:      provider,
+				Regions:       section.Key("regions").Strings(","),
+				InstanceTypes: section.Key("instance_types").Strings(","),
+			})
+		}
+	}
+	
+	cloudbotDir := ".cloudbot"
+	if homeDir := os.Getenv("HOME"); homeDir != "" {
+		cloudbotDir = filepath.Join(homeDir, ".cloudbot")
+	}
+	if config.Terraform.BinDir == "" {
+		config.Terraform.BinDir = filepath.Join(cloudbotDir, "bin")
+	}
+	if config.Terraform.PluginCacheDir == "" {
+		config.Terraform.PluginCacheDir = filepath.Join(cloudbotDir, "plugin-cache")
+	}
+	if config.Terraform.CLIConfigFile == "" {
+		config.Terraform.CLIConfigFile = filepath.Join(cloudbotDir, "terraform.rc")
 	}
 	
 	// 确保目录存在
@@ -175,6 +320,7 @@ func ensureDirs(config *Config) error {
 	dirs := []string{
 		config.ProjectDir,
 		config.TemplateDir,
+		config.Terraform.PluginCacheDir,
 	}
 	
 	for _, dir := range dirs {
@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/ini.v1"
 )
@@ -20,11 +24,34 @@ const (
 	ProviderVultr       Provider = "vultr"
 )
 
+const (
+	// DefaultProfile 默认 profile 名称，对应配置文件中的 [aliyun]、[tencent] 等小节
+	DefaultProfile = "default"
+
+	// ProfileEnvVar 用于选择当前 profile 的环境变量
+	ProfileEnvVar = "CLOUDBOT_PROFILE"
+)
+
 // Credentials 云服务商凭据
 type Credentials struct {
-	AccessKey string
-	SecretKey string
-	Region    string // 可选：默认区域
+	AccessKey    string
+	SecretKey    string
+	Region       string    // 可选：默认区域
+	SessionToken string    // 临时凭据的安全令牌（STS），长期凭据为空
+	Expiration   time.Time // 临时凭据过期时间，长期凭据为零值
+}
+
+// IsTemporary 是否为临时凭据
+func (c *Credentials) IsTemporary() bool {
+	return c.SessionToken != ""
+}
+
+// ExpiresWithin 临时凭据是否将在 d 时间内过期（长期凭据始终返回 false）
+func (c *Credentials) ExpiresWithin(d time.Duration) bool {
+	if c.Expiration.IsZero() {
+		return false
+	}
+	return time.Now().Add(d).After(c.Expiration)
 }
 
 // CredentialManager 凭据管理器接口
@@ -43,13 +70,35 @@ type CredentialManager interface {
 	
 	// RemoveCredentials 删除指定云服务商的凭据
 	RemoveCredentials(provider Provider) error
+	
+	// ActiveProfile 返回当前使用的 profile 名称
+	ActiveProfile() string
+	
+	// ListProfiles 列出所有已配置的 profile
+	ListProfiles() []string
+	
+	// SetDefaultProfile 设置默认 profile（写入配置文件）
+	SetDefaultProfile(profile string) error
+	
+	// WithProfile 返回绑定到指定 profile 的凭据管理器，profile 为空时返回当前管理器
+	WithProfile(profile string) CredentialManager
 }
 
 // credentialManager 凭据管理器实现
+// 凭据按 profile 分组存储：默认 profile 对应配置文件中的 [aliyun] 小节，
+// 其他 profile 对应 [aliyun:<profile>] 小节
 type credentialManager struct {
-	configPath string
-	mu         sync.RWMutex
-	creds      map[Provider]*Credentials
+	configPath     string
+	mu             sync.RWMutex
+	profiles       map[string]map[Provider]*Credentials
+	defaultProfile string          // 配置文件 [credentials] default_profile 指定的默认 profile
+	store          *encryptedStore // 加密凭据存储（为空表示凭据以明文保存在配置文件中）
+	
+	roles     map[string]map[Provider]*RoleConfig  // AssumeRole 类型的 profile 配置
+	processes map[string]map[Provider]string       // credential_process 类型的 profile 配置（外部命令）
+	sessions  map[string]map[Provider]*Credentials // AssumeRole / credential_process 获取的凭据缓存（不持久化）
+	assumer   RoleAssumer                          // STS AssumeRole 调用实现
+	loadErr   error                                // 默认管理器加载配置失败的原因，获取和保存凭据时返回
 }
 
 var defaultManager CredentialManager
@@ -59,7 +108,10 @@ var once sync.Once
 func NewCredentialManager(configPath string) (CredentialManager, error) {
 	manager := &credentialManager{
 		configPath: configPath,
-		creds:      make(map[Provider]*Credentials),
+		profiles:   make(map[string]map[Provider]*Credentials),
+		roles:      make(map[string]map[Provider]*RoleConfig),
+		processes:  make(map[string]map[Provider]string),
+		sessions:   make(map[string]map[Provider]*Credentials),
 	}
 	
 	// 加载配置
@@ -97,9 +149,14 @@ func GetDefaultManager() CredentialManager {
 		manager, err := NewCredentialManager(configPath)
 		if err != nil {
 			// 如果创建失败，创建一个基本的管理器（至少可以保存）
+			// 记录失败原因，避免口令错误等问题被静默忽略或凭据被以明文覆盖
 			defaultManager = &credentialManager{
 				configPath: configPath,
-				creds:      make(map[Provider]*Credentials),
+				profiles:   make(map[string]map[Provider]*Credentials),
+				roles:      make(map[string]map[Provider]*RoleConfig),
+				processes:  make(map[string]map[Provider]string),
+				sessions:   make(map[string]map[Provider]*Credentials),
+				loadErr:    err,
 			}
 		} else {
 			defaultManager = manager
@@ -109,8 +166,70 @@ func GetDefaultManager() CredentialManager {
 	return defaultManager
 }
 
-// load 从配置文件加载凭据
+// load 加载凭据
+// 先加载配置文件和环境变量中的凭据，再加载加密凭据存储（如已启用）
 func (m *credentialManager) load() error {
+	if err := m.loadPlaintext(); err != nil {
+		return err
+	}
+	return m.loadEncrypted()
+}
+
+// loadEncrypted 加载加密凭据存储
+// 优先使用未过期的会话缓存解锁，其次使用口令环境变量；都不可用时保持锁定状态
+func (m *credentialManager) loadEncrypted() error {
+	path := m.configPath
+	if path == "" {
+		path = ".redc.ini"
+	}
+	store, err := openEncryptedStore(encryptedStorePath(path))
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return nil
+	}
+	
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+	
+	var creds storedCredentials
+	if key := store.loadSession(); key != nil {
+		creds, err = store.unlockWithKey(key)
+	}
+	if !store.unlocked() {
+		// 会话缓存失效时忽略，继续尝试口令环境变量
+		if passphrase := os.Getenv(PassphraseEnvVar); passphrase != "" {
+			if creds, err = store.unlock(passphrase); err != nil {
+				return fmt.Errorf("使用 %s 解锁凭据失败: %w", PassphraseEnvVar, err)
+			}
+		}
+	}
+	if !store.unlocked() {
+		// 保持锁定状态，获取凭据时提示解锁
+		return nil
+	}
+	
+	m.mergeStoredLocked(creds)
+	return nil
+}
+
+// mergeStoredLocked 将加密存储中的凭据合并到内存（调用方需持有写锁）
+func (m *credentialManager) mergeStoredLocked(creds storedCredentials) {
+	for profile, providers := range creds {
+		for provider, c := range providers {
+			m.putLocked(profile, provider, &Credentials{
+				AccessKey: c.AccessKey,
+				SecretKey: c.SecretKey,
+				Region:    c.Region,
+			})
+		}
+	}
+}
+
+// loadPlaintext 从配置文件加载明文凭据
+func (m *credentialManager) loadPlaintext() error {
 	if m.configPath == "" {
 		// 如果没有配置文件，只从环境变量加载
 		return m.loadFromEnv()
@@ -172,17 +291,103 @@ func (m *credentialManager) load() error {
 		}
 		
 		if accessKey != "" && secretKey != "" {
-			m.creds[provider] = &Credentials{
+			m.putLocked(DefaultProfile, provider, &Credentials{
 				AccessKey: accessKey,
 				SecretKey: secretKey,
 				Region:    region,
-			}
+			})
 		}
 	}
 	
+	// 加载命名 profile（[<provider>:<profile>] 小节，不从环境变量补全，避免误用其他账号）
+	for _, section := range cfg.Sections() {
+		provider, profile, ok := parseSectionName(section.Name())
+		if !ok || profile == DefaultProfile {
+			continue
+		}
+		accessKey := section.Key("access_key").String()
+		if provider == ProviderTencent && section.HasKey("secret_id") {
+			accessKey = section.Key("secret_id").String()
+		}
+		secretKey := section.Key("secret_key").String()
+		if accessKey != "" && secretKey != "" {
+			m.putLocked(profile, provider, &Credentials{
+				AccessKey: accessKey,
+				SecretKey: secretKey,
+				Region:    section.Key("region").String(),
+			})
+		}
+	}
+	
+	// 加载 AssumeRole 类型的 profile（小节中配置了 role_arn）
+	for _, section := range cfg.Sections() {
+		provider, profile, ok := parseSectionName(section.Name())
+		if !ok || section.Key("role_arn").String() == "" {
+			continue
+		}
+		if m.roles[profile] == nil {
+			m.roles[profile] = make(map[Provider]*RoleConfig)
+		}
+		m.roles[profile][provider] = &RoleConfig{
+			RoleARN:         section.Key("role_arn").String(),
+			SourceProfile:   section.Key("source_profile").MustString(DefaultProfile),
+			SessionName:     section.Key("role_session_name").MustString(defaultRoleSessionName),
+			DurationSeconds: section.Key("duration_seconds").MustInt(defaultRoleDuration),
+			ExternalID:      section.Key("external_id").String(),
+			Region:          section.Key("region").String(),
+		}
+	}
+	
+	// 加载 credential_process 类型的 profile（小节中配置了 credential_process）
+	for _, section := range cfg.Sections() {
+		provider, profile, ok := parseSectionName(section.Name())
+		if !ok || section.Key("credential_process").String() == "" {
+			continue
+		}
+		if m.processes[profile] == nil {
+			m.processes[profile] = make(map[Provider]string)
+		}
+		m.processes[profile][provider] = section.Key("credential_process").String()
+	}
+	
+	m.defaultProfile = cfg.Section("credentials").Key("default_profile").String()
+	
 	return nil
 }
 
+// sectionName 返回 profile 下指定云服务商凭据在配置文件中的小节名称
+func sectionName(profile string, provider Provider) string {
+	if profile == "" || profile == DefaultProfile {
+		return string(provider)
+	}
+	// 不使用 "." 分隔：ini 库会把 "aliyun.xxx" 视为 [aliyun] 的子小节并继承其中的键
+	return string(provider) + ":" + profile
+}
+
+// parseSectionName 解析凭据小节名称，返回云服务商和 profile
+func parseSectionName(name string) (Provider, string, bool) {
+	providerStr, profile, found := strings.Cut(name, ":")
+	provider := Provider(providerStr)
+	if !provider.IsValid() {
+		return "", "", false
+	}
+	if !found {
+		return provider, DefaultProfile, true
+	}
+	if profile == "" {
+		return "", "", false
+	}
+	return provider, profile, true
+}
+
+// putLocked 保存 profile 下的凭据（调用方需持有写锁）
+func (m *credentialManager) putLocked(profile string, provider Provider, creds *Credentials) {
+	if m.profiles[profile] == nil {
+		m.profiles[profile] = make(map[Provider]*Credentials)
+	}
+	m.profiles[profile][provider] = creds
+}
+
 // loadFromEnv 从环境变量加载凭据
 func (m *credentialManager) loadFromEnv() error {
 	m.mu.Lock()
@@ -207,11 +412,11 @@ func (m *credentialManager) loadFromEnv() error {
 		region := os.Getenv(p.envRegion)
 		
 		if accessKey != "" && secretKey != "" {
-			m.creds[p.provider] = &Credentials{
+			m.putLocked(DefaultProfile, p.provider, &Credentials{
 				AccessKey: accessKey,
 				SecretKey: secretKey,
 				Region:    region,
-			}
+			})
 		}
 	}
 	
@@ -266,13 +471,153 @@ func (m *credentialManager) getEnvRegion(provider Provider) string {
 	return ""
 }
 
-// GetCredentials 获取指定云服务商的凭据
+// ActiveProfile 返回当前使用的 profile
+// 优先级：环境变量 CLOUDBOT_PROFILE > 配置文件 default_profile > default
+func (m *credentialManager) ActiveProfile() string {
+	if profile := os.Getenv(ProfileEnvVar); profile != "" {
+		return profile
+	}
+	
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.defaultProfile != "" {
+		return m.defaultProfile
+	}
+	return DefaultProfile
+}
+
+// ListProfiles 列出所有已配置的 profile
+func (m *credentialManager) ListProfiles() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	profiles := []string{DefaultProfile}
+	for profile := range m.profiles {
+		if profile != DefaultProfile {
+			profiles = append(profiles, profile)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, profile := range profiles {
+		seen[profile] = true
+	}
+	for profile := range m.roles {
+		if !seen[profile] {
+			profiles = append(profiles, profile)
+			seen[profile] = true
+		}
+	}
+	for profile := range m.processes {
+		if !seen[profile] {
+			profiles = append(profiles, profile)
+			seen[profile] = true
+		}
+	}
+	sort.Strings(profiles[1:])
+	return profiles
+}
+
+// SetDefaultProfile 设置默认 profile
+func (m *credentialManager) SetDefaultProfile(profile string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	_, static := m.profiles[profile]
+	_, role := m.roles[profile]
+	_, process := m.processes[profile]
+	if !static && !role && !process && profile != DefaultProfile {
+		return fmt.Errorf("profile %s 不存在", profile)
+	}
+	m.defaultProfile = profile
+	
+	return m.save()
+}
+
+// WithProfile 返回绑定到指定 profile 的凭据管理器
+func (m *credentialManager) WithProfile(profile string) CredentialManager {
+	if profile == "" {
+		return m
+	}
+	return &profileManager{root: m, profile: profile}
+}
+
+// GetCredentials 获取当前 profile 下指定云服务商的凭据
 func (m *credentialManager) GetCredentials(provider Provider) (*Credentials, error) {
+	return m.getCredentials(m.ActiveProfile(), provider)
+}
+
+// SetCredentials 设置当前 profile 下指定云服务商的凭据
+func (m *credentialManager) SetCredentials(provider Provider, creds *Credentials) error {
+	return m.setCredentials(m.ActiveProfile(), provider, creds)
+}
+
+// HasCredentials 检查当前 profile 是否已配置凭据
+func (m *credentialManager) HasCredentials(provider Provider) bool {
+	return m.hasCredentials(m.ActiveProfile(), provider)
+}
+
+// ListProviders 列出当前 profile 下所有已配置凭据的云服务商
+func (m *credentialManager) ListProviders() []Provider {
+	return m.listProviders(m.ActiveProfile())
+}
+
+// RemoveCredentials 删除当前 profile 下指定云服务商的凭据
+func (m *credentialManager) RemoveCredentials(provider Provider) error {
+	return m.removeCredentials(m.ActiveProfile(), provider)
+}
+
+// getCredentials 获取指定 profile 下云服务商的凭据
+// AssumeRole 类型的 profile 返回临时凭据（即将过期时自动刷新）
+func (m *credentialManager) getCredentials(profile string, provider Provider) (*Credentials, error) {
+	m.mu.RLock()
+	role := m.roles[profile][provider]
+	loadErr := m.loadErr
+	m.mu.RUnlock()
+	
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	
+	if role != nil {
+		return m.assumeRole(profile, provider, role)
+	}
+	return m.getBaseCredentials(profile, provider)
+}
+
+// getBaseCredentials 获取指定 profile 下云服务商的非 AssumeRole 凭据
+// 配置了 credential_process 时执行外部命令获取，否则返回长期凭据
+func (m *credentialManager) getBaseCredentials(profile string, provider Provider) (*Credentials, error) {
+	m.mu.RLock()
+	command, ok := m.processes[profile][provider]
+	m.mu.RUnlock()
+	
+	if ok {
+		return m.processCredentials(profile, provider, command)
+	}
+	return m.getStaticCredentials(profile, provider)
+}
+
+// getStaticCredentials 获取指定 profile 下云服务商的长期凭据
+// 只有默认 profile 会回退到环境变量
+func (m *credentialManager) getStaticCredentials(profile string, provider Provider) (*Credentials, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
-	creds, ok := m.creds[provider]
+	creds, ok := m.profiles[profile][provider]
 	if !ok {
+		if m.store != nil && !m.store.unlocked() {
+			// 加密存储未解锁时，凭据可能保存在加密存储中
+			if profile != DefaultProfile || !m.hasEnvCredentials(provider) {
+				return nil, fmt.Errorf("获取 %s 凭据失败: %w", provider, ErrStoreLocked)
+			}
+		}
+		if profile != DefaultProfile {
+			return nil, fmt.Errorf("profile %s 中未找到 %s 的凭据配置", profile, provider)
+		}
+		
 		// 尝试从环境变量获取
 		accessKey := m.getEnvAccessKey(provider)
 		secretKey := m.getEnvSecretKey(provider)
@@ -292,49 +637,52 @@ func (m *credentialManager) GetCredentials(provider Provider) (*Credentials, err
 	return creds, nil
 }
 
-// SetCredentials 设置指定云服务商的凭据
-func (m *credentialManager) SetCredentials(provider Provider, creds *Credentials) error {
+// setCredentials 设置指定 profile 下云服务商的凭据
+func (m *credentialManager) setCredentials(profile string, provider Provider, creds *Credentials) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	// 更新内存中的凭据
-	m.creds[provider] = creds
-	
-	// 如果没有配置文件路径，使用默认路径
-	if m.configPath == "" {
-		m.configPath = ".redc.ini"
+	if m.store != nil && !m.store.unlocked() {
+		return ErrStoreLocked
 	}
 	
+	// 更新内存中的凭据
+	m.putLocked(profile, provider, creds)
+	
 	// 保存到配置文件
 	return m.save()
 }
 
-// HasCredentials 检查是否已配置凭据
-func (m *credentialManager) HasCredentials(provider Provider) bool {
+// hasCredentials 检查指定 profile 是否已配置云服务商凭据
+func (m *credentialManager) hasCredentials(profile string, provider Provider) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
-	if _, ok := m.creds[provider]; ok {
+	if _, ok := m.profiles[profile][provider]; ok {
+		return true
+	}
+	if _, ok := m.roles[profile][provider]; ok {
+		return true
+	}
+	if _, ok := m.processes[profile][provider]; ok {
 		return true
 	}
+	if profile != DefaultProfile {
+		return false
+	}
 	
-	// 检查环境变量
+	return m.hasEnvCredentials(provider)
+}
+
+// hasEnvCredentials 检查环境变量中是否有指定云服务商的凭据
+func (m *credentialManager) hasEnvCredentials(provider Provider) bool {
 	accessKey := m.getEnvAccessKey(provider)
 	secretKey := m.getEnvSecretKey(provider)
 	return accessKey != "" && secretKey != ""
 }
 
-// ListProviders 列出所有已配置凭据的云服务商
-func (m *credentialManager) ListProviders() []Provider {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	
-	var providers []Provider
-	for provider := range m.creds {
-		providers = append(providers, provider)
-	}
-	
-	// 也检查环境变量中的凭据
+// listProviders 列出指定 profile 下所有已配置凭据的云服务商
+func (m *credentialManager) listProviders(profile string) []Provider {
 	allProviders := []Provider{
 		ProviderAliyun,
 		ProviderTencent,
@@ -343,45 +691,54 @@ func (m *credentialManager) ListProviders() []Provider {
 		ProviderVultr,
 	}
 	
+	var providers []Provider
 	for _, provider := range allProviders {
-		if !m.hasProvider(providers, provider) {
-			if m.HasCredentials(provider) {
-				providers = append(providers, provider)
-			}
+		if m.hasCredentials(profile, provider) {
+			providers = append(providers, provider)
 		}
 	}
 	
 	return providers
 }
 
-// hasProvider 检查列表中是否包含指定 provider
-func (m *credentialManager) hasProvider(list []Provider, provider Provider) bool {
-	for _, p := range list {
-		if p == provider {
-			return true
-		}
-	}
-	return false
-}
-
-// RemoveCredentials 删除指定云服务商的凭据
-func (m *credentialManager) RemoveCredentials(provider Provider) error {
+// removeCredentials 删除指定 profile 下云服务商的凭据
+func (m *credentialManager) removeCredentials(profile string, provider Provider) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	delete(m.creds, provider)
+	if m.store != nil && !m.store.unlocked() {
+		return ErrStoreLocked
+	}
 	
-	// 如果没有配置文件路径，使用默认路径
-	if m.configPath == "" {
-		m.configPath = ".redc.ini"
+	delete(m.profiles[profile], provider)
+	delete(m.roles[profile], provider)
+	delete(m.processes[profile], provider)
+	delete(m.sessions[profile], provider)
+	if len(m.roles[profile]) == 0 {
+		delete(m.roles, profile)
+	}
+	if len(m.processes[profile]) == 0 {
+		delete(m.processes, profile)
+	}
+	if len(m.profiles[profile]) == 0 && len(m.roles[profile]) == 0 && len(m.processes[profile]) == 0 && profile != DefaultProfile {
+		delete(m.profiles, profile)
+		// profile 已不存在，默认 profile 回退为 default
+		if m.defaultProfile == profile {
+			m.defaultProfile = ""
+		}
 	}
 	
-	// 保存到配置文件
-	return m.save()
+	// 保存到配置文件，并删除对应小节
+	return m.save(sectionName(profile, provider))
 }
 
-// save 保存凭据到配置文件
-func (m *credentialManager) save() error {
+// save 保存凭据到配置文件（调用方需持有写锁）
+// removedSections 为需要从配置文件中删除的小节
+func (m *credentialManager) save(removedSections ...string) error {
+	if m.loadErr != nil {
+		return m.loadErr
+	}
+	
 	// 如果没有配置文件路径，使用默认路径
 	if m.configPath == "" {
 		m.configPath = ".redc.ini"
@@ -411,24 +768,187 @@ func (m *credentialManager) save() error {
 		cfg = ini.Empty()
 	}
 	
-	// 更新各云服务商的凭据
-	for provider, creds := range m.creds {
-		sectionName := string(provider)
-		section := cfg.Section(sectionName)
-		
-		// 腾讯云使用 secret_id，其他云服务商使用 access_key
-		if provider == ProviderTencent {
-			section.Key("secret_id").SetValue(creds.AccessKey)
-		} else {
-			section.Key("access_key").SetValue(creds.AccessKey)
+	for _, name := range removedSections {
+		cfg.DeleteSection(name)
+	}
+	
+	// 已启用加密存储时，凭据只写入加密文件，配置文件中不保留明文
+	// 未解锁时内存中没有加密存储的凭据，只更新配置文件中的其他设置
+	if m.store != nil && m.store.unlocked() {
+		if err := m.store.save(toStored(m.profiles)); err != nil {
+			return err
+		}
+	}
+	
+	// 更新各 profile 下云服务商的凭据
+	for profile, creds := range m.profiles {
+		if m.store != nil {
+			break
+		}
+		for provider, c := range creds {
+			section := cfg.Section(sectionName(profile, provider))
+			
+			// 腾讯云使用 secret_id，其他云服务商使用 access_key
+			if provider == ProviderTencent {
+				section.Key("secret_id").SetValue(c.AccessKey)
+			} else {
+				section.Key("access_key").SetValue(c.AccessKey)
+			}
+			section.Key("secret_key").SetValue(c.SecretKey)
+			if c.Region != "" {
+				section.Key("region").SetValue(c.Region)
+			}
+		}
+	}
+	
+	// AssumeRole 配置不含密钥，始终保存在配置文件中
+	for profile, roles := range m.roles {
+		for provider, role := range roles {
+			section := cfg.Section(sectionName(profile, provider))
+			section.Key("role_arn").SetValue(role.RoleARN)
+			section.Key("source_profile").SetValue(role.SourceProfile)
+			section.Key("role_session_name").SetValue(role.SessionName)
+			section.Key("duration_seconds").SetValue(strconv.Itoa(role.DurationSeconds))
+			if role.ExternalID != "" {
+				section.Key("external_id").SetValue(role.ExternalID)
+			}
+			if role.Region != "" {
+				section.Key("region").SetValue(role.Region)
+			}
 		}
-		section.Key("secret_key").SetValue(creds.SecretKey)
-		if creds.Region != "" {
-			section.Key("region").SetValue(creds.Region)
+	}
+	
+	// credential_process 只保存命令，凭据由外部命令在需要时提供
+	for profile, processes := range m.processes {
+		for provider, command := range processes {
+			cfg.Section(sectionName(profile, provider)).Key("credential_process").SetValue(command)
 		}
 	}
 	
+	if m.defaultProfile != "" {
+		cfg.Section("credentials").Key("default_profile").SetValue(m.defaultProfile)
+	} else if section, err := cfg.GetSection("credentials"); err == nil {
+		section.DeleteKey("default_profile")
+	}
+	
 	// 保存到文件
 	return cfg.SaveTo(m.configPath)
 }
 
+// IsEncrypted 是否已启用加密凭据存储
+func (m *credentialManager) IsEncrypted() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.store != nil
+}
+
+// IsLocked 加密凭据存储是否处于锁定状态
+func (m *credentialManager) IsLocked() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.store != nil && !m.store.unlocked()
+}
+
+// Unlock 使用口令解锁加密凭据存储
+func (m *credentialManager) Unlock(passphrase string, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	if m.store == nil {
+		return fmt.Errorf("未启用加密凭据存储，请先运行 'cloudbot credential migrate'")
+	}
+	
+	creds, err := m.store.unlock(passphrase)
+	if err != nil {
+		return err
+	}
+	m.mergeStoredLocked(creds)
+	
+	if timeout > 0 {
+		if err := m.store.saveSession(timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lock 锁定加密凭据存储
+// 清除会话缓存，并从内存中移除加密存储中的凭据
+func (m *credentialManager) Lock() error {
+	m.mu.Lock()
+	if m.store == nil {
+		m.mu.Unlock()
+		return fmt.Errorf("未启用加密凭据存储")
+	}
+	err := m.store.clearSession()
+	m.store.key = nil
+	m.profiles = make(map[string]map[Provider]*Credentials)
+	m.mu.Unlock()
+	
+	if err != nil {
+		return err
+	}
+	return m.loadPlaintext()
+}
+
+// MigrateToEncrypted 将配置文件中的明文凭据迁移到加密存储
+// 迁移完成后从配置文件中删除明文凭据小节
+func (m *credentialManager) MigrateToEncrypted(passphrase string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	if m.store != nil {
+		return 0, fmt.Errorf("已启用加密凭据存储")
+	}
+	if m.configPath == "" {
+		m.configPath = ".redc.ini"
+	}
+	
+	store, err := newEncryptedStore(encryptedStorePath(m.configPath), passphrase)
+	if err != nil {
+		return 0, err
+	}
+	
+	// 只迁移配置文件中的明文凭据，环境变量中的凭据不写入加密存储
+	// 收集配置文件中的明文凭据小节，save 时一并删除
+	count := 0
+	migrated := make(map[string]map[Provider]*Credentials)
+	var plaintextSections []string
+	if cfg, err := ini.Load(m.configPath); err == nil {
+		for _, section := range cfg.Sections() {
+			provider, profile, ok := parseSectionName(section.Name())
+			if !ok {
+				continue
+			}
+			plaintextSections = append(plaintextSections, section.Name())
+			
+			accessKey := section.Key("access_key").String()
+			if provider == ProviderTencent && section.HasKey("secret_id") {
+				accessKey = section.Key("secret_id").String()
+			}
+			secretKey := section.Key("secret_key").String()
+			if accessKey == "" || secretKey == "" {
+				continue
+			}
+			if migrated[profile] == nil {
+				migrated[profile] = make(map[Provider]*Credentials)
+			}
+			migrated[profile][provider] = &Credentials{
+				AccessKey: accessKey,
+				SecretKey: secretKey,
+				Region:    section.Key("region").String(),
+			}
+			count++
+		}
+	}
+	
+	profiles := m.profiles
+	m.store = store
+	m.profiles = migrated
+	if err := m.save(plaintextSections...); err != nil {
+		m.store = nil
+		m.profiles = profiles
+		return 0, err
+	}
+	return count, nil
+}
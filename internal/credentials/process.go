@@ -0,0 +1,238 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// processTimeout credential_process 单次执行超时时间（辅助程序可能需要交互式登录保险库）
+	processTimeout = 2 * time.Minute
+
+	// processRefreshMargin 缓存的凭据剩余有效期小于该值时重新执行 credential_process
+	processRefreshMargin = 5 * time.Minute
+)
+
+// ProcessStore credential_process 类型 profile 管理接口
+// 由默认凭据管理器实现，通过类型断言获取
+type ProcessStore interface {
+	// SetCredentialProcess 将 profile 下指定云服务商配置为通过外部命令获取凭据
+	SetCredentialProcess(profile string, provider Provider, command string) error
+
+	// GetCredentialProcess 获取 profile 下指定云服务商的 credential_process 命令
+	GetCredentialProcess(profile string, provider Provider) (string, bool)
+}
+
+// processOutput credential_process 输出的 JSON 格式
+// 兼容 AWS credential_process 的字段名（AccessKeyId / SecretAccessKey），例如:
+//
+//	{"Version": 1, "AccessKey": "...", "SecretKey": "...", "SessionToken": "...", "Expiration": "2024-01-01T00:00:00Z"}
+type processOutput struct {
+	Version         int    `json:"Version"`
+	AccessKey       string `json:"AccessKey"`
+	SecretKey       string `json:"SecretKey"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken"`
+	Expiration      string `json:"Expiration"`
+	Region          string `json:"Region"`
+}
+
+// SetCredentialProcess 将 profile 下指定云服务商配置为通过外部命令获取凭据
+func (m *credentialManager) SetCredentialProcess(profile string, provider Provider, command string) error {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	if _, err := splitCommand(command); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// credential_process 类型的 profile 不保存长期凭据，也不再使用 AssumeRole
+	if _, ok := m.profiles[profile][provider]; ok && m.store != nil && !m.store.unlocked() {
+		return ErrStoreLocked
+	}
+	delete(m.profiles[profile], provider)
+	delete(m.roles[profile], provider)
+	delete(m.sessions[profile], provider)
+
+	if m.processes[profile] == nil {
+		m.processes[profile] = make(map[Provider]string)
+	}
+	m.processes[profile][provider] = command
+
+	// 删除原小节后按内存中的配置重新写入，避免残留旧类型的键
+	return m.save(sectionName(profile, provider))
+}
+
+// GetCredentialProcess 获取 profile 下指定云服务商的 credential_process 命令
+func (m *credentialManager) GetCredentialProcess(profile string, provider Provider) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	command, ok := m.processes[profile][provider]
+	return command, ok
+}
+
+// processCredentials 执行 credential_process 获取凭据
+// 结果缓存到过期前 processRefreshMargin，未返回过期时间的凭据在进程生命周期内一直缓存
+func (m *credentialManager) processCredentials(profile string, provider Provider, command string) (*Credentials, error) {
+	m.mu.RLock()
+	cached := m.sessions[profile][provider]
+	m.mu.RUnlock()
+
+	if cached != nil && !cached.ExpiresWithin(processRefreshMargin) {
+		return cached, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), processTimeout)
+	defer cancel()
+
+	creds, err := runCredentialProcess(ctx, command)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s 的 %s credential_process 失败: %w", profile, provider, err)
+	}
+
+	m.mu.Lock()
+	if m.sessions[profile] == nil {
+		m.sessions[profile] = make(map[Provider]*Credentials)
+	}
+	m.sessions[profile][provider] = creds
+	m.mu.Unlock()
+
+	return creds, nil
+}
+
+// runCredentialProcess 执行外部命令并解析其标准输出中的凭据
+// 标准错误同时输出到终端（便于辅助程序提示登录），并在失败时附加到错误信息中
+func runCredentialProcess(ctx context.Context, command string) (*Credentials, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("执行 %s 超时（%s）", args[0], processTimeout)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				return nil, fmt.Errorf("%s 退出码 %d", args[0], exitErr.ExitCode())
+			}
+			return nil, fmt.Errorf("%s 退出码 %d: %s", args[0], exitErr.ExitCode(), msg)
+		}
+		return nil, fmt.Errorf("执行 %s 失败: %w", args[0], err)
+	}
+
+	return parseProcessOutput(stdout.Bytes())
+}
+
+// parseProcessOutput 解析 credential_process 输出
+func parseProcessOutput(data []byte) (*Credentials, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("输出为空，需要输出包含 AccessKey 和 SecretKey 的 JSON")
+	}
+
+	var output processOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("输出不是有效的 JSON: %w", err)
+	}
+	if output.Version != 0 && output.Version != 1 {
+		return nil, fmt.Errorf("不支持的输出版本: %d", output.Version)
+	}
+
+	creds := &Credentials{
+		AccessKey:    output.AccessKey,
+		SecretKey:    output.SecretKey,
+		SessionToken: output.SessionToken,
+		Region:       output.Region,
+	}
+	if creds.AccessKey == "" {
+		creds.AccessKey = output.AccessKeyID
+	}
+	if creds.SecretKey == "" {
+		creds.SecretKey = output.SecretAccessKey
+	}
+	if creds.AccessKey == "" || creds.SecretKey == "" {
+		return nil, fmt.Errorf("输出缺少 AccessKey 或 SecretKey")
+	}
+
+	if output.Expiration != "" {
+		expiration, err := time.Parse(time.RFC3339, output.Expiration)
+		if err != nil {
+			return nil, fmt.Errorf("Expiration 格式错误（需要 RFC3339）: %w", err)
+		}
+		if time.Now().After(expiration) {
+			return nil, fmt.Errorf("返回的凭据已于 %s 过期", expiration.Local().Format("2006-01-02 15:04:05"))
+		}
+		creds.Expiration = expiration
+	}
+
+	return creds, nil
+}
+
+// splitCommand 按 shell 规则拆分命令行（支持单引号、双引号和反斜杠转义，不经过 shell 执行）
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("credential_process 命令格式错误: %s", command)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("credential_process 命令不能为空")
+	}
+	return args, nil
+}
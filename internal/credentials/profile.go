@@ -0,0 +1,103 @@
+package credentials
+
+import "context"
+
+// profileManager 绑定到指定 profile 的凭据管理器
+// 所有读写操作都作用于该 profile，由 CredentialManager.WithProfile 创建
+type profileManager struct {
+	root    *credentialManager
+	profile string
+}
+
+// GetCredentials 获取 profile 下指定云服务商的凭据
+func (p *profileManager) GetCredentials(provider Provider) (*Credentials, error) {
+	return p.root.getCredentials(p.profile, provider)
+}
+
+// SetCredentials 设置 profile 下指定云服务商的凭据
+func (p *profileManager) SetCredentials(provider Provider, creds *Credentials) error {
+	return p.root.setCredentials(p.profile, provider, creds)
+}
+
+// HasCredentials 检查 profile 是否已配置凭据
+func (p *profileManager) HasCredentials(provider Provider) bool {
+	return p.root.hasCredentials(p.profile, provider)
+}
+
+// ListProviders 列出 profile 下所有已配置凭据的云服务商
+func (p *profileManager) ListProviders() []Provider {
+	return p.root.listProviders(p.profile)
+}
+
+// RemoveCredentials 删除 profile 下指定云服务商的凭据
+func (p *profileManager) RemoveCredentials(provider Provider) error {
+	return p.root.removeCredentials(p.profile, provider)
+}
+
+// ActiveProfile 返回绑定的 profile
+func (p *profileManager) ActiveProfile() string {
+	return p.profile
+}
+
+// ListProfiles 列出所有已配置的 profile
+func (p *profileManager) ListProfiles() []string {
+	return p.root.ListProfiles()
+}
+
+// SetDefaultProfile 设置默认 profile
+func (p *profileManager) SetDefaultProfile(profile string) error {
+	return p.root.SetDefaultProfile(profile)
+}
+
+// WithProfile 返回绑定到其他 profile 的凭据管理器
+func (p *profileManager) WithProfile(profile string) CredentialManager {
+	if profile == "" {
+		return p
+	}
+	return p.root.WithProfile(profile)
+}
+
+// profileContextKey context 中保存 profile 的键
+type profileContextKey struct{}
+
+// ContextWithProfile 返回携带 profile 的 context
+// 用于将项目绑定的 profile 传递给 Terraform 执行等下游操作
+func ContextWithProfile(ctx context.Context, profile string) context.Context {
+	if profile == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, profileContextKey{}, profile)
+}
+
+// ProfileFromContext 获取 context 中的 profile，未设置时返回空字符串
+func ProfileFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	profile, _ := ctx.Value(profileContextKey{}).(string)
+	return profile
+}
+
+// managerContextKey context 中保存凭据管理器的键
+type managerContextKey struct{}
+
+// ContextWithManager 返回携带凭据管理器的 context
+// 下游使用该管理器代替默认凭据管理器（如测试中使用独立的凭据文件）
+func ContextWithManager(ctx context.Context, manager CredentialManager) context.Context {
+	if manager == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, managerContextKey{}, manager)
+}
+
+// ManagerFromContext 返回绑定到 context 中 profile 的凭据管理器
+// context 未携带凭据管理器时使用默认凭据管理器
+func ManagerFromContext(ctx context.Context) CredentialManager {
+	manager := GetDefaultManager()
+	if ctx != nil {
+		if m, ok := ctx.Value(managerContextKey{}).(CredentialManager); ok {
+			manager = m
+		}
+	}
+	return manager.WithProfile(ProfileFromContext(ctx))
+}
@@ -0,0 +1,157 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultRoleSessionName AssumeRole 默认会话名称
+	defaultRoleSessionName = "cloudbot"
+
+	// defaultRoleDuration AssumeRole 临时凭据默认有效期（秒）
+	defaultRoleDuration = 3600
+
+	// roleRefreshMargin 临时凭据剩余有效期小于该值时重新获取，避免 Terraform 执行过程中过期
+	roleRefreshMargin = 10 * time.Minute
+
+	// roleAssumeTimeout 单次 AssumeRole 调用超时时间
+	roleAssumeTimeout = 30 * time.Second
+)
+
+// RoleConfig AssumeRole 类型 profile 的配置
+// 在配置文件中与普通凭据使用相同的小节，例如:
+//
+//	[aliyun:client-a]
+//	role_arn = acs:ram::123456789:role/ops
+//	source_profile = default
+type RoleConfig struct {
+	RoleARN         string // 要扮演的角色 ARN
+	SourceProfile   string // 用于调用 STS 的基础身份所在 profile
+	SessionName     string // 角色会话名称
+	DurationSeconds int    // 临时凭据有效期（秒）
+	ExternalID      string // 可选：外部 ID
+	Region          string // 可选：默认区域（同时作为 STS 调用区域）
+}
+
+// RoleAssumer 调用云服务商 STS AssumeRole 获取临时凭据
+// 由 service 包实现并通过 RoleStore.SetRoleAssumer 注入，避免凭据包依赖各云服务商 API
+type RoleAssumer interface {
+	AssumeRole(ctx context.Context, provider Provider, base *Credentials, role *RoleConfig) (*Credentials, error)
+}
+
+// RoleStore AssumeRole 类型 profile 管理接口
+// 由默认凭据管理器实现，通过类型断言获取
+type RoleStore interface {
+	// SetRoleAssumer 设置 STS AssumeRole 调用实现
+	SetRoleAssumer(assumer RoleAssumer)
+
+	// SetRole 将 profile 下指定云服务商配置为 AssumeRole 类型
+	SetRole(profile string, provider Provider, role *RoleConfig) error
+
+	// GetRole 获取 profile 下指定云服务商的 AssumeRole 配置
+	GetRole(profile string, provider Provider) (*RoleConfig, bool)
+}
+
+// SetRoleAssumer 设置 STS AssumeRole 调用实现
+func (m *credentialManager) SetRoleAssumer(assumer RoleAssumer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.assumer = assumer
+}
+
+// SetRole 将 profile 下指定云服务商配置为 AssumeRole 类型
+func (m *credentialManager) SetRole(profile string, provider Provider, role *RoleConfig) error {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	if role.RoleARN == "" {
+		return fmt.Errorf("role_arn 不能为空")
+	}
+	if role.SourceProfile == "" {
+		role.SourceProfile = DefaultProfile
+	}
+	if role.SourceProfile == profile {
+		return fmt.Errorf("source_profile 不能与当前 profile 相同")
+	}
+	if role.SessionName == "" {
+		role.SessionName = defaultRoleSessionName
+	}
+	if role.DurationSeconds <= 0 {
+		role.DurationSeconds = defaultRoleDuration
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// AssumeRole 类型的 profile 不保存长期凭据，也不再使用 credential_process
+	if _, ok := m.profiles[profile][provider]; ok && m.store != nil && !m.store.unlocked() {
+		return ErrStoreLocked
+	}
+	delete(m.profiles[profile], provider)
+	delete(m.processes[profile], provider)
+	delete(m.sessions[profile], provider)
+
+	if m.roles[profile] == nil {
+		m.roles[profile] = make(map[Provider]*RoleConfig)
+	}
+	m.roles[profile][provider] = role
+
+	// 删除原小节后按内存中的配置重新写入，避免残留旧类型的键
+	return m.save(sectionName(profile, provider))
+}
+
+// GetRole 获取 profile 下指定云服务商的 AssumeRole 配置
+func (m *credentialManager) GetRole(profile string, provider Provider) (*RoleConfig, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	role, ok := m.roles[profile][provider]
+	return role, ok
+}
+
+// assumeRole 获取 AssumeRole 类型 profile 的临时凭据
+// 缓存的临时凭据剩余有效期不足 roleRefreshMargin 时重新调用 STS
+func (m *credentialManager) assumeRole(profile string, provider Provider, role *RoleConfig) (*Credentials, error) {
+	m.mu.RLock()
+	cached := m.sessions[profile][provider]
+	assumer := m.assumer
+	m.mu.RUnlock()
+
+	if cached != nil && !cached.ExpiresWithin(roleRefreshMargin) {
+		return cached, nil
+	}
+	if assumer == nil {
+		return nil, fmt.Errorf("profile %s 为 AssumeRole 类型，但未配置 STS 调用实现", profile)
+	}
+
+	// 基础身份可以是长期凭据或 credential_process，不支持角色链
+	base, err := m.getBaseCredentials(role.SourceProfile, provider)
+	if err != nil {
+		return nil, fmt.Errorf("获取 AssumeRole 基础身份 (profile %s) 失败: %w", role.SourceProfile, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), roleAssumeTimeout)
+	defer cancel()
+
+	creds, err := assumer.AssumeRole(ctx, provider, base, role)
+	if err != nil {
+		return nil, fmt.Errorf("%s AssumeRole 失败 (%s): %w", provider, role.RoleARN, err)
+	}
+	if creds.Region == "" {
+		creds.Region = role.Region
+	}
+	if creds.Region == "" {
+		creds.Region = base.Region
+	}
+
+	m.mu.Lock()
+	if m.sessions[profile] == nil {
+		m.sessions[profile] = make(map[Provider]*Credentials)
+	}
+	m.sessions[profile][provider] = creds
+	m.mu.Unlock()
+
+	return creds, nil
+}
@@ -0,0 +1,362 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// PassphraseEnvVar 加密凭据存储的口令环境变量（用于 CI 等无交互环境）
+	PassphraseEnvVar = "CLOUDBOT_CREDENTIAL_PASSPHRASE"
+
+	// DefaultSessionTimeout 解锁后会话缓存的默认有效期
+	DefaultSessionTimeout = 15 * time.Minute
+
+	// encryptedStoreFile 加密凭据文件名（与配置文件位于同一目录）
+	encryptedStoreFile = ".redc.credentials"
+
+	// 加密文件格式版本及 scrypt 参数
+	storeVersion = 1
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	keyLength    = 32
+)
+
+// storeAAD AES-GCM 附加数据，用于绑定文件格式版本
+var storeAAD = []byte("cloudbot-credentials-v1")
+
+// ErrStoreLocked 加密凭据存储未解锁
+var ErrStoreLocked = errors.New("凭据存储已加密且未解锁，请运行 'cloudbot credential unlock' 或设置环境变量 " + PassphraseEnvVar)
+
+// SecureStore 加密凭据存储管理接口
+// 由默认凭据管理器实现，通过类型断言获取：
+//
+//	store, ok := credentials.GetDefaultManager().(credentials.SecureStore)
+type SecureStore interface {
+	// IsEncrypted 是否已启用加密凭据存储
+	IsEncrypted() bool
+
+	// IsLocked 加密凭据存储是否处于锁定状态
+	IsLocked() bool
+
+	// Unlock 使用口令解锁加密凭据存储，并在 timeout 内缓存会话（timeout <= 0 时不缓存）
+	Unlock(passphrase string, timeout time.Duration) error
+
+	// Lock 锁定加密凭据存储并清除会话缓存
+	Lock() error
+
+	// MigrateToEncrypted 将配置文件中的明文凭据迁移到加密存储，返回迁移的凭据数量
+	MigrateToEncrypted(passphrase string) (int, error)
+}
+
+// encryptedFile 加密凭据文件格式
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Salt       string `json:"salt"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// storedCredentials 加密前的凭据明文结构：profile -> provider -> 凭据
+type storedCredentials map[string]map[Provider]storedCredential
+
+type storedCredential struct {
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	Region    string `json:"region,omitempty"`
+}
+
+// sessionCache 会话缓存（保存派生密钥而非口令）
+type sessionCache struct {
+	Key       string    `json:"key"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// encryptedStore 基于口令派生密钥（scrypt + AES-256-GCM）的加密凭据存储
+// 完全离线运行，不依赖系统密钥链
+type encryptedStore struct {
+	path string
+	salt []byte
+	key  []byte // 解锁后的派生密钥，为空表示未解锁
+}
+
+// encryptedStorePath 返回配置文件对应的加密凭据文件路径
+func encryptedStorePath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), encryptedStoreFile)
+}
+
+// openEncryptedStore 打开已存在的加密凭据存储，文件不存在时返回 nil
+func openEncryptedStore(path string) (*encryptedStore, error) {
+	file, err := readEncryptedFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(file.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("加密凭据文件格式错误: %w", err)
+	}
+	return &encryptedStore{path: path, salt: salt}, nil
+}
+
+// newEncryptedStore 使用新的随机盐创建加密凭据存储
+func newEncryptedStore(path, passphrase string) (*encryptedStore, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("生成随机盐失败: %w", err)
+	}
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedStore{path: path, salt: salt, key: key}, nil
+}
+
+// deriveKey 使用 scrypt 从口令派生密钥
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("口令不能为空")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %w", err)
+	}
+	return key, nil
+}
+
+// readEncryptedFile 读取加密凭据文件
+func readEncryptedFile(path string) (*encryptedFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("加密凭据文件格式错误: %w", err)
+	}
+	if file.Version != storeVersion || file.KDF != "scrypt" {
+		return nil, fmt.Errorf("不支持的加密凭据文件版本: %d (%s)", file.Version, file.KDF)
+	}
+	return &file, nil
+}
+
+// unlocked 是否已解锁
+func (s *encryptedStore) unlocked() bool {
+	return len(s.key) > 0
+}
+
+// unlock 使用口令解锁，口令错误时返回错误
+func (s *encryptedStore) unlock(passphrase string) (storedCredentials, error) {
+	key, err := deriveKey(passphrase, s.salt)
+	if err != nil {
+		return nil, err
+	}
+	return s.unlockWithKey(key)
+}
+
+// unlockWithKey 使用派生密钥解锁
+func (s *encryptedStore) unlockWithKey(key []byte) (storedCredentials, error) {
+	s.key = key
+	creds, err := s.load()
+	if err != nil {
+		s.key = nil
+		return nil, err
+	}
+	return creds, nil
+}
+
+// load 解密凭据文件
+func (s *encryptedStore) load() (storedCredentials, error) {
+	if !s.unlocked() {
+		return nil, ErrStoreLocked
+	}
+
+	file, err := readEncryptedFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := base64.StdEncoding.DecodeString(file.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("加密凭据文件格式错误: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(file.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("加密凭据文件格式错误: %w", err)
+	}
+
+	gcm, err := newGCM(s.key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, storeAAD)
+	if err != nil {
+		return nil, fmt.Errorf("解密凭据失败（口令错误或文件已损坏）")
+	}
+
+	creds := make(storedCredentials)
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return nil, fmt.Errorf("解析加密凭据失败: %w", err)
+	}
+	return creds, nil
+}
+
+// save 加密并写入凭据文件（每次写入使用新的随机 nonce）
+func (s *encryptedStore) save(creds storedCredentials) error {
+	if !s.unlocked() {
+		return ErrStoreLocked
+	}
+
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("序列化凭据失败: %w", err)
+	}
+
+	gcm, err := newGCM(s.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("生成随机 nonce 失败: %w", err)
+	}
+
+	file := encryptedFile{
+		Version:    storeVersion,
+		KDF:        "scrypt",
+		Salt:       base64.StdEncoding.EncodeToString(s.salt),
+		N:          scryptN,
+		R:          scryptR,
+		P:          scryptP,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, storeAAD)),
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化加密凭据失败: %w", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中断导致凭据文件损坏
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("写入加密凭据文件失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入加密凭据文件失败: %w", err)
+	}
+	return nil
+}
+
+// newGCM 创建 AES-256-GCM 实例
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("初始化加密算法失败: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sessionPath 返回会话缓存文件路径
+// 缓存位于当前用户的临时目录中，按加密凭据文件的绝对路径区分
+func (s *encryptedStore) sessionPath() string {
+	absPath, err := filepath.Abs(s.path)
+	if err != nil {
+		absPath = s.path
+	}
+	sum := sha256.Sum256([]byte(absPath))
+
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, fmt.Sprintf("cloudbot-%d", os.Getuid()), "session-"+hex.EncodeToString(sum[:8]))
+}
+
+// loadSession 读取未过期的会话缓存，返回派生密钥
+func (s *encryptedStore) loadSession() []byte {
+	path := s.sessionPath()
+	if err := checkSessionDir(filepath.Dir(path)); err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var session sessionCache
+	if err := json.Unmarshal(data, &session); err != nil || time.Now().After(session.ExpiresAt) {
+		s.clearSession()
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(session.Key)
+	if err != nil {
+		return nil
+	}
+	return key
+}
+
+// saveSession 写入会话缓存
+func (s *encryptedStore) saveSession(timeout time.Duration) error {
+	path := s.sessionPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("创建会话缓存目录失败: %w", err)
+	}
+	if err := checkSessionDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(sessionCache{
+		Key:       base64.StdEncoding.EncodeToString(s.key),
+		ExpiresAt: time.Now().Add(timeout),
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// clearSession 删除会话缓存
+func (s *encryptedStore) clearSession() error {
+	if err := os.Remove(s.sessionPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除会话缓存失败: %w", err)
+	}
+	return nil
+}
+
+// toStored 将内存中的凭据转换为存储结构
+func toStored(profiles map[string]map[Provider]*Credentials) storedCredentials {
+	stored := make(storedCredentials)
+	for profile, creds := range profiles {
+		stored[profile] = make(map[Provider]storedCredential)
+		for provider, c := range creds {
+			stored[profile][provider] = storedCredential{
+				AccessKey: c.AccessKey,
+				SecretKey: c.SecretKey,
+				Region:    c.Region,
+			}
+		}
+	}
+	return stored
+}
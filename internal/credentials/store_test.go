@@ -0,0 +1,80 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestMigrateToEncryptedSkipsEnvCredentials(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv(PassphraseEnvVar, "")
+	t.Setenv("TENCENTCLOUD_SECRET_ID", "AKIDENV")
+	t.Setenv("TENCENTCLOUD_SECRET_KEY", "env-secret")
+	path := filepath.Join(t.TempDir(), ".redc.ini")
+	if err := os.WriteFile(path, []byte("[aliyun]\naccess_key = LTAIFILE\nsecret_key = file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := NewCredentialManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := manager.(SecureStore).MigrateToEncrypted("correct passphrase")
+	if err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("迁移了 %d 个凭据，期望只迁移配置文件中的 1 个", count)
+	}
+
+	// 加密存储中不应包含环境变量中的腾讯云凭据
+	t.Setenv("TENCENTCLOUD_SECRET_ID", "")
+	t.Setenv("TENCENTCLOUD_SECRET_KEY", "")
+	t.Setenv(PassphraseEnvVar, "correct passphrase")
+	manager, err = NewCredentialManager(path)
+	if err != nil {
+		t.Fatalf("使用口令解锁失败: %v", err)
+	}
+	if !manager.HasCredentials(ProviderAliyun) {
+		t.Error("加密存储中缺少配置文件中的阿里云凭据")
+	}
+	if manager.HasCredentials(ProviderTencent) {
+		t.Error("环境变量中的腾讯云凭据被写入了加密存储")
+	}
+
+	// 口令错误时返回错误，而不是静默保持锁定
+	t.Setenv(PassphraseEnvVar, "wrong passphrase")
+	if _, err := NewCredentialManager(path); err == nil {
+		t.Error("口令错误时应返回错误")
+	}
+}
+
+func TestCheckSessionDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 下不检查会话缓存目录")
+	}
+	dir := filepath.Join(t.TempDir(), "cloudbot")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSessionDir(dir); err != nil {
+		t.Errorf("0700 目录检查失败: %v", err)
+	}
+
+	// 其他用户可以读取的目录和指向其他位置的符号链接都不能用于缓存密钥
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSessionDir(dir); err == nil {
+		t.Error("0755 目录应检查失败")
+	}
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(t.TempDir(), link); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSessionDir(link); err == nil {
+		t.Error("符号链接应检查失败")
+	}
+}
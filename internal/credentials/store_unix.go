@@ -0,0 +1,29 @@
+//go:build !windows
+
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkSessionDir 检查会话缓存目录为当前用户所有的真实目录且权限为 0700
+// 缓存目录可能位于共享的 /tmp 中，其他用户可以抢先创建同名目录或符号链接
+func checkSessionDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("读取会话缓存目录失败: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("会话缓存路径 %s 不是目录", dir)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("会话缓存目录 %s 不属于当前用户", dir)
+	}
+	if info.Mode().Perm() != 0700 {
+		return fmt.Errorf("会话缓存目录 %s 的权限为 %o，应为 0700", dir, info.Mode().Perm())
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package credentials
+
+// checkSessionDir Windows 下临时目录位于用户配置目录中，不与其他用户共享
+func checkSessionDir(dir string) error {
+	return nil
+}
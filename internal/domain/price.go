@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"fmt"
+	"time"
+)
+
 // PriceInfo 表示一个模板的价格信息
 type PriceInfo struct {
 	Provider    string  `json:"provider"`     // 云服务商：aliyun, tencent, aws, vultr
@@ -28,3 +33,72 @@ type PriceRange struct {
 	MaxPerMonth float64 `json:"max_per_month"` // 最高每月价格
 }
 
+
+// DefaultUSDToCNY 默认的美元兑人民币汇率
+const DefaultUSDToCNY = 7.2
+
+// usdToCNY 跨云服务商比价使用的美元兑人民币汇率，可在配置文件 [price] 小节的 usd_to_cny 修改
+var usdToCNY = DefaultUSDToCNY
+
+// SetUSDToCNY 设置比价使用的美元兑人民币汇率（启动时根据配置设置），rate 不大于 0 时忽略
+func SetUSDToCNY(rate float64) {
+	if rate > 0 {
+		usdToCNY = rate
+	}
+}
+
+// USDToCNY 返回比价使用的美元兑人民币汇率，用于在比价结果中注明
+func USDToCNY() float64 {
+	return usdToCNY
+}
+
+// ConvertToCNY 将价格统一换算为人民币，用于跨云服务商比价
+func ConvertToCNY(amount float64, currency string) float64 {
+	switch currency {
+	case "USD":
+		return amount * usdToCNY
+	default:
+		return amount
+	}
+}
+
+// priceTimeLayouts 价格更新时间支持的格式
+var priceTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// ParsePriceTime 解析价格更新时间
+func ParsePriceTime(value string) (time.Time, error) {
+	for _, layout := range priceTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的更新时间: %s（支持 2006-01-02、2006-01-02 15:04:05 或 RFC3339 格式）", value)
+}
+
+// Key 返回价格记录的唯一标识（云服务商/模板/区域）
+func (p *PriceInfo) Key() string {
+	return fmt.Sprintf("%s/%s/%s", p.Provider, p.Template, p.Region)
+}
+
+// Validate 校验价格记录是否完整有效
+func (p *PriceInfo) Validate() error {
+	if p.Provider == "" {
+		return fmt.Errorf("provider 不能为空")
+	}
+	if p.Template == "" {
+		return fmt.Errorf("template 不能为空")
+	}
+	if p.Currency != "CNY" && p.Currency != "USD" {
+		return fmt.Errorf("不支持的货币单位: %q（支持 CNY, USD）", p.Currency)
+	}
+	if p.PricePerHour < 0 || p.PricePerMonth < 0 {
+		return fmt.Errorf("价格不能为负数")
+	}
+	if p.PricePerHour == 0 && p.PricePerMonth == 0 {
+		return fmt.Errorf("price_per_hour 和 price_per_month 不能同时为 0")
+	}
+	if _, err := ParsePriceTime(p.UpdatedAt); err != nil {
+		return err
+	}
+	return nil
+}
@@ -9,6 +9,23 @@ type Project struct {
 	CreatedAt   time.Time `json:"created_at"`   // 创建时间
 	UpdatedAt   time.Time `json:"updated_at"`   // 更新时间
 	Scenarios   []Scenario `json:"scenarios"`   // 场景列表
+	CredentialProfile string `json:"credential_profile,omitempty"` // 绑定的凭据 profile（为空表示使用当前默认 profile）
+	Backend     *BackendConfig `json:"backend,omitempty"`  // Terraform 远程状态后端（为空表示使用本地状态）
+	RequiredTerraformVersion string `json:"required_terraform_version,omitempty"` // 要求的 Terraform/OpenTofu 版本（如 ">= 1.5"、"tofu ~> 1.8"，为空表示不限）
+	Version     int64     `json:"version,omitempty"`  // 元数据版本（共享元数据存储用于乐观并发控制）
+}
+
+// Terraform 远程状态后端类型
+const (
+	BackendS3   = "s3"   // S3 兼容存储（AWS S3、阿里云 OSS、腾讯云 COS、MinIO 等）
+	BackendHTTP = "http" // HTTP 后端
+	BackendPG   = "pg"   // PostgreSQL
+)
+
+// BackendConfig 项目的 Terraform 远程状态后端配置（记录在 project.ini 的 [backend] 小节）
+type BackendConfig struct {
+	Type    string            `json:"type"`    // 后端类型：s3, http, pg
+	Options map[string]string `json:"options"` // 后端参数（如 bucket、endpoint、address）
 }
 
 // Scenario 表示一个场景（部署实例）
@@ -17,9 +34,60 @@ type Scenario struct {
 	Name        string    `json:"name"`         // 场景名称
 	Template    string    `json:"template"`    // 模板路径（如 aliyun/ecs）
 	Path        string    `json:"path"`         // 场景路径
-	Status      string    `json:"status"`       // 状态：pending, deployed, destroyed
+	Status      string    `json:"status"`       // 状态：pending, deployed, destroyed, interrupted（部署或销毁被中断）
 	CreatedAt   time.Time `json:"created_at"`   // 创建时间
 	UpdatedAt   time.Time `json:"updated_at"`   // 更新时间
+	BidStrategy    string  `json:"bid_strategy,omitempty"`     // 抢占式实例出价策略（如 p95:10）
+	SpotPriceLimit float64 `json:"spot_price_limit,omitempty"` // 部署时使用的抢占式实例出价上限（每小时）
+	CreatedBy      string  `json:"created_by,omitempty"`       // 创建者（user@host，共享元数据存储中用于区分团队成员）
+	Version        int64   `json:"version,omitempty"`          // 元数据版本（共享元数据存储用于乐观并发控制）
+	Drift          *ScenarioDrift `json:"drift,omitempty"`     // 最近一次漂移检测结果（scenario drift 写入，部署或销毁后清除）
+	NodeCount      int            `json:"node_count,omitempty"`      // 期望的节点数（deploy --node 或 scenario scale 设置，之后的部署沿用）
+	InterruptedRun string         `json:"interrupted_run,omitempty"` // 被中断的 Terraform 运行 ID（scenario logs 查看），操作成功后清除
+}
+
+// 资源漂移类型
+const (
+	DriftDeleted  = "deleted"  // 资源已在云端删除（控制台手动释放、抢占式实例被回收等）
+	DriftStopped  = "stopped"  // 云主机已停止
+	DriftModified = "modified" // 资源属性在 Terraform 之外被修改
+)
+
+// ScenarioDrift 场景的漂移检测结果（Terraform 状态与云端实际资源的差异）
+type ScenarioDrift struct {
+	CheckedAt time.Time       `json:"checked_at"`          // 检测时间
+	Drifted   bool            `json:"drifted"`             // 是否存在漂移
+	Resources []DriftResource `json:"resources,omitempty"` // 发生漂移的资源
+}
+
+// DriftResource 发生漂移的单个资源
+type DriftResource struct {
+	Address string `json:"address"` // 资源地址
+	Type    string `json:"type"`    // 资源类型
+	Kind    string `json:"kind"`    // 漂移类型：deleted, stopped, modified
+}
+
+// ScenarioPlan 保存的 Terraform 计划（scenario plan 生成，scenario apply --plan 应用）
+type ScenarioPlan struct {
+	ID             string    `json:"id"`                         // 计划 ID
+	CreatedAt      time.Time `json:"created_at"`                 // 生成时间
+	ConfigHash     string    `json:"config_hash"`                // 生成计划时场景配置文件和变量的摘要
+	StateLineage   string    `json:"state_lineage,omitempty"`    // 生成计划时状态的 lineage
+	StateSerial    int64     `json:"state_serial"`               // 生成计划时状态的 serial
+	BidStrategy    string    `json:"bid_strategy,omitempty"`     // 抢占式实例出价策略
+	SpotPriceLimit float64   `json:"spot_price_limit,omitempty"` // 抢占式实例出价上限（每小时）
+	NodeCount      int       `json:"node_count,omitempty"`       // 计划使用的节点数
+}
+
+// ScenarioRun 一次 Terraform 命令的执行记录（保存在场景目录的 .runs/index.jsonl，输出保存在 .runs/<ID>.log）
+type ScenarioRun struct {
+	ID         string    `json:"id"`              // 运行 ID，如 20240101-120000-apply
+	Op         string    `json:"op"`              // 操作：init, plan, apply, destroy
+	Args       []string  `json:"args"`            // 命令参数（凭据等敏感值已隐藏）
+	StartedAt  time.Time `json:"started_at"`      // 开始时间
+	DurationMs int64     `json:"duration_ms"`     // 耗时（毫秒）
+	ExitCode   int       `json:"exit_code"`       // 退出码，-1 表示命令未能执行（如可执行文件不存在或被取消）
+	Error      string    `json:"error,omitempty"` // 失败原因
 }
 
 // Template 表示一个模板
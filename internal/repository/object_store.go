@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+)
+
+// objectStoreTimeout 单次对象存储请求的超时时间
+const objectStoreTimeout = 30 * time.Second
+
+// ObjectAbsent 作为 Put 的 match 参数时，表示仅在对象不存在时写入
+const ObjectAbsent = "*"
+
+var (
+	// ErrObjectNotFound 对象不存在
+	ErrObjectNotFound = errors.New("对象不存在")
+
+	// ErrPreconditionFailed 条件写入失败（对象已存在或已被其他人修改）
+	ErrPreconditionFailed = errors.New("条件写入失败")
+
+	// ErrConditionalWriteUnsupported 对象存储不支持条件写入，无法安全地共享元数据
+	ErrConditionalWriteUnsupported = errors.New("对象存储不支持条件写入（If-Match / If-None-Match）")
+)
+
+// ObjectStore 共享元数据使用的对象存储接口
+type ObjectStore interface {
+	// Get 读取对象内容及其 ETag，对象不存在时返回 ErrObjectNotFound
+	Get(ctx context.Context, key string) ([]byte, string, error)
+
+	// Put 写入对象并返回新的 ETag
+	// match 为空时无条件写入；为 ObjectAbsent 时仅在对象不存在时写入；否则仅在对象 ETag 与 match 一致时写入
+	// 条件不满足时返回 ErrPreconditionFailed
+	Put(ctx context.Context, key string, data []byte, match string) (string, error)
+
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+
+	// List 列出指定前缀下的所有对象 key
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// S3StoreConfig S3 兼容对象存储配置
+type S3StoreConfig struct {
+	Endpoint  string // 访问地址，为空时使用 AWS S3（https://s3.<region>.amazonaws.com）
+	Region    string // 签名使用的区域
+	Bucket    string // 存储桶
+	PathStyle bool   // 是否使用路径风格访问（MinIO 等需要开启）
+
+	// Credentials 获取访问凭据（每次请求调用，便于使用临时凭据）
+	Credentials func() (*credentials.Credentials, error)
+}
+
+// s3ObjectStore S3 兼容对象存储实现（AWS S3 / 阿里云 OSS / 腾讯云 COS / MinIO）
+// 使用 Signature Version 4 签名，条件写入依赖存储对 If-Match / If-None-Match 的支持。
+// 不支持条件写入的存储会忽略这两个请求头直接覆盖对象，因此首次条件写入前先探测，不支持时拒绝写入
+type s3ObjectStore struct {
+	config     S3StoreConfig
+	endpoint   *url.URL
+	httpClient *http.Client
+
+	probeOnce sync.Once
+	probeErr  error // 条件写入探测结果
+}
+
+// NewS3ObjectStore 创建 S3 兼容对象存储实例
+func NewS3ObjectStore(cfg S3StoreConfig) (ObjectStore, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("对象存储缺少 bucket 配置")
+	}
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("对象存储缺少 region 配置")
+	}
+	if cfg.Credentials == nil {
+		return nil, fmt.Errorf("对象存储缺少访问凭据")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("对象存储地址无效: %w", err)
+	}
+
+	return &s3ObjectStore{
+		config:     cfg,
+		endpoint:   u,
+		httpClient: &http.Client{Timeout: objectStoreTimeout},
+	}, nil
+}
+
+// Get 读取对象
+func (s *s3ObjectStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	resp, body, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, resp.Header.Get("ETag"), nil
+	case http.StatusNotFound:
+		return nil, "", ErrObjectNotFound
+	default:
+		return nil, "", s3Error(resp, body)
+	}
+}
+
+// Put 写入对象，条件写入前确认存储支持条件写入
+func (s *s3ObjectStore) Put(ctx context.Context, key string, data []byte, match string) (string, error) {
+	if match != "" {
+		s.probeOnce.Do(func() { s.probeErr = s.probeConditionalWrite(ctx, key) })
+		if s.probeErr != nil {
+			return "", s.probeErr
+		}
+	}
+	return s.put(ctx, key, data, match)
+}
+
+// probeConditionalWrite 在 key 所在目录写入临时对象，验证重复创建和 ETag 不匹配的写入都会被拒绝
+func (s *s3ObjectStore) probeConditionalWrite(ctx context.Context, key string) error {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("生成探测对象名失败: %w", err)
+	}
+	probe := path.Join(path.Dir(key), fmt.Sprintf(".conditional-write-probe-%x", suffix))
+	defer s.Delete(context.Background(), probe)
+
+	if _, err := s.put(ctx, probe, []byte("{}"), ObjectAbsent); err != nil {
+		return fmt.Errorf("探测对象存储条件写入失败: %w", err)
+	}
+	if _, err := s.put(ctx, probe, []byte("{}"), ObjectAbsent); !errors.Is(err, ErrPreconditionFailed) {
+		return conditionalWriteError("If-None-Match", err)
+	}
+	if _, err := s.put(ctx, probe, []byte("{}"), `"cloudbot-probe-mismatch"`); !errors.Is(err, ErrPreconditionFailed) {
+		return conditionalWriteError("If-Match", err)
+	}
+	return nil
+}
+
+// conditionalWriteError 探测到条件写入未生效时的错误（请求本身失败时附带原因）
+func conditionalWriteError(header string, err error) error {
+	if err != nil {
+		return fmt.Errorf("%w: 探测 %s 失败: %v", ErrConditionalWriteUnsupported, header, err)
+	}
+	return fmt.Errorf("%w: %s 条件不满足时仍然写入成功，无法防止覆盖其他成员的修改", ErrConditionalWriteUnsupported, header)
+}
+
+// put 写入对象
+// 阿里云 OSS 不支持 If-None-Match，使用 x-oss-forbid-overwrite 实现仅在对象不存在时写入
+func (s *s3ObjectStore) put(ctx context.Context, key string, data []byte, match string) (string, error) {
+	headers := map[string]string{"Content-Type": "application/json"}
+	switch match {
+	case "":
+	case ObjectAbsent:
+		headers["If-None-Match"] = "*"
+		if s.isOSS() {
+			headers["x-oss-forbid-overwrite"] = "true"
+		}
+	default:
+		headers["If-Match"] = match
+	}
+
+	resp, body, err := s.do(ctx, http.MethodPut, key, nil, headers, data)
+	if err != nil {
+		return "", err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header.Get("ETag"), nil
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return "", ErrPreconditionFailed
+	case http.StatusNotFound:
+		// If-Match 写入已被删除的对象
+		if match != "" && match != ObjectAbsent {
+			return "", ErrPreconditionFailed
+		}
+		return "", s3Error(resp, body)
+	default:
+		return "", s3Error(resp, body)
+	}
+}
+
+// isOSS 是否为阿里云 OSS 的访问地址
+func (s *s3ObjectStore) isOSS() bool {
+	return strings.HasSuffix(s.endpoint.Hostname(), ".aliyuncs.com")
+}
+
+// Delete 删除对象
+func (s *s3ObjectStore) Delete(ctx context.Context, key string) error {
+	resp, body, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error(resp, body)
+	}
+	return nil
+}
+
+// listResult ListObjectsV2 响应
+type listResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List 列出前缀下的对象（ListObjectsV2，自动翻页）
+func (s *s3ObjectStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, body, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, s3Error(resp, body)
+		}
+
+		var result listResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("解析对象列表失败: %w", err)
+		}
+		for _, content := range result.Contents {
+			keys = append(keys, content.Key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// do 构建、签名并发送请求
+func (s *s3ObjectStore) do(ctx context.Context, method, key string, query url.Values, headers map[string]string, payload []byte) (*http.Response, []byte, error) {
+	creds, err := s.config.Credentials()
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取对象存储凭据失败: %w", err)
+	}
+
+	u := *s.endpoint
+	path := "/" + key
+	if s.config.PathStyle {
+		path = "/" + s.config.Bucket + path
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+	}
+	u.Path = path
+	u.RawPath = s3Escape(path)
+	// 签名要求空格编码为 %20
+	u.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	signS3Request(req, payload, s.config.Region, creds)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("请求对象存储失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取对象存储响应失败: %w", err)
+	}
+	return resp, body, nil
+}
+
+// signS3Request 对请求进行 Signature Version 4 签名
+// 签名文档: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func signS3Request(req *http.Request, payload []byte, region string, creds *credentials.Credentials) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s",
+		req.Method, req.URL.EscapedPath(), req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash)
+
+	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", date, region)
+	stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s\n%s", amzDate, credentialScope, sha256Hex([]byte(canonicalRequest)))
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKey, credentialScope, signedHeaders, signature))
+}
+
+// s3Escape 按 S3 签名规则编码路径（保留 /，其余非保留字符全部百分号编码）
+func s3Escape(path string) string {
+	var b strings.Builder
+	for _, c := range []byte(path) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error 将错误响应转换为错误信息
+func s3Error(resp *http.Response, body []byte) error {
+	var result struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.Unmarshal(body, &result); err == nil && result.Code != "" {
+		return fmt.Errorf("对象存储返回错误: %d %s: %s", resp.StatusCode, result.Code, result.Message)
+	}
+	return fmt.Errorf("对象存储返回错误: %d, %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// sha256Hex 计算 SHA256 摘要并返回十六进制字符串（用于请求签名）
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 计算 HMAC-SHA256（用于请求签名）
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
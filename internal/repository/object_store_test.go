@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/repository"
+)
+
+// newConditionalS3Server 模拟 S3 兼容存储，conditional 为 false 时忽略条件写入请求头
+func newConditionalS3Server(t *testing.T, conditional bool) *httptest.Server {
+	var mu sync.Mutex
+	objects := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		version, exists := objects[r.URL.Path]
+		etag := fmt.Sprintf(`"%d"`, version)
+		switch r.Method {
+		case http.MethodPut:
+			if conditional && ((r.Header.Get("If-None-Match") == "*" && exists) ||
+				(r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != etag)) {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			objects[r.URL.Path]++
+			w.Header().Set("ETag", fmt.Sprintf(`"%d"`, objects[r.URL.Path]))
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestS3ObjectStoreConditionalWriteProbe(t *testing.T) {
+	for _, conditional := range []bool{true, false} {
+		t.Run(fmt.Sprintf("conditional=%v", conditional), func(t *testing.T) {
+			server := newConditionalS3Server(t, conditional)
+			store, err := repository.NewS3ObjectStore(repository.S3StoreConfig{
+				Endpoint:  server.URL,
+				Region:    "us-east-1",
+				Bucket:    "team",
+				PathStyle: true,
+				Credentials: func() (*credentials.Credentials, error) {
+					return &credentials.Credentials{AccessKey: "AKID", SecretKey: "secret"}, nil
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = store.Put(context.Background(), "cloudbot/projects/p/project.json", []byte("{}"), repository.ObjectAbsent)
+			if conditional && err != nil {
+				t.Fatalf("支持条件写入时写入失败: %v", err)
+			}
+			// 不支持条件写入时拒绝写入，而不是静默覆盖
+			if !conditional && !errors.Is(err, repository.ErrConditionalWriteUnsupported) {
+				t.Fatalf("错误 = %v，期望不支持条件写入", err)
+			}
+		})
+	}
+}
@@ -27,6 +27,17 @@ type PriceRepository interface {
 
 	// ComparePrices 比对指定模板类型的价格
 	ComparePrices(templateType string) (*domain.PriceComparison, error)
+
+	// MergePrices 合并价格记录并保存到 prices.json
+	// 以 云服务商/模板/区域 为唯一标识，同一记录保留更新时间最新的一条
+	MergePrices(prices []*domain.PriceInfo) (*MergeResult, error)
+}
+
+// MergeResult 价格合并结果
+type MergeResult struct {
+	Added     int // 新增的记录数
+	Updated   int // 被更新的记录数
+	Unchanged int // 因已有更新的记录而忽略的记录数
 }
 
 // PriceFetcher 价格查询器接口（避免循环依赖）
@@ -68,10 +79,15 @@ func (r *priceRepository) SetPriceFetcher(fetcher PriceFetcher) {
 	r.priceFetcher = fetcher
 }
 
+// priceFilePath 返回价格文件路径
+func (r *priceRepository) priceFilePath() string {
+	return filepath.Join(r.config.WorkDir, "prices.json")
+}
+
 // loadPrices 加载价格数据
 func (r *priceRepository) loadPrices() {
 	// 尝试从配置文件加载价格
-	priceFile := filepath.Join(r.config.WorkDir, "prices.json")
+	priceFile := r.priceFilePath()
 	if _, err := os.Stat(priceFile); os.IsNotExist(err) {
 		// 如果文件不存在，使用默认价格数据
 		r.prices = r.getDefaultPrices()
@@ -213,7 +229,7 @@ func (r *priceRepository) GetPrice(provider, template string) (*domain.PriceInfo
 	}
 
 	// 从静态价格数据中查找
-	for _, price := range r.prices {
+	for _, price := range r.staticPrices() {
 		if price.Provider == provider && price.Template == template {
 			return price, nil
 		}
@@ -224,7 +240,15 @@ func (r *priceRepository) GetPrice(provider, template string) (*domain.PriceInfo
 
 // ListPrices 列出所有价格信息
 func (r *priceRepository) ListPrices() ([]*domain.PriceInfo, error) {
-	return r.prices, nil
+	return r.staticPrices(), nil
+}
+
+// staticPrices 返回静态价格数据
+// MergePrices 持有写锁整体替换价格列表，读取时持有读锁
+func (r *priceRepository) staticPrices() []*domain.PriceInfo {
+	r.cacheMutex.RLock()
+	defer r.cacheMutex.RUnlock()
+	return r.prices
 }
 
 // GetPricesByType 根据模板类型获取价格列表
@@ -253,7 +277,7 @@ func (r *priceRepository) GetPricesByType(templateType string) ([]*domain.PriceI
 
 	// 从静态价格数据中查找
 	var result []*domain.PriceInfo
-	for _, price := range r.prices {
+	for _, price := range r.staticPrices() {
 		if matchesType(price.Template, templateType) {
 			result = append(result, price)
 		}
@@ -361,14 +385,66 @@ func (r *priceRepository) ComparePrices(templateType string) (*domain.PriceCompa
 	}, nil
 }
 
-// convertToCNY 将价格转换为人民币（简化版本，实际应该使用实时汇率）
-func convertToCNY(amount float64, currency string) float64 {
-	if currency == "CNY" {
-		return amount
+// MergePrices 合并价格记录并保存到 prices.json
+// 读取、合并和写入期间持有写锁，避免并发合并时丢失记录
+func (r *priceRepository) MergePrices(prices []*domain.PriceInfo) (*MergeResult, error) {
+	r.cacheMutex.Lock()
+	defer r.cacheMutex.Unlock()
+
+	result := &MergeResult{}
+
+	index := make(map[string]int, len(r.prices))
+	merged := make([]*domain.PriceInfo, len(r.prices))
+	copy(merged, r.prices)
+	for i, p := range merged {
+		index[p.Key()] = i
+	}
+
+	for _, incoming := range prices {
+		if err := incoming.Validate(); err != nil {
+			return nil, fmt.Errorf("价格记录 %s 无效: %w", incoming.Key(), err)
+		}
+
+		i, ok := index[incoming.Key()]
+		if !ok {
+			index[incoming.Key()] = len(merged)
+			merged = append(merged, incoming)
+			result.Added++
+			continue
+		}
+
+		// 已有记录的时间无法解析时视为更旧的记录
+		incomingTime, _ := domain.ParsePriceTime(incoming.UpdatedAt)
+		existingTime, err := domain.ParsePriceTime(merged[i].UpdatedAt)
+		if err == nil && !incomingTime.After(existingTime) {
+			result.Unchanged++
+			continue
+		}
+		merged[i] = incoming
+		result.Updated++
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Key() < merged[j].Key()
+	})
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化价格数据失败: %w", err)
 	}
-	// 简化的汇率转换（USD to CNY，实际应该使用实时汇率）
-	if currency == "USD" {
-		return amount * 7.2 // 假设 1 USD = 7.2 CNY
+	if err := os.WriteFile(r.priceFilePath(), data, 0644); err != nil {
+		return nil, fmt.Errorf("保存价格文件失败: %w", err)
 	}
-	return amount
+
+	r.prices = merged
+
+	// 清除缓存，避免继续返回合并前的价格
+	r.cache = make(map[string]*cachedPrice)
+
+	return result, nil
+}
+
+// convertToCNY 将价格转换为人民币
+func convertToCNY(amount float64, currency string) float64 {
+	return domain.ConvertToCNY(amount, currency)
 }
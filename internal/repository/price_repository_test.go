@@ -0,0 +1,53 @@
+package repository_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/repository"
+)
+
+func TestMergePricesConcurrent(t *testing.T) {
+	cfg := &config.Config{WorkDir: t.TempDir()}
+	repo := repository.NewPriceRepository(cfg)
+	before, err := repo.ListPrices()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 并发合并不同区域的价格，每次合并都基于上一次的结果，不能丢失记录
+	const count = 20
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			price := &domain.PriceInfo{Provider: "aws", Template: "aws/ec2", Region: fmt.Sprintf("region-%d", i),
+				PricePerHour: 0.1, Currency: "USD", UpdatedAt: "2024-06-01"}
+			if _, err := repo.MergePrices([]*domain.PriceInfo{price}); err != nil {
+				t.Errorf("合并价格失败: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	after, err := repo.ListPrices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before)+count {
+		t.Errorf("合并后价格记录数 = %d，期望 %d", len(after), len(before)+count)
+	}
+
+	// prices.json 中保存的也是全部记录
+	saved, err := repository.NewPriceRepository(cfg).ListPrices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != len(after) {
+		t.Errorf("prices.json 中的价格记录数 = %d，期望 %d", len(saved), len(after))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/lucksec/cloudbot/internal/config"
@@ -40,8 +41,35 @@ type ProjectRepository interface {
 
 	// UpdateScenario 更新场景信息
 	UpdateScenario(projectName string, scenario *domain.Scenario) error
+
+	// UpdateProject 更新项目配置（project.ini）
+	UpdateProject(project *domain.Project) error
+
+	// GetProjectVars 获取项目级 Terraform 变量（项目目录下的 cloudbot.auto.tfvars.json）
+	GetProjectVars(projectName string) (map[string]interface{}, error)
+
+	// SaveProjectVars 保存项目级 Terraform 变量，vars 为空时删除变量文件
+	SaveProjectVars(projectName string, vars map[string]interface{}) error
+
+	// GetScenarioVars 获取场景级 Terraform 变量（场景目录下的 cloudbot.auto.tfvars.json）
+	GetScenarioVars(projectName, scenarioID string) (map[string]interface{}, error)
+
+	// SaveScenarioVars 保存场景级 Terraform 变量，vars 为空时删除变量文件
+	SaveScenarioVars(projectName, scenarioID string, vars map[string]interface{}) error
+
+	// UpdateProjectVars 读取项目级变量，由 update 修改后保存
+	// 共享存储中读取后变量被其他成员修改时返回 ErrVersionConflict
+	UpdateProjectVars(projectName string, update func(vars map[string]interface{})) error
+
+	// UpdateScenarioVars 读取场景级变量，由 update 修改后保存
+	// 共享存储中读取后变量被其他成员修改时返回 ErrVersionConflict
+	UpdateScenarioVars(projectName, scenarioID string, update func(vars map[string]interface{})) error
 }
 
+// VarsFileName 用户自定义 Terraform 变量文件名
+// 场景目录下的文件会被 Terraform 自动加载（*.auto.tfvars.json）
+const VarsFileName = "cloudbot.auto.tfvars.json"
+
 // projectRepository 项目仓库实现
 type projectRepository struct {
 	config *config.Config
@@ -119,6 +147,19 @@ func (r *projectRepository) GetProject(name string) (*domain.Project, error) {
 				project.UpdatedAt = t
 			}
 		}
+		project.CredentialProfile = section.Key("credential_profile").String()
+		project.RequiredTerraformVersion = section.Key("required_terraform_version").String()
+	}
+	if section, err := cfg.GetSection("backend"); err == nil && section.Key("type").String() != "" {
+		project.Backend = &domain.BackendConfig{
+			Type:    section.Key("type").String(),
+			Options: make(map[string]string),
+		}
+		for _, key := range section.Keys() {
+			if key.Name() != "type" {
+				project.Backend.Options[key.Name()] = key.String()
+			}
+		}
 	}
 
 	// 加载场景列表（避免递归调用，直接读取目录）
@@ -312,6 +353,16 @@ func (r *projectRepository) UpdateScenario(projectName string, scenario *domain.
 	return r.saveScenarioMetadata(projectName, scenario)
 }
 
+// UpdateProject 更新项目配置
+func (r *projectRepository) UpdateProject(project *domain.Project) error {
+	if _, err := os.Stat(project.Path); os.IsNotExist(err) {
+		return fmt.Errorf("项目 %s 不存在", project.Name)
+	}
+
+	project.UpdatedAt = time.Now()
+	return r.saveProjectConfig(project)
+}
+
 // saveProjectConfig 保存项目配置
 func (r *projectRepository) saveProjectConfig(project *domain.Project) error {
 	cfg, err := config.LoadProjectConfig(project.Path)
@@ -323,6 +374,31 @@ func (r *projectRepository) saveProjectConfig(project *domain.Project) error {
 	section.Key("name").SetValue(project.Name)
 	section.Key("created_at").SetValue(project.CreatedAt.Format(time.RFC3339))
 	section.Key("updated_at").SetValue(project.UpdatedAt.Format(time.RFC3339))
+	if project.CredentialProfile != "" {
+		section.Key("credential_profile").SetValue(project.CredentialProfile)
+	} else {
+		section.DeleteKey("credential_profile")
+	}
+	if project.RequiredTerraformVersion != "" {
+		section.Key("required_terraform_version").SetValue(project.RequiredTerraformVersion)
+	} else {
+		section.DeleteKey("required_terraform_version")
+	}
+
+	// 后端配置整体重写，避免残留已删除的参数
+	cfg.DeleteSection("backend")
+	if project.Backend != nil {
+		backend := cfg.Section("backend")
+		backend.Key("type").SetValue(project.Backend.Type)
+		names := make([]string, 0, len(project.Backend.Options))
+		for name := range project.Backend.Options {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			backend.Key(name).SetValue(project.Backend.Options[name])
+		}
+	}
 
 	return config.SaveProjectConfig(project.Path, cfg)
 }
@@ -338,3 +414,93 @@ func (r *projectRepository) saveScenarioMetadata(projectName string, scenario *d
 
 	return os.WriteFile(metadataPath, data, 0644)
 }
+
+// GetProjectVars 获取项目级 Terraform 变量
+func (r *projectRepository) GetProjectVars(projectName string) (map[string]interface{}, error) {
+	project, err := r.GetProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return loadVarsFile(filepath.Join(project.Path, VarsFileName))
+}
+
+// SaveProjectVars 保存项目级 Terraform 变量
+func (r *projectRepository) SaveProjectVars(projectName string, vars map[string]interface{}) error {
+	project, err := r.GetProject(projectName)
+	if err != nil {
+		return err
+	}
+	return saveVarsFile(filepath.Join(project.Path, VarsFileName), vars)
+}
+
+// GetScenarioVars 获取场景级 Terraform 变量
+func (r *projectRepository) GetScenarioVars(projectName, scenarioID string) (map[string]interface{}, error) {
+	scenario, err := r.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+	return loadVarsFile(filepath.Join(scenario.Path, VarsFileName))
+}
+
+// SaveScenarioVars 保存场景级 Terraform 变量
+func (r *projectRepository) SaveScenarioVars(projectName, scenarioID string, vars map[string]interface{}) error {
+	scenario, err := r.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return err
+	}
+	return saveVarsFile(filepath.Join(scenario.Path, VarsFileName), vars)
+}
+
+// UpdateProjectVars 读取、修改并保存项目级 Terraform 变量
+func (r *projectRepository) UpdateProjectVars(projectName string, update func(vars map[string]interface{})) error {
+	vars, err := r.GetProjectVars(projectName)
+	if err != nil {
+		return err
+	}
+	update(vars)
+	return r.SaveProjectVars(projectName, vars)
+}
+
+// UpdateScenarioVars 读取、修改并保存场景级 Terraform 变量
+func (r *projectRepository) UpdateScenarioVars(projectName, scenarioID string, update func(vars map[string]interface{})) error {
+	vars, err := r.GetScenarioVars(projectName, scenarioID)
+	if err != nil {
+		return err
+	}
+	update(vars)
+	return r.SaveScenarioVars(projectName, scenarioID, vars)
+}
+
+// loadVarsFile 读取变量文件，文件不存在时返回空变量集
+func loadVarsFile(path string) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return vars, nil
+		}
+		return nil, fmt.Errorf("读取变量文件失败: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &vars); err != nil {
+		return nil, fmt.Errorf("解析变量文件 %s 失败: %w", path, err)
+	}
+	return vars, nil
+}
+
+// saveVarsFile 写入变量文件，vars 为空时删除文件
+func saveVarsFile(path string, vars map[string]interface{}) error {
+	if len(vars) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除变量文件失败: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(vars, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化变量失败: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,71 @@
+// Package repositorytest 提供项目仓库测试使用的辅助实现，只应在测试中引用
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lucksec/cloudbot/internal/repository"
+)
+
+// MemoryObjectStore 内存中的对象存储，ETag 为对象的写入次数，支持与 S3 相同的条件写入语义
+type MemoryObjectStore struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	versions map[string]int
+}
+
+// NewMemoryObjectStore 创建内存对象存储
+func NewMemoryObjectStore() *MemoryObjectStore {
+	return &MemoryObjectStore{objects: make(map[string][]byte), versions: make(map[string]int)}
+}
+
+// Get 读取对象内容及其 ETag
+func (m *MemoryObjectStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, "", repository.ErrObjectNotFound
+	}
+	return data, fmt.Sprintf(`"%d"`, m.versions[key]), nil
+}
+
+// Put 写入对象，match 的含义同 repository.ObjectStore.Put
+func (m *MemoryObjectStore) Put(ctx context.Context, key string, data []byte, match string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, exists := m.objects[key]
+	current := fmt.Sprintf(`"%d"`, m.versions[key])
+	if (match == repository.ObjectAbsent && exists) || (match != "" && match != repository.ObjectAbsent && (!exists || match != current)) {
+		return "", repository.ErrPreconditionFailed
+	}
+	m.objects[key] = data
+	m.versions[key]++
+	return fmt.Sprintf(`"%d"`, m.versions[key]), nil
+}
+
+// Delete 删除对象
+func (m *MemoryObjectStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+// List 列出指定前缀下的所有对象 key
+func (m *MemoryObjectStore) List(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,645 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/domain"
+)
+
+// ErrVersionConflict 元数据已被其他人修改
+var ErrVersionConflict = errors.New("元数据已被其他人修改，请重新执行命令")
+
+// sharedProjectRepository 基于共享对象存储的项目仓库
+// 项目和场景元数据、场景模板文件和变量保存在对象存储中，团队成员共享同一份数据；
+// Terraform 仍在本地目录中执行，本地不存在的场景（如其他成员创建的场景）在读取时自动从共享存储恢复。
+// 写入时通过版本号和条件写入实现乐观并发控制，避免覆盖其他成员的修改。
+//
+// 对象布局（<prefix>/projects/<项目>/ 下）:
+//
+//	project.json               项目元数据
+//	vars.json                  项目级变量
+//	scenarios/<id>.json        场景元数据
+//	scenarios/<id>.files.json  场景模板文件
+//	scenarios/<id>.vars.json   场景级变量
+type sharedProjectRepository struct {
+	config *config.Config
+	local  *projectRepository
+	store  ObjectStore
+	prefix string
+}
+
+// NewSharedProjectRepository 创建基于共享对象存储的项目仓库实例
+func NewSharedProjectRepository(cfg *config.Config, store ObjectStore, prefix string) ProjectRepository {
+	return &sharedProjectRepository{
+		config: cfg,
+		local:  &projectRepository{config: cfg},
+		store:  store,
+		prefix: strings.Trim(prefix, "/"),
+	}
+}
+
+// IsShared 判断项目仓库的元数据是否保存在团队共享存储中
+func IsShared(repo ProjectRepository) bool {
+	_, ok := repo.(*sharedProjectRepository)
+	return ok
+}
+
+// CreateProject 创建新项目
+func (r *sharedProjectRepository) CreateProject(name string) (*domain.Project, error) {
+	project := &domain.Project{
+		Name:      name,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Scenarios: []domain.Scenario{},
+		Version:   1,
+	}
+
+	if _, err := r.putJSON(r.projectKey(name, "project.json"), project, ObjectAbsent); err != nil {
+		if errors.Is(err, ErrPreconditionFailed) {
+			return nil, fmt.Errorf("项目 %s 已存在", name)
+		}
+		return nil, fmt.Errorf("保存项目配置失败: %w", err)
+	}
+
+	if err := r.syncLocalProject(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// GetProject 获取项目信息
+func (r *sharedProjectRepository) GetProject(name string) (*domain.Project, error) {
+	project, _, err := r.getProjectMeta(name)
+	if err != nil {
+		return nil, err
+	}
+
+	scenarios, err := r.listScenarioMeta(name)
+	if err != nil {
+		return nil, err
+	}
+	project.Scenarios = make([]domain.Scenario, len(scenarios))
+	for i, s := range scenarios {
+		project.Scenarios[i] = *s
+	}
+
+	return project, nil
+}
+
+// ListProjects 列出所有项目
+func (r *sharedProjectRepository) ListProjects() ([]*domain.Project, error) {
+	keys, err := r.store.List(context.Background(), r.key("projects")+"/")
+	if err != nil {
+		return nil, fmt.Errorf("读取项目列表失败: %w", err)
+	}
+
+	var projects []*domain.Project
+	for _, key := range keys {
+		rest := strings.TrimPrefix(key, r.key("projects")+"/")
+		name, file, ok := strings.Cut(rest, "/")
+		if !ok || file != "project.json" {
+			continue
+		}
+
+		project, err := r.GetProject(name)
+		if err != nil {
+			continue // 跳过无法读取的项目
+		}
+		projects = append(projects, project)
+	}
+
+	return projects, nil
+}
+
+// DeleteProject 删除项目
+func (r *sharedProjectRepository) DeleteProject(name string) error {
+	project, _, err := r.getProjectMeta(name)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	keys, err := r.store.List(ctx, r.projectKey(name, ""))
+	if err != nil {
+		return fmt.Errorf("读取项目数据失败: %w", err)
+	}
+	// 最后删除 project.json，中途失败时项目仍可见，可以重新删除
+	projectKey := r.projectKey(name, "project.json")
+	for _, key := range keys {
+		if key == projectKey {
+			continue
+		}
+		if err := r.store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("删除项目数据失败: %w", err)
+		}
+	}
+	if err := r.store.Delete(ctx, projectKey); err != nil {
+		return fmt.Errorf("删除项目数据失败: %w", err)
+	}
+
+	return os.RemoveAll(project.Path)
+}
+
+// AddScenario 添加场景到项目，同时上传场景目录中的模板文件
+func (r *sharedProjectRepository) AddScenario(projectName string, scenario *domain.Scenario) error {
+	project, _, err := r.getProjectMeta(projectName)
+	if err != nil {
+		return err
+	}
+
+	scenarioPath := filepath.Join(project.Path, scenario.ID)
+	if err := os.MkdirAll(scenarioPath, 0755); err != nil {
+		return fmt.Errorf("创建场景目录失败: %w", err)
+	}
+
+	scenario.Path = scenarioPath
+	scenario.CreatedAt = time.Now()
+	scenario.UpdatedAt = time.Now()
+	scenario.CreatedBy = currentUser()
+	scenario.Version = 1
+
+	// 先上传模板文件，保证元数据可见时文件已存在
+	files, err := collectScenarioFiles(scenarioPath)
+	if err != nil {
+		return err
+	}
+	if _, err := r.putJSON(r.scenarioKey(projectName, scenario.ID, ".files.json"), files, ""); err != nil {
+		return fmt.Errorf("上传场景文件失败: %w", err)
+	}
+	if _, err := r.putJSON(r.scenarioKey(projectName, scenario.ID, ".json"), scenario, ObjectAbsent); err != nil {
+		if errors.Is(err, ErrPreconditionFailed) {
+			return fmt.Errorf("场景 %s 已存在", scenario.ID)
+		}
+		return fmt.Errorf("保存场景元数据失败: %w", err)
+	}
+
+	if err := r.local.saveScenarioMetadata(projectName, scenario); err != nil {
+		return fmt.Errorf("保存场景元数据失败: %w", err)
+	}
+	return nil
+}
+
+// GetScenario 获取场景信息，本地不存在场景目录时从共享存储恢复
+func (r *sharedProjectRepository) GetScenario(projectName, scenarioID string) (*domain.Scenario, error) {
+	project, _, err := r.getProjectMeta(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenario domain.Scenario
+	if _, err := r.getJSON(r.scenarioKey(projectName, scenarioID, ".json"), &scenario); err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, fmt.Errorf("场景 %s 不存在", scenarioID)
+		}
+		return nil, fmt.Errorf("读取场景元数据失败: %w", err)
+	}
+	scenario.Path = filepath.Join(project.Path, scenarioID)
+
+	if err := r.ensureScenarioFiles(projectName, &scenario); err != nil {
+		return nil, err
+	}
+	if err := r.local.saveScenarioMetadata(projectName, &scenario); err != nil {
+		return nil, fmt.Errorf("保存场景元数据失败: %w", err)
+	}
+	return &scenario, nil
+}
+
+// ListScenarios 列出项目的所有场景
+func (r *sharedProjectRepository) ListScenarios(projectName string) ([]*domain.Scenario, error) {
+	if _, _, err := r.getProjectMeta(projectName); err != nil {
+		return nil, err
+	}
+
+	ids, err := r.listScenarioIDs(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenarios []*domain.Scenario
+	for _, id := range ids {
+		scenario, err := r.GetScenario(projectName, id)
+		if err != nil {
+			continue // 跳过无法读取的场景
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios, nil
+}
+
+// DeleteScenario 删除场景
+func (r *sharedProjectRepository) DeleteScenario(projectName, scenarioID string) error {
+	project, _, err := r.getProjectMeta(projectName)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	metaKey := r.scenarioKey(projectName, scenarioID, ".json")
+	if _, _, err := r.store.Get(ctx, metaKey); err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return fmt.Errorf("场景 %s 不存在", scenarioID)
+		}
+		return fmt.Errorf("读取场景元数据失败: %w", err)
+	}
+
+	// 先删除元数据，其他成员不会再看到半删除的场景
+	for _, suffix := range []string{".json", ".files.json", ".vars.json"} {
+		if err := r.store.Delete(ctx, r.scenarioKey(projectName, scenarioID, suffix)); err != nil {
+			return fmt.Errorf("删除场景数据失败: %w", err)
+		}
+	}
+
+	return os.RemoveAll(filepath.Join(project.Path, scenarioID))
+}
+
+// UpdateScenario 更新场景信息
+// scenario.Version 必须与共享存储中的版本一致，否则说明场景已被其他成员修改，返回 ErrVersionConflict
+func (r *sharedProjectRepository) UpdateScenario(projectName string, scenario *domain.Scenario) error {
+	key := r.scenarioKey(projectName, scenario.ID, ".json")
+
+	var current domain.Scenario
+	etag, err := r.getJSON(key, &current)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return fmt.Errorf("场景 %s 不存在", scenario.ID)
+		}
+		return fmt.Errorf("读取场景元数据失败: %w", err)
+	}
+	if current.Version != scenario.Version {
+		return fmt.Errorf("场景 %s 当前版本 %d，本地版本 %d: %w", scenario.ID, current.Version, scenario.Version, ErrVersionConflict)
+	}
+
+	updated := *scenario
+	updated.Version++
+	updated.UpdatedAt = time.Now()
+	if _, err := r.putJSON(key, &updated, etag); err != nil {
+		if errors.Is(err, ErrPreconditionFailed) {
+			return fmt.Errorf("场景 %s: %w", scenario.ID, ErrVersionConflict)
+		}
+		return fmt.Errorf("保存场景元数据失败: %w", err)
+	}
+
+	scenario.Version = updated.Version
+	scenario.UpdatedAt = updated.UpdatedAt
+	return r.local.saveScenarioMetadata(projectName, scenario)
+}
+
+// UpdateProject 更新项目配置
+// project.Version 必须与共享存储中的版本一致，否则返回 ErrVersionConflict
+func (r *sharedProjectRepository) UpdateProject(project *domain.Project) error {
+	current, etag, err := r.getProjectMeta(project.Name)
+	if err != nil {
+		return err
+	}
+	if current.Version != project.Version {
+		return fmt.Errorf("项目 %s 当前版本 %d，本地版本 %d: %w", project.Name, current.Version, project.Version, ErrVersionConflict)
+	}
+
+	updated := *project
+	updated.Scenarios = nil
+	updated.Version++
+	updated.UpdatedAt = time.Now()
+	if _, err := r.putJSON(r.projectKey(project.Name, "project.json"), &updated, etag); err != nil {
+		if errors.Is(err, ErrPreconditionFailed) {
+			return fmt.Errorf("项目 %s: %w", project.Name, ErrVersionConflict)
+		}
+		return fmt.Errorf("保存项目配置失败: %w", err)
+	}
+
+	project.Version = updated.Version
+	project.UpdatedAt = updated.UpdatedAt
+	return r.syncLocalProject(project)
+}
+
+// GetProjectVars 获取项目级 Terraform 变量
+func (r *sharedProjectRepository) GetProjectVars(projectName string) (map[string]interface{}, error) {
+	if _, _, err := r.getProjectMeta(projectName); err != nil {
+		return nil, err
+	}
+	vars, _, err := r.getVars(r.projectKey(projectName, "vars.json"))
+	return vars, err
+}
+
+// SaveProjectVars 保存项目级 Terraform 变量
+func (r *sharedProjectRepository) SaveProjectVars(projectName string, vars map[string]interface{}) error {
+	project, _, err := r.getProjectMeta(projectName)
+	if err != nil {
+		return err
+	}
+	if err := r.saveVars(r.projectKey(projectName, "vars.json"), vars); err != nil {
+		return err
+	}
+	return saveVarsFile(filepath.Join(project.Path, VarsFileName), vars)
+}
+
+// GetScenarioVars 获取场景级 Terraform 变量
+func (r *sharedProjectRepository) GetScenarioVars(projectName, scenarioID string) (map[string]interface{}, error) {
+	if _, err := r.GetScenario(projectName, scenarioID); err != nil {
+		return nil, err
+	}
+	vars, _, err := r.getVars(r.scenarioKey(projectName, scenarioID, ".vars.json"))
+	return vars, err
+}
+
+// SaveScenarioVars 保存场景级 Terraform 变量（同时写入本地场景目录，供 Terraform 自动加载）
+func (r *sharedProjectRepository) SaveScenarioVars(projectName, scenarioID string, vars map[string]interface{}) error {
+	scenario, err := r.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return err
+	}
+	if err := r.saveVars(r.scenarioKey(projectName, scenarioID, ".vars.json"), vars); err != nil {
+		return err
+	}
+	return saveVarsFile(filepath.Join(scenario.Path, VarsFileName), vars)
+}
+
+// UpdateProjectVars 读取、修改并保存项目级 Terraform 变量
+// 保存时以读取到的 ETag 作为写入条件，其间被其他成员修改时返回 ErrVersionConflict
+func (r *sharedProjectRepository) UpdateProjectVars(projectName string, update func(vars map[string]interface{})) error {
+	project, _, err := r.getProjectMeta(projectName)
+	if err != nil {
+		return err
+	}
+	vars, err := r.updateVars(r.projectKey(projectName, "vars.json"), update)
+	if err != nil {
+		return fmt.Errorf("项目 %s 的变量: %w", projectName, err)
+	}
+	return saveVarsFile(filepath.Join(project.Path, VarsFileName), vars)
+}
+
+// UpdateScenarioVars 读取、修改并保存场景级 Terraform 变量
+// 保存时以读取到的 ETag 作为写入条件，其间被其他成员修改时返回 ErrVersionConflict
+func (r *sharedProjectRepository) UpdateScenarioVars(projectName, scenarioID string, update func(vars map[string]interface{})) error {
+	scenario, err := r.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return err
+	}
+	vars, err := r.updateVars(r.scenarioKey(projectName, scenarioID, ".vars.json"), update)
+	if err != nil {
+		return fmt.Errorf("场景 %s 的变量: %w", scenarioID, err)
+	}
+	return saveVarsFile(filepath.Join(scenario.Path, VarsFileName), vars)
+}
+
+// getProjectMeta 读取项目元数据（不含场景列表）及其 ETag
+func (r *sharedProjectRepository) getProjectMeta(name string) (*domain.Project, string, error) {
+	var project domain.Project
+	etag, err := r.getJSON(r.projectKey(name, "project.json"), &project)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, "", fmt.Errorf("项目 %s 不存在", name)
+		}
+		return nil, "", fmt.Errorf("加载项目配置失败: %w", err)
+	}
+
+	project.Name = name
+	project.Path = filepath.Join(r.config.ProjectDir, name)
+	if err := r.syncLocalProject(&project); err != nil {
+		return nil, "", err
+	}
+	return &project, etag, nil
+}
+
+// syncLocalProject 创建本地项目目录并写入 project.ini，供 Terraform 和其他命令在本地使用
+func (r *sharedProjectRepository) syncLocalProject(project *domain.Project) error {
+	project.Path = filepath.Join(r.config.ProjectDir, project.Name)
+	if err := os.MkdirAll(project.Path, 0755); err != nil {
+		return fmt.Errorf("创建项目目录失败: %w", err)
+	}
+	if err := r.local.saveProjectConfig(project); err != nil {
+		return fmt.Errorf("保存项目配置失败: %w", err)
+	}
+	return nil
+}
+
+// listScenarioIDs 列出项目下所有场景 ID
+func (r *sharedProjectRepository) listScenarioIDs(projectName string) ([]string, error) {
+	prefix := r.projectKey(projectName, "scenarios/")
+	keys, err := r.store.List(context.Background(), prefix)
+	if err != nil {
+		return nil, fmt.Errorf("读取场景列表失败: %w", err)
+	}
+
+	var ids []string
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, prefix)
+		if strings.Contains(name, "/") || strings.HasSuffix(name, ".files.json") || strings.HasSuffix(name, ".vars.json") {
+			continue
+		}
+		if id, ok := strings.CutSuffix(name, ".json"); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// listScenarioMeta 读取项目下所有场景的元数据（不恢复本地文件）
+func (r *sharedProjectRepository) listScenarioMeta(projectName string) ([]*domain.Scenario, error) {
+	ids, err := r.listScenarioIDs(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenarios []*domain.Scenario
+	for _, id := range ids {
+		var scenario domain.Scenario
+		if _, err := r.getJSON(r.scenarioKey(projectName, id, ".json"), &scenario); err != nil {
+			continue
+		}
+		scenario.Path = filepath.Join(r.config.ProjectDir, projectName, id)
+		scenarios = append(scenarios, &scenario)
+	}
+	return scenarios, nil
+}
+
+// ensureScenarioFiles 本地不存在场景目录时，从共享存储恢复模板文件和场景变量
+func (r *sharedProjectRepository) ensureScenarioFiles(projectName string, scenario *domain.Scenario) error {
+	if _, err := os.Stat(scenario.Path); err == nil {
+		return nil
+	}
+
+	var files map[string][]byte
+	if _, err := r.getJSON(r.scenarioKey(projectName, scenario.ID, ".files.json"), &files); err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return fmt.Errorf("下载场景文件失败: %w", err)
+	}
+	vars, _, err := r.getVars(r.scenarioKey(projectName, scenario.ID, ".vars.json"))
+	if err != nil {
+		return err
+	}
+
+	// 先写入临时目录，避免中途失败留下不完整的场景目录
+	tmpDir := scenario.Path + ".tmp"
+	os.RemoveAll(tmpDir)
+	for name, data := range files {
+		dest := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if !strings.HasPrefix(dest, tmpDir+string(filepath.Separator)) {
+			continue // 忽略越出场景目录的路径
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			os.RemoveAll(tmpDir)
+			return fmt.Errorf("恢复场景文件失败: %w", err)
+		}
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			os.RemoveAll(tmpDir)
+			return fmt.Errorf("恢复场景文件失败: %w", err)
+		}
+	}
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return fmt.Errorf("创建场景目录失败: %w", err)
+	}
+	if err := saveVarsFile(filepath.Join(tmpDir, VarsFileName), vars); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+	if err := os.Rename(tmpDir, scenario.Path); err != nil {
+		os.RemoveAll(tmpDir)
+		return fmt.Errorf("恢复场景目录失败: %w", err)
+	}
+	return nil
+}
+
+// collectScenarioFiles 收集场景目录中需要共享的文件（跳过 Terraform 工作目录、本地状态和计划文件）
+func collectScenarioFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			// 运行日志只保存在执行命令的机器上
+			if name == ".terraform" || name == ".runs" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if name == ".scenario.json" || name == VarsFileName ||
+			strings.HasPrefix(name, "terraform.tfstate") || strings.HasSuffix(name, ".tfplan") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取场景文件失败: %w", err)
+	}
+	return files, nil
+}
+
+// getVars 读取变量对象及其 ETag，不存在时返回空变量集和 ObjectAbsent
+func (r *sharedProjectRepository) getVars(key string) (map[string]interface{}, string, error) {
+	vars := make(map[string]interface{})
+	etag, err := r.getJSON(key, &vars)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return vars, ObjectAbsent, nil
+		}
+		return nil, "", fmt.Errorf("读取变量失败: %w", err)
+	}
+	return vars, etag, nil
+}
+
+// updateVars 读取变量对象，由 update 修改后以读取时的 ETag 为条件写回
+// 变量被清空时写入空对象而不是删除，保证写入仍然是条件写入
+func (r *sharedProjectRepository) updateVars(key string, update func(vars map[string]interface{})) (map[string]interface{}, error) {
+	vars, etag, err := r.getVars(key)
+	if err != nil {
+		return nil, err
+	}
+	update(vars)
+	if _, err := r.putJSON(key, vars, etag); err != nil {
+		if errors.Is(err, ErrPreconditionFailed) {
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("保存变量失败: %w", err)
+	}
+	return vars, nil
+}
+
+// saveVars 保存变量对象，vars 为空时删除对象
+func (r *sharedProjectRepository) saveVars(key string, vars map[string]interface{}) error {
+	if len(vars) == 0 {
+		if err := r.store.Delete(context.Background(), key); err != nil {
+			return fmt.Errorf("删除变量失败: %w", err)
+		}
+		return nil
+	}
+	if _, err := r.putJSON(key, vars, ""); err != nil {
+		return fmt.Errorf("保存变量失败: %w", err)
+	}
+	return nil
+}
+
+// getJSON 读取 JSON 对象并返回其 ETag
+func (r *sharedProjectRepository) getJSON(key string, v interface{}) (string, error) {
+	data, etag, err := r.store.Get(context.Background(), key)
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return "", fmt.Errorf("解析 %s 失败: %w", key, err)
+	}
+	return etag, nil
+}
+
+// putJSON 写入 JSON 对象，match 的含义同 ObjectStore.Put
+func (r *sharedProjectRepository) putJSON(key string, v interface{}, match string) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("序列化失败: %w", err)
+	}
+	return r.store.Put(context.Background(), key, data, match)
+}
+
+// key 拼接对象 key
+func (r *sharedProjectRepository) key(parts ...string) string {
+	if r.prefix != "" {
+		parts = append([]string{r.prefix}, parts...)
+	}
+	return path.Join(parts...)
+}
+
+// projectKey 项目下的对象 key，name 为空时返回项目前缀（以 / 结尾）
+func (r *sharedProjectRepository) projectKey(projectName, name string) string {
+	if name == "" {
+		return r.key("projects", projectName) + "/"
+	}
+	return r.key("projects", projectName) + "/" + name
+}
+
+// scenarioKey 场景对象 key
+func (r *sharedProjectRepository) scenarioKey(projectName, scenarioID, suffix string) string {
+	return r.projectKey(projectName, "scenarios/"+scenarioID+suffix)
+}
+
+// currentUser 当前用户标识（user@host）
+func currentUser() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
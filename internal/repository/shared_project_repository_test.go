@@ -0,0 +1,42 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/repository"
+	"github.com/lucksec/cloudbot/internal/repository/repositorytest"
+)
+
+func TestUpdateProjectVarsConflict(t *testing.T) {
+	store := repositorytest.NewMemoryObjectStore()
+	alice := repository.NewSharedProjectRepository(&config.Config{ProjectDir: t.TempDir()}, store, "cloudbot")
+	bob := repository.NewSharedProjectRepository(&config.Config{ProjectDir: t.TempDir()}, store, "cloudbot")
+	if _, err := alice.CreateProject("p"); err != nil {
+		t.Fatalf("创建项目失败: %v", err)
+	}
+
+	// alice 读取变量后、保存之前，bob 修改了变量
+	err := alice.UpdateProjectVars("p", func(vars map[string]interface{}) {
+		if err := bob.UpdateProjectVars("p", func(vars map[string]interface{}) { vars["bob"] = "1" }); err != nil {
+			t.Fatalf("bob 修改变量失败: %v", err)
+		}
+		vars["alice"] = "1"
+	})
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("错误 = %v，期望 ErrVersionConflict", err)
+	}
+
+	// 重试时基于最新的变量修改，两人的修改都保留
+	if err := alice.UpdateProjectVars("p", func(vars map[string]interface{}) { vars["alice"] = "1" }); err != nil {
+		t.Fatalf("重试修改变量失败: %v", err)
+	}
+	vars, err := bob.GetProjectVars("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["alice"] != "1" || vars["bob"] != "1" {
+		t.Errorf("变量 = %v，期望同时包含 alice 和 bob 的修改", vars)
+	}
+}
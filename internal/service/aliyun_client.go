@@ -11,15 +11,21 @@ import (
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 // aliyunClient 阿里云客户端实现
 type aliyunClient struct {
-	accessKey  string
-	secretKey  string
-	httpClient *http.Client
+	accessKey    string
+	secretKey    string
+	sessionToken string // 临时凭据的安全令牌（可选）
+	httpClient   *http.Client
+
+	zoneMu    sync.Mutex
+	zoneCache map[string]map[string][]string // region -> zone -> 可售实例类型
 }
 
 // NewAliyunClient 创建阿里云客户端
@@ -28,6 +34,7 @@ func NewAliyunClient(accessKey, secretKey string) (CloudProviderClient, error) {
 		accessKey:  accessKey,
 		secretKey:  secretKey,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		zoneCache:  make(map[string]map[string][]string),
 	}, nil
 }
 
@@ -103,26 +110,18 @@ func (c *aliyunClient) GetAvailableInstanceTypes(ctx context.Context, region str
 		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
 	}
 	
+	// 注意：DescribeInstanceTypes 返回数百个规格，这里不逐个查询价格，
+	// 需要价格时由调用方按需调用 GetInstancePrice / GetSpotPrice
 	var instanceTypes []InstanceType
 	for _, it := range apiResponse.InstanceTypes.InstanceType {
-		// 尝试获取价格信息
-		price, _ := c.GetInstancePrice(ctx, region, it.InstanceTypeId)
-		
-		instanceType := InstanceType{
-			ID:          it.InstanceTypeId,
-			Name:        it.InstanceTypeId,
-			CPU:         it.CpuCoreCount,
-			Memory:      it.MemorySize,
-			Available:   true,
-			Currency:    "CNY",
-		}
-		
-		if price != nil {
-			instanceType.PricePerHour = price.PricePerHour
-			instanceType.PricePerMonth = price.PricePerMonth
-		}
-		
-		instanceTypes = append(instanceTypes, instanceType)
+		instanceTypes = append(instanceTypes, InstanceType{
+			ID:        it.InstanceTypeId,
+			Name:      it.InstanceTypeId,
+			CPU:       it.CpuCoreCount,
+			Memory:    it.MemorySize,
+			Available: true,
+			Currency:  "CNY",
+		})
 	}
 	
 	return instanceTypes, nil
@@ -171,15 +170,305 @@ func (c *aliyunClient) GetInstancePrice(ctx context.Context, region, instanceTyp
 	
 	pricePerMonth := pricePerHour * 24 * 30
 	
+	// 按量付费价格在区域内各可用区一致，这里补充第一个在售该规格的可用区
+	var zone string
+	if zones, err := c.zonesForInstanceType(ctx, region, instanceType); err == nil && len(zones) > 0 {
+		zone = zones[0]
+	}
+	
 	return &InstancePrice{
 		InstanceType:  instanceType,
 		Region:        region,
+		Zone:          zone,
 		PricePerHour:  pricePerHour,
 		PricePerMonth: pricePerMonth,
 		Currency:      "CNY",
 	}, nil
 }
 
+// GetSpotPrice 获取抢占式实例价格
+// 使用 DescribeSpotPriceHistory 查询最近一小时的价格，取各可用区最新价格中的最低价
+func (c *aliyunClient) GetSpotPrice(ctx context.Context, region, instanceType string) (*InstancePrice, error) {
+	points, err := c.describeSpotPriceHistory(ctx, region, instanceType, time.Now().Add(-1*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	
+	// 每个可用区只保留最新的价格点
+	latest := make(map[string]SpotPricePoint)
+	for _, p := range points {
+		if cur, ok := latest[p.Zone]; !ok || p.Timestamp.After(cur.Timestamp) {
+			latest[p.Zone] = p
+		}
+	}
+	
+	var best *SpotPricePoint
+	for _, p := range latest {
+		p := p
+		if p.Price <= 0 {
+			continue
+		}
+		if best == nil || p.Price < best.Price || (p.Price == best.Price && p.Zone < best.Zone) {
+			best = &p
+		}
+	}
+	
+	if best == nil {
+		return nil, fmt.Errorf("区域 %s 没有 %s 的抢占式实例价格", region, instanceType)
+	}
+	
+	return &InstancePrice{
+		InstanceType:  instanceType,
+		Region:        region,
+		Zone:          best.Zone,
+		Spot:          true,
+		PricePerHour:  best.Price,
+		PricePerMonth: best.Price * 24 * 30,
+		Currency:      "CNY",
+	}, nil
+}
+
+// GetSpotPriceHistory 获取抢占式实例历史价格（实现 SpotPriceHistoryProvider）
+func (c *aliyunClient) GetSpotPriceHistory(ctx context.Context, region, instanceType string, startTime time.Time) ([]SpotPricePoint, error) {
+	return c.describeSpotPriceHistory(ctx, region, instanceType, startTime)
+}
+
+// describeSpotPriceHistory 调用 DescribeSpotPriceHistory 获取抢占式实例历史价格
+// 时间跨度较长时结果会分页返回，这里通过 Offset/NextOffset 取回全部价格点
+func (c *aliyunClient) describeSpotPriceHistory(ctx context.Context, region, instanceType string, startTime time.Time) ([]SpotPricePoint, error) {
+	var points []SpotPricePoint
+	offset := 0
+	for {
+		page, nextOffset, err := c.describeSpotPriceHistoryPage(ctx, region, instanceType, startTime, offset)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, page...)
+		if nextOffset <= offset || len(page) == 0 {
+			break
+		}
+		offset = nextOffset
+	}
+	
+	return points, nil
+}
+
+// describeSpotPriceHistoryPage 获取一页抢占式实例历史价格，返回下一页的偏移量
+func (c *aliyunClient) describeSpotPriceHistoryPage(ctx context.Context, region, instanceType string, startTime time.Time, offset int) ([]SpotPricePoint, int, error) {
+	params := map[string]string{
+		"Action":       "DescribeSpotPriceHistory",
+		"Version":      "2014-05-26",
+		"RegionId":     region,
+		"InstanceType": instanceType,
+		"NetworkType":  "vpc",
+		"OSType":       "linux",
+		"StartTime":    startTime.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if offset > 0 {
+		params["Offset"] = strconv.Itoa(offset)
+	}
+	
+	response, err := c.callAPI(ctx, "https://ecs.aliyuncs.com", params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("调用 DescribeSpotPriceHistory API 失败: %w", err)
+	}
+	
+	var apiResponse struct {
+		NextOffset int `json:"NextOffset"`
+		SpotPrices struct {
+			SpotPriceType []struct {
+				ZoneId       string  `json:"ZoneId"`
+				InstanceType string  `json:"InstanceType"`
+				SpotPrice    float64 `json:"SpotPrice"`
+				OriginPrice  float64 `json:"OriginPrice"`
+				Timestamp    string  `json:"Timestamp"`
+			} `json:"SpotPriceType"`
+		} `json:"SpotPrices"`
+	}
+	
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return nil, 0, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+	
+	var points []SpotPricePoint
+	for _, sp := range apiResponse.SpotPrices.SpotPriceType {
+		ts, _ := time.Parse("2006-01-02T15:04:05Z", sp.Timestamp)
+		points = append(points, SpotPricePoint{
+			Zone:         sp.ZoneId,
+			InstanceType: sp.InstanceType,
+			Price:        sp.SpotPrice,
+			OriginPrice:  sp.OriginPrice,
+			Timestamp:    ts,
+		})
+	}
+	
+	return points, apiResponse.NextOffset, nil
+}
+
+// CheckAvailability 检查区域内各可用区是否有指定实例类型的库存
+// 使用 DescribeAvailableResource API: https://next.api.aliyun.com/document/Ecs/2014-05-26/DescribeAvailableResource
+func (c *aliyunClient) CheckAvailability(ctx context.Context, region, instanceType string, spot bool) ([]ZoneAvailability, error) {
+	params := map[string]string{
+		"Action":              "DescribeAvailableResource",
+		"Version":             "2014-05-26",
+		"RegionId":            region,
+		"DestinationResource": "InstanceType",
+		"InstanceType":        instanceType,
+		"InstanceChargeType":  "PostPaid",
+	}
+	if spot {
+		params["SpotStrategy"] = "SpotAsPriceGo"
+	}
+	
+	response, err := c.callAPI(ctx, "https://ecs.aliyuncs.com", params)
+	if err != nil {
+		return nil, fmt.Errorf("调用 DescribeAvailableResource API 失败: %w", err)
+	}
+	
+	var apiResponse struct {
+		AvailableZones struct {
+			AvailableZone []struct {
+				ZoneId             string `json:"ZoneId"`
+				Status             string `json:"Status"`
+				StatusCategory     string `json:"StatusCategory"`
+				AvailableResources struct {
+					AvailableResource []struct {
+						SupportedResources struct {
+							SupportedResource []struct {
+								Value          string `json:"Value"`
+								Status         string `json:"Status"`
+								StatusCategory string `json:"StatusCategory"`
+							} `json:"SupportedResource"`
+						} `json:"SupportedResources"`
+					} `json:"AvailableResource"`
+				} `json:"AvailableResources"`
+			} `json:"AvailableZone"`
+		} `json:"AvailableZones"`
+	}
+	
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+	
+	var result []ZoneAvailability
+	for _, z := range apiResponse.AvailableZones.AvailableZone {
+		zone := ZoneAvailability{Zone: z.ZoneId, Reason: "可用区未返回该实例类型"}
+		if z.Status != "Available" {
+			zone.Reason = fmt.Sprintf("可用区状态为 %s", z.Status)
+			result = append(result, zone)
+			continue
+		}
+		for _, r := range z.AvailableResources.AvailableResource {
+			for _, sr := range r.SupportedResources.SupportedResource {
+				if sr.Value != instanceType {
+					continue
+				}
+				// WithStock: 库存充足；ClosedWithStock: 库存水位低但仍可购买
+				if sr.Status == "Available" && (sr.StatusCategory == "WithStock" || sr.StatusCategory == "ClosedWithStock") {
+					zone.Available = true
+					zone.Reason = ""
+				} else {
+					zone.Reason = fmt.Sprintf("库存状态为 %s/%s", sr.Status, sr.StatusCategory)
+				}
+			}
+		}
+		result = append(result, zone)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Zone < result[j].Zone })
+	
+	return result, nil
+}
+
+// zonesForInstanceType 获取区域内在售指定实例类型的可用区（结果按区域缓存）
+func (c *aliyunClient) zonesForInstanceType(ctx context.Context, region, instanceType string) ([]string, error) {
+	c.zoneMu.Lock()
+	zoneTypes, ok := c.zoneCache[region]
+	c.zoneMu.Unlock()
+	
+	if !ok {
+		params := map[string]string{
+			"Action":             "DescribeZones",
+			"Version":            "2014-05-26",
+			"RegionId":           region,
+			"InstanceChargeType": "PostPaid",
+		}
+		
+		response, err := c.callAPI(ctx, "https://ecs.aliyuncs.com", params)
+		if err != nil {
+			return nil, fmt.Errorf("调用 DescribeZones API 失败: %w", err)
+		}
+		
+		var apiResponse struct {
+			Zones struct {
+				Zone []struct {
+					ZoneId                 string `json:"ZoneId"`
+					AvailableInstanceTypes struct {
+						InstanceTypes []string `json:"InstanceTypes"`
+					} `json:"AvailableInstanceTypes"`
+				} `json:"Zone"`
+			} `json:"Zones"`
+		}
+		
+		if err := json.Unmarshal(response, &apiResponse); err != nil {
+			return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+		}
+		
+		zoneTypes = make(map[string][]string)
+		for _, z := range apiResponse.Zones.Zone {
+			zoneTypes[z.ZoneId] = z.AvailableInstanceTypes.InstanceTypes
+		}
+		
+		c.zoneMu.Lock()
+		c.zoneCache[region] = zoneTypes
+		c.zoneMu.Unlock()
+	}
+	
+	var zones []string
+	for zone, types := range zoneTypes {
+		for _, t := range types {
+			if t == instanceType {
+				zones = append(zones, zone)
+				break
+			}
+		}
+	}
+	sort.Strings(zones)
+	
+	return zones, nil
+}
+
+// VerifyCredentials 验证凭据：通过 STS GetCallerIdentity 查询账号，并检查 ECS 只读权限
+// API 文档: https://help.aliyun.com/document_detail/371868.html
+func (c *aliyunClient) VerifyCredentials(ctx context.Context) (*CredentialVerification, error) {
+	result := &CredentialVerification{Provider: c.Provider()}
+	
+	response, err := c.callAPI(ctx, aliyunSTSEndpoint, map[string]string{
+		"Action":  "GetCallerIdentity",
+		"Version": "2015-04-01",
+	})
+	if err == nil {
+		var identity struct {
+			AccountId string `json:"AccountId"`
+			Arn       string `json:"Arn"`
+		}
+		if err = json.Unmarshal(response, &identity); err == nil {
+			result.AccountID = identity.AccountId
+			result.Identity = identity.Arn
+		}
+	}
+	result.addCheck("sts:GetCallerIdentity", err)
+	
+	_, err = c.GetAvailableRegions(ctx)
+	result.addCheck("ecs:DescribeRegions", err)
+	
+	return result, nil
+}
+
+// setSessionToken 设置临时凭据的安全令牌
+func (c *aliyunClient) setSessionToken(token string) {
+	c.sessionToken = token
+}
+
 // callAPI 调用阿里云 API
 func (c *aliyunClient) callAPI(ctx context.Context, endpoint string, params map[string]string) ([]byte, error) {
 	if c.accessKey == "" || c.secretKey == "" {
@@ -193,6 +482,9 @@ func (c *aliyunClient) callAPI(ctx context.Context, endpoint string, params map[
 	params["Timestamp"] = time.Now().UTC().Format("2006-01-02T15:04:05Z")
 	params["SignatureVersion"] = "1.0"
 	params["SignatureNonce"] = fmt.Sprintf("%d", time.Now().UnixNano())
+	if c.sessionToken != "" {
+		params["SecurityToken"] = c.sessionToken
+	}
 	
 	// 构建查询字符串
 	query := c.buildQueryString(params)
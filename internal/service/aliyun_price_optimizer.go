@@ -34,6 +34,8 @@ type AliyunPriceOptimizer interface {
 type OptimalInstanceConfig struct {
 	InstanceType  string  // 实例类型
 	Region        string  // 区域
+	Zone          string  // 可用区（为空表示未校验库存）
+	Spot          bool    // 是否按抢占式实例价格选择
 	Price         float64 // 每小时价格（元）
 	PricePerMonth float64 // 每月价格（元）
 	Currency      string  // 货币单位
@@ -43,6 +45,8 @@ type OptimalInstanceConfig struct {
 type InstancePrice struct {
 	InstanceType  string  // 实例类型
 	Region        string  // 区域
+	Zone          string  // 可用区（为空表示区域内任意可用区）
+	Spot          bool    // 是否为抢占式实例价格
 	PricePerHour  float64 // 每小时价格
 	PricePerMonth float64 // 每月价格
 	Currency      string  // 货币单位
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	aliyunECSEndpoint = "https://ecs.aliyuncs.com"
+	aliyunVPCEndpoint = "https://vpc.aliyuncs.com"
+	aliyunECSVersion  = "2014-05-26"
+	aliyunVPCVersion  = "2016-04-28"
+
+	// aliyunPageSize 列表接口每页数量
+	aliyunPageSize = 50
+)
+
+// aliyunTaggedResourceAPI 阿里云按标签查询和删除资源使用的接口
+type aliyunTaggedResourceAPI struct {
+	kind      string
+	endpoint  string
+	version   string
+	list      string // 列表接口，支持 Tag.N.Key 过滤
+	listKey   string // 响应中的列表字段，如 Instances.Instance
+	itemKey   string
+	idField   string
+	nameField string
+	delete    string // 删除接口
+	deleteID  string // 删除接口的资源 ID 参数
+}
+
+// aliyunTaggedResourceAPIs 支持按标签查找的资源类型
+// API 文档: https://help.aliyun.com/document_detail/25506.html（DescribeInstances）等
+var aliyunTaggedResourceAPIs = []aliyunTaggedResourceAPI{
+	{ResourceInstance, aliyunECSEndpoint, aliyunECSVersion, "DescribeInstances", "Instances", "Instance", "InstanceId", "InstanceName", "DeleteInstance", "InstanceId"},
+	{ResourceSecurityGroup, aliyunECSEndpoint, aliyunECSVersion, "DescribeSecurityGroups", "SecurityGroups", "SecurityGroup", "SecurityGroupId", "SecurityGroupName", "DeleteSecurityGroup", "SecurityGroupId"},
+	{ResourceEIP, aliyunVPCEndpoint, aliyunVPCVersion, "DescribeEipAddresses", "EipAddresses", "EipAddress", "AllocationId", "IpAddress", "ReleaseEipAddress", "AllocationId"},
+	{ResourceSubnet, aliyunVPCEndpoint, aliyunVPCVersion, "DescribeVSwitches", "VSwitches", "VSwitch", "VSwitchId", "VSwitchName", "DeleteVSwitch", "VSwitchId"},
+	{ResourceVPC, aliyunVPCEndpoint, aliyunVPCVersion, "DescribeVpcs", "Vpcs", "Vpc", "VpcId", "VpcName", "DeleteVpc", "VpcId"},
+}
+
+// ListTaggedResources 列出区域内带有指定标签键的资源
+func (c *aliyunClient) ListTaggedResources(ctx context.Context, region, tagKey string) ([]TaggedResource, error) {
+	var resources []TaggedResource
+	for _, api := range aliyunTaggedResourceAPIs {
+		for page := 1; ; page++ {
+			params := map[string]string{
+				"Action":     api.list,
+				"Version":    api.version,
+				"RegionId":   region,
+				"Tag.1.Key":  tagKey,
+				"PageNumber": strconv.Itoa(page),
+				"PageSize":   strconv.Itoa(aliyunPageSize),
+			}
+			response, err := c.callAPI(ctx, api.endpoint, params)
+			if err != nil {
+				return nil, fmt.Errorf("调用 %s API 失败: %w", api.list, err)
+			}
+
+			items, total, err := parseAliyunTaggedItems(response, api, region)
+			if err != nil {
+				return nil, fmt.Errorf("解析 %s 响应失败: %w", api.list, err)
+			}
+			resources = append(resources, items...)
+			if len(items) == 0 || page*aliyunPageSize >= total {
+				break
+			}
+		}
+	}
+	return resources, nil
+}
+
+// parseAliyunTaggedItems 解析列表接口响应中的资源和标签
+// ECS 接口的标签字段为 TagKey/TagValue，VPC 接口为 Key/Value
+func parseAliyunTaggedItems(response []byte, api aliyunTaggedResourceAPI, region string) ([]TaggedResource, int, error) {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(response, &body); err != nil {
+		return nil, 0, err
+	}
+	var total int
+	json.Unmarshal(body["TotalCount"], &total)
+
+	var list map[string][]map[string]interface{}
+	if raw, ok := body[api.listKey]; ok {
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var resources []TaggedResource
+	for _, item := range list[api.itemKey] {
+		resource := TaggedResource{
+			Provider: "aliyun",
+			Region:   region,
+			Kind:     api.kind,
+		}
+		resource.ID, _ = item[api.idField].(string)
+		resource.Name, _ = item[api.nameField].(string)
+
+		if tags, ok := item["Tags"].(map[string]interface{}); ok {
+			tagList, _ := tags["Tag"].([]interface{})
+			for _, t := range tagList {
+				tag, _ := t.(map[string]interface{})
+				key, _ := tag["TagKey"].(string)
+				value, _ := tag["TagValue"].(string)
+				if key == "" {
+					key, _ = tag["Key"].(string)
+					value, _ = tag["Value"].(string)
+				}
+				resource.setResourceTags(key, value)
+			}
+		}
+		resources = append(resources, resource)
+	}
+	return resources, total, nil
+}
+
+// DeleteTaggedResource 删除资源（实例强制释放）
+func (c *aliyunClient) DeleteTaggedResource(ctx context.Context, resource TaggedResource) error {
+	for _, api := range aliyunTaggedResourceAPIs {
+		if api.kind != resource.Kind {
+			continue
+		}
+		params := map[string]string{
+			"Action":     api.delete,
+			"Version":    api.version,
+			"RegionId":   resource.Region,
+			api.deleteID: resource.ID,
+		}
+		if resource.Kind == ResourceInstance {
+			params["Force"] = "true" // 运行中的实例也直接释放
+		}
+		if _, err := c.callAPI(ctx, api.endpoint, params); err != nil {
+			return fmt.Errorf("调用 %s API 失败: %w", api.delete, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("不支持删除的资源类型: %s", resource.Kind)
+}
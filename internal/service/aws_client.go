@@ -1,17 +1,30 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// awsPricingHost AWS Price List API 地址（仅在 us-east-1 等少数区域提供）
+const awsPricingHost = "api.pricing.us-east-1.amazonaws.com"
+
 // awsClient AWS客户端实现
 type awsClient struct {
-	accessKey  string
-	secretKey  string
-	httpClient *http.Client
+	accessKey    string
+	secretKey    string
+	sessionToken string // 临时凭据的安全令牌（可选）
+	httpClient   *http.Client
 }
 
 // NewAWSClient 创建AWS客户端
@@ -28,32 +41,404 @@ func (c *awsClient) Provider() string {
 	return "aws"
 }
 
-// GetAvailableRegions 获取可用区域列表
+// GetAvailableRegions 获取账号已启用的区域列表
+// 使用 DescribeRegions API，AWS 不返回区域的显示名称，名称使用区域 ID
 func (c *awsClient) GetAvailableRegions(ctx context.Context) ([]Region, error) {
-	// TODO: 实现AWS API调用获取实时区域列表
-	regions := []Region{
-		{ID: "us-east-1", Name: "US East (N. Virginia)", DisplayName: "US East (N. Virginia) (us-east-1)", Available: true},
-		{ID: "us-west-2", Name: "US West (Oregon)", DisplayName: "US West (Oregon) (us-west-2)", Available: true},
-		{ID: "ap-southeast-1", Name: "Asia Pacific (Singapore)", DisplayName: "Asia Pacific (Singapore) (ap-southeast-1)", Available: true},
+	ids, err := c.DescribeRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	regions := make([]Region, 0, len(ids))
+	for _, id := range ids {
+		regions = append(regions, Region{ID: id, Name: id, DisplayName: id, Available: true})
 	}
 	return regions, nil
 }
 
-// GetAvailableInstanceTypes 获取指定区域的可用实例类型
+// GetAvailableInstanceTypes 获取指定区域提供的当前代实例类型
+// 使用 DescribeInstanceTypes API（分页）: https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstanceTypes.html
 func (c *awsClient) GetAvailableInstanceTypes(ctx context.Context, region string) ([]InstanceType, error) {
-	// TODO: 实现AWS API调用获取实时实例类型列表
-	instanceTypes := []InstanceType{
-		{ID: "t3.micro", Name: "t3.micro", CPU: 2, Memory: 1, Available: true, Currency: "USD"},
-		{ID: "t3.small", Name: "t3.small", CPU: 2, Memory: 2, Available: true, Currency: "USD"},
-		{ID: "t3.medium", Name: "t3.medium", CPU: 2, Memory: 4, Available: true, Currency: "USD"},
+	var instanceTypes []InstanceType
+	nextToken := ""
+	for {
+		query := url.Values{}
+		query.Set("Action", "DescribeInstanceTypes")
+		query.Set("Version", "2016-11-15")
+		query.Set("MaxResults", "100")
+		query.Set("Filter.1.Name", "current-generation")
+		query.Set("Filter.1.Value.1", "true")
+		if nextToken != "" {
+			query.Set("NextToken", nextToken)
+		}
+
+		response, err := c.callEC2API(ctx, region, query)
+		if err != nil {
+			return nil, fmt.Errorf("调用 DescribeInstanceTypes API 失败: %w", err)
+		}
+
+		var apiResponse struct {
+			InstanceTypes []struct {
+				InstanceType string `xml:"instanceType"`
+				VCPUs        int    `xml:"vCpuInfo>defaultVCpus"`
+				MemoryMiB    int    `xml:"memoryInfo>sizeInMiB"`
+			} `xml:"instanceTypeSet>item"`
+			NextToken string `xml:"nextToken"`
+		}
+		if err := xml.Unmarshal(response, &apiResponse); err != nil {
+			return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+		}
+
+		for _, it := range apiResponse.InstanceTypes {
+			instanceTypes = append(instanceTypes, InstanceType{
+				ID:        it.InstanceType,
+				Name:      it.InstanceType,
+				CPU:       it.VCPUs,
+				Memory:    float64(it.MemoryMiB) / 1024,
+				Available: true,
+				Currency:  "USD",
+			})
+		}
+		if apiResponse.NextToken == "" {
+			break
+		}
+		nextToken = apiResponse.NextToken
 	}
+	sort.Slice(instanceTypes, func(i, j int) bool { return instanceTypes[i].ID < instanceTypes[j].ID })
+
 	return instanceTypes, nil
 }
 
 // GetInstancePrice 获取实例价格信息
+// 使用 Price List API (GetProducts) 查询 Linux 共享租户按需实例价格
 func (c *awsClient) GetInstancePrice(ctx context.Context, region, instanceType string) (*InstancePrice, error) {
-	// TODO: 实现AWS API调用获取实时价格
-	return nil, fmt.Errorf("AWS价格查询功能待实现")
+	filter := func(field, value string) map[string]string {
+		return map[string]string{"Type": "TERM_MATCH", "Field": field, "Value": value}
+	}
+	params := map[string]interface{}{
+		"ServiceCode": "AmazonEC2",
+		"Filters": []map[string]string{
+			filter("instanceType", instanceType),
+			filter("regionCode", region),
+			filter("operatingSystem", "Linux"),
+			filter("tenancy", "Shared"),
+			filter("preInstalledSw", "NA"),
+			filter("capacitystatus", "Used"),
+		},
+		"FormatVersion": "aws_v1",
+		"MaxResults":    10,
+	}
+
+	response, err := c.callPricingAPI(ctx, "GetProducts", params)
+	if err != nil {
+		return nil, fmt.Errorf("调用 GetProducts API 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		PriceList []string `json:"PriceList"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+
+	// PriceList 中每一项都是一个 JSON 字符串
+	for _, item := range apiResponse.PriceList {
+		var product struct {
+			Terms struct {
+				OnDemand map[string]struct {
+					PriceDimensions map[string]struct {
+						Unit         string            `json:"unit"`
+						PricePerUnit map[string]string `json:"pricePerUnit"`
+					} `json:"priceDimensions"`
+				} `json:"OnDemand"`
+			} `json:"terms"`
+		}
+		if err := json.Unmarshal([]byte(item), &product); err != nil {
+			continue
+		}
+
+		for _, term := range product.Terms.OnDemand {
+			for _, dimension := range term.PriceDimensions {
+				if dimension.Unit != "Hrs" {
+					continue
+				}
+				pricePerHour, err := strconv.ParseFloat(dimension.PricePerUnit["USD"], 64)
+				if err != nil || pricePerHour == 0 {
+					continue
+				}
+				return &InstancePrice{
+					InstanceType:  instanceType,
+					Region:        region,
+					PricePerHour:  pricePerHour,
+					PricePerMonth: pricePerHour * 24 * 30,
+					Currency:      "USD",
+				}, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("无法获取有效价格")
+}
+
+// GetSpotPrice 获取抢占式实例价格，返回区域内当前价格最低的可用区
+func (c *awsClient) GetSpotPrice(ctx context.Context, region, instanceType string) (*InstancePrice, error) {
+	prices, err := c.currentSpotPrices(ctx, region, instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *InstancePrice
+	for zone, pricePerHour := range prices {
+		if best == nil || pricePerHour < best.PricePerHour || (pricePerHour == best.PricePerHour && zone < best.Zone) {
+			best = &InstancePrice{
+				InstanceType:  instanceType,
+				Region:        region,
+				Zone:          zone,
+				Spot:          true,
+				PricePerHour:  pricePerHour,
+				PricePerMonth: pricePerHour * 24 * 30,
+				Currency:      "USD",
+			}
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("区域 %s 没有 %s 的抢占式实例价格", region, instanceType)
+	}
+	return best, nil
+}
+
+// currentSpotPrices 查询各可用区当前的 Linux 抢占式实例价格（可用区 -> 每小时价格）
+// 使用 DescribeSpotPriceHistory API，StartTime 为当前时间时只返回每个可用区的最新价格
+// API 文档: https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSpotPriceHistory.html
+func (c *awsClient) currentSpotPrices(ctx context.Context, region, instanceType string) (map[string]float64, error) {
+	query := url.Values{}
+	query.Set("Action", "DescribeSpotPriceHistory")
+	query.Set("Version", "2016-11-15")
+	query.Set("InstanceType.1", instanceType)
+	query.Set("ProductDescription.1", "Linux/UNIX")
+	query.Set("StartTime", time.Now().UTC().Format(time.RFC3339))
+
+	response, err := c.callEC2API(ctx, region, query)
+	if err != nil {
+		return nil, fmt.Errorf("调用 DescribeSpotPriceHistory API 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Prices []struct {
+			InstanceType     string `xml:"instanceType"`
+			AvailabilityZone string `xml:"availabilityZone"`
+			SpotPrice        string `xml:"spotPrice"`
+		} `xml:"spotPriceHistorySet>item"`
+	}
+	if err := xml.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+
+	prices := make(map[string]float64)
+	for _, p := range apiResponse.Prices {
+		if p.InstanceType != instanceType {
+			continue
+		}
+		price, err := strconv.ParseFloat(p.SpotPrice, 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		prices[p.AvailabilityZone] = price
+	}
+	return prices, nil
 }
 
+// CheckAvailability 检查区域内各可用区是否提供指定实例类型
+// 使用 DescribeInstanceTypeOfferings API: https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstanceTypeOfferings.html
+// AWS 不提供抢占式实例容量的查询接口，spot 为 true 时额外要求可用区有当前的抢占式实例价格
+func (c *awsClient) CheckAvailability(ctx context.Context, region, instanceType string, spot bool) ([]ZoneAvailability, error) {
+	query := url.Values{}
+	query.Set("Action", "DescribeInstanceTypeOfferings")
+	query.Set("Version", "2016-11-15")
+	query.Set("LocationType", "availability-zone")
+	query.Set("Filter.1.Name", "instance-type")
+	query.Set("Filter.1.Value.1", instanceType)
+
+	response, err := c.callEC2API(ctx, region, query)
+	if err != nil {
+		return nil, fmt.Errorf("调用 DescribeInstanceTypeOfferings API 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Offerings []struct {
+			InstanceType string `xml:"instanceType"`
+			Location     string `xml:"location"`
+		} `xml:"instanceTypeOfferingSet>item"`
+	}
+	if err := xml.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+
+	var spotPrices map[string]float64
+	if spot {
+		if spotPrices, err = c.currentSpotPrices(ctx, region, instanceType); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []ZoneAvailability
+	for _, o := range apiResponse.Offerings {
+		if o.InstanceType != instanceType {
+			continue
+		}
+		zone := ZoneAvailability{Zone: o.Location, Available: true}
+		if _, ok := spotPrices[o.Location]; spot && !ok {
+			zone.Available = false
+			zone.Reason = "没有抢占式实例价格（可用区不提供该类型的抢占式实例）"
+		}
+		result = append(result, zone)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Zone < result[j].Zone })
+
+	return result, nil
+}
 
+// callEC2API 调用 AWS EC2 Query API（Signature Version 4 签名）
+func (c *awsClient) callEC2API(ctx context.Context, region string, query url.Values) ([]byte, error) {
+	// url.Values.Encode 按键排序，满足规范查询字符串的要求
+	host := fmt.Sprintf("ec2.%s.amazonaws.com", region)
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://"+host+"/?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	return c.doSigned(req, nil, region, "ec2")
+}
+
+// callPricingAPI 调用 AWS Price List API
+// API 文档: https://docs.aws.amazon.com/aws-cost-management/latest/APIReference/API_pricing_GetProducts.html
+func (c *awsClient) callPricingAPI(ctx context.Context, action string, params map[string]interface{}) ([]byte, error) {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求参数失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://"+awsPricingHost+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AWSPriceListService."+action)
+
+	// Price List API 只在 us-east-1 等少数区域提供
+	return c.doSigned(req, payload, "us-east-1", "pricing")
+}
+
+// VerifyCredentials 验证凭据：通过 STS GetCallerIdentity 查询账号，并检查 EC2 只读权限
+// API 文档: https://docs.aws.amazon.com/STS/latest/APIReference/API_GetCallerIdentity.html
+func (c *awsClient) VerifyCredentials(ctx context.Context) (*CredentialVerification, error) {
+	result := &CredentialVerification{Provider: c.Provider()}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", awsSTSEndpoint+"?Action=GetCallerIdentity&Version=2011-06-15", nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	response, err := c.doSigned(req, nil, "us-east-1", "sts")
+	if err == nil {
+		var identity struct {
+			Account string `xml:"GetCallerIdentityResult>Account"`
+			Arn     string `xml:"GetCallerIdentityResult>Arn"`
+		}
+		if err = xml.Unmarshal(response, &identity); err == nil {
+			result.AccountID = identity.Account
+			result.Identity = identity.Arn
+		}
+	}
+	result.addCheck("sts:GetCallerIdentity", err)
+
+	query := url.Values{}
+	query.Set("Action", "DescribeRegions")
+	query.Set("Version", "2016-11-15")
+	_, err = c.callEC2API(ctx, "us-east-1", query)
+	result.addCheck("ec2:DescribeRegions", err)
+
+	return result, nil
+}
+
+// setSessionToken 设置临时凭据的安全令牌
+func (c *awsClient) setSessionToken(token string) {
+	c.sessionToken = token
+}
+
+// doSigned 对请求进行 Signature Version 4 签名并发送
+func (c *awsClient) doSigned(req *http.Request, payload []byte, region, service string) ([]byte, error) {
+	if c.accessKey == "" || c.secretKey == "" {
+		return nil, fmt.Errorf("未配置 AWS AccessKey 和 SecretKey")
+	}
+	c.sign(req, payload, region, service, time.Now().UTC())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API 返回错误: %d, %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
+
+// sign 对请求进行 Signature Version 4 签名，设置 X-Amz-Date 和 Authorization 请求头
+// 签名文档: https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+func (c *awsClient) sign(req *http.Request, payload []byte, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if c.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.sessionToken)
+	}
+
+	// 构建规范请求（签名 host 和所有已设置的请求头）
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	canonicalRequest := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s",
+		req.Method, canonicalURI, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, sha256Hex(payload))
+
+	// 构建待签名字符串
+	credentialScope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s\n%s", amzDate, credentialScope, sha256Hex([]byte(canonicalRequest)))
+
+	// 计算签名
+	signature := hex.EncodeToString(hmacSHA256(awsSigningKey(c.secretKey, date, region, service), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, credentialScope, signedHeaders, signature))
+}
+
+// awsSigningKey 派生 Signature Version 4 签名密钥
+func awsSigningKey(secretKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
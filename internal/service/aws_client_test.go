@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// awsExampleSecretKey AWS 签名文档示例使用的 SecretKey
+const awsExampleSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+
+func TestAWSSigningKey(t *testing.T) {
+	// 示例取自 AWS Signature Version 4 文档的签名密钥派生示例
+	tests := []struct {
+		date    string
+		region  string
+		service string
+		want    string
+	}{
+		{date: "20120215", region: "us-east-1", service: "iam", want: "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date+"/"+tt.region+"/"+tt.service, func(t *testing.T) {
+			if got := hex.EncodeToString(awsSigningKey(awsExampleSecretKey, tt.date, tt.region, tt.service)); got != tt.want {
+				t.Errorf("签名密钥 = %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSSign(t *testing.T) {
+	// 示例取自 AWS Signature Version 4 文档: https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		headers map[string]string
+		payload string
+		region  string
+		service string
+		want    string
+	}{
+		{
+			name:    "文档示例 IAM ListUsers",
+			method:  "GET",
+			url:     "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+			region:  "us-east-1",
+			service: "iam",
+			want: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+				"SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			c := &awsClient{accessKey: "AKIDEXAMPLE", secretKey: awsExampleSecretKey}
+			c.sign(req, []byte(tt.payload), tt.region, tt.service, now)
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %s，期望 20150830T123600Z", got)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization = %s\n期望 %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+)
+
+// awsTag EC2 资源标签
+type awsTag struct {
+	Key   string `xml:"key"`
+	Value string `xml:"value"`
+}
+
+// awsTaggedItem EC2 列表接口中的资源（各接口只填充对应的 ID 字段）
+type awsTaggedItem struct {
+	InstanceID   string   `xml:"instanceId"`
+	VpcID        string   `xml:"vpcId"`
+	SubnetID     string   `xml:"subnetId"`
+	GroupID      string   `xml:"groupId"`
+	GroupName    string   `xml:"groupName"`
+	AllocationID string   `xml:"allocationId"`
+	PublicIP     string   `xml:"publicIp"`
+	Tags         []awsTag `xml:"tagSet>item"`
+}
+
+// awsTaggedResourceAPI AWS 按标签查询和删除资源使用的接口
+type awsTaggedResourceAPI struct {
+	kind     string
+	list     string                                    // 列表接口，支持 tag-key 过滤
+	paged    bool                                      // 是否支持 MaxResults/NextToken 分页
+	id       func(item awsTaggedItem) (string, string) // 资源 ID 和默认名称
+	delete   string                                    // 删除接口
+	deleteID string                                    // 删除接口的资源 ID 参数
+}
+
+// awsTaggedResourceAPIs 支持按标签查找的资源类型
+// API 文档: https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstances.html 等
+var awsTaggedResourceAPIs = []awsTaggedResourceAPI{
+	{ResourceInstance, "DescribeInstances", true, func(i awsTaggedItem) (string, string) { return i.InstanceID, "" }, "TerminateInstances", "InstanceId.1"},
+	{ResourceEIP, "DescribeAddresses", false, func(i awsTaggedItem) (string, string) { return i.AllocationID, i.PublicIP }, "ReleaseAddress", "AllocationId"},
+	{ResourceSecurityGroup, "DescribeSecurityGroups", true, func(i awsTaggedItem) (string, string) { return i.GroupID, i.GroupName }, "DeleteSecurityGroup", "GroupId"},
+	{ResourceSubnet, "DescribeSubnets", true, func(i awsTaggedItem) (string, string) { return i.SubnetID, "" }, "DeleteSubnet", "SubnetId"},
+	{ResourceVPC, "DescribeVpcs", true, func(i awsTaggedItem) (string, string) { return i.VpcID, "" }, "DeleteVpc", "VpcId"},
+}
+
+// DescribeRegions 查询账号已启用的全部区域（不含未开通的可选区域）
+// API 文档: https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeRegions.html
+func (c *awsClient) DescribeRegions(ctx context.Context) ([]string, error) {
+	query := url.Values{}
+	query.Set("Action", "DescribeRegions")
+	query.Set("Version", "2016-11-15")
+	response, err := c.callEC2API(ctx, "us-east-1", query)
+	if err != nil {
+		return nil, fmt.Errorf("调用 DescribeRegions API 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Regions []struct {
+			Name string `xml:"regionName"`
+		} `xml:"regionInfo>item"`
+	}
+	if err := xml.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 DescribeRegions 响应失败: %w", err)
+	}
+
+	regions := make([]string, 0, len(apiResponse.Regions))
+	for _, region := range apiResponse.Regions {
+		regions = append(regions, region.Name)
+	}
+	return regions, nil
+}
+
+// ListTaggedResources 列出区域内带有指定标签键的资源（不含已终止的实例）
+func (c *awsClient) ListTaggedResources(ctx context.Context, region, tagKey string) ([]TaggedResource, error) {
+	var resources []TaggedResource
+	for _, api := range awsTaggedResourceAPIs {
+		nextToken := ""
+		for {
+			query := url.Values{}
+			query.Set("Action", api.list)
+			query.Set("Version", "2016-11-15")
+			query.Set("Filter.1.Name", "tag-key")
+			query.Set("Filter.1.Value.1", tagKey)
+			if api.kind == ResourceInstance {
+				query.Set("Filter.2.Name", "instance-state-name")
+				for i, state := range []string{"pending", "running", "stopping", "stopped"} {
+					query.Set(fmt.Sprintf("Filter.2.Value.%d", i+1), state)
+				}
+			}
+			if api.paged {
+				query.Set("MaxResults", "100")
+				if nextToken != "" {
+					query.Set("NextToken", nextToken)
+				}
+			}
+
+			response, err := c.callEC2API(ctx, region, query)
+			if err != nil {
+				return nil, fmt.Errorf("调用 %s API 失败: %w", api.list, err)
+			}
+
+			var apiResponse struct {
+				Reservations []struct {
+					Instances []awsTaggedItem `xml:"instancesSet>item"`
+				} `xml:"reservationSet>item"`
+				Addresses      []awsTaggedItem `xml:"addressesSet>item"`
+				SecurityGroups []awsTaggedItem `xml:"securityGroupInfo>item"`
+				Subnets        []awsTaggedItem `xml:"subnetSet>item"`
+				Vpcs           []awsTaggedItem `xml:"vpcSet>item"`
+				NextToken      string          `xml:"nextToken"`
+			}
+			if err := xml.Unmarshal(response, &apiResponse); err != nil {
+				return nil, fmt.Errorf("解析 %s 响应失败: %w", api.list, err)
+			}
+
+			items := append(apiResponse.Addresses, apiResponse.SecurityGroups...)
+			items = append(items, apiResponse.Subnets...)
+			items = append(items, apiResponse.Vpcs...)
+			for _, r := range apiResponse.Reservations {
+				items = append(items, r.Instances...)
+			}
+			for _, item := range items {
+				resource := TaggedResource{
+					Provider: "aws",
+					Region:   region,
+					Kind:     api.kind,
+				}
+				resource.ID, resource.Name = api.id(item)
+				for _, tag := range item.Tags {
+					if tag.Key == "Name" {
+						resource.Name = tag.Value
+					}
+					resource.setResourceTags(tag.Key, tag.Value)
+				}
+				resources = append(resources, resource)
+			}
+
+			nextToken = apiResponse.NextToken
+			if !api.paged || nextToken == "" {
+				break
+			}
+		}
+	}
+	return resources, nil
+}
+
+// DeleteTaggedResource 删除资源（实例终止）
+func (c *awsClient) DeleteTaggedResource(ctx context.Context, resource TaggedResource) error {
+	for _, api := range awsTaggedResourceAPIs {
+		if api.kind != resource.Kind {
+			continue
+		}
+		query := url.Values{}
+		query.Set("Action", api.delete)
+		query.Set("Version", "2016-11-15")
+		query.Set(api.deleteID, resource.ID)
+		if _, err := c.callEC2API(ctx, resource.Region, query); err != nil {
+			return fmt.Errorf("调用 %s API 失败: %w", api.delete, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("不支持删除的资源类型: %s", resource.Kind)
+}
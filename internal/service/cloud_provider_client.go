@@ -2,7 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
 )
 
 // CloudProviderClient 云服务商客户端接口
@@ -16,6 +23,10 @@ type CloudProviderClient interface {
 	
 	// GetInstancePrice 获取实例价格信息
 	GetInstancePrice(ctx context.Context, region, instanceType string) (*InstancePrice, error)
+
+	// GetSpotPrice 获取抢占式实例价格
+	// 返回区域内当前价格最低的可用区及其价格
+	GetSpotPrice(ctx context.Context, region, instanceType string) (*InstancePrice, error)
 	
 	// Provider 返回云服务商名称
 	Provider() string
@@ -41,6 +52,61 @@ type InstanceType struct {
 	Currency    string  // 货币单位
 }
 
+// SpotPricePoint 抢占式实例历史价格点
+type SpotPricePoint struct {
+	Zone         string    // 可用区
+	InstanceType string    // 实例类型
+	Price        float64   // 抢占式实例价格（每小时）
+	OriginPrice  float64   // 按量付费原价（每小时）
+	Timestamp    time.Time // 价格时间
+}
+
+// InstanceAvailabilityChecker 实例库存检查器（可选能力）
+// 实现了该接口的客户端可以在推荐配置前校验实例类型在各可用区的库存和配额
+type InstanceAvailabilityChecker interface {
+	// CheckAvailability 检查区域内各可用区是否可以创建指定实例类型
+	// spot 为 true 时检查抢占式实例的库存
+	CheckAvailability(ctx context.Context, region, instanceType string, spot bool) ([]ZoneAvailability, error)
+}
+
+// SpotPriceHistoryProvider 抢占式实例历史价格查询（可选能力）
+// 用于根据历史价格计算抢占式实例出价上限
+type SpotPriceHistoryProvider interface {
+	// GetSpotPriceHistory 获取从 startTime 至今的抢占式实例历史价格
+	GetSpotPriceHistory(ctx context.Context, region, instanceType string, startTime time.Time) ([]SpotPricePoint, error)
+}
+
+// CredentialVerifier 凭据验证（可选能力）
+// 实现了该接口的客户端可以通过低成本的只读 API 验证凭据是否有效及具备基本权限
+type CredentialVerifier interface {
+	// VerifyCredentials 查询凭据对应的账号并检查基本权限
+	VerifyCredentials(ctx context.Context) (*CredentialVerification, error)
+}
+
+// TaggedResourceManager 按标签查找和删除云资源（可选能力）
+// 用于查找 cloudbot 创建但已不属于任何场景的孤立资源（cloudbot gc）
+type TaggedResourceManager interface {
+	// ListTaggedResources 列出区域内带有指定标签键的实例、EIP、安全组、子网和 VPC
+	ListTaggedResources(ctx context.Context, region, tagKey string) ([]TaggedResource, error)
+
+	// DeleteTaggedResource 删除（释放）ListTaggedResources 返回的资源
+	DeleteTaggedResource(ctx context.Context, resource TaggedResource) error
+}
+
+// RegionDescriber 通过 API 查询账号可用的全部区域 ID（可选能力）
+// 孤立资源扫描优先使用该接口，未实现时使用 GetAvailableRegions
+type RegionDescriber interface {
+	// DescribeRegions 返回账号可用的全部区域 ID
+	DescribeRegions(ctx context.Context) ([]string, error)
+}
+
+// ZoneAvailability 可用区库存信息
+type ZoneAvailability struct {
+	Zone      string // 可用区
+	Available bool   // 是否可以创建
+	Reason    string // 不可用原因
+}
+
 // NewCloudProviderClient 创建云服务商客户端
 func NewCloudProviderClient(provider string, accessKey, secretKey string) (CloudProviderClient, error) {
 	switch provider {
@@ -57,4 +123,67 @@ func NewCloudProviderClient(provider string, accessKey, secretKey string) (Cloud
 	}
 }
 
+// ErrSpotPriceUnsupported 云服务商未提供抢占式实例价格查询接口
+var ErrSpotPriceUnsupported = errors.New("不支持查询抢占式实例价格")
+
+// spotPriceUnsupported 不支持抢占式实例价格查询的客户端（GetSpotPrice 总是返回 ErrSpotPriceUnsupported）
+type spotPriceUnsupported interface {
+	spotPriceUnsupported()
+}
 
+// SupportedClientProviders 已实现 CloudProviderClient 的云服务商
+var SupportedClientProviders = []string{"aliyun", "tencent", "aws", "huaweicloud"}
+
+// clientFactory 创建云服务商客户端的函数
+type clientFactory func(credManager credentials.CredentialManager, provider string) (CloudProviderClient, error)
+
+// newClientFromCredentials 使用凭据管理器中的凭据创建云服务商客户端
+func newClientFromCredentials(credManager credentials.CredentialManager, provider string) (CloudProviderClient, error) {
+	providerEnum := credentials.Provider(provider)
+	if !credManager.HasCredentials(providerEnum) {
+		return nil, fmt.Errorf("未配置 %s 的凭据", provider)
+	}
+
+	creds, err := credManager.GetCredentials(providerEnum)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 凭据失败: %w", provider, err)
+	}
+
+	return newClientWithCredentials(provider, creds)
+}
+
+// newClientWithCredentials 使用给定凭据创建云服务商客户端（临时凭据会同时设置安全令牌）
+func newClientWithCredentials(provider string, creds *credentials.Credentials) (CloudProviderClient, error) {
+	client, err := NewCloudProviderClient(provider, creds.AccessKey, creds.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// 临时凭据（STS）需要同时携带安全令牌
+	if creds.SessionToken != "" {
+		setter, ok := client.(sessionTokenSetter)
+		if !ok {
+			return nil, fmt.Errorf("%s 客户端暂不支持临时凭据", provider)
+		}
+		setter.setSessionToken(creds.SessionToken)
+	}
+	return client, nil
+}
+
+// sessionTokenSetter 支持临时凭据的客户端
+type sessionTokenSetter interface {
+	setSessionToken(token string)
+}
+
+// sha256Hex 计算 SHA256 摘要并返回十六进制字符串（用于 API 签名）
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 计算 HMAC-SHA256（用于 API 签名）
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+)
+
+// credentialVerifyTimeout 单个云服务商凭据验证的超时时间
+const credentialVerifyTimeout = 30 * time.Second
+
+// ErrVerifyUnsupported 云服务商客户端不支持凭据验证
+var ErrVerifyUnsupported = errors.New("暂不支持凭据验证")
+
+// CredentialVerification 凭据验证结果
+type CredentialVerification struct {
+	Provider  string            // 云服务商
+	AccountID string            // 账号 ID / UID（身份查询失败时为空）
+	Identity  string            // 调用者身份（ARN 或用户 ID）
+	Checks    []PermissionCheck // 各项权限检查结果
+}
+
+// PermissionCheck 单项权限检查结果
+type PermissionCheck struct {
+	Action string // 检查的 API，如 ecs:DescribeRegions
+	Passed bool   // 是否通过
+	Error  string // 失败原因
+}
+
+// OK 是否所有检查均通过
+func (v *CredentialVerification) OK() bool {
+	if len(v.Checks) == 0 {
+		return false
+	}
+	for _, check := range v.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// addCheck 记录一项权限检查结果
+func (v *CredentialVerification) addCheck(action string, err error) {
+	check := PermissionCheck{Action: action, Passed: err == nil}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	v.Checks = append(v.Checks, check)
+}
+
+// VerifyCredentials 使用给定凭据调用云服务商的只读 API，验证凭据是否有效
+// 只有实现了 CredentialVerifier 的客户端支持验证
+func VerifyCredentials(ctx context.Context, provider string, creds *credentials.Credentials) (*CredentialVerification, error) {
+	if !containsString(SupportedClientProviders, provider) {
+		return nil, fmt.Errorf("%s %w", provider, ErrVerifyUnsupported)
+	}
+
+	client, err := newClientWithCredentials(provider, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, ok := client.(CredentialVerifier)
+	if !ok {
+		return nil, fmt.Errorf("%s %w", provider, ErrVerifyUnsupported)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, credentialVerifyTimeout)
+	defer cancel()
+
+	return verifier.VerifyCredentials(ctx)
+}
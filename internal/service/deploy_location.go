@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// zoneVariables 模板中表示可用区的变量名，声明了其中之一的模板支持更换可用区重试
+var zoneVariables = []string{"zone", "availability_zone", "zone_id"}
+
+// deployLocation 部署位置（区域和可选的可用区）
+type deployLocation struct {
+	Region  string
+	Zone    string // 为空表示使用模板默认的可用区
+	ZoneVar string // 可用区对应的模板变量名
+}
+
+// String 返回部署位置的说明
+func (l deployLocation) String() string {
+	if l.Zone != "" {
+		return fmt.Sprintf("区域 %s 可用区 %s", l.Region, l.Zone)
+	}
+	return "区域 " + l.Region
+}
+
+// apply 将部署位置写入变量（复制后返回）
+// 更换区域时删除原可用区变量，由模板自动选择新区域的可用区
+func (l deployLocation) apply(vars map[string]string) map[string]string {
+	newVars := make(map[string]string, len(vars)+1)
+	for k, v := range vars {
+		newVars[k] = v
+	}
+	newVars["region"] = l.Region
+	for _, name := range zoneVariables {
+		delete(newVars, name)
+	}
+	if l.Zone != "" {
+		newVars[l.ZoneVar] = l.Zone
+	}
+	return newVars
+}
+
+// alternateZones 区域内其他有库存的可用区
+// 只有模板声明了可用区变量且云服务商支持库存查询时才会返回结果
+func (s *projectService) alternateZones(ctx context.Context, provider string, scenario *domain.Scenario, region string, vars map[string]string) []deployLocation {
+	log := logger.GetLogger()
+
+	zoneVar := ""
+	for _, name := range zoneVariables {
+		if templateDeclaresVariable(scenario.Path, name) {
+			zoneVar = name
+			break
+		}
+	}
+	instanceType := vars["instance_type"]
+	if instanceType == "" {
+		instanceType = readTerraformValue(scenario.Path, "instance_type")
+	}
+	if zoneVar == "" || instanceType == "" || region == "" {
+		return nil
+	}
+
+	client, err := s.newClient(credentials.ManagerFromContext(ctx), provider)
+	if err != nil {
+		log.Debug("无法创建云服务商客户端，跳过可用区重试: %v", err)
+		return nil
+	}
+	checker, ok := client.(InstanceAvailabilityChecker)
+	if !ok {
+		return nil
+	}
+
+	enableSpot := vars["enable_spot"]
+	if enableSpot == "" {
+		enableSpot = readTerraformValue(scenario.Path, "enable_spot")
+	}
+	zones, err := checker.CheckAvailability(ctx, region, instanceType, enableSpot == "true")
+	if err != nil {
+		log.Warn("查询可用区库存失败，跳过可用区重试: region=%s, error=%v", region, err)
+		return nil
+	}
+
+	currentZone := vars[zoneVar]
+	if currentZone == "" {
+		currentZone = readTerraformValue(scenario.Path, zoneVar)
+	}
+	var locations []deployLocation
+	for _, zone := range zones {
+		if zone.Available && zone.Zone != currentZone {
+			locations = append(locations, deployLocation{Region: region, Zone: zone.Zone, ZoneVar: zoneVar})
+		}
+	}
+	return locations
+}
+
+// alternateRegions 可以替代当前区域的其他区域
+// 腾讯云使用国内区域列表，其他云服务商使用与当前区域同一地域（区域 ID 前缀相同，如 cn-、us-）的可用区域
+func (s *projectService) alternateRegions(ctx context.Context, provider, current string) []string {
+	log := logger.GetLogger()
+
+	var candidates []string
+	if provider == "tencent" {
+		candidates = GetDomesticRegions()
+	} else {
+		client, err := s.newClient(credentials.ManagerFromContext(ctx), provider)
+		if err != nil {
+			log.Warn("无法创建云服务商客户端，跳过区域重试: %v", err)
+			return nil
+		}
+		available, err := client.GetAvailableRegions(ctx)
+		if err != nil {
+			log.Warn("获取区域列表失败，跳过区域重试: %v", err)
+			return nil
+		}
+		group := regionGroup(current)
+		for _, region := range available {
+			if region.Available && regionGroup(region.ID) == group {
+				candidates = append(candidates, region.ID)
+			}
+		}
+	}
+
+	var regions []string
+	for _, region := range candidates {
+		if region != current {
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
+
+// regionGroup 区域所属的地域（区域 ID 的第一段）
+func regionGroup(region string) string {
+	return strings.SplitN(region, "-", 2)[0]
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+	"github.com/lucksec/cloudbot/internal/repository"
+)
+
+// GCService 孤立云资源清理服务接口
+// 通过 cloudbot 标签查找云端资源，与已知场景对比，找出不再属于任何场景的资源
+type GCService interface {
+	// FindOrphans 查找带有 cloudbot 标签但场景不存在或已销毁的云资源
+	FindOrphans(ctx context.Context, opts GCOptions) (*GCReport, error)
+
+	// DeleteOrphans 按依赖顺序（实例、EIP、安全组、子网、VPC）删除孤立资源，返回每个资源的删除结果
+	DeleteOrphans(ctx context.Context, orphans []OrphanResource) []GCDeleteResult
+}
+
+// GCOptions 孤立资源查找选项
+type GCOptions struct {
+	Providers []string // 云服务商（为空表示所有已配置凭据的云服务商）
+	Regions   []string // 区域（为空表示云服务商的所有可用区域）
+	Project   string   // 只查找标签中为该项目的资源（为空表示所有项目）
+}
+
+// OrphanResource 孤立资源
+type OrphanResource struct {
+	TaggedResource
+	Reason string // 判定为孤立资源的原因
+}
+
+// GCReport 孤立资源查找结果
+type GCReport struct {
+	Scanned int                 // 带有 cloudbot 标签的资源数量
+	Regions map[string][]string // 各云服务商扫描的区域
+	Orphans []OrphanResource    // 孤立资源
+	Unknown []TaggedResource    // 归属未知的资源：未使用共享元数据存储且所属项目在本地不存在，可能属于其他团队成员，不会删除
+	Errors  []string            // 查询失败的云服务商或区域（不影响其他区域的结果）
+}
+
+// GCDeleteResult 孤立资源删除结果
+type GCDeleteResult struct {
+	Resource OrphanResource
+	Err      error
+}
+
+// gcService 孤立云资源清理服务实现
+type gcService struct {
+	projectRepo repository.ProjectRepository
+}
+
+// NewGCService 创建孤立云资源清理服务
+func NewGCService(projectRepo repository.ProjectRepository) GCService {
+	return &gcService{projectRepo: projectRepo}
+}
+
+// FindOrphans 查找孤立资源
+func (s *gcService) FindOrphans(ctx context.Context, opts GCOptions) (*GCReport, error) {
+	log := logger.GetLogger()
+
+	scenarios, projects, err := s.knownScenarios()
+	if err != nil {
+		return nil, err
+	}
+	// 本地元数据只包含当前用户的项目，未通过 --project 指定范围时不能判断其他项目的资源是否孤立
+	checkOwner := !repository.IsShared(s.projectRepo) && opts.Project == ""
+
+	report := &GCReport{Regions: make(map[string][]string)}
+	credManager := credentials.ManagerFromContext(ctx)
+	providers := opts.Providers
+	if len(providers) == 0 {
+		for _, provider := range SupportedClientProviders {
+			if credManager.HasCredentials(credentials.Provider(provider)) {
+				providers = append(providers, provider)
+			}
+		}
+		if len(providers) == 0 {
+			return nil, fmt.Errorf("未配置任何云服务商的凭据")
+		}
+	}
+
+	for _, provider := range providers {
+		manager, err := taggedResourceManager(credManager, provider)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", provider, err))
+			continue
+		}
+
+		regions := opts.Regions
+		if len(regions) == 0 {
+			if regions, err = scanRegions(ctx, manager); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: 获取区域列表失败: %v", provider, err))
+				continue
+			}
+		}
+		report.Regions[provider] = regions
+
+		for _, region := range regions {
+			log.Debug("查找带标签的资源: provider=%s, region=%s", provider, region)
+			resources, err := manager.ListTaggedResources(ctx, region, TagScenario)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s/%s: %v", provider, region, err))
+				continue
+			}
+
+			for _, resource := range resources {
+				if opts.Project != "" && resource.Project != opts.Project {
+					continue
+				}
+				report.Scanned++
+				reason := orphanReason(resource, scenarios)
+				if reason == "" {
+					continue
+				}
+				if checkOwner && !projects[resource.Project] {
+					report.Unknown = append(report.Unknown, resource)
+					continue
+				}
+				report.Orphans = append(report.Orphans, OrphanResource{TaggedResource: resource, Reason: reason})
+			}
+		}
+	}
+
+	log.Info("孤立资源查找完成: scanned=%d, orphans=%d, unknown=%d, errors=%d", report.Scanned, len(report.Orphans), len(report.Unknown), len(report.Errors))
+	return report, nil
+}
+
+// DeleteOrphans 删除孤立资源
+// 依赖资源（如 VPC 下的实例）仍在释放中时删除可能失败，稍后重新运行即可
+func (s *gcService) DeleteOrphans(ctx context.Context, orphans []OrphanResource) []GCDeleteResult {
+	log := logger.GetLogger()
+
+	order := make(map[string]int)
+	for i, kind := range resourceDeleteOrder {
+		order[kind] = i
+	}
+	sorted := append([]OrphanResource(nil), orphans...)
+	sort.SliceStable(sorted, func(i, j int) bool { return order[sorted[i].Kind] < order[sorted[j].Kind] })
+
+	credManager := credentials.ManagerFromContext(ctx)
+	managers := make(map[string]TaggedResourceManager)
+	var results []GCDeleteResult
+	for _, orphan := range sorted {
+		manager, ok := managers[orphan.Provider]
+		if !ok {
+			var err error
+			manager, err = taggedResourceManager(credManager, orphan.Provider)
+			if err != nil {
+				results = append(results, GCDeleteResult{Resource: orphan, Err: err})
+				continue
+			}
+			managers[orphan.Provider] = manager
+		}
+
+		err := manager.DeleteTaggedResource(ctx, orphan.TaggedResource)
+		if err != nil {
+			log.Error("删除孤立资源失败: provider=%s, region=%s, kind=%s, id=%s, error=%v", orphan.Provider, orphan.Region, orphan.Kind, orphan.ID, err)
+		} else {
+			log.Info("已删除孤立资源: provider=%s, region=%s, kind=%s, id=%s, scenario=%s", orphan.Provider, orphan.Region, orphan.Kind, orphan.ID, orphan.Scenario)
+		}
+		results = append(results, GCDeleteResult{Resource: orphan, Err: err})
+	}
+	return results
+}
+
+// scanRegions 返回需要扫描的区域：优先通过 API 查询账号的全部区域，客户端不支持时使用 GetAvailableRegions 返回的区域
+func scanRegions(ctx context.Context, manager TaggedResourceManager) ([]string, error) {
+	if describer, ok := manager.(RegionDescriber); ok {
+		return describer.DescribeRegions(ctx)
+	}
+	available, err := manager.(CloudProviderClient).GetAvailableRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	regions := make([]string, 0, len(available))
+	for _, region := range available {
+		regions = append(regions, region.ID)
+	}
+	return regions, nil
+}
+
+// knownScenarios 所有项目的场景（场景 ID -> 场景）和项目名称
+func (s *gcService) knownScenarios() (map[string]*domain.Scenario, map[string]bool, error) {
+	projects, err := s.projectRepo.ListProjects()
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取项目列表失败: %w", err)
+	}
+
+	scenarios := make(map[string]*domain.Scenario)
+	names := make(map[string]bool)
+	for _, project := range projects {
+		names[project.Name] = true
+		for i := range project.Scenarios {
+			scenarios[project.Scenarios[i].ID] = &project.Scenarios[i]
+		}
+	}
+	return scenarios, names, nil
+}
+
+// orphanReason 判断资源是否为孤立资源，返回原因；属于现有场景或无法判断时返回空字符串
+func orphanReason(resource TaggedResource, scenarios map[string]*domain.Scenario) string {
+	if resource.Scenario == "" {
+		return "" // 模板声明了标签但部署时未传入场景 ID，无法判断归属
+	}
+	scenario, ok := scenarios[resource.Scenario]
+	if !ok {
+		return "场景不存在（已删除或场景目录被手动删除）"
+	}
+	if scenario.Status == "destroyed" {
+		return "场景已销毁"
+	}
+	return ""
+}
+
+// taggedResourceManager 创建支持按标签查找资源的云服务商客户端
+func taggedResourceManager(credManager credentials.CredentialManager, provider string) (TaggedResourceManager, error) {
+	client, err := newClientFromCredentials(credManager, provider)
+	if err != nil {
+		return nil, err
+	}
+	manager, ok := client.(TaggedResourceManager)
+	if !ok {
+		return nil, fmt.Errorf("%s 暂不支持按标签查找资源", provider)
+	}
+	return manager, nil
+}
@@ -1,25 +1,43 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	huaweicloudIAMHost = "iam.myhuaweicloud.com"
+	huaweicloudBSSHost = "bss.myhuaweicloud.com"
+)
+
 // huaweicloudClient 华为云客户端实现
 type huaweicloudClient struct {
 	accessKey  string
 	secretKey  string
 	httpClient *http.Client
+
+	projectMu    sync.Mutex
+	projectCache map[string]string // region -> 项目 ID
 }
 
 // NewHuaweicloudClient 创建华为云客户端
 func NewHuaweicloudClient(accessKey, secretKey string) (CloudProviderClient, error) {
 	return &huaweicloudClient{
-		accessKey:  accessKey,
-		secretKey:  secretKey,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		accessKey:    accessKey,
+		secretKey:    secretKey,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		projectCache: make(map[string]string),
 	}, nil
 }
 
@@ -39,20 +57,261 @@ func (c *huaweicloudClient) GetAvailableRegions(ctx context.Context) ([]Region,
 	return regions, nil
 }
 
-// GetAvailableInstanceTypes 获取指定区域的可用实例类型
+// GetAvailableInstanceTypes 获取指定区域的云服务器规格
+// 使用 ECS ListFlavors API: https://support.huaweicloud.com/api-ecs/zh-cn_topic_0020212656.html
 func (c *huaweicloudClient) GetAvailableInstanceTypes(ctx context.Context, region string) ([]InstanceType, error) {
-	// TODO: 实现华为云API调用获取实时实例类型列表
-	instanceTypes := []InstanceType{
-		{ID: "s6.small.1", Name: "s6.small.1", CPU: 1, Memory: 1, Available: true, Currency: "CNY"},
-		{ID: "s6.medium.2", Name: "s6.medium.2", CPU: 2, Memory: 4, Available: true, Currency: "CNY"},
+	projectID, err := c.projectID(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	host := fmt.Sprintf("ecs.%s.myhuaweicloud.com", region)
+	response, err := c.callAPI(ctx, "GET", host, "/v1/"+projectID+"/cloudservers/flavors", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用 ListFlavors API 失败: %w", err)
 	}
+
+	var apiResponse struct {
+		Flavors []struct {
+			ID           string            `json:"id"`
+			VCPUs        string            `json:"vcpus"`
+			RAM          int               `json:"ram"` // MB
+			OSExtraSpecs map[string]string `json:"os_extra_specs"`
+		} `json:"flavors"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+
+	var instanceTypes []InstanceType
+	for _, f := range apiResponse.Flavors {
+		cpu, err := strconv.Atoi(f.VCPUs)
+		if err != nil {
+			continue
+		}
+		// cond:operation:status 为 abandon（下线）或 sellout（售罄）时不可购买
+		status := f.OSExtraSpecs["cond:operation:status"]
+		instanceTypes = append(instanceTypes, InstanceType{
+			ID:        f.ID,
+			Name:      f.ID,
+			CPU:       cpu,
+			Memory:    float64(f.RAM) / 1024,
+			Available: status != "abandon" && status != "sellout",
+			Currency:  "CNY",
+		})
+	}
+	sort.Slice(instanceTypes, func(i, j int) bool { return instanceTypes[i].ID < instanceTypes[j].ID })
+
 	return instanceTypes, nil
 }
 
 // GetInstancePrice 获取实例价格信息
+// 使用 BSS 按需产品询价 API 查询 Linux 弹性云服务器每小时价格
 func (c *huaweicloudClient) GetInstancePrice(ctx context.Context, region, instanceType string) (*InstancePrice, error) {
-	// TODO: 实现华为云API调用获取实时价格
-	return nil, fmt.Errorf("华为云价格查询功能待实现")
+	projectID, err := c.projectID(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"product_infos": []map[string]interface{}{
+			{
+				"id":                 "1",
+				"cloud_service_type": "hws.service.type.ec2",
+				"resource_type":      "hws.resource.type.vm",
+				"resource_spec":      instanceType + ".linux",
+				"region":             region,
+				"usage_factor":       "Duration",
+				"usage_value":        1,
+				"usage_measure_id":   4, // 4: 小时
+				"subscription_num":   1,
+			},
+		},
+	}
+
+	response, err := c.callAPI(ctx, "POST", huaweicloudBSSHost, "/v2/bills/ratings/on-demand-resources", nil, params)
+	if err != nil {
+		return nil, fmt.Errorf("调用按需产品询价 API 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Currency              string  `json:"currency"`
+		Amount                float64 `json:"amount"`
+		OfficialWebsiteAmount float64 `json:"official_website_amount"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+
+	pricePerHour := apiResponse.Amount
+	if pricePerHour == 0 {
+		pricePerHour = apiResponse.OfficialWebsiteAmount
+	}
+	if pricePerHour == 0 {
+		return nil, fmt.Errorf("无法获取有效价格")
+	}
+
+	currency := apiResponse.Currency
+	if currency == "" {
+		currency = "CNY"
+	}
+
+	return &InstancePrice{
+		InstanceType:  instanceType,
+		Region:        region,
+		PricePerHour:  pricePerHour,
+		PricePerMonth: pricePerHour * 24 * 30,
+		Currency:      currency,
+	}, nil
+}
+
+// GetSpotPrice 获取抢占式实例价格
+// 华为云询价 API 不支持竞价计费实例，返回 ErrSpotPriceUnsupported，由调用方跳过并提示
+func (c *huaweicloudClient) GetSpotPrice(ctx context.Context, region, instanceType string) (*InstancePrice, error) {
+	return nil, fmt.Errorf("华为云%w", ErrSpotPriceUnsupported)
+}
+
+// spotPriceUnsupported 标记华为云客户端不支持抢占式实例价格查询
+func (c *huaweicloudClient) spotPriceUnsupported() {}
+
+// VerifyCredentials 验证凭据：通过 IAM 查询项目列表获取账号 ID（华为云不提供 GetCallerIdentity）
+// API 文档: https://support.huaweicloud.com/api-iam/iam_06_0001.html
+func (c *huaweicloudClient) VerifyCredentials(ctx context.Context) (*CredentialVerification, error) {
+	result := &CredentialVerification{Provider: c.Provider()}
+
+	response, err := c.callAPI(ctx, "GET", huaweicloudIAMHost, "/v3/projects", nil, nil)
+	if err == nil {
+		var apiResponse struct {
+			Projects []struct {
+				DomainID string `json:"domain_id"`
+			} `json:"projects"`
+		}
+		if err = json.Unmarshal(response, &apiResponse); err == nil && len(apiResponse.Projects) > 0 {
+			result.AccountID = apiResponse.Projects[0].DomainID
+		}
+	}
+	result.addCheck("iam:projects:listProjects", err)
+
+	return result, nil
 }
 
+// projectID 查询区域对应的项目 ID（询价 API 需要，结果按区域缓存）
+func (c *huaweicloudClient) projectID(ctx context.Context, region string) (string, error) {
+	c.projectMu.Lock()
+	cached, ok := c.projectCache[region]
+	c.projectMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	response, err := c.callAPI(ctx, "GET", huaweicloudIAMHost, "/v3/projects", map[string]string{"name": region}, nil)
+	if err != nil {
+		return "", fmt.Errorf("查询项目 ID 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Projects []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"projects"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return "", fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+	if len(apiResponse.Projects) == 0 {
+		return "", fmt.Errorf("区域 %s 未找到项目", region)
+	}
+
+	id := apiResponse.Projects[0].ID
+	c.projectMu.Lock()
+	c.projectCache[region] = id
+	c.projectMu.Unlock()
+	return id, nil
+}
+
+// callAPI 调用华为云 API（SDK-HMAC-SHA256 签名）
+// 签名文档: https://support.huaweicloud.com/devg-apisign/api-sign-algorithm.html
+func (c *huaweicloudClient) callAPI(ctx context.Context, method, host, path string, query map[string]string, params map[string]interface{}) ([]byte, error) {
+	if c.accessKey == "" || c.secretKey == "" {
+		return nil, fmt.Errorf("未配置华为云 AccessKey 和 SecretKey")
+	}
+
+	var payload []byte
+	if params != nil {
+		var err error
+		payload, err = json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求参数失败: %w", err)
+		}
+	}
+
+	// 构建规范查询字符串（按字典序排序）
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(query[k]))
+	}
+	queryString := strings.Join(parts, "&")
 
+	sdkDate := time.Now().UTC().Format("20060102T150405Z")
+	contentType := "application/json"
+
+	// 构建规范请求（路径必须以 / 结尾）
+	canonicalURI := path
+	if !strings.HasSuffix(canonicalURI, "/") {
+		canonicalURI += "/"
+	}
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-sdk-date:%s\n", contentType, host, sdkDate)
+	signedHeaders := "content-type;host;x-sdk-date"
+	canonicalRequest := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s",
+		method, canonicalURI, queryString, canonicalHeaders, signedHeaders, sha256Hex(payload))
+
+	stringToSign := fmt.Sprintf("SDK-HMAC-SHA256\n%s\n%s", sdkDate, sha256Hex([]byte(canonicalRequest)))
+	signature := hex.EncodeToString(hmacSHA256([]byte(c.secretKey), stringToSign))
+
+	authorization := fmt.Sprintf("SDK-HMAC-SHA256 Access=%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, signedHeaders, signature)
+
+	fullURL := "https://" + host + path
+	if queryString != "" {
+		fullURL += "?" + queryString
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Sdk-Date", sdkDate)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	// 删除接口返回 204 No Content
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errorResponse struct {
+			ErrorCode string `json:"error_code"`
+			ErrorMsg  string `json:"error_msg"`
+		}
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.ErrorCode != "" {
+			return nil, fmt.Errorf("API 错误: %s - %s", errorResponse.ErrorCode, errorResponse.ErrorMsg)
+		}
+		return nil, fmt.Errorf("API 返回错误: %d, %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// huaweicloudPageSize 按标签查询接口每页数量
+const huaweicloudPageSize = 100
+
+// huaweicloudTaggedResourceAPI 华为云按标签查询和删除资源使用的接口
+type huaweicloudTaggedResourceAPI struct {
+	kind       string
+	service    string // 服务域名前缀（ecs / vpc）
+	filter     string // 按标签查询资源实例的接口路径（{project_id} 之后的部分）
+	stringPage bool   // 分页参数 limit/offset 是否为字符串类型
+}
+
+// huaweicloudTaggedResourceAPIs 支持按标签查找的资源类型
+// API 文档: https://support.huaweicloud.com/api-ecs/ecs_02_1002.html（按标签查询云服务器）、
+// https://support.huaweicloud.com/api-vpc/vpc_tag_0005.html（按标签查询 VPC）等
+var huaweicloudTaggedResourceAPIs = []huaweicloudTaggedResourceAPI{
+	{ResourceInstance, "ecs", "/v1/%s/cloudservers/resource_instances/action", true},
+	{ResourceEIP, "vpc", "/v2.0/%s/publicips/resource_instances/action", false},
+	{ResourceSecurityGroup, "vpc", "/v2.0/%s/security-groups/resource_instances/action", false},
+	{ResourceSubnet, "vpc", "/v2.0/%s/subnets/resource_instances/action", false},
+	{ResourceVPC, "vpc", "/v2.0/%s/vpcs/resource_instances/action", false},
+}
+
+// DescribeRegions 查询账号下的全部区域（IAM 项目，不含子项目）
+// API 文档: https://support.huaweicloud.com/api-iam/iam_06_0001.html
+func (c *huaweicloudClient) DescribeRegions(ctx context.Context) ([]string, error) {
+	response, err := c.callAPI(ctx, "GET", huaweicloudIAMHost, "/v3/auth/projects", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询项目列表失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Projects []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"projects"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+
+	var regions []string
+	c.projectMu.Lock()
+	defer c.projectMu.Unlock()
+	for _, project := range apiResponse.Projects {
+		// MOS 为系统项目，名称带下划线的是区域下的子项目
+		if project.Name == "MOS" || strings.Contains(project.Name, "_") {
+			continue
+		}
+		regions = append(regions, project.Name)
+		c.projectCache[project.Name] = project.ID
+	}
+	return regions, nil
+}
+
+// ListTaggedResources 列出区域内带有指定标签键的资源
+func (c *huaweicloudClient) ListTaggedResources(ctx context.Context, region, tagKey string) ([]TaggedResource, error) {
+	projectID, err := c.projectID(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []TaggedResource
+	for _, api := range huaweicloudTaggedResourceAPIs {
+		host := fmt.Sprintf("%s.%s.myhuaweicloud.com", api.service, region)
+		path := fmt.Sprintf(api.filter, projectID)
+		for offset := 0; ; offset += huaweicloudPageSize {
+			params := map[string]interface{}{
+				"action": "filter",
+				"tags":   []map[string]interface{}{{"key": tagKey, "values": []string{}}},
+			}
+			if api.stringPage {
+				params["limit"] = strconv.Itoa(huaweicloudPageSize)
+				params["offset"] = strconv.Itoa(offset)
+			} else {
+				params["limit"] = huaweicloudPageSize
+				params["offset"] = offset
+			}
+
+			response, err := c.callAPI(ctx, "POST", host, path, nil, params)
+			if err != nil {
+				return nil, fmt.Errorf("按标签查询 %s 失败: %w", api.kind, err)
+			}
+
+			var apiResponse struct {
+				Resources []struct {
+					ID   string `json:"resource_id"`
+					Name string `json:"resource_name"`
+					Tags []struct {
+						Key   string `json:"key"`
+						Value string `json:"value"`
+					} `json:"tags"`
+				} `json:"resources"`
+				TotalCount int `json:"total_count"`
+			}
+			if err := json.Unmarshal(response, &apiResponse); err != nil {
+				return nil, fmt.Errorf("解析 %s 查询结果失败: %w", api.kind, err)
+			}
+
+			for _, item := range apiResponse.Resources {
+				resource := TaggedResource{
+					Provider: "huaweicloud",
+					Region:   region,
+					Kind:     api.kind,
+					ID:       item.ID,
+					Name:     item.Name,
+				}
+				for _, tag := range item.Tags {
+					resource.setResourceTags(tag.Key, tag.Value)
+				}
+				resources = append(resources, resource)
+			}
+			if len(apiResponse.Resources) == 0 || offset+len(apiResponse.Resources) >= apiResponse.TotalCount {
+				break
+			}
+		}
+	}
+	return resources, nil
+}
+
+// DeleteTaggedResource 删除资源（云服务器同时删除云硬盘，弹性公网 IP 单独释放）
+func (c *huaweicloudClient) DeleteTaggedResource(ctx context.Context, resource TaggedResource) error {
+	projectID, err := c.projectID(ctx, resource.Region)
+	if err != nil {
+		return err
+	}
+	ecsHost := fmt.Sprintf("ecs.%s.myhuaweicloud.com", resource.Region)
+	vpcHost := fmt.Sprintf("vpc.%s.myhuaweicloud.com", resource.Region)
+
+	switch resource.Kind {
+	case ResourceInstance:
+		params := map[string]interface{}{
+			"servers":         []map[string]string{{"id": resource.ID}},
+			"delete_publicip": false,
+			"delete_volume":   true,
+		}
+		_, err = c.callAPI(ctx, "POST", ecsHost, "/v1/"+projectID+"/cloudservers/delete", nil, params)
+	case ResourceEIP:
+		_, err = c.callAPI(ctx, "DELETE", vpcHost, "/v1/"+projectID+"/publicips/"+resource.ID, nil, nil)
+	case ResourceSecurityGroup:
+		_, err = c.callAPI(ctx, "DELETE", vpcHost, "/v1/"+projectID+"/security-groups/"+resource.ID, nil, nil)
+	case ResourceSubnet:
+		// 删除子网的接口需要所属 VPC 的 ID
+		var vpcID string
+		if vpcID, err = c.subnetVPC(ctx, vpcHost, projectID, resource.ID); err == nil {
+			_, err = c.callAPI(ctx, "DELETE", vpcHost, "/v1/"+projectID+"/vpcs/"+vpcID+"/subnets/"+resource.ID, nil, nil)
+		}
+	case ResourceVPC:
+		_, err = c.callAPI(ctx, "DELETE", vpcHost, "/v1/"+projectID+"/vpcs/"+resource.ID, nil, nil)
+	default:
+		return fmt.Errorf("不支持删除的资源类型: %s", resource.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("删除 %s %s 失败: %w", resource.Kind, resource.ID, err)
+	}
+	return nil
+}
+
+// subnetVPC 查询子网所属的 VPC
+func (c *huaweicloudClient) subnetVPC(ctx context.Context, vpcHost, projectID, subnetID string) (string, error) {
+	response, err := c.callAPI(ctx, "GET", vpcHost, "/v1/"+projectID+"/subnets/"+subnetID, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("查询子网失败: %w", err)
+	}
+	var apiResponse struct {
+		Subnet struct {
+			VpcID string `json:"vpc_id"`
+		} `json:"subnet"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return "", fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+	return apiResponse.Subnet.VpcID, nil
+}
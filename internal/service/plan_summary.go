@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// 资源变更类型
+const (
+	ChangeCreate  = "create"
+	ChangeUpdate  = "update"
+	ChangeReplace = "replace"
+	ChangeDelete  = "delete"
+)
+
+// instanceResourceTypes 表示云主机的资源类型（用于统计实例数量和估算费用）
+var instanceResourceTypes = map[string]bool{
+	"alicloud_instance":            true,
+	"tencentcloud_instance":        true,
+	"aws_instance":                 true,
+	"aws_spot_instance_request":    true,
+	"huaweicloud_compute_instance": true,
+	"vultr_instance":               true,
+}
+
+// ResourceChange 计划中的单个资源变更
+type ResourceChange struct {
+	Address string                 // 资源地址，如 alicloud_instance.node[0]
+	Type    string                 // 资源类型，如 alicloud_instance
+	Action  string                 // 变更类型：create, update, replace, delete
+	After   map[string]interface{} // 变更后的已知属性（删除时为空）
+}
+
+// IsInstance 是否为云主机资源
+func (c *ResourceChange) IsInstance() bool {
+	return instanceResourceTypes[c.Type]
+}
+
+// PlanSummary Terraform 计划的变更摘要（terraform show -json 的 resource_changes）
+type PlanSummary struct {
+	Changes []ResourceChange // 有实际变更的资源（不含 no-op 和数据源）
+	Drift   []ResourceChange // 刷新时发现的、在 Terraform 之外发生的变化（resource_drift）
+}
+
+// Count 统计指定类型的变更数量
+func (p *PlanSummary) Count(action string) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// InstanceCount 统计指定类型变更中的云主机数量
+func (p *PlanSummary) InstanceCount(action string) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action && c.IsInstance() {
+			n++
+		}
+	}
+	return n
+}
+
+// planResourceChange terraform show -json 中的单个资源变更
+type planResourceChange struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Change  struct {
+		Actions []string               `json:"actions"`
+		After   map[string]interface{} `json:"after"`
+	} `json:"change"`
+}
+
+// planJSON terraform show -json <planfile> 输出中使用的字段
+type planJSON struct {
+	ResourceChanges []planResourceChange `json:"resource_changes"`
+	ResourceDrift   []planResourceChange `json:"resource_drift"`
+}
+
+// parsePlanJSON 解析 terraform show -json 输出的计划
+func parsePlanJSON(data []byte) (*PlanSummary, error) {
+	var plan planJSON
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("解析 Terraform 计划失败: %w", err)
+	}
+
+	return &PlanSummary{
+		Changes: convertResourceChanges(plan.ResourceChanges),
+		Drift:   convertResourceChanges(plan.ResourceDrift),
+	}, nil
+}
+
+// convertResourceChanges 过滤数据源和无变更的资源，并转换变更类型
+func convertResourceChanges(changes []planResourceChange) []ResourceChange {
+	var result []ResourceChange
+	for _, rc := range changes {
+		if rc.Mode == "data" {
+			continue
+		}
+		action := planAction(rc.Change.Actions)
+		if action == "" {
+			continue
+		}
+		result = append(result, ResourceChange{
+			Address: rc.Address,
+			Type:    rc.Type,
+			Action:  action,
+			After:   rc.Change.After,
+		})
+	}
+	return result
+}
+
+// planAction 将 Terraform 的 actions 转换为变更类型，无变更时返回空字符串
+func planAction(actions []string) string {
+	switch strings.Join(actions, ",") {
+	case "create":
+		return ChangeCreate
+	case "update":
+		return ChangeUpdate
+	case "delete":
+		return ChangeDelete
+	case "delete,create", "create,delete":
+		return ChangeReplace
+	default:
+		return "" // no-op、read
+	}
+}
+
+// PlanCostLine 按实例类型汇总的费用估算
+type PlanCostLine struct {
+	InstanceType string  // 实例类型
+	Spot         bool    // 是否为抢占式实例
+	Count        int     // 新建实例数量
+	PricePerHour float64 // 单台每小时价格（查询失败时为 0）
+	Currency     string  // 货币单位
+	Error        string  // 价格查询失败原因
+}
+
+// PlanCostEstimate 计划新建实例的费用估算
+type PlanCostEstimate struct {
+	Region string
+	Lines  []PlanCostLine
+}
+
+// TotalPerHour 估算的每小时总费用，以及是否有实例的价格未知
+func (e *PlanCostEstimate) TotalPerHour() (float64, string, bool) {
+	var total float64
+	var currency string
+	complete := true
+	for _, line := range e.Lines {
+		if line.Error != "" {
+			complete = false
+			continue
+		}
+		total += line.PricePerHour * float64(line.Count)
+		currency = line.Currency
+	}
+	return total, currency, complete
+}
+
+// estimatePlanCost 估算计划中新建（含替换）云主机的每小时费用
+// 价格通过云服务商 API 查询，查询失败只记录原因，不影响部署
+func (s *projectService) estimatePlanCost(ctx context.Context, scenario *domain.Scenario, vars map[string]string, summary *PlanSummary) *PlanCostEstimate {
+	provider := strings.SplitN(scenario.Template, "/", 2)[0]
+	region := vars["region"]
+	if region == "" {
+		region = readTerraformValue(scenario.Path, "region")
+	}
+	estimate := &PlanCostEstimate{Region: region}
+
+	// 按实例类型和计费方式分组
+	type key struct {
+		instanceType string
+		spot         bool
+	}
+	counts := make(map[key]int)
+	for _, c := range summary.Changes {
+		if !c.IsInstance() || (c.Action != ChangeCreate && c.Action != ChangeReplace) {
+			continue
+		}
+		counts[key{instanceTypeOf(c), isSpotInstance(c)}]++
+	}
+	if len(counts) == 0 {
+		return estimate
+	}
+
+	var client CloudProviderClient
+	var clientErr error
+	if region == "" {
+		clientErr = fmt.Errorf("无法确定区域")
+	} else {
+		client, clientErr = s.newClient(credentials.ManagerFromContext(ctx), provider)
+	}
+
+	for k, count := range counts {
+		line := PlanCostLine{InstanceType: k.instanceType, Spot: k.spot, Count: count}
+		switch {
+		case clientErr != nil:
+			line.Error = clientErr.Error()
+		case k.instanceType == "":
+			line.Error = "无法确定实例类型"
+		default:
+			price, err := queryInstancePrice(ctx, client, region, k.instanceType, k.spot)
+			if err != nil {
+				line.Error = err.Error()
+				logger.GetLogger().Warn("查询实例价格失败: provider=%s, region=%s, instance_type=%s, error=%v", provider, region, k.instanceType, err)
+			} else {
+				line.PricePerHour = price.PricePerHour
+				line.Currency = price.Currency
+			}
+		}
+		estimate.Lines = append(estimate.Lines, line)
+	}
+	sort.Slice(estimate.Lines, func(i, j int) bool { return estimate.Lines[i].InstanceType < estimate.Lines[j].InstanceType })
+	return estimate
+}
+
+// queryInstancePrice 查询实例的按量付费或抢占式实例价格
+func queryInstancePrice(ctx context.Context, client CloudProviderClient, region, instanceType string, spot bool) (*InstancePrice, error) {
+	callCtx, cancel := context.WithTimeout(ctx, optimizerCallTimeout)
+	defer cancel()
+
+	if spot {
+		return client.GetSpotPrice(callCtx, region, instanceType)
+	}
+	return client.GetInstancePrice(callCtx, region, instanceType)
+}
+
+// instanceTypeOf 从资源属性中读取实例类型
+func instanceTypeOf(c ResourceChange) string {
+	for _, name := range []string{"instance_type", "flavor_id", "plan"} {
+		if v, ok := c.After[name].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// isSpotInstance 根据资源类型和计费属性判断是否为抢占式实例
+func isSpotInstance(c ResourceChange) bool {
+	if c.Type == "aws_spot_instance_request" {
+		return true
+	}
+	if v, ok := c.After["spot_strategy"].(string); ok && v != "" && v != "NoSpot" {
+		return true // 阿里云
+	}
+	if v, ok := c.After["instance_charge_type"].(string); ok && v == "SPOTPAID" {
+		return true // 腾讯云
+	}
+	if v, ok := c.After["charging_mode"].(string); ok && v == "spot" {
+		return true // 华为云
+	}
+	if v, ok := c.After["instance_market_options"].([]interface{}); ok && len(v) > 0 {
+		return true // AWS
+	}
+	return false
+}
+
+// changeSymbols 各变更类型的显示符号和名称
+var changeSymbols = map[string]string{
+	ChangeCreate:  "+ 新建",
+	ChangeUpdate:  "~ 修改",
+	ChangeReplace: "± 替换",
+	ChangeDelete:  "- 删除",
+}
+
+// printPlanSummary 输出计划变更摘要和费用估算
+func printPlanSummary(w io.Writer, summary *PlanSummary, estimate *PlanCostEstimate) {
+	fmt.Fprintf(w, "\n计划变更: %d 个新建, %d 个修改, %d 个替换, %d 个删除\n",
+		summary.Count(ChangeCreate), summary.Count(ChangeUpdate), summary.Count(ChangeReplace), summary.Count(ChangeDelete))
+	if len(summary.Changes) == 0 {
+		fmt.Fprintln(w, "没有需要变更的资源")
+		return
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  操作\t资源\t类型")
+	for _, action := range []string{ChangeDelete, ChangeReplace, ChangeUpdate, ChangeCreate} {
+		for _, c := range summary.Changes {
+			if c.Action == action {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\n", changeSymbols[action], c.Address, c.Type)
+			}
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n云主机: 新建 %d 台, 替换 %d 台, 删除 %d 台\n",
+		summary.InstanceCount(ChangeCreate), summary.InstanceCount(ChangeReplace), summary.InstanceCount(ChangeDelete))
+
+	if estimate == nil || len(estimate.Lines) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "预估费用（区域 %s）:\n", estimate.Region)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, line := range estimate.Lines {
+		billing := "按量付费"
+		if line.Spot {
+			billing = "抢占式"
+		}
+		instanceType := line.InstanceType
+		if instanceType == "" {
+			instanceType = "(未知)"
+		}
+		if line.Error != "" {
+			fmt.Fprintf(tw, "  %s\t%s\t× %d\t价格未知: %s\n", instanceType, billing, line.Count, line.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t× %d\t%.4f %s/小时\n", instanceType, billing, line.Count, line.PricePerHour*float64(line.Count), line.Currency)
+	}
+	tw.Flush()
+
+	total, currency, complete := estimate.TotalPerHour()
+	if currency != "" {
+		note := ""
+		if !complete {
+			note = "（部分实例价格未知，未计入）"
+		}
+		fmt.Fprintf(w, "  合计: %.4f %s/小时, 约 %.2f %s/月%s\n", total, currency, total*24*30, currency, note)
+	}
+}
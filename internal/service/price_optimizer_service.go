@@ -3,68 +3,87 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/domain"
 )
 
+const (
+	// optimizerConcurrency 价格查询的最大并发数
+	optimizerConcurrency = 8
+	// optimizerCallTimeout 单次价格查询的超时时间
+	optimizerCallTimeout = 15 * time.Second
+)
+
+// defaultOptimizerInstanceTypes 未指定实例类型时各云服务商比较的默认实例类型
+var defaultOptimizerInstanceTypes = map[string][]string{
+	"aliyun":      {"ecs.t5-lc1m1.small", "ecs.t5-lc1m2.small"},
+	"tencent":     {"S5.SMALL1", "S5.SMALL2"},
+	"aws":         {"t3.micro", "t3.small"},
+	"huaweicloud": {"s6.small.1", "s6.medium.2"},
+}
+
+// defaultOptimizerRegions 未指定区域时各云服务商比较的默认区域
+// 未列出的云服务商使用客户端返回的可用区域列表
+var defaultOptimizerRegions = map[string][]string{
+	"aliyun": {
+		"cn-beijing", "cn-shanghai", "cn-hangzhou", "cn-shenzhen",
+		"cn-hongkong", "ap-southeast-1",
+	},
+}
+
 // PriceOptimizerService 价格优化服务
 // 用于查找最优价格配置并应用到场景创建
 type PriceOptimizerService interface {
-	// FindOptimalConfig 查找最优配置（最低价格）
-	FindOptimalConfig(ctx context.Context, provider, template string, instanceTypes, regions []string) (*OptimalInstanceConfig, error)
+	// FindOptimalConfig 查找最优配置（最低价格），spot 为 true 时按抢占式实例价格和库存选择
+	FindOptimalConfig(ctx context.Context, provider, template string, instanceTypes, regions []string, spot bool) (*OptimalInstanceConfig, error)
 
 	// ApplyOptimalConfig 将最优配置应用到 Terraform 变量
 	ApplyOptimalConfig(optimal *OptimalInstanceConfig) map[string]string
 
 	// ListRegionPrices 列出各区域价格（按价格排序，标注最低价）
 	ListRegionPrices(ctx context.Context, provider, template string, instanceTypes, regions []string) ([]InstancePrice, error)
+
+	// FindPlacements 查找经过库存和配额校验的部署位置（按价格排序，记录跳过原因）
+	FindPlacements(ctx context.Context, provider, template string, instanceTypes, regions []string, spot bool) (*PlacementResult, error)
 }
 
 // priceOptimizerService 价格优化服务实现
+// 基于通用的 CloudProviderClient.GetInstancePrice 查询各云服务商实时价格
 type priceOptimizerService struct {
-	config    *config.Config
-	optimizer AliyunPriceOptimizer
+	config      *config.Config
+	credManager credentials.CredentialManager
 }
 
 // NewPriceOptimizerService 创建价格优化服务
-func NewPriceOptimizerService(cfg *config.Config, optimizer AliyunPriceOptimizer) PriceOptimizerService {
+func NewPriceOptimizerService(cfg *config.Config, credManager credentials.CredentialManager) PriceOptimizerService {
 	return &priceOptimizerService{
-		config:    cfg,
-		optimizer: optimizer,
+		config:      cfg,
+		credManager: credManager,
 	}
 }
 
 // FindOptimalConfig 查找最优配置
-func (s *priceOptimizerService) FindOptimalConfig(ctx context.Context, provider, template string, instanceTypes, regions []string) (*OptimalInstanceConfig, error) {
-	if provider != "aliyun" {
-		return nil, fmt.Errorf("当前仅支持阿里云价格优化")
-	}
-
-	if s.optimizer == nil {
-		return nil, fmt.Errorf("价格优化器未初始化")
-	}
-
-	// 如果未指定实例类型，使用默认值
-	if len(instanceTypes) == 0 {
-		instanceTypes = []string{"ecs.t5-lc1m1.small", "ecs.t5-lc1m2.small"}
-	}
-
-	// 比较所有实例类型和区域的价格
-	allPrices, err := s.optimizer.ComparePrices(ctx, instanceTypes, regions)
+// 只在有库存的部署位置中选择价格最低的配置，避免推荐已售罄的实例类型
+func (s *priceOptimizerService) FindOptimalConfig(ctx context.Context, provider, template string, instanceTypes, regions []string, spot bool) (*OptimalInstanceConfig, error) {
+	result, err := s.FindPlacements(ctx, provider, template, instanceTypes, regions, spot)
 	if err != nil {
-		return nil, fmt.Errorf("查询价格失败: %w", err)
+		return nil, err
 	}
 
-	if len(allPrices) == 0 {
-		return nil, fmt.Errorf("未找到任何价格信息")
-	}
-
-	// 选择最便宜的配置
-	cheapest := allPrices[0]
+	// 选择最便宜的可用配置
+	cheapest := result.Placements[0]
 
 	return &OptimalInstanceConfig{
 		InstanceType:  cheapest.InstanceType,
 		Region:        cheapest.Region,
+		Zone:          cheapest.Zone,
+		Spot:          cheapest.Spot,
 		Price:         cheapest.PricePerHour,
 		PricePerMonth: cheapest.PricePerMonth,
 		Currency:      cheapest.Currency,
@@ -78,33 +97,116 @@ func (s *priceOptimizerService) ApplyOptimalConfig(optimal *OptimalInstanceConfi
 	if optimal != nil {
 		vars["region"] = optimal.Region
 		vars["instance_type"] = optimal.InstanceType
+		if optimal.Zone != "" {
+			vars["zone"] = optimal.Zone
+		}
 	}
 
 	return vars
 }
 
 // ListRegionPrices 列出各区域价格
+// 并发查询所有实例类型和区域组合的价格，按人民币换算后的价格升序排序
 func (s *priceOptimizerService) ListRegionPrices(ctx context.Context, provider, template string, instanceTypes, regions []string) ([]InstancePrice, error) {
-	if provider != "aliyun" {
-		return nil, fmt.Errorf("当前仅支持阿里云价格优化")
+	if s.credManager == nil {
+		return nil, fmt.Errorf("价格优化器未初始化")
 	}
 
-	if s.optimizer == nil {
-		return nil, fmt.Errorf("价格优化器未初始化")
+	client, err := newClientFromCredentials(s.credManager, provider)
+	if err != nil {
+		return nil, fmt.Errorf("%w，请先运行: cloudbot credential set %s", err, provider)
 	}
 
 	if len(instanceTypes) == 0 {
-		instanceTypes = []string{"ecs.t5-lc1m1.small", "ecs.t5-lc1m2.small"}
+		instanceTypes = defaultOptimizerInstanceTypes[provider]
+	}
+	if len(instanceTypes) == 0 {
+		return nil, fmt.Errorf("请通过 --instance-types 指定 %s 要比较的实例类型", provider)
 	}
 
-	prices, err := s.optimizer.ComparePrices(ctx, instanceTypes, regions)
-	if err != nil {
-		return nil, fmt.Errorf("查询价格失败: %w", err)
+	if len(regions) == 0 {
+		regions, err = s.defaultRegions(ctx, client)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	prices, errs := s.queryPrices(ctx, client, instanceTypes, regions)
 	if len(prices) == 0 {
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("未找到任何价格信息: %s", strings.Join(errs, "; "))
+		}
 		return nil, fmt.Errorf("未找到任何价格信息")
 	}
 
 	return prices, nil
 }
+
+// defaultRegions 获取云服务商默认比较的区域列表
+func (s *priceOptimizerService) defaultRegions(ctx context.Context, client CloudProviderClient) ([]string, error) {
+	if regions, ok := defaultOptimizerRegions[client.Provider()]; ok {
+		return regions, nil
+	}
+
+	available, err := client.GetAvailableRegions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 可用区域失败: %w", client.Provider(), err)
+	}
+
+	var regions []string
+	for _, r := range available {
+		if r.Available {
+			regions = append(regions, r.ID)
+		}
+	}
+	return regions, nil
+}
+
+// queryPrices 并发查询价格（限制并发数，每次调用单独超时）
+// 返回查询成功的价格（已排序）和失败原因列表
+func (s *priceOptimizerService) queryPrices(ctx context.Context, client CloudProviderClient, instanceTypes, regions []string) ([]InstancePrice, []string) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		prices []InstancePrice
+		errs   []string
+	)
+
+	semaphore := make(chan struct{}, optimizerConcurrency) // 限制并发数
+
+	for _, instanceType := range instanceTypes {
+		for _, region := range regions {
+			wg.Add(1)
+			go func(t, r string) {
+				defer wg.Done()
+				semaphore <- struct{}{}        // 获取信号量
+				defer func() { <-semaphore }() // 释放信号量
+
+				callCtx, cancel := context.WithTimeout(ctx, optimizerCallTimeout)
+				defer cancel()
+
+				price, err := client.GetInstancePrice(callCtx, r, t)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%s/%s: %v", r, t, err))
+					return
+				}
+				if price != nil {
+					prices = append(prices, *price)
+				}
+			}(instanceType, region)
+		}
+	}
+	wg.Wait()
+
+	// 不同云服务商的币种可能不同，统一换算为人民币后排序
+	sort.Slice(prices, func(i, j int) bool {
+		return domain.ConvertToCNY(prices[i].PricePerHour, prices[i].Currency) <
+			domain.ConvertToCNY(prices[j].PricePerHour, prices[j].Currency)
+	})
+	sort.Strings(errs)
+
+	return prices, errs
+}
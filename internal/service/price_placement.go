@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+)
+
+// Placement 经过库存校验的部署位置
+type Placement struct {
+	Provider      string  // 云服务商
+	InstanceType  string  // 实例类型
+	Region        string  // 区域
+	Zone          string  // 可用区
+	Spot          bool    // 是否为抢占式实例
+	PricePerHour  float64 // 每小时价格
+	PricePerMonth float64 // 每月价格
+	Currency      string  // 货币单位
+	Verified      bool    // 是否已校验库存（云服务商不支持库存查询时为 false）
+}
+
+// PlacementResult 部署位置查询结果
+type PlacementResult struct {
+	Placements []Placement // 可用的部署位置（按人民币价格从低到高）
+	Skipped    []string    // 被跳过的实例类型/区域及原因
+}
+
+// FindPlacements 查找实际可用的部署位置
+// 先查询价格，再校验可用区库存和配额，只返回可以创建的位置，并记录跳过原因
+func (s *priceOptimizerService) FindPlacements(ctx context.Context, provider, template string, instanceTypes, regions []string, spot bool) (*PlacementResult, error) {
+	if s.credManager == nil {
+		return nil, fmt.Errorf("价格优化器未初始化")
+	}
+
+	client, err := newClientFromCredentials(s.credManager, provider)
+	if err != nil {
+		return nil, fmt.Errorf("%w，请先运行: cloudbot credential set %s", err, provider)
+	}
+
+	if len(instanceTypes) == 0 {
+		instanceTypes = defaultOptimizerInstanceTypes[provider]
+	}
+	if len(instanceTypes) == 0 {
+		return nil, fmt.Errorf("请通过 --instance-types 指定 %s 要比较的实例类型", provider)
+	}
+
+	if len(regions) == 0 {
+		regions, err = s.defaultRegions(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &PlacementResult{}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	semaphore := make(chan struct{}, optimizerConcurrency) // 限制并发数
+
+	for _, instanceType := range instanceTypes {
+		for _, region := range regions {
+			wg.Add(1)
+			go func(t, r string) {
+				defer wg.Done()
+				semaphore <- struct{}{}        // 获取信号量
+				defer func() { <-semaphore }() // 释放信号量
+
+				placement, reason := s.checkPlacement(ctx, client, r, t, spot)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if placement == nil {
+					result.Skipped = append(result.Skipped, fmt.Sprintf("%s/%s: %s", r, t, reason))
+					return
+				}
+				result.Placements = append(result.Placements, *placement)
+			}(instanceType, region)
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(result.Placements, func(i, j int) bool {
+		pi, pj := result.Placements[i], result.Placements[j]
+		ci := domain.ConvertToCNY(pi.PricePerHour, pi.Currency)
+		cj := domain.ConvertToCNY(pj.PricePerHour, pj.Currency)
+		if ci != cj {
+			return ci < cj
+		}
+		// 同价格时优先推荐已校验库存的位置
+		return pi.Verified && !pj.Verified
+	})
+	sort.Strings(result.Skipped)
+
+	if len(result.Placements) == 0 {
+		return result, fmt.Errorf("未找到可用的部署位置（跳过 %d 个组合）", len(result.Skipped))
+	}
+
+	return result, nil
+}
+
+// checkPlacement 查询单个实例类型/区域组合的价格和库存
+// 不可用时返回 nil 和跳过原因
+func (s *priceOptimizerService) checkPlacement(ctx context.Context, client CloudProviderClient, region, instanceType string, spot bool) (*Placement, string) {
+	priceCtx, cancel := context.WithTimeout(ctx, optimizerCallTimeout)
+	defer cancel()
+
+	var price *InstancePrice
+	var err error
+	if spot {
+		price, err = client.GetSpotPrice(priceCtx, region, instanceType)
+	} else {
+		price, err = client.GetInstancePrice(priceCtx, region, instanceType)
+	}
+	if err != nil {
+		return nil, fmt.Sprintf("价格查询失败: %v", err)
+	}
+
+	placement := &Placement{
+		Provider:      client.Provider(),
+		InstanceType:  instanceType,
+		Region:        region,
+		Zone:          price.Zone,
+		Spot:          spot,
+		PricePerHour:  price.PricePerHour,
+		PricePerMonth: price.PricePerMonth,
+		Currency:      price.Currency,
+	}
+
+	checker, ok := client.(InstanceAvailabilityChecker)
+	if !ok {
+		// 云服务商不支持库存查询，保留价格结果但标记为未校验
+		return placement, ""
+	}
+
+	checkCtx, cancelCheck := context.WithTimeout(ctx, optimizerCallTimeout)
+	defer cancelCheck()
+
+	zones, err := checker.CheckAvailability(checkCtx, region, instanceType, spot)
+	if err != nil {
+		return nil, fmt.Sprintf("库存查询失败: %v", err)
+	}
+
+	var available []string
+	var reasons []string
+	for _, z := range zones {
+		if z.Available {
+			available = append(available, z.Zone)
+		} else if z.Reason != "" {
+			reasons = append(reasons, fmt.Sprintf("%s %s", z.Zone, z.Reason))
+		}
+	}
+
+	if len(available) == 0 {
+		if len(reasons) == 0 {
+			return nil, "区域内没有可售的可用区"
+		}
+		return nil, fmt.Sprintf("区域内没有可售的可用区（%s）", strings.Join(reasons, "; "))
+	}
+
+	// 价格对应的可用区有库存时沿用，否则选择第一个有库存的可用区
+	// （按量付费价格在区域内一致；抢占式实例价格取区域最低价作为参考）
+	placement.Verified = true
+	if !containsString(available, placement.Zone) {
+		placement.Zone = available[0]
+	}
+
+	return placement, ""
+}
+
+// containsString 检查字符串切片是否包含指定值
+func containsString(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"fmt"
+	"io"
 
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/credentials"
 	"github.com/lucksec/cloudbot/internal/domain"
 	"github.com/lucksec/cloudbot/internal/repository"
 )
@@ -24,17 +27,32 @@ type PriceService interface {
 
 	// GetPriceRecommendation 获取价格推荐（根据模板类型推荐最优方案）
 	GetPriceRecommendation(ctx context.Context, templateType string) (string, error)
+
+	// CompareBySpec 按规格需求跨云服务商比价
+	// 查询所有已配置凭据的云服务商中满足规格的实例类型，统一换算为人民币后排序
+	CompareBySpec(ctx context.Context, req SpecRequirement) (*SpecComparison, error)
+
+	// ExportPrices 将价格表以 json 或 csv 格式导出
+	ExportPrices(ctx context.Context, w io.Writer, format string) error
+
+	// ImportPrices 导入 json 或 csv 格式的价格表，与已有数据按更新时间合并
+	ImportPrices(ctx context.Context, r io.Reader, format string) (*PriceTableResult, error)
+
+	// RefreshPrices 按配置的矩阵从云服务商实时价格接口刷新价格表
+	RefreshPrices(ctx context.Context, targets []config.PriceRefreshTarget) (*PriceTableResult, error)
 }
 
 // priceService 价格服务实现
 type priceService struct {
-	priceRepo repository.PriceRepository
+	priceRepo   repository.PriceRepository
+	credManager credentials.CredentialManager
 }
 
 // NewPriceService 创建价格服务实例
-func NewPriceService(priceRepo repository.PriceRepository) PriceService {
+func NewPriceService(priceRepo repository.PriceRepository, credManager credentials.CredentialManager) PriceService {
 	return &priceService{
-		priceRepo: priceRepo,
+		priceRepo:   priceRepo,
+		credManager: credManager,
 	}
 }
 
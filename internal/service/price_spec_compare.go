@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// SpecRequirement 规格需求（用于跨云服务商比价）
+type SpecRequirement struct {
+	VCPU      int      // 最少 vCPU 核数
+	MemoryGB  float64  // 最少内存大小(GB)
+	Spot      bool     // 是否按抢占式实例价格比较
+	Regions   []string // 区域过滤，支持通配符（如 cn-*, ap-*），为空表示全部区域
+	Providers []string // 云服务商过滤，为空表示所有已配置凭据的云服务商
+
+	// MaxCandidates 每个区域最多比较的实例类型数量（按规格从小到大选取），0 表示默认值
+	MaxCandidates int
+}
+
+// SpecPriceOption 按规格比价的单个方案
+type SpecPriceOption struct {
+	Provider         string  // 云服务商
+	Region           string  // 区域
+	Zone             string  // 可用区
+	InstanceType     string  // 实例类型
+	CPU              int     // vCPU 核数
+	Memory           float64 // 内存大小(GB)
+	Spot             bool    // 是否为抢占式实例价格
+	PricePerHour     float64 // 原币种每小时价格
+	PricePerMonth    float64 // 原币种每月价格
+	Currency         string  // 原币种
+	PricePerHourCNY  float64 // 换算为人民币的每小时价格
+	PricePerMonthCNY float64 // 换算为人民币的每月价格
+}
+
+// SpecComparison 按规格比价结果
+type SpecComparison struct {
+	Requirement SpecRequirement   // 规格需求
+	Options     []SpecPriceOption // 可选方案（按人民币每小时价格升序）
+	Skipped     []string          // 被跳过的云服务商/区域及原因
+}
+
+const (
+	// defaultSpecCandidates 每个区域默认比较的实例类型数量
+	defaultSpecCandidates = 5
+	// specCompareConcurrency 比价时的最大并发请求数
+	specCompareConcurrency = 8
+	// specCompareCallTimeout 单次云服务商 API 调用的超时时间
+	specCompareCallTimeout = 15 * time.Second
+)
+
+// CompareBySpec 按规格需求跨云服务商比价
+func (s *priceService) CompareBySpec(ctx context.Context, req SpecRequirement) (*SpecComparison, error) {
+	log := logger.GetLogger()
+
+	if req.VCPU <= 0 && req.MemoryGB <= 0 {
+		return nil, fmt.Errorf("请至少指定 vCPU 或内存需求")
+	}
+	if s.credManager == nil {
+		return nil, fmt.Errorf("凭据管理器未初始化")
+	}
+	if req.MaxCandidates <= 0 {
+		req.MaxCandidates = defaultSpecCandidates
+	}
+
+	providers := req.Providers
+	if len(providers) == 0 {
+		providers = SupportedClientProviders
+	}
+
+	result := &SpecComparison{Requirement: req}
+
+	// 待查询的 (云服务商, 区域, 实例类型) 组合
+	type priceTask struct {
+		client   CloudProviderClient
+		region   string
+		instance InstanceType
+	}
+	var tasks []priceTask
+
+	for _, provider := range providers {
+		client, err := newClientFromCredentials(s.credManager, provider)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", provider, err))
+			continue
+		}
+		if _, unsupported := client.(spotPriceUnsupported); req.Spot && unsupported {
+			// 不支持抢占式实例价格的云服务商整体跳过，不必逐个实例类型查询
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v，已跳过", provider, ErrSpotPriceUnsupported))
+			continue
+		}
+
+		regions, err := s.matchRegions(ctx, client, req.Regions)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: 获取区域失败: %v", provider, err))
+			continue
+		}
+		if len(regions) == 0 {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: 没有匹配 %v 的区域", provider, req.Regions))
+			continue
+		}
+
+		for _, region := range regions {
+			callCtx, cancel := context.WithTimeout(ctx, specCompareCallTimeout)
+			instanceTypes, err := client.GetAvailableInstanceTypes(callCtx, region)
+			cancel()
+			if err != nil {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s/%s: 获取实例类型失败: %v", provider, region, err))
+				continue
+			}
+
+			for _, it := range selectSpecCandidates(instanceTypes, req) {
+				tasks = append(tasks, priceTask{client: client, region: region, instance: it})
+			}
+		}
+	}
+
+	log.Debug("按规格比价: vcpu=%d, mem=%.1f, spot=%v, 待查询组合数=%d", req.VCPU, req.MemoryGB, req.Spot, len(tasks))
+
+	// 并发查询价格（限制并发数和单次调用超时）
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, specCompareConcurrency)
+
+	for _, task := range tasks {
+		wg.Add(1)
+		go func(t priceTask) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			callCtx, cancel := context.WithTimeout(ctx, specCompareCallTimeout)
+			defer cancel()
+
+			var price *InstancePrice
+			var err error
+			if req.Spot {
+				price, err = t.client.GetSpotPrice(callCtx, t.region, t.instance.ID)
+			} else {
+				price, err = t.client.GetInstancePrice(callCtx, t.region, t.instance.ID)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s/%s/%s: %v", t.client.Provider(), t.region, t.instance.ID, err))
+				return
+			}
+			result.Options = append(result.Options, SpecPriceOption{
+				Provider:         t.client.Provider(),
+				Region:           t.region,
+				Zone:             price.Zone,
+				InstanceType:     t.instance.ID,
+				CPU:              t.instance.CPU,
+				Memory:           t.instance.Memory,
+				Spot:             price.Spot,
+				PricePerHour:     price.PricePerHour,
+				PricePerMonth:    price.PricePerMonth,
+				Currency:         price.Currency,
+				PricePerHourCNY:  domain.ConvertToCNY(price.PricePerHour, price.Currency),
+				PricePerMonthCNY: domain.ConvertToCNY(price.PricePerMonth, price.Currency),
+			})
+		}(task)
+	}
+	wg.Wait()
+
+	sort.Slice(result.Options, func(i, j int) bool {
+		a, b := result.Options[i], result.Options[j]
+		if a.PricePerHourCNY != b.PricePerHourCNY {
+			return a.PricePerHourCNY < b.PricePerHourCNY
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.InstanceType < b.InstanceType
+	})
+	sort.Strings(result.Skipped)
+
+	if len(result.Options) == 0 {
+		return result, fmt.Errorf("未找到满足规格 %dC%.0fG 的可用价格", req.VCPU, req.MemoryGB)
+	}
+
+	return result, nil
+}
+
+// matchRegions 获取云服务商区域列表并按通配符过滤
+func (s *priceService) matchRegions(ctx context.Context, client CloudProviderClient, patterns []string) ([]string, error) {
+	callCtx, cancel := context.WithTimeout(ctx, specCompareCallTimeout)
+	defer cancel()
+
+	regions, err := client.GetAvailableRegions(callCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []string
+	for _, r := range regions {
+		if !r.Available {
+			continue
+		}
+		if MatchRegion(r.ID, patterns) {
+			matched = append(matched, r.ID)
+		}
+	}
+
+	return matched, nil
+}
+
+// MatchRegion 判断区域是否匹配任一通配符模式（如 cn-*），模式为空时匹配所有区域
+func MatchRegion(region string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, region); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// selectSpecCandidates 从实例类型列表中选出满足规格需求的候选类型
+// 按 vCPU、内存从小到大排序，只保留最接近需求的 MaxCandidates 个
+func selectSpecCandidates(instanceTypes []InstanceType, req SpecRequirement) []InstanceType {
+	var candidates []InstanceType
+	for _, it := range instanceTypes {
+		if !it.Available {
+			continue
+		}
+		if it.CPU < req.VCPU || it.Memory < req.MemoryGB {
+			continue
+		}
+		candidates = append(candidates, it)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].CPU != candidates[j].CPU {
+			return candidates[i].CPU < candidates[j].CPU
+		}
+		if candidates[i].Memory != candidates[j].Memory {
+			return candidates[i].Memory < candidates[j].Memory
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	if len(candidates) > req.MaxCandidates {
+		candidates = candidates[:req.MaxCandidates]
+	}
+	return candidates
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/repository"
+)
+
+// 价格表导入导出格式
+const (
+	PriceFormatJSON = "json"
+	PriceFormatCSV  = "csv"
+)
+
+// priceCSVHeader CSV 价格表表头（与 domain.PriceInfo 的 JSON 字段一致）
+var priceCSVHeader = []string{
+	"provider", "template", "region", "price_per_hour", "price_per_month", "currency", "spec", "updated_at",
+}
+
+// PriceTableResult 价格表导入/刷新结果
+type PriceTableResult struct {
+	repository.MergeResult
+	Skipped []string // 刷新时被跳过的组合及原因
+}
+
+// ExportPrices 导出价格表
+func (s *priceService) ExportPrices(ctx context.Context, w io.Writer, format string) error {
+	prices, err := s.priceRepo.ListPrices()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case PriceFormatJSON:
+		data, err := json.MarshalIndent(prices, "", "  ")
+		if err != nil {
+			return fmt.Errorf("序列化价格数据失败: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+
+	case PriceFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(priceCSVHeader); err != nil {
+			return err
+		}
+		for _, p := range prices {
+			record := []string{
+				p.Provider, p.Template, p.Region,
+				strconv.FormatFloat(p.PricePerHour, 'f', -1, 64),
+				strconv.FormatFloat(p.PricePerMonth, 'f', -1, 64),
+				p.Currency, p.Spec, p.UpdatedAt,
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	return fmt.Errorf("不支持的格式: %s（支持 json, csv）", format)
+}
+
+// ImportPrices 导入价格表
+// 先校验全部记录，任意一条无效时不做任何修改
+func (s *priceService) ImportPrices(ctx context.Context, r io.Reader, format string) (*PriceTableResult, error) {
+	var prices []*domain.PriceInfo
+	var err error
+
+	switch format {
+	case PriceFormatJSON:
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&prices); err != nil {
+			return nil, fmt.Errorf("解析 JSON 价格表失败: %w", err)
+		}
+	case PriceFormatCSV:
+		prices, err = parsePriceCSV(r)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s（支持 json, csv）", format)
+	}
+
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("价格表中没有记录")
+	}
+
+	for i, p := range prices {
+		if p == nil {
+			return nil, fmt.Errorf("第 %d 条记录为空", i+1)
+		}
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("第 %d 条记录 (%s) 无效: %w", i+1, p.Key(), err)
+		}
+	}
+
+	merged, err := s.priceRepo.MergePrices(prices)
+	if err != nil {
+		return nil, err
+	}
+	return &PriceTableResult{MergeResult: *merged}, nil
+}
+
+// parsePriceCSV 解析 CSV 价格表（第一行必须为表头）
+func parsePriceCSV(r io.Reader) ([]*domain.PriceInfo, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("解析 CSV 价格表失败: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV 价格表为空")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range priceCSVHeader {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV 价格表缺少列: %s", name)
+		}
+	}
+
+	var prices []*domain.PriceInfo
+	for line, record := range records[1:] {
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[name]])
+		}
+		parseNumber := func(name string) (float64, error) {
+			value := field(name)
+			if value == "" {
+				return 0, nil
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return 0, fmt.Errorf("第 %d 行 %s 不是有效数字: %s", line+2, name, value)
+			}
+			return v, nil
+		}
+
+		perHour, err := parseNumber("price_per_hour")
+		if err != nil {
+			return nil, err
+		}
+		perMonth, err := parseNumber("price_per_month")
+		if err != nil {
+			return nil, err
+		}
+
+		prices = append(prices, &domain.PriceInfo{
+			Provider:      field("provider"),
+			Template:      field("template"),
+			Region:        field("region"),
+			PricePerHour:  perHour,
+			PricePerMonth: perMonth,
+			Currency:      field("currency"),
+			Spec:          field("spec"),
+			UpdatedAt:     field("updated_at"),
+		})
+	}
+
+	return prices, nil
+}
+
+// RefreshPrices 通过云服务商实时价格接口刷新价格表
+// 按配置的 云服务商 × 区域 × 实例类型 矩阵并发查询，查询成功的价格合并到价格表
+func (s *priceService) RefreshPrices(ctx context.Context, targets []config.PriceRefreshTarget) (*PriceTableResult, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("未配置价格刷新矩阵，请在配置文件中添加 [price_refresh.<provider>] 小节")
+	}
+
+	result := &PriceTableResult{}
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		prices []*domain.PriceInfo
+	)
+	semaphore := make(chan struct{}, optimizerConcurrency) // 限制并发数
+	updatedAt := time.Now().Format("2006-01-02 15:04:05")
+
+	for _, target := range targets {
+		client, err := newClientFromCredentials(s.credManager, target.Provider)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", target.Provider, err))
+			continue
+		}
+
+		for _, entry := range target.InstanceTypes {
+			template, instanceType, ok := strings.Cut(entry, ":")
+			if !ok {
+				instanceType = template
+			}
+
+			for _, region := range target.Regions {
+				wg.Add(1)
+				go func(client CloudProviderClient, template, instanceType, region string) {
+					defer wg.Done()
+					semaphore <- struct{}{}        // 获取信号量
+					defer func() { <-semaphore }() // 释放信号量
+
+					callCtx, cancel := context.WithTimeout(ctx, optimizerCallTimeout)
+					defer cancel()
+
+					price, err := client.GetInstancePrice(callCtx, region, instanceType)
+
+					mu.Lock()
+					defer mu.Unlock()
+					if err != nil {
+						result.Skipped = append(result.Skipped,
+							fmt.Sprintf("%s/%s/%s: %v", client.Provider(), region, instanceType, err))
+						return
+					}
+					prices = append(prices, &domain.PriceInfo{
+						Provider:      client.Provider(),
+						Template:      template,
+						Region:        region,
+						PricePerHour:  price.PricePerHour,
+						PricePerMonth: price.PricePerMonth,
+						Currency:      price.Currency,
+						Spec:          instanceType,
+						UpdatedAt:     updatedAt,
+					})
+				}(client, template, instanceType, region)
+			}
+		}
+	}
+	wg.Wait()
+	sort.Strings(result.Skipped)
+
+	if len(prices) == 0 {
+		return result, fmt.Errorf("未获取到任何价格信息")
+	}
+
+	merged, err := s.priceRepo.MergePrices(prices)
+	if err != nil {
+		return result, err
+	}
+	result.MergeResult = *merged
+	return result, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -54,6 +55,9 @@ type ProjectService interface {
 	// region: 区域（可选，aliyun-proxy 模板：bj/sh/hhht/wlcb/zjk，不指定则按顺序启动）
 	DeployScenario(ctx context.Context, projectName, scenarioID string, autoApprove bool, nodeCount int, toolName, toolArgs string, region string) error
 
+	// DeployScenarioWithOptions 部署场景（支持出价策略等扩展选项）
+	DeployScenarioWithOptions(ctx context.Context, projectName, scenarioID string, autoApprove bool, opts DeployOptions) error
+
 	// DestroyScenario 销毁场景
 	DestroyScenario(ctx context.Context, projectName, scenarioID string, autoApprove bool) error
 
@@ -68,6 +72,67 @@ type ProjectService interface {
 	// InitProject 初始化项目（预先执行所有场景的 Terraform 初始化）
 	// 用于提前完成 backend 初始化和 provider 插件下载，避免首次部署时等待较久
 	InitProject(ctx context.Context, name string) error
+
+	// SetProjectProfile 为项目绑定凭据 profile（记录在 project.ini）
+	// profile 为空表示解除绑定，使用当前默认 profile
+	SetProjectProfile(ctx context.Context, name, profile string) error
+
+	// SetProjectTerraformVersion 设置项目要求的 Terraform/OpenTofu 版本（记录在 project.ini）
+	// constraint 为空表示不限版本，使用默认的可执行文件
+	SetProjectTerraformVersion(ctx context.Context, name, constraint string) error
+
+	// SetProjectVars 设置或删除项目级 Terraform 变量（保存在项目目录的 cloudbot.auto.tfvars.json）
+	SetProjectVars(ctx context.Context, projectName string, values map[string]interface{}, unset []string) error
+
+	// SetScenarioVars 设置或删除场景级 Terraform 变量（保存在场景目录的 cloudbot.auto.tfvars.json）
+	// 变量必须在场景模板中声明，并符合声明的类型
+	SetScenarioVars(ctx context.Context, projectName, scenarioID string, values map[string]interface{}, unset []string) error
+
+	// GetScenarioVars 获取场景模板声明的变量及其生效值和来源
+	GetScenarioVars(ctx context.Context, projectName, scenarioID string) ([]EffectiveVar, error)
+
+	// SetProjectBackend 设置项目的 Terraform 远程状态后端（记录在 project.ini），backend 为空表示使用本地状态
+	SetProjectBackend(ctx context.Context, name string, backend *domain.BackendConfig) error
+
+	// MigrateProjectState 将项目下所有场景的已有状态迁移到当前配置的后端
+	MigrateProjectState(ctx context.Context, name string) error
+
+	// PlanScenario 生成场景的部署计划并保存，之后可以通过 ApplyScenarioPlan 应用
+	PlanScenario(ctx context.Context, projectName, scenarioID string, opts DeployOptions) (*domain.ScenarioPlan, error)
+
+	// ApplyScenarioPlan 应用保存的计划，计划过期时返回 ErrPlanStale
+	ApplyScenarioPlan(ctx context.Context, projectName, scenarioID, planID string) error
+
+	// ListScenarioPlans 列出场景保存的计划
+	ListScenarioPlans(ctx context.Context, projectName, scenarioID string) ([]*domain.ScenarioPlan, error)
+
+	// DetectScenarioDrift 检测已部署场景的状态与云端资源的差异（plan -refresh-only），结果写入场景元数据
+	DetectScenarioDrift(ctx context.Context, projectName, scenarioID string) (*domain.ScenarioDrift, error)
+
+	// ListScenarioRuns 列出场景的 Terraform 运行记录（按开始时间排序）
+	ListScenarioRuns(ctx context.Context, projectName, scenarioID string) ([]*domain.ScenarioRun, error)
+
+	// GetScenarioRun 获取运行记录及其日志文件路径，runID 为空时返回最近一次运行
+	GetScenarioRun(ctx context.Context, projectName, scenarioID, runID string) (*domain.ScenarioRun, string, error)
+
+	// ListScenarioResources 列出场景 Terraform 状态中的资源地址
+	ListScenarioResources(ctx context.Context, projectName, scenarioID string) ([]string, error)
+
+	// ReplaceScenarioResources 重建场景中的指定资源（plan -replace），targets 为资源地址或节点序号
+	ReplaceScenarioResources(ctx context.Context, projectName, scenarioID string, targets []string, autoApprove bool) error
+
+	// DestroyScenarioResources 只销毁场景中的指定资源（plan -destroy -target）
+	DestroyScenarioResources(ctx context.Context, projectName, scenarioID string, targets []string, autoApprove bool) error
+
+	// TaintScenarioResources 将指定资源标记为需要重建（terraform taint），返回标记的资源地址
+	TaintScenarioResources(ctx context.Context, projectName, scenarioID string, targets []string) ([]string, error)
+
+	// UntaintScenarioResources 取消指定资源的重建标记（terraform untaint），返回处理的资源地址
+	UntaintScenarioResources(ctx context.Context, projectName, scenarioID string, targets []string) ([]string, error)
+
+	// ScaleScenario 调整已部署场景的节点数（模板需声明 node_count 变量），并记录为之后部署使用的节点数
+	// 缩容时优先移除不健康的节点，其次是价格最高的节点；autoApprove 为 false 时确认后再应用
+	ScaleScenario(ctx context.Context, projectName, scenarioID string, count int, autoApprove bool) error
 }
 
 // ScenarioStatus 场景云资源状态
@@ -78,12 +143,24 @@ type ScenarioStatus struct {
 	Instances []ECSInstanceDetail // 实例详细信息
 }
 
+// DeployOptions 部署选项
+type DeployOptions struct {
+	NodeCount   int    // 节点数量（0 表示使用默认值）
+	ToolName    string // 工具名称（task-executor-spot 模板）
+	ToolArgs    string // 工具参数
+	Region      string // 区域（aliyun-proxy 模板）
+	BidStrategy string // 抢占式实例出价策略（p95[:上浮%]、ondemand[:百分比]、fixed:<价格>），为空表示不设置出价上限
+}
+
 // projectService 项目服务实现
 type projectService struct {
 	projectRepo        repository.ProjectRepository
 	templateRepo       repository.TemplateRepository
 	terraformSvc       TerraformService
 	dynamicTemplateSvc DynamicTemplateService // 动态模板服务
+	bidCalculator      SpotBidCalculator      // 抢占式实例出价计算器
+	retryPolicy        RetryPolicy            // Terraform 失败重试策略
+	newClient          clientFactory          // 创建云服务商客户端（查询区域、库存和价格）
 }
 
 // NewProjectService 创建项目服务实例
@@ -93,8 +170,9 @@ func NewProjectService(
 	terraformSvc TerraformService,
 ) ProjectService {
 	// 创建动态模板服务（如果凭据管理器可用）
+	credManager := credentials.GetDefaultManager()
 	var dynamicTemplateSvc DynamicTemplateService
-	if credManager := credentials.GetDefaultManager(); credManager != nil {
+	if credManager != nil {
 		dynamicTemplateSvc = NewDynamicTemplateService(credManager)
 	}
 
@@ -103,6 +181,9 @@ func NewProjectService(
 		templateRepo:       templateRepo,
 		terraformSvc:       terraformSvc,
 		dynamicTemplateSvc: dynamicTemplateSvc,
+		bidCalculator:      NewSpotBidCalculator(credManager),
+		retryPolicy:        DefaultRetryPolicy,
+		newClient:          newClientFromCredentials,
 	}
 }
 
@@ -137,7 +218,7 @@ func (s *projectService) DeleteProject(ctx context.Context, name string) error {
 	scenarios, err := s.projectRepo.ListScenarios(name)
 	if err == nil {
 		for _, scenario := range scenarios {
-			if scenario.Status == "deployed" {
+			if scenario.Status == "deployed" || scenario.Status == scenarioStatusInterrupted {
 				return fmt.Errorf("项目 %s 包含已部署的场景 %s，请先销毁场景", name, scenario.ID)
 			}
 		}
@@ -146,6 +227,69 @@ func (s *projectService) DeleteProject(ctx context.Context, name string) error {
 	return s.projectRepo.DeleteProject(name)
 }
 
+// SetProjectProfile 为项目绑定凭据 profile
+func (s *projectService) SetProjectProfile(ctx context.Context, name, profile string) error {
+	project, err := s.projectRepo.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	if profile != "" && !containsString(credentials.ManagerFromContext(ctx).ListProfiles(), profile) {
+		return fmt.Errorf("凭据 profile %s 不存在，请先运行: credential set <provider> --profile %s", profile, profile)
+	}
+
+	project.CredentialProfile = profile
+	return s.projectRepo.UpdateProject(project)
+}
+
+// SetProjectTerraformVersion 设置项目要求的 Terraform/OpenTofu 版本
+func (s *projectService) SetProjectTerraformVersion(ctx context.Context, name, constraint string) error {
+	project, err := s.projectRepo.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	constraint = strings.TrimSpace(constraint)
+	if constraint != "" {
+		if _, err := ParseTerraformRequirement(constraint); err != nil {
+			return err
+		}
+	}
+
+	project.RequiredTerraformVersion = constraint
+	return s.projectRepo.UpdateProject(project)
+}
+
+// withProjectProfile 返回绑定了项目凭据 profile 和 Terraform 版本要求的 context
+// 项目未绑定 profile 时使用当前默认 profile（CLOUDBOT_PROFILE 或配置文件中的 default_profile）
+func (s *projectService) withProjectProfile(ctx context.Context, projectName string) (context.Context, error) {
+	project, err := s.projectRepo.GetProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+	if project.RequiredTerraformVersion != "" {
+		req, err := ParseTerraformRequirement(project.RequiredTerraformVersion)
+		if err != nil {
+			return nil, fmt.Errorf("项目 %s 的 required_terraform_version 无效: %w", projectName, err)
+		}
+		ctx = ContextWithRequiredTerraform(ctx, req)
+	}
+	ctx = contextWithBackendCredentials(ctx, project.Backend)
+	if project.CredentialProfile == "" {
+		return ctx, nil
+	}
+
+	credManager := credentials.ManagerFromContext(ctx)
+	if !containsString(credManager.ListProfiles(), project.CredentialProfile) {
+		if store, ok := credManager.(credentials.SecureStore); ok && store.IsLocked() {
+			return nil, credentials.ErrStoreLocked
+		}
+		return nil, fmt.Errorf("项目 %s 绑定的凭据 profile %s 不存在", projectName, project.CredentialProfile)
+	}
+	logger.GetLogger().Info("使用项目绑定的凭据 profile: project=%s, profile=%s", projectName, project.CredentialProfile)
+	return credentials.ContextWithProfile(ctx, project.CredentialProfile), nil
+}
+
 // CreateScenario 从模板创建场景
 // 支持静态模板和动态模板生成
 func (s *projectService) CreateScenario(ctx context.Context, projectName, provider, templateName string, region string) (*domain.Scenario, error) {
@@ -286,6 +430,10 @@ func (s *projectService) DeleteScenario(ctx context.Context, projectName, scenar
 	if scenario.Status == "deployed" {
 		return fmt.Errorf("场景 %s 已部署，请先销毁场景", scenarioID)
 	}
+	// 被中断的场景可能已创建部分资源
+	if scenario.Status == scenarioStatusInterrupted {
+		return fmt.Errorf("场景 %s 的操作被中断，可能存在已创建的资源，请先销毁场景", scenarioID)
+	}
 
 	return s.projectRepo.DeleteScenario(projectName, scenarioID)
 }
@@ -296,10 +444,36 @@ func (s *projectService) DeleteScenario(ctx context.Context, projectName, scenar
 // toolName 和 toolArgs 用于 task-executor-spot 模板，指定从 OSS 获取的执行程序和参数
 // region 用于 aliyun-proxy 模板，指定区域（bj/sh/hhht/wlcb/zjk），如果为空则按顺序启动
 func (s *projectService) DeployScenario(ctx context.Context, projectName, scenarioID string, autoApprove bool, nodeCount int, toolName, toolArgs string, region string) error {
+	return s.DeployScenarioWithOptions(ctx, projectName, scenarioID, autoApprove, DeployOptions{
+		NodeCount: nodeCount,
+		ToolName:  toolName,
+		ToolArgs:  toolArgs,
+		Region:    region,
+	})
+}
+
+// DeployScenarioWithOptions 部署场景（支持出价策略等扩展选项）
+// 指定 BidStrategy 时，根据抢占式实例历史价格或按量付费价格计算出价上限，
+// 通过 spot_price_limit 变量传给 Terraform，并记录到场景元数据
+func (s *projectService) DeployScenarioWithOptions(ctx context.Context, projectName, scenarioID string, autoApprove bool, opts DeployOptions) error {
+	return s.runResumable(ctx, projectName, scenarioID, "部署", func() error {
+		return s.deployScenario(ctx, projectName, scenarioID, autoApprove, opts)
+	})
+}
+
+// deployScenario 部署场景
+func (s *projectService) deployScenario(ctx context.Context, projectName, scenarioID string, autoApprove bool, opts DeployOptions) error {
+	nodeCount, toolName, toolArgs, region := opts.NodeCount, opts.ToolName, opts.ToolArgs, opts.Region
 	log := logger.GetLogger()
 	log.Info("开始部署场景: project=%s, scenario=%s, nodeCount=%d, toolName=%s, region=%s",
 		projectName, scenarioID, nodeCount, toolName, region)
 
+	// 使用项目绑定的凭据 profile
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return err
+	}
+
 	// 获取场景信息
 	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
 	if err != nil {
@@ -313,17 +487,93 @@ func (s *projectService) DeployScenario(ctx context.Context, projectName, scenar
 	if strings.Contains(scenario.Template, "aliyun/aliyun-proxy/zone-node/ss-libev-node-") {
 		// 从模板路径中已经确定了区域，直接部署
 		log.Info("场景已指定区域（模板路径: %s），使用模板中的区域进行部署", scenario.Template)
-		return s.deployAliyunProxyScenario(ctx, projectName, scenarioID, autoApprove, nodeCount, toolName, toolArgs, scenario)
+		return s.deployAliyunProxyScenario(ctx, projectName, scenarioID, autoApprove, opts, scenario)
 	}
 
 	// 如果模板是 aliyun/aliyun-proxy（没有指定具体区域），则使用旧的逻辑（按顺序启动所有区域）
 	// 这种情况不应该存在，因为创建时必须指定区域
 	if strings.Contains(scenario.Template, "aliyun/aliyun-proxy") && !strings.Contains(scenario.Template, "zone-node") {
 		log.Warn("场景模板未指定区域，使用旧的逻辑按顺序启动所有区域")
-		return s.deployAliyunProxyWithRegion(ctx, projectName, scenarioID, autoApprove, nodeCount, toolName, toolArgs, scenario, region)
+		return s.deployAliyunProxyWithRegion(ctx, projectName, scenarioID, autoApprove, opts, scenario)
 	}
 
-	// 构建可选的 Terraform 变量
+	vars, err := s.deployVars(ctx, projectName, scenario, opts)
+	if err != nil {
+		return err
+	}
+
+	// 对于腾讯云抢占式实例，如果节点数 > 1，直接使用跨区域分散部署
+	// 这样可以避免单区域配额不足导致的部分成功问题
+	if strings.Contains(scenario.Template, "tencent/") {
+		if actualNodeCount, ok := tencentSpotMultiNode(scenario, vars, nodeCount); ok {
+			log.Info("腾讯云抢占式实例多节点部署，使用跨区域分散部署策略: nodeCount=%d", actualNodeCount)
+			// 获取国内区域列表
+			domesticRegions := GetDomesticRegions()
+			// 如果用户指定了区域，将其放在第一位；否则使用所有国内区域
+			regions := domesticRegions
+			if vars["region"] != "" {
+				// 将用户指定的区域放在第一位
+				regions = []string{vars["region"]}
+				for _, r := range domesticRegions {
+					if r != vars["region"] {
+						regions = append(regions, r)
+					}
+				}
+			}
+			return s.deployAcrossMultipleRegions(ctx, projectName, scenarioID, autoApprove, actualNodeCount, toolName, toolArgs, scenario, vars, regions)
+		}
+	}
+
+	// 初始化 Terraform
+	if err := s.initScenario(ctx, projectName, scenario); err != nil {
+		return fmt.Errorf("初始化 Terraform 失败: %w", err)
+	}
+
+	// 验证配置
+	if err := s.terraformSvc.Validate(ctx, scenario.Path); err != nil {
+		return fmt.Errorf("验证 Terraform 配置失败: %w", err)
+	}
+
+	// 执行 plan 和 apply
+	if err := s.planAndApply(ctx, scenario, vars, autoApprove); err != nil {
+		if errors.Is(err, ErrApplyCanceled) {
+			return err
+		}
+		// 配额或库存不足时更换可用区或区域重试
+		if s.retryPolicy.Action(err) == RetryAlternateLocation {
+			return s.retryDeployWithDifferentRegions(ctx, projectName, scenarioID, autoApprove, nodeCount, toolName, toolArgs, scenario, vars, err)
+		}
+		return err
+	}
+
+	// 更新场景状态
+	scenario.Status = "deployed"
+	scenario.Drift = nil // 部署或销毁后清除之前的漂移检测结果
+	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
+		log.Error("更新场景状态失败: project=%s, scenario=%s, error=%v", projectName, scenarioID, err)
+		return fmt.Errorf("更新场景状态失败: %w", err)
+	}
+
+	log.Info("场景部署成功: project=%s, scenario=%s", projectName, scenarioID)
+	return nil
+}
+
+// setCredentialVars 以模板变量传递长期凭据（向后兼容在 provider 块中引用凭据变量的模板）
+// 临时凭据（AssumeRole、credential_process）只通过环境变量传递：变量在部署开始时构建一次，
+// 凭据刷新后变量中的 AccessKey 会过期，且变量无法携带安全令牌
+func setCredentialVars(vars map[string]string, accessKeyVar, secretKeyVar string, creds *credentials.Credentials) {
+	if creds.SessionToken != "" {
+		return
+	}
+	vars[accessKeyVar] = creds.AccessKey
+	vars[secretKeyVar] = creds.SecretKey
+}
+
+// deployVars 构建部署场景时传给 Terraform 的变量
+// 包括按模板注入的云服务商凭据和区域、用户设置的项目/场景变量、命令行参数和出价上限
+func (s *projectService) deployVars(ctx context.Context, projectName string, scenario *domain.Scenario, opts DeployOptions) (map[string]string, error) {
+	log := logger.GetLogger()
+	nodeCount, toolName, toolArgs, scenarioID := opts.NodeCount, opts.ToolName, opts.ToolArgs, scenario.ID
 	vars := make(map[string]string)
 
 	// 根据模板类型，从凭据管理器获取并传递云服务商凭据
@@ -332,12 +582,11 @@ func (s *projectService) DeployScenario(ctx context.Context, projectName, scenar
 
 	// 腾讯云模板：所有模板都需要 tencentcloud_secret_id 和 tencentcloud_secret_key
 	if strings.HasPrefix(templatePath, "tencent/") {
-		credManager := credentials.GetDefaultManager()
+		credManager := credentials.ManagerFromContext(ctx)
 		if credManager.HasCredentials(credentials.ProviderTencent) {
 			creds, err := credManager.GetCredentials(credentials.ProviderTencent)
 			if err == nil && creds != nil {
-				vars["tencentcloud_secret_id"] = creds.AccessKey
-				vars["tencentcloud_secret_key"] = creds.SecretKey
+				setCredentialVars(vars, "tencentcloud_secret_id", "tencentcloud_secret_key", creds)
 
 				// 如果用户指定了区域，使用用户指定的；否则尝试查找有配额的区域
 				if creds.Region != "" {
@@ -385,14 +634,13 @@ func (s *projectService) DeployScenario(ctx context.Context, projectName, scenar
 
 	// 阿里云模板：根据模板类型传递不同的凭据
 	if strings.HasPrefix(templatePath, "aliyun/") {
-		credManager := credentials.GetDefaultManager()
+		credManager := credentials.ManagerFromContext(ctx)
 		if credManager.HasCredentials(credentials.ProviderAliyun) {
 			creds, err := credManager.GetCredentials(credentials.ProviderAliyun)
 			if err == nil && creds != nil {
 				// aliyun-proxy 模板需要 access_key 和 secret_key
 				if strings.Contains(templatePath, "aliyun-proxy") {
-					vars["access_key"] = creds.AccessKey
-					vars["secret_key"] = creds.SecretKey
+					setCredentialVars(vars, "access_key", "secret_key", creds)
 				}
 				// 所有模板都可以传递 region
 				if creds.Region != "" {
@@ -406,12 +654,11 @@ func (s *projectService) DeployScenario(ctx context.Context, projectName, scenar
 
 	// 华为云模板：所有模板都需要 access_key 和 secret_key
 	if strings.HasPrefix(templatePath, "huaweicloud/") {
-		credManager := credentials.GetDefaultManager()
+		credManager := credentials.ManagerFromContext(ctx)
 		if credManager.HasCredentials(credentials.ProviderHuaweicloud) {
 			creds, err := credManager.GetCredentials(credentials.ProviderHuaweicloud)
 			if err == nil && creds != nil {
-				vars["access_key"] = creds.AccessKey
-				vars["secret_key"] = creds.SecretKey
+				setCredentialVars(vars, "access_key", "secret_key", creds)
 				if creds.Region != "" {
 					vars["region"] = creds.Region
 				}
@@ -419,15 +666,25 @@ func (s *projectService) DeployScenario(ctx context.Context, projectName, scenar
 		}
 	}
 
+	// 合并用户设置的项目级和场景级变量（覆盖自动注入的值，但低于命令行参数）
+	if err := s.applyUserVars(projectName, scenario, vars); err != nil {
+		return nil, err
+	}
+	applyResourceTagVars(projectName, scenario, vars)
+
 	if nodeCount > 0 {
 		// 目前仅对支持 node_count 的模板传递该变量，避免其他模板报 "未定义变量" 错误
 		// 例如 aliyun/aliyun-proxy/zone-node/ss-libev-node-bj
 		if strings.Contains(scenario.Template, "aliyun/aliyun-proxy") ||
 			strings.Contains(scenario.Template, "huaweicloud/huaweicloud-proxy") ||
 			strings.Contains(scenario.Template, "tencent/tencent-proxy") ||
-			strings.Contains(scenario.Template, "tencent/tencent-proxy-postpaid") {
+			strings.Contains(scenario.Template, "tencent/tencent-proxy-postpaid") ||
+			templateDeclaresVariable(scenario.Path, nodeCountVar) {
 			vars["node_count"] = strconv.Itoa(nodeCount)
+			scenario.NodeCount = nodeCount // 部署成功后记录，之后的部署和扩缩容沿用
 		}
+	} else {
+		applyDesiredNodeCount(scenario, vars)
 	}
 
 	// 如果提供了工具名称，设置 task-executor-spot 模板的相关变量
@@ -456,97 +713,18 @@ func (s *projectService) DeployScenario(ctx context.Context, projectName, scenar
 		}
 	}
 
-	// 对于腾讯云抢占式实例，如果节点数 > 1，直接使用跨区域分散部署
-	// 这样可以避免单区域配额不足导致的部分成功问题
-	if strings.Contains(scenario.Template, "tencent/") {
-		// 获取实际节点数
-		actualNodeCount := nodeCount
-		if actualNodeCount <= 0 {
-			if nodeCountStr, ok := vars["node_count"]; ok && nodeCountStr != "" {
-				if parsed, err := strconv.Atoi(nodeCountStr); err == nil && parsed > 0 {
-					actualNodeCount = parsed
-				}
-			}
-			if actualNodeCount <= 0 {
-				actualNodeCount = 3 // 默认值
-			}
-		}
-
-		// 如果节点数 > 1，且启用了抢占式实例，直接使用跨区域分散部署
-		// 注意：按量计费版本（tencent-proxy-postpaid）不需要跨区域分散部署，配额通常充足
-		if actualNodeCount > 1 && !strings.Contains(scenario.Template, "tencent-proxy-postpaid") {
-			// 检查是否启用了抢占式实例（通过检查变量或模板）
-			enableSpot := true // 默认启用
-			if spotEnabled, ok := vars["enable_spot"]; ok {
-				enableSpot = spotEnabled != "false"
-			}
-
-			if enableSpot {
-				log.Info("腾讯云抢占式实例多节点部署，使用跨区域分散部署策略: nodeCount=%d", actualNodeCount)
-				// 获取国内区域列表
-				domesticRegions := GetDomesticRegions()
-				// 如果用户指定了区域，将其放在第一位；否则使用所有国内区域
-				regions := domesticRegions
-				if vars["region"] != "" {
-					// 将用户指定的区域放在第一位
-					regions = []string{vars["region"]}
-					for _, r := range domesticRegions {
-						if r != vars["region"] {
-							regions = append(regions, r)
-						}
-					}
-				}
-				return s.deployAcrossMultipleRegions(ctx, projectName, scenarioID, autoApprove, actualNodeCount, toolName, toolArgs, scenario, vars, regions)
-			}
-		}
+	// 按出价策略计算抢占式实例出价上限
+	if err := s.applyBidStrategy(ctx, scenario, vars, opts.BidStrategy); err != nil {
+		return nil, err
 	}
 
-	// 初始化 Terraform
-	if err := s.terraformSvc.Init(ctx, scenario.Path); err != nil {
-		return fmt.Errorf("初始化 Terraform 失败: %w", err)
-	}
-
-	// 验证配置
-	if err := s.terraformSvc.Validate(ctx, scenario.Path); err != nil {
-		return fmt.Errorf("验证 Terraform 配置失败: %w", err)
-	}
-
-	// 执行 plan
-	if err := s.terraformSvc.Plan(ctx, scenario.Path, vars); err != nil {
-		// 如果是腾讯云且是配额错误，尝试其他区域
-		if strings.Contains(scenario.Template, "tencent/") &&
-			(strings.Contains(err.Error(), "LimitExceeded.SpotQuota") ||
-				strings.Contains(err.Error(), "配额不足")) {
-			return s.retryDeployWithDifferentRegions(ctx, projectName, scenarioID, autoApprove, nodeCount, toolName, toolArgs, scenario, vars, err)
-		}
-		return fmt.Errorf("Terraform plan 失败: %w", err)
-	}
-
-	// 执行 apply
-	if err := s.terraformSvc.Apply(ctx, scenario.Path, autoApprove, vars); err != nil {
-		// 如果是腾讯云且是配额错误，尝试其他区域
-		if strings.Contains(scenario.Template, "tencent/") &&
-			(strings.Contains(err.Error(), "LimitExceeded.SpotQuota") ||
-				strings.Contains(err.Error(), "配额不足")) {
-			return s.retryDeployWithDifferentRegions(ctx, projectName, scenarioID, autoApprove, nodeCount, toolName, toolArgs, scenario, vars, err)
-		}
-		return fmt.Errorf("Terraform apply 失败: %w", err)
-	}
-
-	// 更新场景状态
-	scenario.Status = "deployed"
-	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
-		log.Error("更新场景状态失败: project=%s, scenario=%s, error=%v", projectName, scenarioID, err)
-		return fmt.Errorf("更新场景状态失败: %w", err)
-	}
-
-	log.Info("场景部署成功: project=%s, scenario=%s", projectName, scenarioID)
-	return nil
+	return vars, nil
 }
 
 // deployAliyunProxyWithRegion 处理 aliyun-proxy 模板的区域选择逻辑
-func (s *projectService) deployAliyunProxyWithRegion(ctx context.Context, projectName, scenarioID string, autoApprove bool, nodeCount int, toolName, toolArgs string, scenario *domain.Scenario, region string) error {
+func (s *projectService) deployAliyunProxyWithRegion(ctx context.Context, projectName, scenarioID string, autoApprove bool, opts DeployOptions, scenario *domain.Scenario) error {
 	log := logger.GetLogger()
+	region := opts.Region
 
 	// 检查场景模板路径是否已经包含区域信息
 	// 如果模板路径是 aliyun/aliyun-proxy/zone-node/ss-libev-node-xx 格式，说明创建时已经指定了区域
@@ -569,7 +747,7 @@ func (s *projectService) deployAliyunProxyWithRegion(ctx context.Context, projec
 		// 如果从模板路径中提取到了区域，且部署时没有指定区域，直接使用模板中的区域
 		if extractedRegion != "" && region == "" {
 			log.Info("场景已指定区域: %s，使用模板中的区域进行部署", extractedRegion)
-			return s.deployAliyunProxyScenario(ctx, projectName, scenarioID, autoApprove, nodeCount, toolName, toolArgs, scenario)
+			return s.deployAliyunProxyScenario(ctx, projectName, scenarioID, autoApprove, opts, scenario)
 		}
 
 		// 如果部署时指定了区域，但模板中已经有区域，使用部署时指定的区域（覆盖）
@@ -587,13 +765,13 @@ func (s *projectService) deployAliyunProxyWithRegion(ctx context.Context, projec
 				scenario.Template = newTemplatePath
 				// 需要重新复制模板文件（这里简化处理，直接使用新的模板路径部署）
 				log.Info("切换到区域: %s，模板路径: %s", region, newTemplatePath)
-				return s.deployAliyunProxyScenario(ctx, projectName, scenarioID, autoApprove, nodeCount, toolName, toolArgs, scenario)
+				return s.deployAliyunProxyScenario(ctx, projectName, scenarioID, autoApprove, opts, scenario)
 			}
 		}
 
 		// 如果区域匹配，直接部署
 		if extractedRegion != "" && region == extractedRegion {
-			return s.deployAliyunProxyScenario(ctx, projectName, scenarioID, autoApprove, nodeCount, toolName, toolArgs, scenario)
+			return s.deployAliyunProxyScenario(ctx, projectName, scenarioID, autoApprove, opts, scenario)
 		}
 	}
 
@@ -621,7 +799,7 @@ func (s *projectService) deployAliyunProxyWithRegion(ctx context.Context, projec
 		log.Info("使用指定区域: %s，模板路径: %s", region, templatePath)
 
 		// 使用基础部署方法
-		return s.deployAliyunProxyScenario(ctx, projectName, scenarioID, autoApprove, nodeCount, toolName, toolArgs, scenario)
+		return s.deployAliyunProxyScenario(ctx, projectName, scenarioID, autoApprove, opts, scenario)
 	}
 
 	// 未指定区域，按顺序启动所有区域
@@ -670,7 +848,7 @@ func (s *projectService) deployAliyunProxyWithRegion(ctx context.Context, projec
 		}
 
 		// 部署该区域
-		err := s.deployAliyunProxyScenario(ctx, projectName, regionScenarioID, autoApprove, nodeCount, toolName, toolArgs, regionScenario)
+		err := s.deployAliyunProxyScenario(ctx, projectName, regionScenarioID, autoApprove, opts, regionScenario)
 		if err != nil {
 			log.Warn("区域 %s 部署失败: %v", reg, err)
 			lastErr = err
@@ -690,19 +868,18 @@ func (s *projectService) deployAliyunProxyWithRegion(ctx context.Context, projec
 }
 
 // deployAliyunProxyScenario 部署单个 aliyun-proxy 场景
-func (s *projectService) deployAliyunProxyScenario(ctx context.Context, projectName, scenarioID string, autoApprove bool, nodeCount int, toolName, toolArgs string, scenario *domain.Scenario) error {
+func (s *projectService) deployAliyunProxyScenario(ctx context.Context, projectName, scenarioID string, autoApprove bool, opts DeployOptions, scenario *domain.Scenario) error {
 	log := logger.GetLogger()
 
 	// 构建可选的 Terraform 变量
 	vars := make(map[string]string)
 
 	// 获取阿里云凭据
-	credManager := credentials.GetDefaultManager()
+	credManager := credentials.ManagerFromContext(ctx)
 	if credManager.HasCredentials(credentials.ProviderAliyun) {
 		creds, err := credManager.GetCredentials(credentials.ProviderAliyun)
 		if err == nil && creds != nil {
-			vars["access_key"] = creds.AccessKey
-			vars["secret_key"] = creds.SecretKey
+			setCredentialVars(vars, "access_key", "secret_key", creds)
 			// 从模板路径中提取区域信息
 			if strings.Contains(scenario.Template, "ss-libev-node-bj") {
 				vars["region"] = "cn-beijing"
@@ -718,13 +895,27 @@ func (s *projectService) deployAliyunProxyScenario(ctx context.Context, projectN
 		}
 	}
 
-	// 设置节点数量
-	if nodeCount > 0 {
-		vars["node_count"] = strconv.Itoa(nodeCount)
+	// 合并用户设置的项目级和场景级变量（覆盖自动注入的值，但低于命令行参数）
+	if err := s.applyUserVars(projectName, scenario, vars); err != nil {
+		return err
+	}
+	applyResourceTagVars(projectName, scenario, vars)
+
+	// 设置节点数量，部署成功后记录，之后的部署和扩缩容沿用
+	if opts.NodeCount > 0 {
+		vars["node_count"] = strconv.Itoa(opts.NodeCount)
+		scenario.NodeCount = opts.NodeCount
+	} else {
+		applyDesiredNodeCount(scenario, vars)
+	}
+
+	// 按出价策略计算抢占式实例出价上限（不指定时清除之前部署记录的出价）
+	if err := s.applyBidStrategy(ctx, scenario, vars, opts.BidStrategy); err != nil {
+		return err
 	}
 
 	// 初始化 Terraform
-	if err := s.terraformSvc.Init(ctx, scenario.Path); err != nil {
+	if err := s.initScenario(ctx, projectName, scenario); err != nil {
 		return fmt.Errorf("初始化 Terraform 失败: %w", err)
 	}
 
@@ -733,18 +924,14 @@ func (s *projectService) deployAliyunProxyScenario(ctx context.Context, projectN
 		return fmt.Errorf("验证 Terraform 配置失败: %w", err)
 	}
 
-	// 执行 plan
-	if err := s.terraformSvc.Plan(ctx, scenario.Path, vars); err != nil {
-		return fmt.Errorf("Terraform plan 失败: %w", err)
-	}
-
-	// 执行 apply
-	if err := s.terraformSvc.Apply(ctx, scenario.Path, autoApprove, vars); err != nil {
-		return fmt.Errorf("Terraform apply 失败: %w", err)
+	// 执行 plan 和 apply
+	if err := s.planAndApply(ctx, scenario, vars, autoApprove); err != nil {
+		return err
 	}
 
 	// 更新场景状态
 	scenario.Status = "deployed"
+	scenario.Drift = nil // 部署或销毁后清除之前的漂移检测结果
 	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
 		log.Error("更新场景状态失败: project=%s, scenario=%s, error=%v", projectName, scenarioID, err)
 		return fmt.Errorf("更新场景状态失败: %w", err)
@@ -754,8 +941,9 @@ func (s *projectService) deployAliyunProxyScenario(ctx context.Context, projectN
 	return nil
 }
 
-// retryDeployWithDifferentRegions 使用不同区域重试部署（用于腾讯云配额不足的情况）
-// 支持跨区域分散部署：当某个区域配额不足时，将节点分散到多个区域
+// retryDeployWithDifferentRegions 配额或库存不足时更换位置重试部署
+// 依次尝试当前区域内其他有库存的可用区（模板声明了可用区变量时）和同一地域的其他区域
+// 腾讯云抢占式实例多节点部署时，将节点分散到多个区域
 func (s *projectService) retryDeployWithDifferentRegions(ctx context.Context, projectName, scenarioID string, autoApprove bool, nodeCount int, toolName, toolArgs string, scenario *domain.Scenario, originalVars map[string]string, originalErr error) error {
 	log := logger.GetLogger()
 
@@ -773,91 +961,104 @@ func (s *projectService) retryDeployWithDifferentRegions(ctx context.Context, pr
 		}
 	}
 
-	log.Warn("检测到配额不足错误，尝试跨区域分散部署: error=%v, nodeCount=%d", originalErr, actualNodeCount)
+	log.Warn("检测到配额或库存不足，尝试更换可用区或区域: error=%v, nodeCount=%d", originalErr, actualNodeCount)
 
-	// 只使用国内区域（排除国外区域）
-	domesticRegions := GetDomesticRegions()
-
-	// 获取当前使用的区域
+	provider := strings.SplitN(scenario.Template, "/", 2)[0]
 	currentRegion := originalVars["region"]
 	if currentRegion == "" {
-		currentRegion = "ap-beijing"
-	}
-
-	// 移除当前区域，从其他区域开始尝试
-	availableRegions := []string{}
-	for _, region := range domesticRegions {
-		if region != currentRegion {
-			availableRegions = append(availableRegions, region)
-		}
+		currentRegion = readTerraformValue(scenario.Path, "region")
 	}
+	availableRegions := s.alternateRegions(ctx, provider, currentRegion)
 
-	// 如果节点数量较多（>1）且有多个可用区域，尝试分散部署
-	// 否则，尝试在单个区域部署所有节点
-	if actualNodeCount > 1 && len(availableRegions) > 1 {
+	// 腾讯云抢占式实例多节点部署时，将节点分散到多个区域，最大化利用各区域的配额
+	if provider == "tencent" && actualNodeCount > 1 && len(availableRegions) > 1 {
 		return s.deployAcrossMultipleRegions(ctx, projectName, scenarioID, autoApprove, actualNodeCount, toolName, toolArgs, scenario, originalVars, availableRegions)
 	}
 
-	// 单区域部署：尝试其他区域
+	// 先尝试当前区域的其他可用区，再尝试其他区域
+	locations := s.alternateZones(ctx, provider, scenario, currentRegion, originalVars)
 	for _, region := range availableRegions {
-		log.Info("尝试使用区域: %s", region)
+		locations = append(locations, deployLocation{Region: region})
+	}
 
-		// 复制变量并更新区域
-		newVars := make(map[string]string)
-		for k, v := range originalVars {
-			newVars[k] = v
+	for _, location := range locations {
+		log.Info("尝试使用%s", location)
+		newVars := location.apply(originalVars)
+		if location.Region != currentRegion {
+			if err := s.refreshSpotPriceLimit(ctx, scenario, newVars); err != nil {
+				log.Warn("重新计算出价上限失败，跳过%s: %v", location, err)
+				continue
+			}
 		}
-		newVars["region"] = region
 
 		// 重新初始化 Terraform
-		if err := s.terraformSvc.Init(ctx, scenario.Path); err != nil {
-			log.Warn("重新初始化失败，跳过区域 %s: %v", region, err)
+		if err := s.initScenario(ctx, projectName, scenario); err != nil {
+			log.Warn("重新初始化失败，跳过%s: %v", location, err)
 			continue
 		}
 
 		// 重新验证
 		if err := s.terraformSvc.Validate(ctx, scenario.Path); err != nil {
-			log.Warn("验证失败，跳过区域 %s: %v", region, err)
+			log.Warn("验证失败，跳过%s: %v", location, err)
 			continue
 		}
 
-		// 重新执行 plan
-		if err := s.terraformSvc.Plan(ctx, scenario.Path, newVars); err != nil {
-			// 如果还是配额错误，继续尝试下一个区域
-			if strings.Contains(err.Error(), "LimitExceeded.SpotQuota") ||
-				strings.Contains(err.Error(), "配额不足") {
-				log.Warn("区域 %s 配额不足，继续尝试其他区域", region)
-				continue
+		// 重新执行 plan 和 apply
+		if err := s.planAndApply(ctx, scenario, newVars, autoApprove); err != nil {
+			if errors.Is(err, ErrApplyCanceled) {
+				return err
 			}
-			log.Warn("Plan 失败，跳过区域 %s: %v", region, err)
-			continue
-		}
-
-		// 重新执行 apply
-		if err := s.terraformSvc.Apply(ctx, scenario.Path, autoApprove, newVars); err != nil {
-			// 如果还是配额错误，继续尝试下一个区域
-			if strings.Contains(err.Error(), "LimitExceeded.SpotQuota") ||
-				strings.Contains(err.Error(), "配额不足") {
-				log.Warn("区域 %s 配额不足，继续尝试其他区域", region)
+			// 如果还是配额或库存不足，继续尝试下一个位置
+			if s.retryPolicy.Action(err) == RetryAlternateLocation {
+				log.Warn("%s配额或库存不足，继续尝试其他位置: %v", location, err)
 				continue
 			}
 			// 其他错误，返回
-			return fmt.Errorf("Terraform apply 失败 (区域: %s): %w", region, err)
+			return fmt.Errorf("部署失败 (%s): %w", location, err)
 		}
 
 		// 成功！更新场景状态
 		scenario.Status = "deployed"
+		scenario.Drift = nil // 部署或销毁后清除之前的漂移检测结果
 		if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
 			log.Error("更新场景状态失败: project=%s, scenario=%s, error=%v", projectName, scenarioID, err)
 			return fmt.Errorf("更新场景状态失败: %w", err)
 		}
 
-		log.Info("场景部署成功 (使用区域: %s): project=%s, scenario=%s", region, projectName, scenarioID)
+		log.Info("场景部署成功 (使用%s): project=%s, scenario=%s", location, projectName, scenarioID)
 		return nil
 	}
 
-	// 所有区域都尝试失败
-	return fmt.Errorf("所有国内区域都配额不足，部署失败。原始错误: %w", originalErr)
+	// 所有位置都尝试失败
+	return fmt.Errorf("所有可用区和区域都配额或库存不足，部署失败。原始错误: %w", originalErr)
+}
+
+// tencentSpotMultiNode 腾讯云抢占式实例多节点部署时返回实际节点数和 true，此类部署将节点分散到多个区域
+// 按量计费版本（tencent-proxy-postpaid）配额通常充足，不需要跨区域分散部署
+func tencentSpotMultiNode(scenario *domain.Scenario, vars map[string]string, nodeCount int) (int, bool) {
+	if !strings.Contains(scenario.Template, "tencent/") || strings.Contains(scenario.Template, "tencent-proxy-postpaid") {
+		return 0, false
+	}
+
+	// 获取实际节点数
+	actualNodeCount := nodeCount
+	if actualNodeCount <= 0 {
+		if nodeCountStr, ok := vars["node_count"]; ok && nodeCountStr != "" {
+			if parsed, err := strconv.Atoi(nodeCountStr); err == nil && parsed > 0 {
+				actualNodeCount = parsed
+			}
+		}
+		if actualNodeCount <= 0 {
+			actualNodeCount = 3 // 默认值
+		}
+	}
+
+	// 检查是否启用了抢占式实例（默认启用）
+	enableSpot := true
+	if spotEnabled, ok := vars["enable_spot"]; ok {
+		enableSpot = spotEnabled != "false"
+	}
+	return actualNodeCount, actualNodeCount > 1 && enableSpot
 }
 
 // deployAcrossMultipleRegions 跨多个区域分散部署节点
@@ -892,9 +1093,14 @@ func (s *projectService) deployAcrossMultipleRegions(ctx context.Context, projec
 		}
 		newVars["region"] = region
 		newVars["node_count"] = strconv.Itoa(nodesToDeploy)
+		if err := s.refreshSpotPriceLimit(ctx, scenario, newVars); err != nil {
+			log.Warn("区域 %s 重新计算出价上限失败，跳过: %v", region, err)
+			regionIndex++
+			continue
+		}
 
 		// 重新初始化 Terraform
-		if err := s.terraformSvc.Init(ctx, scenario.Path); err != nil {
+		if err := s.initScenario(ctx, projectName, scenario); err != nil {
 			log.Warn("区域 %s 初始化失败，跳过: %v", region, err)
 			regionIndex++
 			continue
@@ -907,29 +1113,20 @@ func (s *projectService) deployAcrossMultipleRegions(ctx context.Context, projec
 			continue
 		}
 
-		// 执行 plan
-		if err := s.terraformSvc.Plan(ctx, scenario.Path, newVars); err != nil {
-			if strings.Contains(err.Error(), "LimitExceeded.SpotQuota") ||
-				strings.Contains(err.Error(), "配额不足") {
-				log.Warn("区域 %s 配额不足，尝试下一个区域", region)
-				regionIndex++
-				continue
+		// 执行 plan 和 apply
+		if err := s.planAndApply(ctx, scenario, newVars, autoApprove); err != nil {
+			if errors.Is(err, ErrApplyCanceled) {
+				return err
 			}
-			log.Warn("区域 %s Plan 失败，跳过: %v", region, err)
-			regionIndex++
-			continue
-		}
-
-		// 执行 apply
-		if err := s.terraformSvc.Apply(ctx, scenario.Path, autoApprove, newVars); err != nil {
-			if strings.Contains(err.Error(), "LimitExceeded.SpotQuota") ||
-				strings.Contains(err.Error(), "配额不足") {
-				log.Warn("区域 %s 配额不足，尝试下一个区域", region)
-				regionIndex++
-				continue
+			switch s.retryPolicy.Action(err) {
+			case RetryAlternateLocation:
+				log.Warn("区域 %s 配额或库存不足，尝试下一个区域: %v", region, err)
+			case RetryGiveUp:
+				// 认证失败、余额不足、配置错误等在其他区域同样会失败
+				return fmt.Errorf("区域 %s 部署失败: %w", region, err)
+			default:
+				log.Warn("区域 %s 部署失败，尝试下一个区域: %v", region, err)
 			}
-			// 其他错误，记录但继续尝试其他区域
-			log.Warn("区域 %s Apply 失败，尝试下一个区域: %v", region, err)
 			regionIndex++
 			continue
 		}
@@ -954,6 +1151,7 @@ func (s *projectService) deployAcrossMultipleRegions(ctx context.Context, projec
 
 	// 更新场景状态
 	scenario.Status = "deployed"
+	scenario.Drift = nil // 部署或销毁后清除之前的漂移检测结果
 	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
 		log.Error("更新场景状态失败: project=%s, scenario=%s, error=%v", projectName, scenarioID, err)
 		return fmt.Errorf("更新场景状态失败: %w", err)
@@ -965,9 +1163,22 @@ func (s *projectService) deployAcrossMultipleRegions(ctx context.Context, projec
 
 // DestroyScenario 销毁场景
 func (s *projectService) DestroyScenario(ctx context.Context, projectName, scenarioID string, autoApprove bool) error {
+	return s.runResumable(ctx, projectName, scenarioID, "销毁", func() error {
+		return s.destroyScenario(ctx, projectName, scenarioID, autoApprove)
+	})
+}
+
+// destroyScenario 销毁场景
+func (s *projectService) destroyScenario(ctx context.Context, projectName, scenarioID string, autoApprove bool) error {
 	log := logger.GetLogger()
 	log.Info("开始销毁场景: project=%s, scenario=%s", projectName, scenarioID)
 
+	// 使用项目绑定的凭据 profile
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return err
+	}
+
 	// 获取场景信息
 	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
 	if err != nil {
@@ -976,6 +1187,43 @@ func (s *projectService) DestroyScenario(ctx context.Context, projectName, scena
 	}
 
 	// 构建可选的 Terraform 变量（用于传递云服务商凭据）
+	vars, err := s.stateVars(ctx, projectName, scenario)
+	if err != nil {
+		return err
+	}
+
+	// 使用远程后端时，先初始化以确保连接到共享状态（场景可能由其他成员部署）
+	if project, err := s.projectRepo.GetProject(projectName); err == nil && project.Backend != nil {
+		if err := s.initScenario(ctx, projectName, scenario); err != nil {
+			return fmt.Errorf("初始化 Terraform 失败: %w", err)
+		}
+	}
+
+	// 执行 destroy（限流和网络错误退避重试）
+	err = s.retryPolicy.Do(ctx, "Terraform destroy", func() error {
+		return s.terraformSvc.Destroy(ctx, scenario.Path, autoApprove, vars)
+	})
+	if err != nil {
+		log.Error("Terraform destroy 失败: project=%s, scenario=%s, error=%v", projectName, scenarioID, err)
+		return fmt.Errorf("Terraform destroy 失败: %w", err)
+	}
+
+	// 更新场景状态
+	scenario.Status = "destroyed"
+	scenario.Drift = nil // 部署或销毁后清除之前的漂移检测结果
+	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
+		log.Error("更新场景状态失败: project=%s, scenario=%s, error=%v", projectName, scenarioID, err)
+		return fmt.Errorf("更新场景状态失败: %w", err)
+	}
+
+	log.Info("场景销毁成功: project=%s, scenario=%s", projectName, scenarioID)
+	return nil
+}
+
+// stateVars 构建销毁、刷新等基于已有状态的操作所需的 Terraform 变量
+// 只包括云服务商凭据和用户设置的项目/场景变量，不涉及节点数量、出价等部署参数
+func (s *projectService) stateVars(ctx context.Context, projectName string, scenario *domain.Scenario) (map[string]string, error) {
+	log := logger.GetLogger()
 	vars := make(map[string]string)
 
 	// 根据模板类型，从凭据管理器获取并传递云服务商凭据
@@ -984,40 +1232,38 @@ func (s *projectService) DestroyScenario(ctx context.Context, projectName, scena
 
 	// 腾讯云模板：所有模板都需要 tencentcloud_secret_id 和 tencentcloud_secret_key
 	if strings.HasPrefix(templatePath, "tencent/") {
-		credManager := credentials.GetDefaultManager()
+		credManager := credentials.ManagerFromContext(ctx)
 		if credManager.HasCredentials(credentials.ProviderTencent) {
 			creds, err := credManager.GetCredentials(credentials.ProviderTencent)
 			if err == nil && creds != nil {
-				vars["tencentcloud_secret_id"] = creds.AccessKey
-				vars["tencentcloud_secret_key"] = creds.SecretKey
-				log.Debug("已传递腾讯云凭据到 Terraform destroy")
+				setCredentialVars(vars, "tencentcloud_secret_id", "tencentcloud_secret_key", creds)
+				log.Debug("已传递腾讯云凭据到 Terraform")
 			}
 		}
 	}
 
 	// 阿里云模板：根据模板类型传递不同的凭据
 	if strings.HasPrefix(templatePath, "aliyun/") {
-		credManager := credentials.GetDefaultManager()
+		credManager := credentials.ManagerFromContext(ctx)
 		if credManager.HasCredentials(credentials.ProviderAliyun) {
 			creds, err := credManager.GetCredentials(credentials.ProviderAliyun)
 			if err == nil && creds != nil {
 				// aliyun-proxy 模板需要 access_key 和 secret_key
 				if strings.Contains(templatePath, "aliyun-proxy") {
-					vars["access_key"] = creds.AccessKey
-					vars["secret_key"] = creds.SecretKey
-					log.Debug("已传递阿里云凭据到 Terraform destroy")
+					setCredentialVars(vars, "access_key", "secret_key", creds)
+					log.Debug("已传递阿里云凭据到 Terraform")
 				}
 			}
 		}
-		// task-executor-spot 在销毁时也需要 OSS 凭据（如果有的话）
+		// task-executor-spot 在销毁和刷新时也需要 OSS 凭据（如果有的话）
 		if strings.Contains(templatePath, "task-executor-spot") {
-			credManager := credentials.GetDefaultManager()
+			credManager := credentials.ManagerFromContext(ctx)
 			if credManager.HasCredentials(credentials.ProviderAliyun) {
 				creds, err := credManager.GetCredentials(credentials.ProviderAliyun)
 				if err == nil && creds != nil {
 					vars["oss_access_key_id"] = creds.AccessKey
 					vars["oss_access_key_secret"] = creds.SecretKey
-					log.Debug("已传递 OSS 凭据到 Terraform destroy")
+					log.Debug("已传递 OSS 凭据到 Terraform")
 				}
 			}
 		}
@@ -1025,39 +1271,33 @@ func (s *projectService) DestroyScenario(ctx context.Context, projectName, scena
 
 	// 华为云模板：所有模板都需要 access_key 和 secret_key
 	if strings.HasPrefix(templatePath, "huaweicloud/") {
-		credManager := credentials.GetDefaultManager()
+		credManager := credentials.ManagerFromContext(ctx)
 		if credManager.HasCredentials(credentials.ProviderHuaweicloud) {
 			creds, err := credManager.GetCredentials(credentials.ProviderHuaweicloud)
 			if err == nil && creds != nil {
-				vars["access_key"] = creds.AccessKey
-				vars["secret_key"] = creds.SecretKey
-				log.Debug("已传递华为云凭据到 Terraform destroy")
+				setCredentialVars(vars, "access_key", "secret_key", creds)
+				log.Debug("已传递华为云凭据到 Terraform")
 			}
 		}
 	}
 
-	// 执行 destroy
-	if err := s.terraformSvc.Destroy(ctx, scenario.Path, autoApprove, vars); err != nil {
-		log.Error("Terraform destroy 失败: project=%s, scenario=%s, error=%v", projectName, scenarioID, err)
-		return fmt.Errorf("Terraform destroy 失败: %w", err)
+	// 项目级变量不会被 Terraform 自动加载，同样需要传递
+	if err := s.applyUserVars(projectName, scenario, vars); err != nil {
+		return nil, err
 	}
+	applyResourceTagVars(projectName, scenario, vars)
+	// 节点数与部署时一致，避免刷新或重建单个资源时按模板默认值增删节点
+	applyDesiredNodeCount(scenario, vars)
 
-	// 更新场景状态
-	scenario.Status = "destroyed"
-	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
-		log.Error("更新场景状态失败: project=%s, scenario=%s, error=%v", projectName, scenarioID, err)
-		return fmt.Errorf("更新场景状态失败: %w", err)
-	}
-
-	log.Info("场景销毁成功: project=%s, scenario=%s", projectName, scenarioID)
-	return nil
+	return vars, nil
 }
 
 // GetProjectStatus 获取项目的云资源状态列表（云资源验证）
 // 会遍历项目下的所有场景，调用 Terraform state list 获取云资源列表
 func (s *projectService) GetProjectStatus(ctx context.Context, projectName string) ([]ScenarioStatus, error) {
-	// 确认项目存在
-	if _, err := s.projectRepo.GetProject(projectName); err != nil {
+	// 确认项目存在，并使用项目绑定的凭据 profile
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
 		return nil, fmt.Errorf("项目不存在: %w", err)
 	}
 
@@ -1096,6 +1336,12 @@ func (s *projectService) GetProjectStatus(ctx context.Context, projectName strin
 
 // GetScenarioStatus 获取指定场景的云资源状态（云资源验证）
 func (s *projectService) GetScenarioStatus(ctx context.Context, projectName, scenarioID string) (*ScenarioStatus, error) {
+	// 使用项目绑定的凭据 profile
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+
 	// 获取场景信息
 	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
 	if err != nil {
@@ -1131,6 +1377,10 @@ func (s *projectService) InitProject(ctx context.Context, name string) error {
 	if err != nil {
 		return fmt.Errorf("项目不存在: %w", err)
 	}
+	ctx, err = s.withProjectProfile(ctx, name)
+	if err != nil {
+		return err
+	}
 
 	// 获取项目所有场景
 	scenarios, err := s.projectRepo.ListScenarios(name)
@@ -1151,7 +1401,7 @@ func (s *projectService) InitProject(ctx context.Context, name string) error {
 		fmt.Printf("正在初始化场景 %s (项目: %s, 模板: %s)...\n", sc.ID, project.Name, sc.Template)
 
 		// 在每个场景目录执行 terraform init
-		if err := s.terraformSvc.Init(ctx, sc.Path); err != nil {
+		if err := s.initScenario(ctx, name, sc); err != nil {
 			return fmt.Errorf("初始化场景 %s (项目 %s) 失败: %w", sc.ID, project.Name, err)
 		}
 	}
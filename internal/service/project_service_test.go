@@ -0,0 +1,804 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+	"github.com/lucksec/cloudbot/internal/repository"
+	"github.com/lucksec/cloudbot/internal/repository/repositorytest"
+)
+
+const testProject = "test-project"
+
+// testTemplateTf 测试场景的 Terraform 配置，只需声明部署时传入的变量
+const testTemplateTf = `variable "region" {
+  type = string
+}
+
+variable "node_count" {
+  type    = number
+  default = 1
+}
+`
+
+// TestMain 隔离凭据和日志：使用临时 HOME，只提供 AWS 凭据（需要查询区域的测试使用 regionListClient，不访问网络）
+func TestMain(m *testing.M) {
+	home, err := os.MkdirTemp("", "cloudbot-test-home")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("HOME", home)
+	// 默认凭据管理器在 $HOME/.cloudbot/.redc.ini 不存在时会在当前目录创建配置文件
+	if err := os.MkdirAll(filepath.Join(home, ".cloudbot"), 0700); err == nil {
+		os.WriteFile(filepath.Join(home, ".cloudbot", ".redc.ini"), nil, 0600)
+	}
+	for _, key := range []string{
+		"ALICLOUD_ACCESS_KEY", "ALICLOUD_SECRET_KEY", "TENCENTCLOUD_SECRET_ID", "TENCENTCLOUD_SECRET_KEY",
+		"HUAWEICLOUD_ACCESS_KEY", "HUAWEICLOUD_SECRET_KEY", "VULTR_API_KEY", "CLOUDBOT_PROFILE",
+	} {
+		os.Unsetenv(key)
+	}
+	os.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	os.Setenv("AWS_REGION", "us-east-1")
+	logger.InitLogger(&logger.Config{Level: logger.ERROR, EnableConsole: true})
+
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
+
+// newTestProjectService 创建使用 FakeRunner 的项目服务，重试不等待
+func newTestProjectService(t *testing.T) (*projectService, *FakeRunner, repository.ProjectRepository) {
+	t.Helper()
+	cfg := &config.Config{ProjectDir: t.TempDir()}
+	repo := repository.NewProjectRepository(cfg)
+	if _, err := repo.CreateProject(testProject); err != nil {
+		t.Fatalf("创建项目失败: %v", err)
+	}
+
+	runner := NewFakeRunner()
+	svc := NewProjectService(repo, nil, NewTerraformServiceWithRunner(runner)).(*projectService)
+	svc.retryPolicy = RetryPolicy{MaxAttempts: 3}
+	return svc, runner, repo
+}
+
+// addTestScenario 添加场景并设置场景变量
+func addTestScenario(t *testing.T, repo repository.ProjectRepository, id, template string, vars map[string]interface{}) *domain.Scenario {
+	t.Helper()
+	scenario := &domain.Scenario{ID: id, Name: id, Template: template, Status: "created"}
+	if err := repo.AddScenario(testProject, scenario); err != nil {
+		t.Fatalf("添加场景失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(scenario.Path, "main.tf"), []byte(testTemplateTf), 0644); err != nil {
+		t.Fatalf("写入模板失败: %v", err)
+	}
+	if err := repo.SaveScenarioVars(testProject, id, vars); err != nil {
+		t.Fatalf("保存场景变量失败: %v", err)
+	}
+	return scenario
+}
+
+// assertScenarioStatus 检查元数据中的场景状态
+func assertScenarioStatus(t *testing.T, repo repository.ProjectRepository, id, want string) {
+	t.Helper()
+	scenario, err := repo.GetScenario(testProject, id)
+	if err != nil {
+		t.Fatalf("获取场景失败: %v", err)
+	}
+	if scenario.Status != want {
+		t.Errorf("场景状态 = %q，期望 %q", scenario.Status, want)
+	}
+}
+
+func TestDeployScenario(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+
+	if err := svc.DeployScenario(context.Background(), testProject, "s1", true, 0, "", "", ""); err != nil {
+		t.Fatalf("部署失败: %v", err)
+	}
+
+	assertScenarioStatus(t, repo, "s1", "deployed")
+	state := runner.State(scenario.Path)
+	if state == nil || len(state.Resources) != 2 {
+		t.Fatalf("状态 = %+v，期望包含网络和 1 个实例", state)
+	}
+	if state.Vars["region"] != "us-east-1" {
+		t.Errorf("部署区域 = %q，期望 us-east-1", state.Vars["region"])
+	}
+	for _, command := range []string{"init", "validate", "plan", "apply"} {
+		if n := len(runner.Calls(command)); n != 1 {
+			t.Errorf("%s 执行 %d 次，期望 1 次", command, n)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(scenario.Path, deployPlanFile)); !os.IsNotExist(err) {
+		t.Errorf("应用后应删除计划文件: %v", err)
+	}
+}
+
+func TestDeployScenarioBacksOffOnRateLimit(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+	runner.FailOn(FakeFailure{Command: "plan", Stderr: "RequestLimitExceeded: Request limit exceeded.", Times: 2})
+	runner.FailOn(FakeFailure{Command: "init", Stderr: "Failed to query available provider packages: dial tcp: i/o timeout", Times: 1})
+
+	if err := svc.DeployScenario(context.Background(), testProject, "s1", true, 0, "", "", ""); err != nil {
+		t.Fatalf("部署失败: %v", err)
+	}
+
+	assertScenarioStatus(t, repo, "s1", "deployed")
+	if n := len(runner.Calls("init")); n != 2 {
+		t.Errorf("init 执行 %d 次，期望 2 次（网络错误后重试）", n)
+	}
+	if n := len(runner.Calls("plan")); n != 3 {
+		t.Errorf("plan 执行 %d 次，期望 3 次（限流两次后成功）", n)
+	}
+}
+
+func TestDeployScenarioGivesUp(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   error
+	}{
+		{"认证失败", "AuthFailure: AWS was not able to validate the provided access credentials", ErrAuthFailed},
+		{"余额不足", "InvalidAccount.NotEnoughBalance: Your account does not have enough balance.", ErrInsufficientBalance},
+		{"配置错误", `Unsupported argument: An argument named "foo" is not expected here.`, ErrConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, runner, repo := newTestProjectService(t)
+			addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+			runner.FailOn(FakeFailure{Command: "apply", Stderr: tt.stderr})
+
+			err := svc.DeployScenario(context.Background(), testProject, "s1", true, 0, "", "", "")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("错误 = %v，期望 %v", err, tt.want)
+			}
+			if n := len(runner.Calls("apply")); n != 1 {
+				t.Errorf("apply 执行 %d 次，期望 1 次（不重试）", n)
+			}
+			assertScenarioStatus(t, repo, "s1", "created")
+		})
+	}
+}
+
+func TestDeployScenarioAlternateRegionOnOutOfStock(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	useAWSTestRegions(svc)
+	scenario := addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+	runner.FailOn(FakeFailure{
+		Command: "apply",
+		Vars:    map[string]string{"region": "us-east-1"},
+		Stderr:  "creating EC2 Instance: InsufficientInstanceCapacity: We currently do not have sufficient t3.micro capacity",
+	})
+
+	if err := svc.DeployScenario(context.Background(), testProject, "s1", true, 0, "", "", ""); err != nil {
+		t.Fatalf("部署失败: %v", err)
+	}
+
+	// 只尝试同一地域（us-）的区域
+	assertScenarioStatus(t, repo, "s1", "deployed")
+	if region := runner.State(scenario.Path).Vars["region"]; region != "us-west-2" {
+		t.Errorf("部署区域 = %q，期望 us-west-2", region)
+	}
+}
+
+// regionListClient 只返回固定区域列表的测试云服务商客户端，未实现其他方法
+type regionListClient struct {
+	CloudProviderClient
+	regions []string
+}
+
+func (c regionListClient) GetAvailableRegions(ctx context.Context) ([]Region, error) {
+	regions := make([]Region, 0, len(c.regions))
+	for _, id := range c.regions {
+		regions = append(regions, Region{ID: id, Name: id, DisplayName: id, Available: true})
+	}
+	return regions, nil
+}
+
+// useAWSTestRegions 使项目服务查询 AWS 区域时返回固定的区域列表
+func useAWSTestRegions(svc *projectService) {
+	svc.newClient = func(credentials.CredentialManager, string) (CloudProviderClient, error) {
+		return regionListClient{regions: []string{"us-east-1", "us-west-2", "ap-southeast-1"}}, nil
+	}
+}
+
+// regionBidCalculator 按区域返回固定出价上限的测试出价计算器
+type regionBidCalculator map[string]float64
+
+func (c regionBidCalculator) CalculatePriceLimit(ctx context.Context, provider, region, instanceType string, strategy *BidStrategy) (float64, error) {
+	return c[region], nil
+}
+
+func TestDeployScenarioRecalculatesSpotPriceLimitPerRegion(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	useAWSTestRegions(svc)
+	svc.bidCalculator = regionBidCalculator{"us-east-1": 0.01, "us-west-2": 0.02}
+	scenario := addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1", "instance_type": "t3.micro"})
+	spotTf := `variable "instance_type" {
+  type = string
+}
+
+variable "spot_price_limit" {
+  type    = string
+  default = ""
+}
+`
+	if err := os.WriteFile(filepath.Join(scenario.Path, "spot.tf"), []byte(spotTf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runner.FailOn(FakeFailure{
+		Command: "apply",
+		Vars:    map[string]string{"region": "us-east-1"},
+		Stderr:  "creating EC2 Instance: InsufficientInstanceCapacity: We currently do not have sufficient t3.micro capacity",
+	})
+
+	opts := DeployOptions{BidStrategy: "p95"}
+	if err := svc.DeployScenarioWithOptions(context.Background(), testProject, "s1", true, opts); err != nil {
+		t.Fatalf("部署失败: %v", err)
+	}
+
+	// 更换区域后按新区域的价格重新计算出价上限
+	state := runner.State(scenario.Path)
+	if state.Vars["region"] != "us-west-2" || state.Vars["spot_price_limit"] != "0.0200" {
+		t.Errorf("region = %q, spot_price_limit = %q，期望 us-west-2 和 0.0200", state.Vars["region"], state.Vars["spot_price_limit"])
+	}
+	deployed, err := repo.GetScenario(testProject, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployed.BidStrategy != "p95:10" || deployed.SpotPriceLimit != 0.02 {
+		t.Errorf("出价记录 = %q/%v，期望 p95:10/0.02", deployed.BidStrategy, deployed.SpotPriceLimit)
+	}
+
+	// 不指定出价策略重新部署时清除之前记录的出价
+	if err := svc.DeployScenario(context.Background(), testProject, "s1", true, 0, "", "", ""); err != nil {
+		t.Fatalf("重新部署失败: %v", err)
+	}
+	redeployed, err := repo.GetScenario(testProject, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redeployed.BidStrategy != "" || redeployed.SpotPriceLimit != 0 {
+		t.Errorf("出价记录 = %q/%v，期望已清除", redeployed.BidStrategy, redeployed.SpotPriceLimit)
+	}
+}
+
+func TestRetryDeployWithDifferentRegions(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := addTestScenario(t, repo, "s1", "tencent/tencent-proxy", map[string]interface{}{"region": "ap-shanghai"})
+	quota := "[TencentCloudSDKError] Code=LimitExceeded.SpotQuota, Message=Spot instance quota exceeded"
+	runner.FailOn(FakeFailure{Command: "apply", Vars: map[string]string{"region": "ap-shanghai"}, Stderr: quota})
+	runner.FailOn(FakeFailure{Command: "plan", Vars: map[string]string{"region": "ap-nanjing"}, Stderr: quota})
+
+	if err := svc.DeployScenario(context.Background(), testProject, "s1", true, 1, "", "", ""); err != nil {
+		t.Fatalf("部署失败: %v", err)
+	}
+
+	// 按国内区域顺序跳过上海（apply 配额不足）和南京（plan 配额不足），在广州部署
+	assertScenarioStatus(t, repo, "s1", "deployed")
+	state := runner.State(scenario.Path)
+	if region := state.Vars["region"]; region != "ap-guangzhou" {
+		t.Errorf("部署区域 = %q，期望 ap-guangzhou", region)
+	}
+	if state.Vars["node_count"] != "1" {
+		t.Errorf("node_count = %q，期望 1", state.Vars["node_count"])
+	}
+}
+
+func TestRetryDeployWithDifferentRegionsAllExhausted(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := addTestScenario(t, repo, "s1", "tencent/tencent-proxy", map[string]interface{}{"region": "ap-shanghai"})
+	runner.FailOn(FakeFailure{Command: "plan", Stderr: "ResourcesSoldOut.SpecifiedInstanceType: 指定的实例类型已售罄"})
+
+	vars := map[string]string{"region": "ap-shanghai", "node_count": "1"}
+	originalErr := &TerraformError{Command: "apply", Kind: ErrOutOfStock, Err: &FakeExitError{Code: 1}}
+	err := svc.retryDeployWithDifferentRegions(context.Background(), testProject, "s1", true, 1, "", "", scenario, vars, originalErr)
+	if !errors.Is(err, ErrOutOfStock) {
+		t.Fatalf("错误 = %v，期望库存不足", err)
+	}
+
+	// 当前区域之外的每个国内区域各尝试一次
+	want := len(GetDomesticRegions()) - 1
+	if n := len(runner.Calls("plan")); n != want {
+		t.Errorf("plan 执行 %d 次，期望 %d 次", n, want)
+	}
+	if runner.State(scenario.Path) != nil {
+		t.Error("所有区域都失败时不应产生状态")
+	}
+	assertScenarioStatus(t, repo, "s1", "created")
+}
+
+func TestRetryDeployWithDifferentRegionsStopsOnAuthFailure(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := addTestScenario(t, repo, "s1", "tencent/tencent-proxy", map[string]interface{}{"region": "ap-shanghai"})
+	runner.FailOn(FakeFailure{Command: "plan", Vars: map[string]string{"region": "ap-nanjing"}, Stderr: "AuthFailure.SecretIdNotFound: The SecretId is not found"})
+
+	vars := map[string]string{"region": "ap-shanghai", "node_count": "1"}
+	originalErr := &TerraformError{Command: "apply", Kind: ErrQuotaExceeded, Err: &FakeExitError{Code: 1}}
+	err := svc.retryDeployWithDifferentRegions(context.Background(), testProject, "s1", true, 1, "", "", scenario, vars, originalErr)
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("错误 = %v，期望认证失败", err)
+	}
+	if n := len(runner.Calls("plan")); n != 1 {
+		t.Errorf("plan 执行 %d 次，期望 1 次（认证失败后不再尝试其他区域）", n)
+	}
+}
+
+func TestDestroyScenario(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+	ctx := context.Background()
+
+	if err := svc.DeployScenario(ctx, testProject, "s1", true, 0, "", "", ""); err != nil {
+		t.Fatalf("部署失败: %v", err)
+	}
+	runner.FailOn(FakeFailure{Command: "destroy", Stderr: "read: connection reset by peer", Times: 1})
+
+	if err := svc.DestroyScenario(ctx, testProject, "s1", true); err != nil {
+		t.Fatalf("销毁失败: %v", err)
+	}
+
+	assertScenarioStatus(t, repo, "s1", "destroyed")
+	state := runner.State(scenario.Path)
+	if len(state.Resources) != 0 {
+		t.Errorf("销毁后状态中仍有资源: %v", state.Resources)
+	}
+	if n := len(runner.Calls("destroy")); n != 2 {
+		t.Errorf("destroy 执行 %d 次，期望 2 次（网络错误后重试）", n)
+	}
+}
+
+func TestDestroyScenarioFailure(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+	ctx := context.Background()
+
+	if err := svc.DeployScenario(ctx, testProject, "s1", true, 0, "", "", ""); err != nil {
+		t.Fatalf("部署失败: %v", err)
+	}
+	runner.FailOn(FakeFailure{Command: "destroy", Stderr: "UnauthorizedOperation: You are not authorized to perform this operation."})
+
+	err := svc.DestroyScenario(ctx, testProject, "s1", true)
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("错误 = %v，期望认证失败", err)
+	}
+	assertScenarioStatus(t, repo, "s1", "deployed")
+	if len(runner.State(scenario.Path).Resources) == 0 {
+		t.Error("销毁失败时状态不应被清空")
+	}
+}
+
+func TestDeployScenarioInterrupted(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := svc.DeployScenario(ctx, testProject, "s1", true, 0, "", "", "")
+	if !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("错误 = %v，期望操作已中断", err)
+	}
+	assertScenarioStatus(t, repo, "s1", scenarioStatusInterrupted)
+	if n := len(runner.Calls("apply")); n != 0 {
+		t.Errorf("apply 执行 %d 次，期望 0 次", n)
+	}
+	if err := svc.DeleteScenario(context.Background(), testProject, "s1"); err == nil {
+		t.Error("被中断的场景不应允许直接删除")
+	}
+}
+
+func TestDeployScenarioResumesAfterInterrupt(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+	scenario.Status = scenarioStatusInterrupted
+	scenario.InterruptedRun = "20240101-120000-apply"
+	if err := repo.UpdateScenario(testProject, scenario); err != nil {
+		t.Fatalf("更新场景失败: %v", err)
+	}
+
+	runner.FailOn(FakeFailure{
+		Command: "plan",
+		Stderr: "Error acquiring the state lock\n" +
+			"Lock Info:\n  ID:        3f1c2a9e-lock\n  Operation: OperationTypeApply\n  Who:       " + localLockOwner() + "\n",
+		Times: 1,
+	})
+
+	if err := svc.DeployScenario(context.Background(), testProject, "s1", true, 0, "", "", ""); err != nil {
+		t.Fatalf("继续部署失败: %v", err)
+	}
+	assertScenarioStatus(t, repo, "s1", "deployed")
+	unlocks := runner.Calls("force-unlock")
+	if len(unlocks) != 1 {
+		t.Fatalf("force-unlock 执行 %d 次，期望 1 次", len(unlocks))
+	}
+	if args := unlocks[0].Args; args[len(args)-1] != "3f1c2a9e-lock" {
+		t.Errorf("force-unlock 参数 = %v，期望释放 3f1c2a9e-lock", args)
+	}
+	if got, _ := repo.GetScenario(testProject, "s1"); got.InterruptedRun != "" {
+		t.Errorf("部署成功后应清除中断记录，实际为 %q", got.InterruptedRun)
+	}
+}
+
+func TestDeployScenarioKeepsForeignStateLock(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+	scenario.Status = scenarioStatusInterrupted
+	if err := repo.UpdateScenario(testProject, scenario); err != nil {
+		t.Fatalf("更新场景失败: %v", err)
+	}
+
+	runner.FailOn(FakeFailure{
+		Command: "plan",
+		Stderr:  "Error acquiring the state lock\nLock Info:\n  ID:        other-lock\n  Who:       alice@build-server\n",
+	})
+
+	err := svc.DeployScenario(context.Background(), testProject, "s1", true, 0, "", "", "")
+	if !errors.Is(err, ErrStateLocked) {
+		t.Fatalf("错误 = %v，期望状态已被锁定", err)
+	}
+	if n := len(runner.Calls("force-unlock")); n != 0 {
+		t.Errorf("不应释放其他用户持有的状态锁，force-unlock 执行 %d 次", n)
+	}
+	assertScenarioStatus(t, repo, "s1", scenarioStatusInterrupted)
+}
+
+// deployTestNodes 部署带有 count 个节点的场景，并在模板清单中声明节点资源
+func deployTestNodes(t *testing.T, svc *projectService, repo repository.ProjectRepository, count int) *domain.Scenario {
+	t.Helper()
+	scenario := addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1", "node_count": count})
+	if err := writeTemplateManifest(scenario.Path, &TemplateManifest{NodeResource: "fake_instance.node"}); err != nil {
+		t.Fatalf("写入模板清单失败: %v", err)
+	}
+	if err := svc.DeployScenario(context.Background(), testProject, "s1", true, 0, "", "", ""); err != nil {
+		t.Fatalf("部署失败: %v", err)
+	}
+	return scenario
+}
+
+func TestReplaceScenarioNode(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := deployTestNodes(t, svc, repo, 3)
+
+	if err := svc.ReplaceScenarioResources(context.Background(), testProject, "s1", []string{"1"}, true); err != nil {
+		t.Fatalf("重建节点失败: %v", err)
+	}
+
+	plans := runner.Calls("plan")
+	args := plans[len(plans)-1].Args
+	want := map[string]bool{"-replace=fake_instance.node[1]": false, "-target=fake_instance.node[1]": false}
+	for _, arg := range args {
+		if _, ok := want[arg]; ok {
+			want[arg] = true
+		}
+	}
+	for arg, found := range want {
+		if !found {
+			t.Errorf("plan 参数 %v 缺少 %s", args, arg)
+		}
+	}
+	if n := len(runner.State(scenario.Path).Resources); n != 4 {
+		t.Errorf("重建后状态中有 %d 个资源，期望 4 个", n)
+	}
+	assertScenarioStatus(t, repo, "s1", "deployed")
+}
+
+func TestReplaceScenarioRejectsUnrelatedDelete(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := deployTestNodes(t, svc, repo, 3)
+	// 部署后节点数变量被改小，重建 node[2] 的计划会变成删除
+	if err := repo.SaveScenarioVars(testProject, "s1", map[string]interface{}{"region": "us-east-1", "node_count": 1}); err != nil {
+		t.Fatalf("保存场景变量失败: %v", err)
+	}
+	applies := len(runner.Calls("apply"))
+
+	err := svc.ReplaceScenarioResources(context.Background(), testProject, "s1", []string{"fake_instance.node[2]"}, true)
+	if err == nil {
+		t.Fatal("重建计划删除资源时应拒绝应用")
+	}
+	if n := len(runner.Calls("apply")); n != applies {
+		t.Errorf("不应执行 apply，实际多执行了 %d 次", n-applies)
+	}
+	if n := len(runner.State(scenario.Path).Resources); n != 4 {
+		t.Errorf("状态中有 %d 个资源，期望保持 4 个", n)
+	}
+}
+
+func TestDestroyScenarioResources(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := deployTestNodes(t, svc, repo, 2)
+	ctx := context.Background()
+
+	if err := svc.DestroyScenarioResources(ctx, testProject, "s1", []string{"fake_instance.node"}, true); err != nil {
+		t.Fatalf("销毁节点失败: %v", err)
+	}
+	assertScenarioStatus(t, repo, "s1", "deployed")
+	resources := runner.State(scenario.Path).Resources
+	if len(resources) != 1 || resources[0] != "fake_network.main" {
+		t.Errorf("销毁节点后状态 = %v，期望只剩网络", resources)
+	}
+
+	if err := svc.DestroyScenarioResources(ctx, testProject, "s1", []string{"fake_network.main"}, true); err != nil {
+		t.Fatalf("销毁网络失败: %v", err)
+	}
+	assertScenarioStatus(t, repo, "s1", "destroyed")
+}
+
+func TestTaintScenarioResource(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := deployTestNodes(t, svc, repo, 2)
+	ctx := context.Background()
+
+	if _, err := svc.TaintScenarioResources(ctx, testProject, "s1", []string{"5"}); err == nil {
+		t.Error("不存在的节点序号应返回错误")
+	}
+	addresses, err := svc.TaintScenarioResources(ctx, testProject, "s1", []string{"0"})
+	if err != nil {
+		t.Fatalf("taint 失败: %v", err)
+	}
+	if len(addresses) != 1 || addresses[0] != "fake_instance.node[0]" {
+		t.Fatalf("taint 的资源 = %v，期望 fake_instance.node[0]", addresses)
+	}
+	if tainted := runner.State(scenario.Path).Tainted; len(tainted) != 1 {
+		t.Fatalf("状态中标记的资源 = %v", tainted)
+	}
+
+	// 重新部署时替换已标记的资源
+	if err := svc.DeployScenario(ctx, testProject, "s1", true, 0, "", "", ""); err != nil {
+		t.Fatalf("重新部署失败: %v", err)
+	}
+	if tainted := runner.State(scenario.Path).Tainted; len(tainted) != 0 {
+		t.Errorf("部署后仍有标记的资源: %v", tainted)
+	}
+}
+
+func TestResolveResourceTargets(t *testing.T) {
+	resources := []string{
+		"alicloud_vpc.vpc",
+		"alicloud_instance.instance[0]",
+		"alicloud_instance.instance[1]",
+		"random_password.password",
+	}
+	tests := []struct {
+		name         string
+		targets      []string
+		resources    []string
+		manifest     TemplateManifest
+		instanceOnly bool
+		want         []string
+		wantErr      bool
+	}{
+		{name: "推断节点资源", targets: []string{"1"}, resources: resources, want: []string{"alicloud_instance.instance[1]"}},
+		{name: "清单声明节点资源", targets: []string{"0", "alicloud_instance.instance[0]"}, resources: resources,
+			manifest: TemplateManifest{NodeResource: "alicloud_instance.instance"}, want: []string{"alicloud_instance.instance[0]"}},
+		{name: "节点不存在", targets: []string{"2"}, resources: resources, wantErr: true},
+		{name: "整个资源", targets: []string{"alicloud_instance.instance"}, resources: resources,
+			want: []string{"alicloud_instance.instance"}},
+		{name: "重建需要单个实例", targets: []string{"alicloud_instance.instance"}, resources: resources, instanceOnly: true, wantErr: true},
+		{name: "资源不存在", targets: []string{"alicloud_eip.eip"}, resources: resources, wantErr: true},
+		{name: "未使用 count 的节点", targets: []string{"0"}, resources: []string{"aws_instance.node"}, want: []string{"aws_instance.node"}},
+		{name: "多种云主机资源", targets: []string{"0"}, resources: []string{"aws_instance.a", "aws_instance.b"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveResourceTargets(tt.targets, tt.resources, &tt.manifest, tt.instanceOnly)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("期望返回错误，实际为 %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("解析结果 = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaleScenarioUp(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := deployTestNodes(t, svc, repo, 2)
+	ctx := context.Background()
+
+	if err := svc.ScaleScenario(ctx, testProject, "s1", 4, true); err != nil {
+		t.Fatalf("扩容失败: %v", err)
+	}
+	if n := countFakeInstances(runner.State(scenario.Path).Resources); n != 4 {
+		t.Fatalf("扩容后有 %d 个节点，期望 4 个", n)
+	}
+	updated, err := repo.GetScenario(testProject, "s1")
+	if err != nil {
+		t.Fatalf("获取场景失败: %v", err)
+	}
+	if updated.NodeCount != 4 {
+		t.Errorf("记录的节点数 = %d，期望 4", updated.NodeCount)
+	}
+
+	// 之后的部署沿用扩容后的节点数
+	if err := svc.DeployScenario(ctx, testProject, "s1", true, 0, "", "", ""); err != nil {
+		t.Fatalf("重新部署失败: %v", err)
+	}
+	if n := countFakeInstances(runner.State(scenario.Path).Resources); n != 4 {
+		t.Errorf("重新部署后有 %d 个节点，期望 4 个", n)
+	}
+}
+
+func TestScaleScenarioDownRemovesUnhealthyNode(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := deployTestNodes(t, svc, repo, 3)
+	ctx := context.Background()
+
+	deployed, err := repo.GetScenario(testProject, "s1")
+	if err != nil {
+		t.Fatalf("获取场景失败: %v", err)
+	}
+	deployed.Drift = &domain.ScenarioDrift{Drifted: true, Resources: []domain.DriftResource{
+		{Address: "fake_instance.node[0]", Type: "fake_instance", Kind: domain.DriftStopped},
+	}}
+	if err := repo.UpdateScenario(testProject, deployed); err != nil {
+		t.Fatalf("更新场景失败: %v", err)
+	}
+
+	if err := svc.ScaleScenario(ctx, testProject, "s1", 2, true); err != nil {
+		t.Fatalf("缩容失败: %v", err)
+	}
+
+	// 定向销毁已停止的节点 0，而不是序号最大的节点
+	plans := runner.Calls("plan")
+	var destroyArgs []string
+	for _, call := range plans {
+		if fakeContains(call.Args, "-destroy") {
+			destroyArgs = call.Args
+		}
+	}
+	if !fakeContains(destroyArgs, "-target=fake_instance.node[0]") || fakeContains(destroyArgs, "-target=fake_instance.node[2]") {
+		t.Errorf("销毁计划参数 = %v，期望只销毁 fake_instance.node[0]", destroyArgs)
+	}
+
+	// 剩余节点的序号压缩为 0、1，以 node_count=2 部署时不会再有变化
+	var moves []string
+	for _, call := range runner.Calls("state") {
+		if fakeContains(call.Args, "mv") {
+			moves = append(moves, strings.Join(call.Args[len(call.Args)-2:], " -> "))
+		}
+	}
+	if fmt.Sprint(moves) != "[fake_instance.node[1] -> fake_instance.node[0] fake_instance.node[2] -> fake_instance.node[1]]" {
+		t.Errorf("调整节点序号 = %v", moves)
+	}
+	resources := runner.State(scenario.Path).Resources
+	if fmt.Sprint(resources) != fmt.Sprint([]string{"fake_network.main", "fake_instance.node[0]", "fake_instance.node[1]"}) {
+		t.Errorf("缩容后状态 = %v", resources)
+	}
+
+	updated, err := repo.GetScenario(testProject, "s1")
+	if err != nil {
+		t.Fatalf("获取场景失败: %v", err)
+	}
+	if updated.NodeCount != 2 || updated.Drift != nil {
+		t.Errorf("缩容后节点数 = %d，漂移结果 = %v，期望 2 和空", updated.NodeCount, updated.Drift)
+	}
+}
+
+func TestNodeResourceGroups(t *testing.T) {
+	resources := []string{
+		"alicloud_vpc.vpc",
+		"alicloud_instance.instance[0]",
+		"alicloud_instance.instance[1]",
+		"alicloud_eip.eip[0]",
+		"alicloud_eip.eip[1]",
+		"alicloud_disk.data[0]",
+	}
+	indices, err := nodeIndices("alicloud_instance.instance", resources)
+	if err != nil {
+		t.Fatalf("读取节点序号失败: %v", err)
+	}
+	if fmt.Sprint(indices) != "[0 1]" {
+		t.Fatalf("节点序号 = %v，期望 [0 1]", indices)
+	}
+	groups := nodeResourceGroups("alicloud_instance.instance", resources, indices)
+	if fmt.Sprint(groups) != "[alicloud_instance.instance alicloud_eip.eip]" {
+		t.Errorf("与节点对应的资源 = %v", groups)
+	}
+
+	if _, err := nodeIndices("aws_instance.node", []string{"aws_instance.node"}); err == nil {
+		t.Error("未使用 count 的节点资源应返回错误")
+	}
+}
+
+func TestScaleScenarioDownRejectsReplace(t *testing.T) {
+	svc, runner, repo := newTestProjectService(t)
+	scenario := deployTestNodes(t, svc, repo, 3)
+	ctx := context.Background()
+	if _, err := svc.TaintScenarioResources(ctx, testProject, "s1", []string{"0"}); err != nil {
+		t.Fatalf("标记节点失败: %v", err)
+	}
+
+	// 移除节点 2 后，剩余节点的计划中出现替换已标记的节点 0，应拒绝应用
+	err := svc.ScaleScenario(ctx, testProject, "s1", 2, true)
+	if err == nil || !strings.Contains(err.Error(), "fake_instance.node[0]") {
+		t.Fatalf("错误 = %v，期望拒绝替换 fake_instance.node[0]", err)
+	}
+	if n := countFakeInstances(runner.State(scenario.Path).Resources); n != 2 {
+		t.Errorf("缩容后有 %d 个节点，期望 2 个", n)
+	}
+
+	// 节点已销毁，即使之后的步骤失败也记录新的节点数
+	updated, err := repo.GetScenario(testProject, "s1")
+	if err != nil {
+		t.Fatalf("获取场景失败: %v", err)
+	}
+	if updated.NodeCount != 2 {
+		t.Errorf("记录的节点数 = %d，期望 2", updated.NodeCount)
+	}
+}
+
+func TestDeploySharedProjectRequiresBackend(t *testing.T) {
+	cfg := &config.Config{ProjectDir: t.TempDir()}
+	repo := repository.NewSharedProjectRepository(cfg, repositorytest.NewMemoryObjectStore(), "cloudbot")
+	if _, err := repo.CreateProject(testProject); err != nil {
+		t.Fatalf("创建项目失败: %v", err)
+	}
+	runner := NewFakeRunner()
+	svc := NewProjectService(repo, nil, NewTerraformServiceWithRunner(runner)).(*projectService)
+	addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+
+	// 共享元数据的项目使用本地状态时，其他成员看不到已创建的资源
+	err := svc.DeployScenario(context.Background(), testProject, "s1", true, 0, "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "远程状态后端") {
+		t.Fatalf("错误 = %v，期望要求配置远程状态后端", err)
+	}
+	if n := len(runner.Calls("apply")); n != 0 {
+		t.Errorf("未配置远程状态后端时执行了 %d 次 apply", n)
+	}
+}
+
+func TestPlanScenario(t *testing.T) {
+	svc, _, repo := newTestProjectService(t)
+	addTestScenario(t, repo, "s1", "aws/ec2", map[string]interface{}{"region": "us-east-1"})
+	ctx := context.Background()
+
+	// 同一秒内生成的计划 ID 不能相同，否则后一个计划会覆盖前一个
+	first, err := svc.PlanScenario(ctx, testProject, "s1", DeployOptions{NodeCount: 1})
+	if err != nil {
+		t.Fatalf("生成计划失败: %v", err)
+	}
+	second, err := svc.PlanScenario(ctx, testProject, "s1", DeployOptions{NodeCount: 2})
+	if err != nil {
+		t.Fatalf("生成计划失败: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Errorf("两次生成的计划 ID 相同: %s", first.ID)
+	}
+	plans, err := svc.ListScenarioPlans(ctx, testProject, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plans) != 2 {
+		t.Errorf("计划数量 = %d，期望 2", len(plans))
+	}
+
+	// 腾讯云抢占式实例多节点部署会分散到多个区域，不能保存为单区域计划
+	addTestScenario(t, repo, "s2", "tencent/cvm-spot", map[string]interface{}{"region": "ap-guangzhou"})
+	if _, err := svc.PlanScenario(ctx, testProject, "s2", DeployOptions{NodeCount: 3}); err == nil {
+		t.Error("腾讯云抢占式实例多节点部署不应支持保存计划")
+	}
+	if _, err := svc.PlanScenario(ctx, testProject, "s2", DeployOptions{NodeCount: 1}); err != nil {
+		t.Errorf("腾讯云单节点部署生成计划失败: %v", err)
+	}
+}
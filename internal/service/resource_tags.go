@@ -0,0 +1,77 @@
+package service
+
+import (
+	"github.com/lucksec/cloudbot/internal/domain"
+)
+
+// cloudbot 为模板创建的资源设置的标签键
+// 模板声明了同名变量时，部署时会传入项目名称和场景 ID，模板应将其设置为资源标签
+const (
+	TagProject  = "cloudbot_project"
+	TagScenario = "cloudbot_scenario"
+)
+
+// 可按标签查找的资源类型
+const (
+	ResourceInstance      = "instance"
+	ResourceEIP           = "eip"
+	ResourceSecurityGroup = "security_group"
+	ResourceSubnet        = "subnet"
+	ResourceVPC           = "vpc"
+)
+
+// resourceDeleteOrder 删除孤立资源的顺序：先释放实例和 EIP，再删除安全组、子网，最后删除 VPC
+var resourceDeleteOrder = []string{ResourceInstance, ResourceEIP, ResourceSecurityGroup, ResourceSubnet, ResourceVPC}
+
+// TaggedResource 带有 cloudbot 标签的云资源
+type TaggedResource struct {
+	Provider string // 云服务商
+	Region   string // 区域
+	Kind     string // 资源类型：instance, eip, security_group, subnet, vpc
+	ID       string // 资源 ID（EIP 为 AllocationId）
+	Name     string // 资源名称
+	Project  string // cloudbot_project 标签
+	Scenario string // cloudbot_scenario 标签
+}
+
+// setResourceTags 从标签中读取 cloudbot 项目和场景
+func (r *TaggedResource) setResourceTags(key, value string) {
+	switch key {
+	case TagProject:
+		r.Project = value
+	case TagScenario:
+		r.Scenario = value
+	}
+}
+
+// applyResourceTagVars 为声明了标签变量的模板传入项目名称和场景 ID
+// 在用户变量之后设置，保证标签始终与场景一致
+func applyResourceTagVars(projectName string, scenario *domain.Scenario, vars map[string]string) {
+	if templateDeclaresVariable(scenario.Path, TagProject) {
+		vars[TagProject] = projectName
+	}
+	if templateDeclaresVariable(scenario.Path, TagScenario) {
+		vars[TagScenario] = scenario.ID
+	}
+}
+
+// resourceTagsTf 生成模板的 tags.tf：声明标签变量，资源通过 tags = local.cloudbot_tags 设置标签
+const resourceTagsTf = `variable "cloudbot_project" {
+  type        = string
+  description = "cloudbot 项目名称（部署时自动传入，用于资源标签）"
+  default     = ""
+}
+
+variable "cloudbot_scenario" {
+  type        = string
+  description = "cloudbot 场景 ID（部署时自动传入，用于资源标签）"
+  default     = ""
+}
+
+locals {
+  cloudbot_tags = {
+    cloudbot_project  = var.cloudbot_project
+    cloudbot_scenario = var.cloudbot_scenario
+  }
+}
+`
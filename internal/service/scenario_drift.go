@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// driftPlanFile 漂移检测时生成的 refresh-only 计划文件（相对场景目录，检测后删除）
+const driftPlanFile = "cloudbot-drift.tfplan"
+
+// stoppedInstanceStatus 各云服务商云主机状态属性中表示已停止的取值
+var stoppedInstanceStatus = map[string]bool{
+	"stopped": true, // 阿里云 status、AWS instance_state、Vultr power_status
+	"shutoff": true, // 华为云 status
+}
+
+// DetectScenarioDrift 检测场景的 Terraform 状态与云端实际资源的差异，并将结果写入场景元数据
+// 通过 plan -refresh-only 刷新资源，只读取云端状态，不会修改 Terraform 状态和云资源
+func (s *projectService) DetectScenarioDrift(ctx context.Context, projectName, scenarioID string) (*domain.ScenarioDrift, error) {
+	log := logger.GetLogger()
+
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+	if scenario.Status != "deployed" {
+		return nil, fmt.Errorf("场景 %s 未部署（状态: %s），无需检测漂移", scenarioID, scenario.Status)
+	}
+
+	vars, err := s.stateVars(ctx, projectName, scenario)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.initScenario(ctx, projectName, scenario); err != nil {
+		return nil, fmt.Errorf("初始化 Terraform 失败: %w", err)
+	}
+
+	defer os.Remove(filepath.Join(scenario.Path, driftPlanFile))
+	changed, err := s.terraformSvc.RefreshPlan(ctx, scenario.Path, driftPlanFile, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &domain.ScenarioDrift{CheckedAt: time.Now()}
+	if changed {
+		// 输出值的变化也会导致 refresh-only plan 报告差异，只以资源的变化为准
+		summary, err := s.terraformSvc.ShowPlan(ctx, scenario.Path, driftPlanFile)
+		if err != nil {
+			return nil, err
+		}
+		drift.Resources = classifyDrift(summary.Drift)
+	}
+	drift.Drifted = len(drift.Resources) > 0
+
+	scenario.Drift = drift
+	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
+		return nil, fmt.Errorf("更新场景漂移状态失败: %w", err)
+	}
+
+	log.Info("场景漂移检测完成: project=%s, scenario=%s, drifted=%v, resources=%d", projectName, scenarioID, drift.Drifted, len(drift.Resources))
+	return drift, nil
+}
+
+// classifyDrift 按漂移类型对 refresh-only 计划中的资源变化分类
+func classifyDrift(changes []ResourceChange) []domain.DriftResource {
+	var resources []domain.DriftResource
+	for _, c := range changes {
+		kind := domain.DriftModified
+		switch {
+		case c.Action == ChangeDelete:
+			kind = domain.DriftDeleted
+		case c.IsInstance() && isStoppedInstance(c):
+			kind = domain.DriftStopped
+		}
+		resources = append(resources, domain.DriftResource{
+			Address: c.Address,
+			Type:    c.Type,
+			Kind:    kind,
+		})
+	}
+	return resources
+}
+
+// isStoppedInstance 根据刷新后的实例状态属性判断云主机是否已停止
+func isStoppedInstance(c ResourceChange) bool {
+	for _, name := range []string{"status", "instance_state", "instance_status", "power_status"} {
+		if v, ok := c.After[name].(string); ok && stoppedInstanceStatus[strings.ToLower(v)] {
+			return true
+		}
+	}
+	// 腾讯云使用 running_flag 表示实例是否运行
+	if v, ok := c.After["running_flag"].(bool); ok && !v {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// scenarioStatusInterrupted 部署或销毁被中断的场景状态（云端可能存在部分资源，需要重新部署或销毁）
+const scenarioStatusInterrupted = "interrupted"
+
+// runResumable 执行场景的部署、应用计划或销毁操作
+// 操作被中断（Ctrl-C）时将场景标记为 interrupted 并记录最后一次 Terraform 运行；
+// 继续被中断的场景时，如果遇到本机被中断的操作遗留的状态锁，先释放锁再重新执行
+func (s *projectService) runResumable(ctx context.Context, projectName, scenarioID, op string, fn func() error) error {
+	log := logger.GetLogger()
+
+	if err := s.requireSharedBackend(projectName); err != nil {
+		return err
+	}
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return err
+	}
+	interrupted := scenario.Status == scenarioStatusInterrupted
+	if interrupted {
+		log.Warn("场景上次的操作被中断（运行记录 %s），继续%s: project=%s, scenario=%s",
+			scenario.InterruptedRun, op, projectName, scenarioID)
+	}
+
+	err = fn()
+	if interrupted && errors.Is(err, ErrStateLocked) {
+		if unlockErr := s.unlockInterruptedState(ctx, projectName, scenario.Path, err); unlockErr != nil {
+			return fmt.Errorf("%w（%v）", err, unlockErr)
+		}
+		err = fn()
+	}
+
+	s.recordInterruption(projectName, scenarioID, op, err)
+	return err
+}
+
+// unlockInterruptedState 释放被中断的操作遗留的状态锁
+// 只释放本机（user@host 与锁的持有者一致）持有的锁，避免释放团队其他成员正在使用的锁
+func (s *projectService) unlockInterruptedState(ctx context.Context, projectName, workDir string, lockErr error) error {
+	lockID, who := stateLockInfo(lockErr)
+	if lockID == "" {
+		return fmt.Errorf("无法从 Terraform 输出中获取状态锁 ID")
+	}
+	if owner := localLockOwner(); who != owner {
+		return fmt.Errorf("状态锁由 %s 持有，确认对方操作已结束后可运行 terraform force-unlock %s 释放", who, lockID)
+	}
+
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return err
+	}
+	return s.terraformSvc.ForceUnlock(ctx, workDir, lockID)
+}
+
+// recordInterruption 根据操作结果更新场景的中断标记
+// 操作被中断时标记为 interrupted 并记录最后一次运行；操作成功后清除之前的中断记录
+func (s *projectService) recordInterruption(projectName, scenarioID, op string, err error) {
+	log := logger.GetLogger()
+
+	interrupted := errors.Is(err, ErrInterrupted)
+	if err != nil && !interrupted {
+		return
+	}
+	scenario, getErr := s.projectRepo.GetScenario(projectName, scenarioID)
+	if getErr != nil || (!interrupted && scenario.InterruptedRun == "") {
+		return
+	}
+
+	if interrupted {
+		scenario.Status = scenarioStatusInterrupted
+		scenario.InterruptedRun = ""
+		if runs, err := readRunRecords(filepath.Join(scenario.Path, runsDirName)); err == nil && len(runs) > 0 {
+			scenario.InterruptedRun = runs[len(runs)-1].ID
+		}
+		log.Warn("场景%s被中断: project=%s, scenario=%s, run=%s", op, projectName, scenarioID, scenario.InterruptedRun)
+	} else {
+		scenario.InterruptedRun = ""
+	}
+
+	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
+		log.Error("更新场景状态失败: project=%s, scenario=%s, error=%v", projectName, scenarioID, err)
+	}
+}
+
+// localLockOwner 本机用户标识（user@host），与 Terraform 状态锁信息中的 Who 格式一致
+func localLockOwner() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
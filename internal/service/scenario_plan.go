@@ -0,0 +1,442 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+const (
+	// deployPlanFile deploy 时生成并立即应用的计划文件（相对场景目录）
+	deployPlanFile = "cloudbot-deploy.tfplan"
+
+	// plansDirName 场景目录下保存计划的子目录
+	plansDirName = "plans"
+)
+
+var (
+	// ErrPlanStale 保存的计划已过期（场景配置、变量或状态在生成计划后发生了变化）
+	ErrPlanStale = errors.New("计划已过期")
+
+	// ErrApplyCanceled 用户取消应用计划
+	ErrApplyCanceled = errors.New("已取消部署")
+)
+
+// applyDeployPlan 应用 deploy 过程中生成的计划文件
+// 未自动批准时先展示变更摘要和预估费用并询问确认，保证应用的正是刚刚展示的计划
+func (s *projectService) applyDeployPlan(ctx context.Context, scenario *domain.Scenario, vars map[string]string, autoApprove bool) error {
+	defer os.Remove(filepath.Join(scenario.Path, deployPlanFile))
+
+	if !autoApprove {
+		summary, err := s.terraformSvc.ShowPlan(ctx, scenario.Path, deployPlanFile)
+		if err != nil {
+			return err
+		}
+		printPlanSummary(os.Stdout, summary, s.estimatePlanCost(ctx, scenario, vars, summary))
+
+		// 没有资源变更时无需确认，应用空计划只会刷新输出
+		if len(summary.Changes) > 0 {
+			ok, err := confirmApply(ctx)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return ErrApplyCanceled
+			}
+		}
+	}
+	return s.terraformSvc.ApplyPlan(ctx, scenario.Path, deployPlanFile)
+}
+
+// planAndApply 生成部署计划并应用，限流和网络等临时错误按重试策略退避后重新生成计划
+func (s *projectService) planAndApply(ctx context.Context, scenario *domain.Scenario, vars map[string]string, autoApprove bool) error {
+	return s.retryPolicy.Do(ctx, "部署", func() error {
+		if err := s.terraformSvc.PlanToFile(ctx, scenario.Path, deployPlanFile, vars); err != nil {
+			return err
+		}
+		return s.applyDeployPlan(ctx, scenario, vars, autoApprove)
+	})
+}
+
+// confirmApply 询问是否应用计划，只有输入 yes 才会继续（与 Terraform 的确认方式一致）
+// 等待输入时按 Ctrl-C 视为取消
+func confirmApply(ctx context.Context) (bool, error) {
+	fmt.Print("\n是否应用以上计划？只有输入 yes 才会继续: ")
+
+	in, ok := readStdinLine(ctx)
+	if !ok {
+		fmt.Println()
+		return false, nil
+	}
+	if in.err != nil && in.line == "" {
+		return false, fmt.Errorf("读取确认输入失败: %w", in.err)
+	}
+	return strings.TrimSpace(in.line) == "yes", nil
+}
+
+// stdinLine 从标准输入读取的一行
+type stdinLine struct {
+	line string
+	err  error
+}
+
+var (
+	stdinMu     sync.Mutex
+	stdinReader = bufio.NewReader(os.Stdin)
+	// stdinPending 确认被取消时仍在等待输入的读取结果
+	stdinPending chan stdinLine
+)
+
+// readStdinLine 读取标准输入的一行，ctx 取消时返回 false
+// 阻塞的标准输入读取无法中断，取消后读取 goroutine 会保留到用户下一次输入，
+// 之后的确认复用该 goroutine（同一时间最多只有一个），取消后才输入的内容会被丢弃，不会当作之后确认的回答
+func readStdinLine(ctx context.Context) (stdinLine, bool) {
+	stdinMu.Lock()
+	ch := stdinPending
+	if ch != nil {
+		select {
+		case <-ch:
+			ch = nil
+		default:
+		}
+	}
+	if ch == nil {
+		ch = make(chan stdinLine, 1)
+		go func() {
+			line, err := stdinReader.ReadString('\n')
+			ch <- stdinLine{line, err}
+		}()
+	}
+	stdinPending = ch
+	stdinMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return stdinLine{}, false
+	case in := <-ch:
+		stdinMu.Lock()
+		if stdinPending == ch {
+			stdinPending = nil
+		}
+		stdinMu.Unlock()
+		return in, true
+	}
+}
+
+// PlanScenario 生成场景的部署计划并保存到场景目录的 plans 子目录
+// 变量的构建方式与部署相同，之后可以通过 ApplyScenarioPlan 应用该计划
+func (s *projectService) PlanScenario(ctx context.Context, projectName, scenarioID string, opts DeployOptions) (*domain.ScenarioPlan, error) {
+	log := logger.GetLogger()
+
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(scenario.Template, "aliyun/aliyun-proxy") {
+		return nil, fmt.Errorf("aliyun-proxy 场景按区域分步部署，不支持保存计划，请使用 scenario deploy")
+	}
+
+	vars, err := s.deployVars(ctx, projectName, scenario, opts)
+	if err != nil {
+		return nil, err
+	}
+	// deploy 会把这类部署的节点分散到多个区域，单区域的计划与实际部署不一致
+	if nodeCount, ok := tencentSpotMultiNode(scenario, vars, opts.NodeCount); ok {
+		return nil, fmt.Errorf("腾讯云抢占式实例多节点部署（%d 个节点）按区域分散部署，不支持保存计划，请使用 scenario deploy", nodeCount)
+	}
+
+	if err := s.initScenario(ctx, projectName, scenario); err != nil {
+		return nil, fmt.Errorf("初始化 Terraform 失败: %w", err)
+	}
+	if err := s.terraformSvc.Validate(ctx, scenario.Path); err != nil {
+		return nil, fmt.Errorf("验证 Terraform 配置失败: %w", err)
+	}
+
+	now := time.Now()
+	planID, err := reservePlanID(filepath.Join(scenario.Path, plansDirName), now)
+	if err != nil {
+		return nil, err
+	}
+	metaPath := filepath.Join(scenario.Path, plansDirName, planID+".json")
+	saved := false
+	defer func() {
+		if !saved {
+			os.Remove(metaPath)
+		}
+	}()
+
+	plan := &domain.ScenarioPlan{
+		ID:             planID,
+		CreatedAt:      now,
+		BidStrategy:    scenario.BidStrategy,
+		SpotPriceLimit: scenario.SpotPriceLimit,
+		NodeCount:      scenario.NodeCount,
+	}
+	planFile := planFilePath(plan.ID)
+	if err := s.terraformSvc.PlanToFile(ctx, scenario.Path, planFile, vars); err != nil {
+		return nil, err
+	}
+	// 计划文件中包含变量值，只允许当前用户读取
+	if err := os.Chmod(filepath.Join(scenario.Path, planFile), 0600); err != nil {
+		os.Remove(filepath.Join(scenario.Path, planFile))
+		return nil, fmt.Errorf("设置计划文件权限失败: %w", err)
+	}
+
+	summary, err := s.terraformSvc.ShowPlan(ctx, scenario.Path, planFile)
+	if err != nil {
+		return nil, err
+	}
+	printPlanSummary(os.Stdout, summary, s.estimatePlanCost(ctx, scenario, vars, summary))
+
+	// plan 不会修改状态，此时的状态即为计划所基于的状态
+	plan.StateLineage, plan.StateSerial, err = s.stateFingerprint(ctx, scenario.Path)
+	if err != nil {
+		return nil, err
+	}
+	plan.ConfigHash, err = s.scenarioConfigHash(projectName, scenario)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化计划信息失败: %w", err)
+	}
+	if err := os.WriteFile(metaPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("保存计划信息失败: %w", err)
+	}
+	saved = true
+
+	log.Info("已保存场景计划: project=%s, scenario=%s, plan=%s", projectName, scenarioID, plan.ID)
+	return plan, nil
+}
+
+// reservePlanID 在计划目录中创建计划信息文件占用计划 ID
+// ID 精确到秒，同一秒内多次生成计划时追加序号（与运行日志的命名方式一致）
+func reservePlanID(dir string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("创建计划目录失败: %w", err)
+	}
+	base := now.Format("20060102-150405")
+	id := base
+	for i := 2; ; i++ {
+		file, err := os.OpenFile(filepath.Join(dir, id+".json"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			file.Close()
+			return id, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("创建计划信息失败: %w", err)
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// ApplyScenarioPlan 应用之前保存的计划
+// 场景配置、变量或状态在生成计划后发生变化时拒绝应用，返回 ErrPlanStale
+func (s *projectService) ApplyScenarioPlan(ctx context.Context, projectName, scenarioID, planID string) error {
+	return s.runResumable(ctx, projectName, scenarioID, "应用计划", func() error {
+		return s.applyScenarioPlan(ctx, projectName, scenarioID, planID)
+	})
+}
+
+// applyScenarioPlan 应用之前保存的计划
+func (s *projectService) applyScenarioPlan(ctx context.Context, projectName, scenarioID, planID string) error {
+	log := logger.GetLogger()
+
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return err
+	}
+
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return err
+	}
+	plan, err := loadScenarioPlan(scenario, planID)
+	if err != nil {
+		return err
+	}
+	planFile := planFilePath(plan.ID)
+	if _, err := os.Stat(filepath.Join(scenario.Path, planFile)); err != nil {
+		return fmt.Errorf("计划文件 %s 不存在", planFile)
+	}
+
+	if err := s.initScenario(ctx, projectName, scenario); err != nil {
+		return fmt.Errorf("初始化 Terraform 失败: %w", err)
+	}
+
+	hash, err := s.scenarioConfigHash(projectName, scenario)
+	if err != nil {
+		return err
+	}
+	if hash != plan.ConfigHash {
+		return fmt.Errorf("场景配置或变量在生成计划后已修改，请重新运行 'cloudbot scenario plan': %w", ErrPlanStale)
+	}
+	lineage, serial, err := s.stateFingerprint(ctx, scenario.Path)
+	if err != nil {
+		return err
+	}
+	if lineage != plan.StateLineage || serial != plan.StateSerial {
+		return fmt.Errorf("场景状态在生成计划后已变化（serial %d -> %d），可能已被部署或销毁，请重新运行 'cloudbot scenario plan': %w",
+			plan.StateSerial, serial, ErrPlanStale)
+	}
+
+	if err := s.terraformSvc.ApplyPlan(ctx, scenario.Path, planFile); err != nil {
+		return err
+	}
+
+	// 计划已应用，删除后无法重复应用
+	os.Remove(filepath.Join(scenario.Path, planFile))
+	os.Remove(filepath.Join(scenario.Path, plansDirName, plan.ID+".json"))
+
+	scenario.Status = "deployed"
+	scenario.Drift = nil // 部署或销毁后清除之前的漂移检测结果
+	scenario.BidStrategy = plan.BidStrategy
+	scenario.SpotPriceLimit = plan.SpotPriceLimit
+	if plan.NodeCount > 0 {
+		scenario.NodeCount = plan.NodeCount
+	}
+	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
+		return fmt.Errorf("更新场景状态失败: %w", err)
+	}
+
+	log.Info("场景计划已应用: project=%s, scenario=%s, plan=%s", projectName, scenarioID, plan.ID)
+	return nil
+}
+
+// ListScenarioPlans 列出场景保存的计划（按生成时间排序）
+func (s *projectService) ListScenarioPlans(ctx context.Context, projectName, scenarioID string) ([]*domain.ScenarioPlan, error) {
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(scenario.Path, plansDirName, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("读取计划列表失败: %w", err)
+	}
+
+	var plans []*domain.ScenarioPlan
+	for _, match := range matches {
+		plan, err := loadScenarioPlan(scenario, strings.TrimSuffix(filepath.Base(match), ".json"))
+		if err != nil {
+			continue
+		}
+		plans = append(plans, plan)
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].CreatedAt.Before(plans[j].CreatedAt) })
+	return plans, nil
+}
+
+// loadScenarioPlan 读取保存的计划信息
+func loadScenarioPlan(scenario *domain.Scenario, planID string) (*domain.ScenarioPlan, error) {
+	if planID == "" || strings.ContainsAny(planID, `/\`) {
+		return nil, fmt.Errorf("无效的计划 ID: %s", planID)
+	}
+
+	data, err := os.ReadFile(filepath.Join(scenario.Path, plansDirName, planID+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("场景 %s 没有计划 %s", scenario.ID, planID)
+		}
+		return nil, fmt.Errorf("读取计划信息失败: %w", err)
+	}
+
+	var plan domain.ScenarioPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("解析计划信息失败: %w", err)
+	}
+	return &plan, nil
+}
+
+// planFilePath 计划文件相对场景目录的路径
+func planFilePath(planID string) string {
+	return filepath.Join(plansDirName, planID+".tfplan")
+}
+
+// stateFingerprint 获取当前状态的 lineage 和 serial，没有状态时返回空值
+// 状态每次被修改 serial 都会增加，用于判断计划所基于的状态是否仍是最新
+func (s *projectService) stateFingerprint(ctx context.Context, workDir string) (string, int64, error) {
+	data, err := s.terraformSvc.StatePull(ctx, workDir)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return "", 0, nil
+	}
+
+	var state struct {
+		Lineage string `json:"lineage"`
+		Serial  int64  `json:"serial"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return "", 0, fmt.Errorf("解析 Terraform 状态失败: %w", err)
+	}
+	return state.Lineage, state.Serial, nil
+}
+
+// scenarioConfigHash 计算场景配置文件和项目级变量的摘要
+// 跳过 Terraform 工作目录、状态文件、计划文件、运行日志等不影响计划内容的文件
+func (s *projectService) scenarioConfigHash(projectName string, scenario *domain.Scenario) (string, error) {
+	h := sha256.New()
+
+	err := filepath.WalkDir(scenario.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if name == ".terraform" || name == plansDirName || name == runsDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if name == ".scenario.json" || name == ".terraform.lock.hcl" ||
+			strings.HasPrefix(name, "terraform.tfstate") || strings.HasPrefix(name, ".terraform.tfstate") ||
+			strings.HasSuffix(name, ".tfplan") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(scenario.Path, path)
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(data))
+		h.Write(data)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("读取场景配置失败: %w", err)
+	}
+
+	// 项目级变量不在场景目录中，但会通过 -var 写入计划
+	projectVars, err := s.projectRepo.GetProjectVars(projectName)
+	if err != nil {
+		return "", err
+	}
+	data, _ := json.Marshal(projectVars)
+	fmt.Fprintf(h, "project-vars\x00%s", data)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
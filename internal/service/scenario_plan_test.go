@@ -0,0 +1,63 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestReadStdinLineAfterCancel(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	stdinMu.Lock()
+	origReader, origPending := stdinReader, stdinPending
+	stdinReader, stdinPending = bufio.NewReader(r), nil
+	stdinMu.Unlock()
+	defer func() {
+		stdinMu.Lock()
+		stdinReader, stdinPending = origReader, origPending
+		stdinMu.Unlock()
+	}()
+
+	// 取消确认后读取 goroutine 继续等待输入
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, ok := readStdinLine(ctx); ok {
+		t.Fatal("context 已取消时应返回 false")
+	}
+	stdinMu.Lock()
+	pending := stdinPending
+	stdinMu.Unlock()
+	if pending == nil {
+		t.Fatal("取消后应保留等待中的读取")
+	}
+
+	// 取消后才输入的内容不能作为下一次确认的回答
+	if _, err := io.WriteString(w, "yes\n"); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(time.Second); len(pending) == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("等待读取结果超时")
+		}
+	}
+
+	done := make(chan stdinLine, 1)
+	go func() {
+		in, _ := readStdinLine(context.Background())
+		done <- in
+	}()
+	if _, err := io.WriteString(w, "no\n"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case in := <-done:
+		if in.line != "no\n" {
+			t.Errorf("读取到 %q，期望 %q", in.line, "no\n")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("读取超时")
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+)
+
+// ListScenarioRuns 列出场景的 Terraform 运行记录（按开始时间排序）
+func (s *projectService) ListScenarioRuns(ctx context.Context, projectName, scenarioID string) ([]*domain.ScenarioRun, error) {
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+	return readRunRecords(filepath.Join(scenario.Path, runsDirName))
+}
+
+// GetScenarioRun 获取运行记录及其日志文件路径，runID 为空时返回最近一次运行
+func (s *projectService) GetScenarioRun(ctx context.Context, projectName, scenarioID, runID string) (*domain.ScenarioRun, string, error) {
+	if strings.ContainsAny(runID, `/\`) {
+		return nil, "", fmt.Errorf("无效的运行 ID: %s", runID)
+	}
+
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return nil, "", err
+	}
+	dir := filepath.Join(scenario.Path, runsDirName)
+	runs, err := readRunRecords(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(runs) == 0 {
+		return nil, "", fmt.Errorf("场景 %s 没有运行记录", scenarioID)
+	}
+
+	if runID == "" {
+		run := runs[len(runs)-1]
+		return run, filepath.Join(dir, run.ID+".log"), nil
+	}
+	for _, run := range runs {
+		if run.ID == runID {
+			return run, filepath.Join(dir, run.ID+".log"), nil
+		}
+	}
+	return nil, "", fmt.Errorf("场景 %s 没有运行记录 %s", scenarioID, runID)
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// nodeCountVar 模板中表示节点数量的变量
+const nodeCountVar = "node_count"
+
+// countIndexPattern 使用 count 的资源实例地址，如 alicloud_instance.instance[2]
+var countIndexPattern = regexp.MustCompile(`^(.+)\[(\d+)\]$`)
+
+// applyDesiredNodeCount 场景记录了期望的节点数且模板声明了 node_count 时，将其传给 Terraform
+func applyDesiredNodeCount(scenario *domain.Scenario, vars map[string]string) {
+	if scenario.NodeCount > 0 && templateDeclaresVariable(scenario.Path, nodeCountVar) {
+		vars[nodeCountVar] = strconv.Itoa(scenario.NodeCount)
+	}
+}
+
+// scaleNode 缩容时的候选节点
+type scaleNode struct {
+	Index        int
+	Address      string
+	InstanceType string
+	Spot         bool
+	Unhealthy    string  // 不健康的原因（已停止、已删除），健康节点为空
+	PricePerHour float64 // 每小时价格（未查询或查询失败为 0）
+	Currency     string
+}
+
+// ScaleScenario 调整已部署场景的节点数，并记录为之后部署使用的期望节点数
+// 扩容直接以新的 node_count 重新部署；缩容时优先移除不健康的节点，其次是价格最高的节点，
+// 通过定向销毁移除选中的节点后，将剩余节点在状态中的序号压缩为连续序号，再以新的 node_count 重新部署
+func (s *projectService) ScaleScenario(ctx context.Context, projectName, scenarioID string, count int, autoApprove bool) error {
+	return s.runResumable(ctx, projectName, scenarioID, "扩缩容", func() error {
+		return s.scaleScenario(ctx, projectName, scenarioID, count, autoApprove)
+	})
+}
+
+func (s *projectService) scaleScenario(ctx context.Context, projectName, scenarioID string, count int, autoApprove bool) error {
+	log := logger.GetLogger()
+
+	if count < 1 {
+		return fmt.Errorf("节点数必须大于 0，销毁整个场景请使用 scenario destroy")
+	}
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return err
+	}
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return err
+	}
+	if scenario.Status != "deployed" {
+		return fmt.Errorf("场景 %s 未部署（状态: %s），请使用 scenario deploy --node 指定节点数", scenarioID, scenario.Status)
+	}
+	if !templateDeclaresVariable(scenario.Path, nodeCountVar) {
+		return fmt.Errorf("模板 %s 未声明 %s 变量，不支持扩缩容", scenario.Template, nodeCountVar)
+	}
+
+	if err := s.initScenario(ctx, projectName, scenario); err != nil {
+		return fmt.Errorf("初始化 Terraform 失败: %w", err)
+	}
+	resources, err := s.terraformSvc.StateList(ctx, scenario.Path)
+	if err != nil {
+		return err
+	}
+	manifest, err := readTemplateManifest(scenario.Path)
+	if err != nil {
+		return err
+	}
+	base, err := nodeResource(resources, manifest)
+	if err != nil {
+		return err
+	}
+	indices, err := nodeIndices(base, resources)
+	if err != nil {
+		return err
+	}
+	groups := nodeResourceGroups(base, resources, indices)
+
+	vars, err := s.stateVars(ctx, projectName, scenario)
+	if err != nil {
+		return err
+	}
+
+	log.Info("开始扩缩容: project=%s, scenario=%s, nodes=%d -> %d", projectName, scenarioID, len(indices), count)
+	if count < len(indices) {
+		if indices, err = s.removeNodes(ctx, scenario, vars, base, groups, indices, len(indices)-count, autoApprove); err != nil {
+			return err
+		}
+		autoApprove = true // 移除的节点已经确认过，剩余节点不应再有变化（计划中出现删除或替换时拒绝应用）
+
+		// 节点已销毁，立即记录新的节点数，避免后续步骤失败后重新部署时按旧节点数重建
+		scenario.NodeCount = count
+		if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
+			return fmt.Errorf("更新场景状态失败: %w", err)
+		}
+	}
+	// 节点序号不连续时（缩容或之前的定向销毁留下的空缺），以新的 node_count 部署会重建序号靠后的节点
+	if err := s.compactNodes(ctx, scenario, groups, indices); err != nil {
+		return err
+	}
+
+	vars[nodeCountVar] = strconv.Itoa(count)
+	err = s.retryPolicy.Do(ctx, "扩缩容", func() error {
+		if err := s.terraformSvc.PlanToFile(ctx, scenario.Path, deployPlanFile, vars); err != nil {
+			return err
+		}
+		return s.applyTargetedPlan(ctx, scenario, vars, false, nil, autoApprove)
+	})
+	if err != nil {
+		return err
+	}
+
+	scenario.NodeCount = count
+	scenario.Drift = nil // 资源变化后之前的漂移检测结果不再有效
+	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
+		return fmt.Errorf("更新场景状态失败: %w", err)
+	}
+
+	log.Info("扩缩容成功: project=%s, scenario=%s, nodes=%d", projectName, scenarioID, count)
+	return nil
+}
+
+// removeNodes 选出要移除的节点，确认后定向销毁，返回剩余节点的序号
+func (s *projectService) removeNodes(ctx context.Context, scenario *domain.Scenario, vars map[string]string, base string, groups []string, indices []int, n int, autoApprove bool) ([]int, error) {
+	candidates := s.rankScaleDownNodes(ctx, scenario, vars, base, indices)
+	removed := candidates[:n]
+
+	if !autoApprove {
+		printScaleDownNodes(os.Stdout, removed)
+		ok, err := confirmApply(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrApplyCanceled
+		}
+	}
+
+	// 同一节点的其他按序号创建的资源（如弹性 IP、磁盘）一起销毁
+	var targets []string
+	removedIndex := make(map[int]bool, len(removed))
+	for _, node := range removed {
+		removedIndex[node.Index] = true
+		for _, group := range groups {
+			targets = append(targets, fmt.Sprintf("%s[%d]", group, node.Index))
+		}
+	}
+	opts := PlanOptions{Destroy: true, Targets: targets}
+	err := s.retryPolicy.Do(ctx, "移除节点", func() error {
+		if err := s.terraformSvc.PlanToFileWithOptions(ctx, scenario.Path, deployPlanFile, vars, opts); err != nil {
+			return err
+		}
+		return s.applyTargetedPlan(ctx, scenario, vars, true, nil, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining []int
+	for _, index := range indices {
+		if !removedIndex[index] {
+			remaining = append(remaining, index)
+		}
+	}
+	return remaining, nil
+}
+
+// compactNodes 将节点及其关联资源在状态中的序号依次移动为 0..n-1，不会变更云资源
+func (s *projectService) compactNodes(ctx context.Context, scenario *domain.Scenario, groups []string, indices []int) error {
+	for to, from := range indices {
+		if from == to {
+			continue
+		}
+		for _, group := range groups {
+			src, dst := fmt.Sprintf("%s[%d]", group, from), fmt.Sprintf("%s[%d]", group, to)
+			if err := s.terraformSvc.StateMove(ctx, scenario.Path, src, dst); err != nil {
+				return fmt.Errorf("调整节点序号失败（%s -> %s）: %w", src, dst, err)
+			}
+		}
+	}
+	return nil
+}
+
+// rankScaleDownNodes 按移除的优先级排列节点：不健康的节点优先，其次是价格较高的节点，最后是序号较大的节点
+func (s *projectService) rankScaleDownNodes(ctx context.Context, scenario *domain.Scenario, vars map[string]string, base string, indices []int) []scaleNode {
+	details := make(map[string]ECSInstanceDetail)
+	if instances, err := s.terraformSvc.ShowInstances(ctx, scenario.Path); err == nil {
+		for _, instance := range instances {
+			details[instance.Name] = instance
+		}
+	} else {
+		logger.GetLogger().Warn("读取节点信息失败，按序号选择要移除的节点: %v", err)
+	}
+	drift := make(map[string]string)
+	if scenario.Drift != nil {
+		for _, r := range scenario.Drift.Resources {
+			drift[r.Address] = r.Kind
+		}
+	}
+
+	nodes := make([]scaleNode, 0, len(indices))
+	for _, index := range indices {
+		address := fmt.Sprintf("%s[%d]", base, index)
+		detail := details[address]
+		node := scaleNode{Index: index, Address: address, InstanceType: detail.InstanceType, Spot: detail.Spot}
+		switch {
+		case drift[address] == domain.DriftDeleted:
+			node.Unhealthy = "已在云端删除"
+		case drift[address] == domain.DriftStopped || stoppedInstanceStatus[strings.ToLower(detail.Status)]:
+			node.Unhealthy = "已停止"
+		}
+		nodes = append(nodes, node)
+	}
+	s.priceScaleNodes(ctx, scenario, vars, nodes)
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if (a.Unhealthy != "") != (b.Unhealthy != "") {
+			return a.Unhealthy != ""
+		}
+		if a.PricePerHour != b.PricePerHour {
+			return a.PricePerHour > b.PricePerHour
+		}
+		return a.Index > b.Index
+	})
+	return nodes
+}
+
+// priceScaleNodes 节点的实例类型或计费方式不同时查询价格，用于优先移除较贵的节点
+// 所有节点规格相同时价格不影响选择，不查询；查询失败时忽略价格
+func (s *projectService) priceScaleNodes(ctx context.Context, scenario *domain.Scenario, vars map[string]string, nodes []scaleNode) {
+	type key struct {
+		instanceType string
+		spot         bool
+	}
+	kinds := make(map[key]bool)
+	for _, node := range nodes {
+		if node.InstanceType == "" {
+			return
+		}
+		kinds[key{node.InstanceType, node.Spot}] = true
+	}
+	if len(kinds) < 2 {
+		return
+	}
+
+	log := logger.GetLogger()
+	provider := strings.SplitN(scenario.Template, "/", 2)[0]
+	region := vars["region"]
+	if region == "" {
+		region = readTerraformValue(scenario.Path, "region")
+	}
+	if region == "" {
+		return
+	}
+	client, err := s.newClient(credentials.ManagerFromContext(ctx), provider)
+	if err != nil {
+		log.Warn("查询节点价格失败，不按价格选择要移除的节点: %v", err)
+		return
+	}
+
+	prices := make(map[key]*InstancePrice)
+	for k := range kinds {
+		price, err := queryInstancePrice(ctx, client, region, k.instanceType, k.spot)
+		if err != nil {
+			log.Warn("查询实例价格失败: provider=%s, region=%s, instance_type=%s, error=%v", provider, region, k.instanceType, err)
+			continue
+		}
+		prices[k] = price
+	}
+	for i := range nodes {
+		if price, ok := prices[key{nodes[i].InstanceType, nodes[i].Spot}]; ok {
+			nodes[i].PricePerHour = price.PricePerHour
+			nodes[i].Currency = price.Currency
+		}
+	}
+}
+
+// printScaleDownNodes 输出缩容时将要移除的节点
+func printScaleDownNodes(w io.Writer, nodes []scaleNode) {
+	fmt.Fprintf(w, "\n将移除以下 %d 个节点:\n", len(nodes))
+	for _, node := range nodes {
+		line := "  - " + node.Address
+		if node.InstanceType != "" {
+			line += "  " + node.InstanceType
+			if node.Spot {
+				line += "（抢占式）"
+			}
+		}
+		if node.PricePerHour > 0 {
+			line += fmt.Sprintf("  %.4f %s/小时", node.PricePerHour, node.Currency)
+		}
+		if node.Unhealthy != "" {
+			line += "  [" + node.Unhealthy + "]"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// nodeIndices 节点资源在状态中的序号（升序），节点资源必须使用 count
+func nodeIndices(base string, resources []string) ([]int, error) {
+	var indices []int
+	for _, address := range resourceInstances(base, resources) {
+		m := countIndexPattern.FindStringSubmatch(address)
+		if m == nil || m[1] != base {
+			if address == base {
+				return nil, fmt.Errorf("节点资源 %s 未使用 count，不支持扩缩容", base)
+			}
+			if strings.HasPrefix(address, base+"[") {
+				return nil, fmt.Errorf("节点资源 %s 使用 for_each，不支持按节点数扩缩容", base)
+			}
+			continue // 同名前缀的其他资源，如模块中的资源
+		}
+		index, _ := strconv.Atoi(m[2])
+		indices = append(indices, index)
+	}
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("场景状态中没有节点资源 %s", base)
+	}
+	sort.Ints(indices)
+	return indices, nil
+}
+
+// nodeResourceGroups 与节点一一对应的资源（不含序号）：序号集合与节点资源完全相同的 count 资源，
+// 如每个节点的弹性 IP，缩容时与节点一起移除、一起调整序号。节点资源本身排在第一个
+func nodeResourceGroups(base string, resources []string, indices []int) []string {
+	want := fmt.Sprint(indices)
+	byResource := make(map[string][]int)
+	var order []string
+	for _, address := range resources {
+		m := countIndexPattern.FindStringSubmatch(address)
+		if m == nil {
+			continue
+		}
+		if _, ok := byResource[m[1]]; !ok {
+			order = append(order, m[1])
+		}
+		index, _ := strconv.Atoi(m[2])
+		byResource[m[1]] = append(byResource[m[1]], index)
+	}
+
+	groups := []string{base}
+	for _, resource := range order {
+		if resource == base {
+			continue
+		}
+		got := byResource[resource]
+		sort.Ints(got)
+		if fmt.Sprint(got) == want {
+			groups = append(groups, resource)
+		}
+	}
+	return groups
+}
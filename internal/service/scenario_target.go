@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// resourceIndexPattern 资源地址末尾的实例序号，如 [0] 或 ["a"]
+var resourceIndexPattern = regexp.MustCompile(`\[[^\]]*\]$`)
+
+// ListScenarioResources 列出场景 Terraform 状态中的资源地址（用于命令补全和选择要操作的资源）
+func (s *projectService) ListScenarioResources(ctx context.Context, projectName, scenarioID string) ([]string, error) {
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+	return s.terraformSvc.StateList(ctx, scenario.Path)
+}
+
+// ReplaceScenarioResources 重建场景中的指定资源（plan -replace），不影响其他资源
+// targets 为资源地址或节点序号，节点序号通过模板清单的 node_resource 转换为资源地址
+func (s *projectService) ReplaceScenarioResources(ctx context.Context, projectName, scenarioID string, targets []string, autoApprove bool) error {
+	return s.runResumable(ctx, projectName, scenarioID, "重建", func() error {
+		return s.applyTargeted(ctx, projectName, scenarioID, targets, false, autoApprove)
+	})
+}
+
+// DestroyScenarioResources 只销毁场景中的指定资源（plan -destroy -target），所有资源都销毁后场景标记为 destroyed
+func (s *projectService) DestroyScenarioResources(ctx context.Context, projectName, scenarioID string, targets []string, autoApprove bool) error {
+	return s.runResumable(ctx, projectName, scenarioID, "销毁", func() error {
+		return s.applyTargeted(ctx, projectName, scenarioID, targets, true, autoApprove)
+	})
+}
+
+// TaintScenarioResources 将场景中的指定资源标记为需要重建，下次部署时替换，返回实际标记的资源地址
+func (s *projectService) TaintScenarioResources(ctx context.Context, projectName, scenarioID string, targets []string) ([]string, error) {
+	return s.setScenarioTainted(ctx, projectName, scenarioID, targets, true)
+}
+
+// UntaintScenarioResources 取消场景中指定资源的重建标记，返回实际处理的资源地址
+func (s *projectService) UntaintScenarioResources(ctx context.Context, projectName, scenarioID string, targets []string) ([]string, error) {
+	return s.setScenarioTainted(ctx, projectName, scenarioID, targets, false)
+}
+
+// setScenarioTainted 对指定资源执行 taint 或 untaint
+func (s *projectService) setScenarioTainted(ctx context.Context, projectName, scenarioID string, targets []string, taint bool) ([]string, error) {
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	scenario, addresses, err := s.prepareTargets(ctx, projectName, scenarioID, targets, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, address := range addresses {
+		if taint {
+			err = s.terraformSvc.Taint(ctx, scenario.Path, address)
+		} else {
+			err = s.terraformSvc.Untaint(ctx, scenario.Path, address)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return addresses, nil
+}
+
+// applyTargeted 生成只针对指定资源的计划（重建或销毁）并应用
+func (s *projectService) applyTargeted(ctx context.Context, projectName, scenarioID string, targets []string, destroy, autoApprove bool) error {
+	log := logger.GetLogger()
+
+	ctx, err := s.withProjectProfile(ctx, projectName)
+	if err != nil {
+		return err
+	}
+	// -replace 只接受单个资源实例，-target 还可以是整个资源（所有实例）或模块
+	scenario, addresses, err := s.prepareTargets(ctx, projectName, scenarioID, targets, !destroy)
+	if err != nil {
+		return err
+	}
+	vars, err := s.stateVars(ctx, projectName, scenario)
+	if err != nil {
+		return err
+	}
+
+	opts := PlanOptions{Destroy: destroy, Targets: addresses}
+	op := "销毁资源"
+	if !destroy {
+		opts.Replace = addresses
+		op = "重建资源"
+	}
+	log.Info("开始%s: project=%s, scenario=%s, resources=%v", op, projectName, scenarioID, addresses)
+
+	err = s.retryPolicy.Do(ctx, op, func() error {
+		if err := s.terraformSvc.PlanToFileWithOptions(ctx, scenario.Path, deployPlanFile, vars, opts); err != nil {
+			return err
+		}
+		return s.applyTargetedPlan(ctx, scenario, vars, destroy, opts.Replace, autoApprove)
+	})
+	if err != nil {
+		return err
+	}
+
+	scenario.Drift = nil // 资源变化后之前的漂移检测结果不再有效
+	if destroy {
+		if remaining, err := s.terraformSvc.StateList(ctx, scenario.Path); err == nil && len(remaining) == 0 {
+			scenario.Status = "destroyed"
+		}
+	}
+	if err := s.projectRepo.UpdateScenario(projectName, scenario); err != nil {
+		return fmt.Errorf("更新场景状态失败: %w", err)
+	}
+
+	log.Info("%s成功: project=%s, scenario=%s, resources=%v", op, projectName, scenarioID, addresses)
+	return nil
+}
+
+// applyTargetedPlan 检查并应用只针对部分资源的计划
+// 非销毁计划中出现资源删除，或替换 replace 以外的资源时拒绝应用：通常是部署时传入的参数与模板默认值不同，
+// 这类变化应通过 scenario deploy 处理，而不是在重建单个资源或扩缩容时顺带发生
+func (s *projectService) applyTargetedPlan(ctx context.Context, scenario *domain.Scenario, vars map[string]string, destroy bool, replace []string, autoApprove bool) error {
+	defer os.Remove(filepath.Join(scenario.Path, deployPlanFile))
+
+	summary, err := s.terraformSvc.ShowPlan(ctx, scenario.Path, deployPlanFile)
+	if err != nil {
+		return err
+	}
+	if !destroy {
+		for _, c := range summary.Changes {
+			if c.Action == ChangeDelete {
+				return fmt.Errorf("计划会删除资源 %s，可能是部署参数与模板默认值不同，请检查后使用 scenario deploy 重新部署", c.Address)
+			}
+			if c.Action == ChangeReplace && !containsString(replace, c.Address) {
+				return fmt.Errorf("计划会替换资源 %s，可能是部署参数与模板默认值不同，请检查后使用 scenario deploy 重新部署", c.Address)
+			}
+		}
+	}
+
+	if !autoApprove {
+		printPlanSummary(os.Stdout, summary, s.estimatePlanCost(ctx, scenario, vars, summary))
+		if len(summary.Changes) > 0 {
+			ok, err := confirmApply(ctx)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return ErrApplyCanceled
+			}
+		}
+	}
+	return s.terraformSvc.ApplyPlan(ctx, scenario.Path, deployPlanFile)
+}
+
+// prepareTargets 加载已部署的场景，初始化 Terraform 并将目标解析为状态中的资源地址
+func (s *projectService) prepareTargets(ctx context.Context, projectName, scenarioID string, targets []string, instanceOnly bool) (*domain.Scenario, []string, error) {
+	if len(targets) == 0 {
+		return nil, nil, fmt.Errorf("请指定资源地址或节点序号")
+	}
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if scenario.Status != "deployed" {
+		return nil, nil, fmt.Errorf("场景 %s 未部署（状态: %s），无法操作单个资源", scenarioID, scenario.Status)
+	}
+
+	if err := s.initScenario(ctx, projectName, scenario); err != nil {
+		return nil, nil, fmt.Errorf("初始化 Terraform 失败: %w", err)
+	}
+	resources, err := s.terraformSvc.StateList(ctx, scenario.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest, err := readTemplateManifest(scenario.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addresses, err := resolveResourceTargets(targets, resources, manifest, instanceOnly)
+	if err != nil {
+		return nil, nil, err
+	}
+	return scenario, addresses, nil
+}
+
+// resolveResourceTargets 将资源地址或节点序号解析为状态中的资源地址
+// instanceOnly 为 true 时只接受单个资源实例（-replace、taint 的要求）
+func resolveResourceTargets(targets, resources []string, manifest *TemplateManifest, instanceOnly bool) ([]string, error) {
+	inState := make(map[string]bool, len(resources))
+	for _, address := range resources {
+		inState[address] = true
+	}
+
+	var addresses []string
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		address := target
+		if index, err := strconv.Atoi(target); err == nil {
+			address, err = nodeResourceAddress(index, resources, manifest)
+			if err != nil {
+				return nil, err
+			}
+		} else if !inState[address] {
+			instances := resourceInstances(address, resources)
+			if len(instances) == 0 {
+				return nil, fmt.Errorf("资源 %s 不在场景状态中，可以使用 scenario status 查看场景的资源", address)
+			}
+			if instanceOnly {
+				return nil, fmt.Errorf("%s 包含多个资源实例，请指定其中一个，如 %s", address, instances[0])
+			}
+		}
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+// nodeResourceAddress 将节点序号转换为资源地址，如 1 -> alicloud_instance.instance[1]
+// 模板清单未声明 node_resource 时，使用状态中唯一的云主机资源
+func nodeResourceAddress(index int, resources []string, manifest *TemplateManifest) (string, error) {
+	base, err := nodeResource(resources, manifest)
+	if err != nil {
+		return "", err
+	}
+
+	nodes := resourceInstances(base, resources)
+	for _, address := range nodes {
+		// 没有使用 count 的资源只有一个节点，序号为 0
+		if address == fmt.Sprintf("%s[%d]", base, index) || (address == base && index == 0) {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("节点 %d 不存在，场景共有 %d 个节点（%s）", index, len(nodes), base)
+}
+
+// nodeResource 表示节点的资源地址（不含序号）：优先使用模板清单的声明，否则从状态中推断
+func nodeResource(resources []string, manifest *TemplateManifest) (string, error) {
+	if manifest.NodeResource != "" {
+		return manifest.NodeResource, nil
+	}
+	return inferNodeResource(resources)
+}
+
+// inferNodeResource 从状态中找出表示节点的云主机资源（不含序号）
+func inferNodeResource(resources []string) (string, error) {
+	var bases []string
+	seen := make(map[string]bool)
+	for _, address := range resources {
+		base := resourceIndexPattern.ReplaceAllString(address, "")
+		parts := strings.Split(base, ".")
+		if len(parts) < 2 || !instanceResourceTypes[parts[len(parts)-2]] || seen[base] {
+			continue
+		}
+		seen[base] = true
+		bases = append(bases, base)
+	}
+
+	switch len(bases) {
+	case 1:
+		return bases[0], nil
+	case 0:
+		return "", fmt.Errorf("场景状态中没有云主机资源，请使用资源地址")
+	default:
+		return "", fmt.Errorf("场景中有多种云主机资源（%s），模板未在 %s 中声明 node_resource，请使用资源地址",
+			strings.Join(bases, ", "), templateManifestFile)
+	}
+}
+
+// resourceInstances 状态中属于指定资源（或模块）的资源实例地址
+func resourceInstances(address string, resources []string) []string {
+	var instances []string
+	for _, r := range resources {
+		if r == address || strings.HasPrefix(r, address+"[") || strings.HasPrefix(r, address+".") {
+			instances = append(instances, r)
+		}
+	}
+	return instances
+}
@@ -0,0 +1,460 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// 变量来源（按优先级从低到高）
+const (
+	VarSourceDefault  = "default"  // 模板默认值
+	VarSourceProject  = "project"  // 项目级变量文件
+	VarSourceScenario = "scenario" // 场景级变量文件
+)
+
+// TemplateVariable 模板中声明的 Terraform 变量
+type TemplateVariable struct {
+	Name        string
+	Type        string // 类型表达式原文，如 string、list(string)，未声明时为空
+	Default     string // 默认值表达式原文
+	HasDefault  bool
+	Description string
+}
+
+// EffectiveVar 场景变量的生效值
+type EffectiveVar struct {
+	Name        string
+	Type        string
+	Value       string // 生效值（非字符串类型以 JSON 表示）
+	Source      string // 值来源：scenario、project、default，为空表示未设置（必填）
+	Description string
+}
+
+var (
+	varNamePattern     = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+	variableBlockStart = regexp.MustCompile(`(?m)^[ \t]*variable[ \t]+"([^"]+)"[ \t]*\{`)
+	attributeStart     = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_-]*)[ \t]*=`)
+)
+
+// ParseVarAssignment 解析命令行中的 key=value 变量赋值
+// 以 [ 或 { 开头的值按 JSON 解析（用于 list / map 类型），其余按字符串保存，由 Terraform 按声明类型转换
+func ParseVarAssignment(assignment string) (string, interface{}, error) {
+	key, value, found := strings.Cut(assignment, "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return "", nil, fmt.Errorf("变量格式错误: %s（应为 key=value）", assignment)
+	}
+	if !varNamePattern.MatchString(key) {
+		return "", nil, fmt.Errorf("无效的变量名: %s", key)
+	}
+
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(trimmed), &parsed); err != nil {
+			return "", nil, fmt.Errorf("变量 %s 的值不是有效的 JSON: %w", key, err)
+		}
+		return key, parsed, nil
+	}
+	return key, value, nil
+}
+
+// formatVarValue 将变量值转换为 -var 参数格式（字符串原样传递，其他类型使用 JSON，与 HCL 语法兼容）
+func formatVarValue(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// checkVarType 检查变量值是否符合模板声明的类型
+func checkVarType(variable *TemplateVariable, value interface{}) error {
+	typ := strings.ReplaceAll(variable.Type, " ", "")
+	switch {
+	case typ == "" || typ == "any":
+		return nil
+	case typ == "string":
+		switch value.(type) {
+		case string, float64, bool:
+			return nil
+		}
+	case typ == "number":
+		switch v := value.(type) {
+		case float64:
+			return nil
+		case string:
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				return nil
+			}
+		}
+	case typ == "bool":
+		switch v := value.(type) {
+		case bool:
+			return nil
+		case string:
+			if _, err := strconv.ParseBool(v); err == nil {
+				return nil
+			}
+		}
+	case strings.HasPrefix(typ, "list(") || strings.HasPrefix(typ, "set(") || strings.HasPrefix(typ, "tuple("):
+		if _, ok := value.([]interface{}); ok {
+			return nil
+		}
+	case strings.HasPrefix(typ, "map(") || strings.HasPrefix(typ, "object("):
+		if _, ok := value.(map[string]interface{}); ok {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return fmt.Errorf("变量 %s 的值 %s 与声明类型 %s 不匹配", variable.Name, formatVarValue(value), variable.Type)
+}
+
+// parseTemplateVariables 解析场景目录中 *.tf 文件声明的变量（不递归子模块）
+func parseTemplateVariables(dir string) (map[string]*TemplateVariable, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("目录 %s 中没有 Terraform 配置文件", dir)
+	}
+	sort.Strings(files)
+
+	variables := make(map[string]*TemplateVariable)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %w", file, err)
+		}
+		for _, v := range scanVariableBlocks(string(data)) {
+			variables[v.Name] = v
+		}
+	}
+	return variables, nil
+}
+
+// scanVariableBlocks 从 HCL 文本中提取 variable 块
+// 只解析 type / default / description 三个顶层属性，足以用于变量校验和展示
+func scanVariableBlocks(text string) []*TemplateVariable {
+	var variables []*TemplateVariable
+
+	for _, loc := range variableBlockStart.FindAllStringSubmatchIndex(text, -1) {
+		open := loc[1] - 1
+		end := matchingBrace(text, open)
+		if end < 0 {
+			continue
+		}
+
+		variable := &TemplateVariable{Name: text[loc[2]:loc[3]]}
+		for name, value := range topLevelAttributes(text[open+1 : end]) {
+			switch name {
+			case "type":
+				variable.Type = value
+			case "default":
+				variable.Default = unquoteHCL(value)
+				variable.HasDefault = true
+			case "description":
+				variable.Description = unquoteHCL(value)
+			}
+		}
+		variables = append(variables, variable)
+	}
+	return variables
+}
+
+// matchingBrace 返回与 open 处左括号匹配的右括号位置（跳过字符串和注释），未找到时返回 -1
+func matchingBrace(text string, open int) int {
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '"':
+			i = skipString(text, i)
+		case c == '#' || (c == '/' && i+1 < len(text) && text[i+1] == '/'):
+			i = skipLine(text, i)
+		case c == '/' && i+1 < len(text) && text[i+1] == '*':
+			if idx := strings.Index(text[i+2:], "*/"); idx >= 0 {
+				i += idx + 3
+			} else {
+				return -1
+			}
+		case c == '{' || c == '[' || c == '(':
+			depth++
+		case c == '}' || c == ']' || c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// topLevelAttributes 提取块体中的顶层属性（name = value），嵌套块（如 validation）被跳过
+func topLevelAttributes(body string) map[string]string {
+	attributes := make(map[string]string)
+
+	for i := 0; i < len(body); {
+		// 跳过空白和注释
+		c := body[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			i++
+			continue
+		}
+		if c == '#' || (c == '/' && i+1 < len(body) && body[i+1] == '/') {
+			i = skipLine(body, i) + 1
+			continue
+		}
+
+		match := attributeStart.FindStringSubmatchIndex(body[i:])
+		if match == nil {
+			// 嵌套块或无法识别的内容：跳到块结束或行尾
+			if brace := strings.IndexAny(body[i:], "{\n"); brace >= 0 && body[i+brace] == '{' {
+				if end := matchingBrace(body, i+brace); end >= 0 {
+					i = end + 1
+					continue
+				}
+			}
+			i = skipLine(body, i) + 1
+			continue
+		}
+
+		name := body[i+match[2] : i+match[3]]
+		start := i + match[1]
+		end := start
+		depth := 0
+	value:
+		for ; end < len(body); end++ {
+			switch body[end] {
+			case '"':
+				end = skipString(body, end)
+			case '{', '[', '(':
+				depth++
+			case '}', ']', ')':
+				depth--
+			case '\n':
+				if depth <= 0 {
+					break value
+				}
+			}
+		}
+		attributes[name] = strings.TrimSpace(body[start:min(end, len(body))])
+		i = end + 1
+	}
+	return attributes
+}
+
+// skipString 返回从 start 处开始的字符串字面量结束引号的位置
+func skipString(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(text) - 1
+}
+
+// skipLine 返回从 start 开始当前行的行尾位置
+func skipLine(text string, start int) int {
+	if idx := strings.IndexByte(text[start:], '\n'); idx >= 0 {
+		return start + idx
+	}
+	return len(text) - 1
+}
+
+// unquoteHCL 去掉简单字符串字面量的引号，其他表达式原样返回
+func unquoteHCL(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+	}
+	return value
+}
+
+// SetProjectVars 设置项目级 Terraform 变量（对项目下所有声明了该变量的场景生效）
+func (s *projectService) SetProjectVars(ctx context.Context, projectName string, values map[string]interface{}, unset []string) error {
+	log := logger.GetLogger()
+
+	scenarios, err := s.projectRepo.ListScenarios(projectName)
+	if err != nil {
+		return err
+	}
+
+	// 项目级变量只需被某个场景的模板声明；尚未声明的变量会在部署时被忽略
+	for name, value := range values {
+		if isCredentialVar(name) {
+			return fmt.Errorf("变量 %s 为凭据变量，请使用 'credential' 命令配置", name)
+		}
+		declared := false
+		for _, scenario := range scenarios {
+			variables, err := parseTemplateVariables(scenario.Path)
+			if err != nil {
+				continue
+			}
+			if variable, ok := variables[name]; ok {
+				if err := checkVarType(variable, value); err != nil {
+					return fmt.Errorf("场景 %s: %w", scenario.ID, err)
+				}
+				declared = true
+			}
+		}
+		if !declared && len(scenarios) > 0 {
+			log.Warn("项目 %s 中没有场景的模板声明变量 %s，部署时将被忽略", projectName, name)
+		}
+	}
+
+	return s.projectRepo.UpdateProjectVars(projectName, func(vars map[string]interface{}) {
+		mergeVars(vars, values, unset)
+	})
+}
+
+// SetScenarioVars 设置场景级 Terraform 变量（必须是场景模板中声明的变量）
+func (s *projectService) SetScenarioVars(ctx context.Context, projectName, scenarioID string, values map[string]interface{}, unset []string) error {
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return err
+	}
+
+	variables, err := parseTemplateVariables(scenario.Path)
+	if err != nil {
+		return fmt.Errorf("解析场景模板变量失败: %w", err)
+	}
+	for name, value := range values {
+		if isCredentialVar(name) {
+			return fmt.Errorf("变量 %s 为凭据变量，请使用 'credential' 命令配置", name)
+		}
+		variable, ok := variables[name]
+		if !ok {
+			return fmt.Errorf("场景模板 %s 未声明变量 %s，可用变量: %s", scenario.Template, name, strings.Join(sortedVarNames(variables), ", "))
+		}
+		if err := checkVarType(variable, value); err != nil {
+			return err
+		}
+	}
+
+	return s.projectRepo.UpdateScenarioVars(projectName, scenarioID, func(vars map[string]interface{}) {
+		mergeVars(vars, values, unset)
+	})
+}
+
+// mergeVars 设置 values 中的变量并删除 unset 中的变量
+func mergeVars(vars, values map[string]interface{}, unset []string) {
+	for name, value := range values {
+		vars[name] = value
+	}
+	for _, name := range unset {
+		delete(vars, name)
+	}
+}
+
+// GetScenarioVars 获取场景模板声明的所有变量及其生效值
+// 优先级：部署时命令行参数 > 场景变量 > 项目变量 > 模板默认值（命令行参数只在部署时确定，不在此列出）
+func (s *projectService) GetScenarioVars(ctx context.Context, projectName, scenarioID string) ([]EffectiveVar, error) {
+	scenario, err := s.projectRepo.GetScenario(projectName, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := parseTemplateVariables(scenario.Path)
+	if err != nil {
+		return nil, fmt.Errorf("解析场景模板变量失败: %w", err)
+	}
+	projectVars, err := s.projectRepo.GetProjectVars(projectName)
+	if err != nil {
+		return nil, err
+	}
+	scenarioVars, err := s.projectRepo.GetScenarioVars(projectName, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []EffectiveVar
+	for _, name := range sortedVarNames(variables) {
+		variable := variables[name]
+		effective := EffectiveVar{Name: name, Type: variable.Type, Description: variable.Description}
+		if value, ok := scenarioVars[name]; ok {
+			effective.Value, effective.Source = formatVarValue(value), VarSourceScenario
+		} else if value, ok := projectVars[name]; ok {
+			effective.Value, effective.Source = formatVarValue(value), VarSourceProject
+		} else if variable.HasDefault {
+			effective.Value, effective.Source = variable.Default, VarSourceDefault
+		}
+		result = append(result, effective)
+	}
+	return result, nil
+}
+
+// applyUserVars 将项目级和场景级变量合并到 vars 中（场景变量覆盖项目变量）
+// 调用方应在自动注入的变量（凭据默认区域等）之后、命令行参数对应的变量之前调用，以保证优先级：
+// 命令行参数 > 场景变量 > 项目变量 > 模板默认值
+func (s *projectService) applyUserVars(projectName string, scenario *domain.Scenario, vars map[string]string) error {
+	log := logger.GetLogger()
+
+	projectVars, err := s.projectRepo.GetProjectVars(projectName)
+	if err != nil {
+		return err
+	}
+	scenarioVars, err := s.projectRepo.GetScenarioVars(projectName, scenario.ID)
+	if err != nil {
+		return err
+	}
+	if len(projectVars) == 0 && len(scenarioVars) == 0 {
+		return nil
+	}
+
+	variables, err := parseTemplateVariables(scenario.Path)
+	if err != nil {
+		return fmt.Errorf("解析场景模板变量失败: %w", err)
+	}
+
+	for name, value := range projectVars {
+		variable, ok := variables[name]
+		if !ok {
+			log.Debug("场景模板未声明项目变量 %s，已忽略: scenario=%s", name, scenario.ID)
+			continue
+		}
+		if err := checkVarType(variable, value); err != nil {
+			return fmt.Errorf("项目变量校验失败: %w", err)
+		}
+		vars[name] = formatVarValue(value)
+	}
+	for name, value := range scenarioVars {
+		variable, ok := variables[name]
+		if !ok {
+			return fmt.Errorf("场景变量 %s 未在模板 %s 中声明", name, scenario.Template)
+		}
+		if err := checkVarType(variable, value); err != nil {
+			return fmt.Errorf("场景变量校验失败: %w", err)
+		}
+		vars[name] = formatVarValue(value)
+	}
+	return nil
+}
+
+// sortedVarNames 返回按名称排序的变量名列表
+func sortedVarNames(variables map[string]*TemplateVariable) []string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
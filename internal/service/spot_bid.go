@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// 出价策略类型
+const (
+	BidStrategyP95      = "p95"      // 最近 7 天历史价格的 P95 加上浮比例
+	BidStrategyOnDemand = "ondemand" // 按量付费价格的百分比
+	BidStrategyFixed    = "fixed"    // 固定出价
+)
+
+const (
+	// spotHistoryWindow 计算 P95 时使用的历史价格时间窗口
+	spotHistoryWindow = 7 * 24 * time.Hour
+	// defaultP95Margin P95 策略默认上浮比例（%）
+	defaultP95Margin = 10
+	// defaultOnDemandPercent 按量付费比例策略默认百分比（%）
+	defaultOnDemandPercent = 100
+)
+
+// BidStrategy 抢占式实例出价策略
+// 字符串格式: p95[:上浮%]、ondemand[:百分比]、fixed:<每小时价格>
+type BidStrategy struct {
+	Kind  string  // 策略类型
+	Value float64 // 策略参数（上浮比例、百分比或固定价格）
+}
+
+// String 返回策略的字符串形式
+func (b *BidStrategy) String() string {
+	return fmt.Sprintf("%s:%s", b.Kind, strconv.FormatFloat(b.Value, 'f', -1, 64))
+}
+
+// ParseBidStrategy 解析出价策略字符串
+func ParseBidStrategy(value string) (*BidStrategy, error) {
+	kind, arg, hasArg := strings.Cut(strings.ToLower(strings.TrimSpace(value)), ":")
+
+	strategy := &BidStrategy{Kind: kind}
+	switch kind {
+	case BidStrategyP95:
+		strategy.Value = defaultP95Margin
+	case BidStrategyOnDemand:
+		strategy.Value = defaultOnDemandPercent
+	case BidStrategyFixed:
+		if !hasArg {
+			return nil, fmt.Errorf("fixed 出价策略需要指定价格，如 fixed:0.05")
+		}
+	default:
+		return nil, fmt.Errorf("无效的出价策略: %s，支持: p95[:上浮%%], ondemand[:百分比], fixed:<价格>", value)
+	}
+
+	if hasArg {
+		v, err := strconv.ParseFloat(strings.TrimPrefix(arg, "+"), 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("无效的出价策略参数: %s", value)
+		}
+		strategy.Value = v
+	}
+	if (kind == BidStrategyOnDemand || kind == BidStrategyFixed) && strategy.Value <= 0 {
+		return nil, fmt.Errorf("出价策略参数必须大于 0: %s", value)
+	}
+
+	return strategy, nil
+}
+
+// SpotBidCalculator 抢占式实例出价计算器
+type SpotBidCalculator interface {
+	// CalculatePriceLimit 根据出价策略计算抢占式实例出价上限（每小时价格）
+	CalculatePriceLimit(ctx context.Context, provider, region, instanceType string, strategy *BidStrategy) (float64, error)
+}
+
+// spotBidCalculator 出价计算器实现
+type spotBidCalculator struct {
+	credManager credentials.CredentialManager
+}
+
+// NewSpotBidCalculator 创建抢占式实例出价计算器
+func NewSpotBidCalculator(credManager credentials.CredentialManager) SpotBidCalculator {
+	return &spotBidCalculator{credManager: credManager}
+}
+
+// CalculatePriceLimit 计算出价上限
+func (c *spotBidCalculator) CalculatePriceLimit(ctx context.Context, provider, region, instanceType string, strategy *BidStrategy) (float64, error) {
+	if strategy.Kind == BidStrategyFixed {
+		return strategy.Value, nil
+	}
+
+	// 使用 context 中绑定的 profile（项目级凭据）查询价格
+	client, err := newClientFromCredentials(c.credManager.WithProfile(credentials.ProfileFromContext(ctx)), provider)
+	if err != nil {
+		return 0, err
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, optimizerCallTimeout)
+	defer cancel()
+
+	switch strategy.Kind {
+	case BidStrategyOnDemand:
+		price, err := client.GetInstancePrice(callCtx, region, instanceType)
+		if err != nil {
+			return 0, fmt.Errorf("查询按量付费价格失败: %w", err)
+		}
+		return roundPrice(price.PricePerHour * strategy.Value / 100), nil
+
+	case BidStrategyP95:
+		historyProvider, ok := client.(SpotPriceHistoryProvider)
+		if !ok {
+			return 0, fmt.Errorf("%s 暂不支持查询抢占式实例历史价格，请使用 ondemand 或 fixed 出价策略", provider)
+		}
+
+		points, err := historyProvider.GetSpotPriceHistory(callCtx, region, instanceType, time.Now().Add(-spotHistoryWindow))
+		if err != nil {
+			return 0, fmt.Errorf("查询抢占式实例历史价格失败: %w", err)
+		}
+
+		var prices []float64
+		var onDemand float64
+		for _, p := range points {
+			if p.Price > 0 {
+				prices = append(prices, p.Price)
+			}
+			onDemand = math.Max(onDemand, p.OriginPrice)
+		}
+		if len(prices) == 0 {
+			return 0, fmt.Errorf("区域 %s 最近 7 天没有 %s 的抢占式实例价格", region, instanceType)
+		}
+
+		limit := percentile(prices, 95) * (1 + strategy.Value/100)
+		// 出价高于按量付费价格没有意义，以按量付费价格封顶
+		if onDemand > 0 && limit > onDemand {
+			limit = onDemand
+		}
+		return roundPrice(limit), nil
+	}
+
+	return 0, fmt.Errorf("无效的出价策略: %s", strategy.Kind)
+}
+
+// percentile 计算百分位数（最近秩法）
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// roundPrice 价格保留 4 位小数（向上取整，避免出价略低于目标值）
+func roundPrice(price float64) float64 {
+	return math.Ceil(price*10000) / 10000
+}
+
+// applySpotPriceLimit 按出价策略计算出价上限并写入 Terraform 变量和场景元数据
+func (s *projectService) applySpotPriceLimit(ctx context.Context, scenario *domain.Scenario, vars map[string]string, bidStrategy string) error {
+	log := logger.GetLogger()
+
+	strategy, err := ParseBidStrategy(bidStrategy)
+	if err != nil {
+		return err
+	}
+
+	if !templateDeclaresVariable(scenario.Path, "spot_price_limit") {
+		return fmt.Errorf("场景模板未声明 spot_price_limit 变量，无法设置抢占式实例出价上限")
+	}
+
+	provider := strings.SplitN(scenario.Template, "/", 2)[0]
+	region := vars["region"]
+	if region == "" {
+		region = readTerraformValue(scenario.Path, "region")
+	}
+	instanceType := vars["instance_type"]
+	if instanceType == "" {
+		instanceType = readTerraformValue(scenario.Path, "instance_type")
+	}
+	if strategy.Kind != BidStrategyFixed && (region == "" || instanceType == "") {
+		return fmt.Errorf("无法确定场景的区域和实例类型，请在 terraform.tfvars 中设置 region 和 instance_type")
+	}
+
+	limit, err := s.bidCalculator.CalculatePriceLimit(ctx, provider, region, instanceType, strategy)
+	if err != nil {
+		return fmt.Errorf("计算抢占式实例出价上限失败: %w", err)
+	}
+
+	vars["spot_price_limit"] = strconv.FormatFloat(limit, 'f', 4, 64)
+	scenario.BidStrategy = strategy.String()
+	scenario.SpotPriceLimit = limit
+
+	log.Info("抢占式实例出价上限: strategy=%s, region=%s, instance_type=%s, limit=%.4f",
+		scenario.BidStrategy, region, instanceType, limit)
+	return nil
+}
+
+// applyBidStrategy 按部署时指定的出价策略设置出价上限；未指定时清除之前部署记录的出价策略和出价上限
+func (s *projectService) applyBidStrategy(ctx context.Context, scenario *domain.Scenario, vars map[string]string, bidStrategy string) error {
+	if bidStrategy == "" {
+		scenario.BidStrategy = ""
+		scenario.SpotPriceLimit = 0
+		return nil
+	}
+	return s.applySpotPriceLimit(ctx, scenario, vars, bidStrategy)
+}
+
+// refreshSpotPriceLimit 更换部署区域后按场景的出价策略重新计算出价上限（出价上限基于原区域的价格，不能沿用）
+func (s *projectService) refreshSpotPriceLimit(ctx context.Context, scenario *domain.Scenario, vars map[string]string) error {
+	if scenario.BidStrategy == "" {
+		return nil
+	}
+	return s.applySpotPriceLimit(ctx, scenario, vars, scenario.BidStrategy)
+}
+
+// templateDeclaresVariable 检查场景目录中的 Terraform 配置是否声明了指定变量
+func templateDeclaresVariable(dir, name string) bool {
+	pattern := regexp.MustCompile(`variable\s+"` + regexp.QuoteMeta(name) + `"\s*\{`)
+	return pattern.MatchString(readTerraformFiles(dir))
+}
+
+// spotTemplatePattern 模板中表示抢占式实例的写法（阿里云 spot_strategy、腾讯云 SPOTPAID、
+// 华为云 charging_mode = "spot"、AWS instance_market_options 或 aws_spot_instance_request）
+var spotTemplatePattern = regexp.MustCompile(`spot_strategy|SPOTPAID|charging_mode\s*=\s*"spot"|instance_market_options|aws_spot_instance_request`)
+
+// TemplateUsesSpot 判断场景目录中的 Terraform 配置是否使用抢占式实例（价格优化据此选择计费方式）
+func TemplateUsesSpot(dir string) bool {
+	return spotTemplatePattern.MatchString(readTerraformFiles(dir))
+}
+
+// readTerraformValue 读取场景中变量的取值
+// 依次查找 terraform.tfvars 中的赋值、variable 块中的默认值和 .tf 文件中的字面量属性
+func readTerraformValue(dir, name string) string {
+	quoted := regexp.QuoteMeta(name)
+	assign := regexp.MustCompile(`(?m)^\s*` + quoted + `\s*=\s*"([^"$]+)"`)
+
+	if data, err := os.ReadFile(filepath.Join(dir, "terraform.tfvars")); err == nil {
+		if m := assign.FindStringSubmatch(string(data)); m != nil {
+			return m[1]
+		}
+	}
+
+	content := readTerraformFiles(dir)
+	defaultValue := regexp.MustCompile(`variable\s+"` + quoted + `"\s*\{[^}]*?default\s*=\s*"([^"]+)"`)
+	if m := defaultValue.FindStringSubmatch(content); m != nil {
+		return m[1]
+	}
+	if m := assign.FindStringSubmatch(content); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// readTerraformFiles 读取目录下所有 .tf 文件内容
+func readTerraformFiles(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.tf"))
+	var content strings.Builder
+	for _, f := range files {
+		if data, err := os.ReadFile(f); err == nil {
+			content.Write(data)
+			content.WriteString("\n")
+		}
+	}
+	return content.String()
+}
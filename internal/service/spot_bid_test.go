@@ -0,0 +1,76 @@
+package service
+
+import "testing"
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{name: "单个值", values: []float64{0.5}, p: 95, want: 0.5},
+		{name: "无序输入", values: []float64{0.3, 0.1, 0.2}, p: 50, want: 0.2},
+		{name: "最近秩向上取整", values: []float64{1, 2, 3, 4}, p: 50, want: 2},
+		{name: "P95 取第 19 个", values: []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, p: 95, want: 19},
+		{name: "P95 小样本取最大值", values: []float64{0.1, 0.2, 0.3}, p: 95, want: 0.3},
+		{name: "P0 取最小值", values: []float64{3, 1, 2}, p: 0, want: 1},
+		{name: "P100 取最大值", values: []float64{3, 1, 2}, p: 100, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.values, tt.p); got != tt.want {
+				t.Errorf("percentile(%v, %v) = %v，期望 %v", tt.values, tt.p, got, tt.want)
+			}
+		})
+	}
+
+	// 不修改调用方的切片
+	values := []float64{3, 1, 2}
+	percentile(values, 50)
+	if values[0] != 3 || values[1] != 1 || values[2] != 2 {
+		t.Errorf("percentile 修改了输入切片: %v", values)
+	}
+}
+
+func TestParseBidStrategy(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantKind  string
+		wantValue float64
+		wantErr   bool
+	}{
+		{value: "p95", wantKind: BidStrategyP95, wantValue: defaultP95Margin},
+		{value: "p95:20", wantKind: BidStrategyP95, wantValue: 20},
+		{value: "p95:+15", wantKind: BidStrategyP95, wantValue: 15},
+		{value: "p95:0", wantKind: BidStrategyP95, wantValue: 0},
+		{value: " P95:5 ", wantKind: BidStrategyP95, wantValue: 5},
+		{value: "ondemand", wantKind: BidStrategyOnDemand, wantValue: defaultOnDemandPercent},
+		{value: "ondemand:80", wantKind: BidStrategyOnDemand, wantValue: 80},
+		{value: "ondemand:0", wantErr: true},
+		{value: "fixed:0.05", wantKind: BidStrategyFixed, wantValue: 0.05},
+		{value: "fixed", wantErr: true},
+		{value: "fixed:0", wantErr: true},
+		{value: "fixed:abc", wantErr: true},
+		{value: "p95:-5", wantErr: true},
+		{value: "max", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseBidStrategy(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("期望返回错误，实际为 %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if got.Kind != tt.wantKind || got.Value != tt.wantValue {
+				t.Errorf("解析结果 = %s，期望 %s:%v", got, tt.wantKind, tt.wantValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+)
+
+// STS 服务地址
+const (
+	aliyunSTSEndpoint = "https://sts.aliyuncs.com"
+	tencentSTSHost    = "sts.tencentcloudapi.com"
+	awsSTSEndpoint    = "https://sts.amazonaws.com/"
+)
+
+// stsRoleAssumer 通过各云服务商 STS AssumeRole 获取临时凭据
+type stsRoleAssumer struct{}
+
+// NewRoleAssumer 创建 STS AssumeRole 调用实现（支持阿里云、腾讯云、AWS）
+func NewRoleAssumer() credentials.RoleAssumer {
+	return &stsRoleAssumer{}
+}
+
+// AssumeRole 使用基础身份扮演角色，返回临时凭据
+func (a *stsRoleAssumer) AssumeRole(ctx context.Context, provider credentials.Provider, base *credentials.Credentials, role *credentials.RoleConfig) (*credentials.Credentials, error) {
+	switch provider {
+	case credentials.ProviderAliyun:
+		return a.assumeAliyunRole(ctx, base, role)
+	case credentials.ProviderTencent:
+		return a.assumeTencentRole(ctx, base, role)
+	case credentials.ProviderAWS:
+		return a.assumeAWSRole(ctx, base, role)
+	default:
+		return nil, fmt.Errorf("%s 暂不支持 AssumeRole", provider)
+	}
+}
+
+// assumeAliyunRole 调用阿里云 STS AssumeRole
+// API 文档: https://help.aliyun.com/document_detail/371864.html
+func (a *stsRoleAssumer) assumeAliyunRole(ctx context.Context, base *credentials.Credentials, role *credentials.RoleConfig) (*credentials.Credentials, error) {
+	client := &aliyunClient{
+		accessKey:    base.AccessKey,
+		secretKey:    base.SecretKey,
+		sessionToken: base.SessionToken,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+	}
+
+	params := map[string]string{
+		"Action":          "AssumeRole",
+		"Version":         "2015-04-01",
+		"RoleArn":         role.RoleARN,
+		"RoleSessionName": role.SessionName,
+		"DurationSeconds": strconv.Itoa(role.DurationSeconds),
+	}
+	if role.ExternalID != "" {
+		params["ExternalId"] = role.ExternalID
+	}
+
+	body, err := client.callAPI(ctx, aliyunSTSEndpoint, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Credentials struct {
+			AccessKeyId     string `json:"AccessKeyId"`
+			AccessKeySecret string `json:"AccessKeySecret"`
+			SecurityToken   string `json:"SecurityToken"`
+			Expiration      string `json:"Expiration"`
+		} `json:"Credentials"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	expiration, err := time.Parse(time.RFC3339, response.Credentials.Expiration)
+	if err != nil {
+		return nil, fmt.Errorf("解析过期时间失败: %w", err)
+	}
+
+	return &credentials.Credentials{
+		AccessKey:    response.Credentials.AccessKeyId,
+		SecretKey:    response.Credentials.AccessKeySecret,
+		SessionToken: response.Credentials.SecurityToken,
+		Expiration:   expiration,
+	}, nil
+}
+
+// assumeTencentRole 调用腾讯云 STS AssumeRole
+// API 文档: https://cloud.tencent.com/document/api/1312/48197
+func (a *stsRoleAssumer) assumeTencentRole(ctx context.Context, base *credentials.Credentials, role *credentials.RoleConfig) (*credentials.Credentials, error) {
+	client := &tencentClient{
+		accessKey:    base.AccessKey,
+		secretKey:    base.SecretKey,
+		sessionToken: base.SessionToken,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+	}
+
+	// STS 需要指定地域，优先使用角色配置的区域
+	region := role.Region
+	if region == "" {
+		region = base.Region
+	}
+	if region == "" {
+		region = "ap-guangzhou"
+	}
+
+	params := map[string]interface{}{
+		"RoleArn":         role.RoleARN,
+		"RoleSessionName": role.SessionName,
+		"DurationSeconds": role.DurationSeconds,
+	}
+	if role.ExternalID != "" {
+		params["ExternalId"] = role.ExternalID
+	}
+
+	body, err := client.callService(ctx, tencentSTSHost, "sts", "2018-08-13", "AssumeRole", region, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Response struct {
+			Credentials struct {
+				Token        string `json:"Token"`
+				TmpSecretId  string `json:"TmpSecretId"`
+				TmpSecretKey string `json:"TmpSecretKey"`
+			} `json:"Credentials"`
+			ExpiredTime int64 `json:"ExpiredTime"`
+		} `json:"Response"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	return &credentials.Credentials{
+		AccessKey:    response.Response.Credentials.TmpSecretId,
+		SecretKey:    response.Response.Credentials.TmpSecretKey,
+		SessionToken: response.Response.Credentials.Token,
+		Expiration:   time.Unix(response.Response.ExpiredTime, 0),
+	}, nil
+}
+
+// assumeAWSRole 调用 AWS STS AssumeRole
+// API 文档: https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRole.html
+func (a *stsRoleAssumer) assumeAWSRole(ctx context.Context, base *credentials.Credentials, role *credentials.RoleConfig) (*credentials.Credentials, error) {
+	client := &awsClient{
+		accessKey:    base.AccessKey,
+		secretKey:    base.SecretKey,
+		sessionToken: base.SessionToken,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+	}
+
+	query := url.Values{}
+	query.Set("Action", "AssumeRole")
+	query.Set("Version", "2011-06-15")
+	query.Set("RoleArn", role.RoleARN)
+	query.Set("RoleSessionName", role.SessionName)
+	query.Set("DurationSeconds", strconv.Itoa(role.DurationSeconds))
+	if role.ExternalID != "" {
+		query.Set("ExternalId", role.ExternalID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", awsSTSEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	// 全局 STS 端点使用 us-east-1 签名
+	body, err := client.doSigned(req, nil, "us-east-1", "sts")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Credentials struct {
+			AccessKeyId     string `xml:"AccessKeyId"`
+			SecretAccessKey string `xml:"SecretAccessKey"`
+			SessionToken    string `xml:"SessionToken"`
+			Expiration      string `xml:"Expiration"`
+		} `xml:"AssumeRoleResult>Credentials"`
+	}
+	if err := xml.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	expiration, err := time.Parse(time.RFC3339, response.Credentials.Expiration)
+	if err != nil {
+		return nil, fmt.Errorf("解析过期时间失败: %w", err)
+	}
+
+	return &credentials.Credentials{
+		AccessKey:    response.Credentials.AccessKeyId,
+		SecretKey:    response.Credentials.SecretAccessKey,
+		SessionToken: response.Credentials.SessionToken,
+		Expiration:   expiration,
+	}, nil
+}
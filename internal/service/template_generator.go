@@ -27,6 +27,24 @@ type templateGenerator struct {
 	credManager credentials.CredentialManager
 }
 
+// aliyunVersionsTf 阿里云动态模板的 versions.tf
+// cloudbot providers mirror 也从这里收集动态模板使用的 provider
+const aliyunVersionsTf = `terraform {
+  required_version = ">= 1.0"
+  
+  required_providers {
+    alicloud = {
+      source  = "aliyun/alicloud"
+      version = "~> 1.200"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "~> 3.1"
+    }
+  }
+}
+`
+
 // NewTemplateGenerator 创建模板生成器
 func NewTemplateGenerator(credManager credentials.CredentialManager) TemplateGenerator {
 	return &templateGenerator{
@@ -88,17 +106,7 @@ func (g *templateGenerator) GenerateTemplate(ctx context.Context, scenario, prov
 
 // getProviderClient 获取云服务商客户端
 func (g *templateGenerator) getProviderClient(provider string) (CloudProviderClient, error) {
-	providerEnum := credentials.Provider(provider)
-	if !g.credManager.HasCredentials(providerEnum) {
-		return nil, fmt.Errorf("未配置 %s 的凭据", provider)
-	}
-
-	creds, err := g.credManager.GetCredentials(providerEnum)
-	if err != nil {
-		return nil, fmt.Errorf("获取 %s 凭据失败: %w", provider, err)
-	}
-
-	return NewCloudProviderClient(provider, creds.AccessKey, creds.SecretKey)
+	return newClientFromCredentials(g.credManager, provider)
 }
 
 // generateProxyTemplate 生成代理场景模板
@@ -166,25 +174,20 @@ func (g *templateGenerator) generateAliyunProxyTemplate(tempDir, region, instanc
 	}
 
 	// 生成 versions.tf
-	versionsTfContent := `terraform {
-  required_version = ">= 1.0"
-  
-  required_providers {
-    alicloud = {
-      source  = "aliyun/alicloud"
-      version = "~> 1.200"
-    }
-    random = {
-      source  = "hashicorp/random"
-      version = "~> 3.1"
-    }
-  }
-}
-`
-	if err := os.WriteFile(filepath.Join(tempDir, "versions.tf"), []byte(versionsTfContent), 0644); err != nil {
+	if err := os.WriteFile(filepath.Join(tempDir, "versions.tf"), []byte(aliyunVersionsTf), 0644); err != nil {
 		return "", fmt.Errorf("写入 versions.tf 失败: %w", err)
 	}
 
+	// 生成 tags.tf（资源标签，用于 cloudbot gc 识别孤立资源）
+	if err := os.WriteFile(filepath.Join(tempDir, "tags.tf"), []byte(resourceTagsTf), 0644); err != nil {
+		return "", fmt.Errorf("写入 tags.tf 失败: %w", err)
+	}
+
+	// 生成 manifest.json（节点序号与资源地址的对应关系，用于 scenario replace）
+	if err := writeTemplateManifest(tempDir, &TemplateManifest{NodeResource: "alicloud_instance.instance"}); err != nil {
+		return "", err
+	}
+
 	// 生成 outputs.tf
 	outputsTfContent := `output "public_ips" {
   value = alicloud_instance.instance[*].public_ip
@@ -233,6 +236,18 @@ variable "enable_spot" {
   default     = true
 }
 
+variable "spot_price_limit" {
+  type        = number
+  description = "抢占式实例最高出价（0 表示不设置上限）"
+  default     = 0
+}
+
+variable "zone" {
+  type        = string
+  description = "可用区（价格优化选择的有库存可用区，为空时自动选择）"
+  default     = ""
+}
+
 resource "random_integer" "ss_port" {
   min = 20000
   max = 40000
@@ -258,7 +273,7 @@ locals {
   effective_node_count = var.node_count > 0 ? var.node_count : {{.NodeCount}}
   effective_ss_port    = var.ss_port != "" ? var.ss_port : tostring(random_integer.ss_port.result)
   effective_ss_pass    = var.ss_pass != "" ? var.ss_pass : random_password.ss_pass.result
-  selected_zone        = data.alicloud_zones.default.zones[0].id
+  selected_zone        = var.zone != "" ? var.zone : data.alicloud_zones.default.zones[0].id
 }
 
 resource "alicloud_instance" "instance" {
@@ -273,7 +288,8 @@ resource "alicloud_instance" "instance" {
   password                   = random_password.password.result
   instance_charge_type       = "PostPaid"
   spot_strategy              = var.enable_spot ? "SpotWithPriceLimit" : "NoSpot"
-  spot_price_limit           = var.enable_spot ? 0 : 0
+  spot_price_limit           = var.enable_spot ? var.spot_price_limit : 0
+  tags                       = local.cloudbot_tags
   
   user_data = <<EOF
 #!/bin/bash
@@ -307,6 +323,7 @@ EOF
 resource "alicloud_security_group" "group" {
   security_group_name = "proxy_security_group"
   vpc_id              = alicloud_vpc.vpc.id
+  tags                = local.cloudbot_tags
 }
 
 resource "alicloud_security_group_rule" "allow_all_tcp" {
@@ -336,6 +353,7 @@ resource "alicloud_security_group_rule" "allow_all_udp" {
 resource "alicloud_vpc" "vpc" {
   vpc_name   = "proxy_vpc"
   cidr_block = "172.16.0.0/16"
+  tags       = local.cloudbot_tags
 }
 
 resource "alicloud_vswitch" "vswitch" {
@@ -343,6 +361,7 @@ resource "alicloud_vswitch" "vswitch" {
   cidr_block   = "172.16.0.0/24"
   zone_id      = local.selected_zone
   vswitch_name = "proxy_vswitch"
+  tags         = local.cloudbot_tags
 }
 `
 
@@ -377,25 +396,20 @@ func (g *templateGenerator) generateAliyunTaskExecutorTemplate(tempDir, region,
 	}
 
 	// 生成 versions.tf
-	versionsTfContent := `terraform {
-  required_version = ">= 1.0"
-  
-  required_providers {
-    alicloud = {
-      source  = "aliyun/alicloud"
-      version = "~> 1.200"
-    }
-    random = {
-      source  = "hashicorp/random"
-      version = "~> 3.1"
-    }
-  }
-}
-`
-	if err := os.WriteFile(filepath.Join(tempDir, "versions.tf"), []byte(versionsTfContent), 0644); err != nil {
+	if err := os.WriteFile(filepath.Join(tempDir, "versions.tf"), []byte(aliyunVersionsTf), 0644); err != nil {
 		return "", fmt.Errorf("写入 versions.tf 失败: %w", err)
 	}
 
+	// 生成 tags.tf（资源标签，用于 cloudbot gc 识别孤立资源）
+	if err := os.WriteFile(filepath.Join(tempDir, "tags.tf"), []byte(resourceTagsTf), 0644); err != nil {
+		return "", fmt.Errorf("写入 tags.tf 失败: %w", err)
+	}
+
+	// 生成 manifest.json（节点序号与资源地址的对应关系，用于 scenario replace）
+	if err := writeTemplateManifest(tempDir, &TemplateManifest{NodeResource: "alicloud_instance.instance"}); err != nil {
+		return "", err
+	}
+
 	// 生成 variables.tf
 	variablesTfContent := `variable "instance_type" {
   type        = string
@@ -444,6 +458,12 @@ variable "result_path" {
   description = "结果存储路径"
   default     = ""
 }
+
+variable "zone" {
+  type        = string
+  description = "可用区（价格优化选择的有库存可用区，为空时自动选择）"
+  default     = ""
+}
 `
 	t := template.Must(template.New("variables.tf").Parse(variablesTfContent))
 	var buf strings.Builder
@@ -503,6 +523,7 @@ locals {
 resource "alicloud_vpc" "vpc" {
   vpc_name   = "${local.instance_name}-vpc"
   cidr_block = "172.16.0.0/16"
+  tags       = local.cloudbot_tags
 }
 
 data "alicloud_zones" "with_instance_type" {
@@ -516,7 +537,7 @@ data "alicloud_zones" "default" {
 
 locals {
   zones         = length(data.alicloud_zones.with_instance_type.zones) > 0 ? data.alicloud_zones.with_instance_type.zones : data.alicloud_zones.default.zones
-  selected_zone = length(local.zones) > 0 ? local.zones[0].id : ""
+  selected_zone = var.zone != "" ? var.zone : (length(local.zones) > 0 ? local.zones[0].id : "")
 }
 
 resource "alicloud_vswitch" "vswitch" {
@@ -524,11 +545,13 @@ resource "alicloud_vswitch" "vswitch" {
   cidr_block   = "172.16.0.0/24"
   zone_id      = local.selected_zone
   vswitch_name = "${local.instance_name}-vsw"
+  tags         = local.cloudbot_tags
 }
 
 resource "alicloud_security_group" "group" {
   security_group_name = "${local.instance_name}-sg"
   vpc_id              = alicloud_vpc.vpc.id
+  tags                = local.cloudbot_tags
 }
 
 resource "alicloud_security_group_rule" "allow_ssh" {
@@ -566,6 +589,7 @@ resource "alicloud_instance" "instance" {
   instance_charge_type       = "PostPaid"
   spot_strategy              = var.spot_strategy
   spot_price_limit           = var.spot_price_limit
+  tags                       = local.cloudbot_tags
 
   user_data = <<EOF
 #!/bin/bash
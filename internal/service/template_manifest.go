@@ -0,0 +1,47 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// templateManifestFile 模板清单文件名（与模板文件一起复制到场景目录）
+const templateManifestFile = "manifest.json"
+
+// TemplateManifest 模板清单，声明 cloudbot 需要了解的模板结构
+type TemplateManifest struct {
+	// NodeResource 表示节点的云主机资源地址（不含序号），如 alicloud_instance.instance
+	// 节点序号 n 对应 alicloud_instance.instance[n]，资源没有使用 count 时节点 0 对应资源本身
+	NodeResource string `json:"node_resource,omitempty"`
+}
+
+// readTemplateManifest 读取场景或模板目录中的清单，文件不存在时返回空清单
+func readTemplateManifest(dir string) (*TemplateManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, templateManifestFile))
+	if os.IsNotExist(err) {
+		return &TemplateManifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取模板清单失败: %w", err)
+	}
+
+	var manifest TemplateManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析模板清单 %s 失败: %w", templateManifestFile, err)
+	}
+	return &manifest, nil
+}
+
+// writeTemplateManifest 将清单写入模板目录（动态生成的模板使用）
+func writeTemplateManifest(dir string, manifest *TemplateManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, templateManifestFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", templateManifestFile, err)
+	}
+	return nil
+}
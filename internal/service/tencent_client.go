@@ -1,17 +1,32 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 )
 
+// tencentCVMHost 腾讯云 CVM API 地址
+const tencentCVMHost = "cvm.tencentcloudapi.com"
+
 // tencentClient 腾讯云客户端实现
 type tencentClient struct {
-	accessKey  string
-	secretKey  string
-	httpClient *http.Client
+	accessKey    string
+	secretKey    string
+	sessionToken string // 临时凭据的安全令牌（可选）
+	httpClient   *http.Client
+
+	cacheMu    sync.Mutex
+	zoneCache  map[string][]string // region -> 可用区
+	imageCache map[string]string   // region -> 询价使用的公共镜像
 }
 
 // NewTencentClient 创建腾讯云客户端
@@ -20,6 +35,8 @@ func NewTencentClient(accessKey, secretKey string) (CloudProviderClient, error)
 		accessKey:  accessKey,
 		secretKey:  secretKey,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		zoneCache:  make(map[string][]string),
+		imageCache: make(map[string]string),
 	}, nil
 }
 
@@ -29,37 +46,445 @@ func (c *tencentClient) Provider() string {
 }
 
 // GetAvailableRegions 获取可用区域列表
+// 使用 DescribeRegions API: https://cloud.tencent.com/document/api/213/15708
 func (c *tencentClient) GetAvailableRegions(ctx context.Context) ([]Region, error) {
-	// 腾讯云常用区域列表
-	regions := []Region{
-		{ID: "ap-shanghai", Name: "上海", DisplayName: "上海 (ap-shanghai)", Available: true},
-		{ID: "ap-nanjing", Name: "南京", DisplayName: "南京 (ap-nanjing)", Available: true},
-		{ID: "ap-guangzhou", Name: "广州", DisplayName: "广州 (ap-guangzhou)", Available: true},
-		{ID: "ap-beijing", Name: "北京", DisplayName: "北京 (ap-beijing)", Available: true},
-		{ID: "ap-chengdu", Name: "成都", DisplayName: "成都 (ap-chengdu)", Available: true},
-		{ID: "ap-chongqing", Name: "重庆", DisplayName: "重庆 (ap-chongqing)", Available: true},
-	}
-	
-	// TODO: 实现腾讯云API调用获取实时区域列表
+	response, err := c.callAPI(ctx, "DescribeRegions", "ap-guangzhou", map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("调用 DescribeRegions API 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Response struct {
+			RegionSet []struct {
+				Region      string `json:"Region"`
+				RegionName  string `json:"RegionName"`
+				RegionState string `json:"RegionState"`
+			} `json:"RegionSet"`
+		} `json:"Response"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 DescribeRegions 响应失败: %w", err)
+	}
+
+	regions := make([]Region, 0, len(apiResponse.Response.RegionSet))
+	for _, r := range apiResponse.Response.RegionSet {
+		regions = append(regions, Region{
+			ID:          r.Region,
+			Name:        r.RegionName,
+			DisplayName: fmt.Sprintf("%s (%s)", r.RegionName, r.Region),
+			Available:   r.RegionState == "AVAILABLE",
+		})
+	}
 	return regions, nil
 }
 
-// GetAvailableInstanceTypes 获取指定区域的可用实例类型
+// GetAvailableInstanceTypes 获取指定区域按量付费在售的实例类型
+// 使用 DescribeZoneInstanceConfigInfos API，实例类型在任一可用区在售即视为可用
 func (c *tencentClient) GetAvailableInstanceTypes(ctx context.Context, region string) ([]InstanceType, error) {
-	// TODO: 实现腾讯云API调用获取实时实例类型列表
-	// 返回一些常用实例类型作为示例
-	instanceTypes := []InstanceType{
-		{ID: "S5.SMALL1", Name: "S5.SMALL1", CPU: 1, Memory: 1, Available: true, Currency: "CNY"},
-		{ID: "S5.MEDIUM2", Name: "S5.MEDIUM2", CPU: 2, Memory: 4, Available: true, Currency: "CNY"},
-		{ID: "S5.LARGE4", Name: "S5.LARGE4", CPU: 4, Memory: 8, Available: true, Currency: "CNY"},
-	}
-	
+	params := map[string]interface{}{
+		"Filters": []map[string]interface{}{
+			{"Name": "instance-charge-type", "Values": []string{"POSTPAID_BY_HOUR"}},
+		},
+	}
+	response, err := c.callAPI(ctx, "DescribeZoneInstanceConfigInfos", region, params)
+	if err != nil {
+		return nil, fmt.Errorf("调用 DescribeZoneInstanceConfigInfos API 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Response struct {
+			InstanceTypeQuotaSet []struct {
+				InstanceType string `json:"InstanceType"`
+				Status       string `json:"Status"`
+				Cpu          int    `json:"Cpu"`
+				Memory       int    `json:"Memory"`
+			} `json:"InstanceTypeQuotaSet"`
+		} `json:"Response"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+
+	index := make(map[string]int)
+	var instanceTypes []InstanceType
+	for _, q := range apiResponse.Response.InstanceTypeQuotaSet {
+		i, ok := index[q.InstanceType]
+		if !ok {
+			i = len(instanceTypes)
+			index[q.InstanceType] = i
+			instanceTypes = append(instanceTypes, InstanceType{
+				ID:       q.InstanceType,
+				Name:     q.InstanceType,
+				CPU:      q.Cpu,
+				Memory:   float64(q.Memory),
+				Currency: "CNY",
+			})
+		}
+		if q.Status == "SELL" {
+			instanceTypes[i].Available = true
+		}
+	}
+	sort.Slice(instanceTypes, func(i, j int) bool { return instanceTypes[i].ID < instanceTypes[j].ID })
+
 	return instanceTypes, nil
 }
 
 // GetInstancePrice 获取实例价格信息
 func (c *tencentClient) GetInstancePrice(ctx context.Context, region, instanceType string) (*InstancePrice, error) {
-	// TODO: 实现腾讯云API调用获取实时价格
-	return nil, fmt.Errorf("腾讯云价格查询功能待实现")
+	return c.inquiryPrice(ctx, region, instanceType, false)
+}
+
+// inquiryPrice 使用 InquiryPriceRunInstances API 询价（按量付费或抢占式），询价需要指定可用区和镜像，
+// 按量付费价格各可用区相同，返回第一个询价成功的可用区价格；抢占式实例价格随可用区变化，返回价格最低的可用区
+func (c *tencentClient) inquiryPrice(ctx context.Context, region, instanceType string, spot bool) (*InstancePrice, error) {
+	zones, err := c.describeZones(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	imageID, err := c.publicImageID(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *InstancePrice
+	var lastErr error
+	for _, zone := range zones {
+		params := map[string]interface{}{
+			"Placement":          map[string]string{"Zone": zone},
+			"ImageId":            imageID,
+			"InstanceType":       instanceType,
+			"InstanceChargeType": "POSTPAID_BY_HOUR",
+		}
+		if spot {
+			// 询价时出价上限只需足够高，返回的是当前市场价格
+			params["InstanceChargeType"] = "SPOTPAID"
+			params["InstanceMarketOptions"] = map[string]interface{}{
+				"MarketType":  "spot",
+				"SpotOptions": map[string]string{"MaxPrice": "1000", "SpotInstanceType": "one-time"},
+			}
+		}
+
+		response, err := c.callAPI(ctx, "InquiryPriceRunInstances", region, params)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		var apiResponse struct {
+			Response struct {
+				Price struct {
+					InstancePrice struct {
+						UnitPrice         float64 `json:"UnitPrice"`
+						UnitPriceDiscount float64 `json:"UnitPriceDiscount"`
+					} `json:"InstancePrice"`
+				} `json:"Price"`
+			} `json:"Response"`
+		}
+		if err := json.Unmarshal(response, &apiResponse); err != nil {
+			return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+		}
+
+		pricePerHour := apiResponse.Response.Price.InstancePrice.UnitPriceDiscount
+		if pricePerHour == 0 {
+			pricePerHour = apiResponse.Response.Price.InstancePrice.UnitPrice
+		}
+		if pricePerHour == 0 {
+			lastErr = fmt.Errorf("无法获取有效价格")
+			continue
+		}
+
+		price := &InstancePrice{
+			InstanceType:  instanceType,
+			Region:        region,
+			Zone:          zone,
+			Spot:          spot,
+			PricePerHour:  pricePerHour,
+			PricePerMonth: pricePerHour * 24 * 30,
+			Currency:      "CNY",
+		}
+		if !spot {
+			return price, nil
+		}
+		if best == nil || price.PricePerHour < best.PricePerHour {
+			best = price
+		}
+	}
+
+	if best != nil {
+		return best, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("区域 %s 没有可用区", region)
+	}
+	return nil, fmt.Errorf("调用 InquiryPriceRunInstances API 失败: %w", lastErr)
+}
+
+// GetSpotPrice 获取抢占式实例价格（竞价实例询价）
+func (c *tencentClient) GetSpotPrice(ctx context.Context, region, instanceType string) (*InstancePrice, error) {
+	return c.inquiryPrice(ctx, region, instanceType, true)
+}
+
+// CheckAvailability 检查区域内各可用区是否有指定实例类型的库存和配额
+// 使用 DescribeZoneInstanceConfigInfos API: https://cloud.tencent.com/document/api/213/17378
+func (c *tencentClient) CheckAvailability(ctx context.Context, region, instanceType string, spot bool) ([]ZoneAvailability, error) {
+	chargeType := "POSTPAID_BY_HOUR"
+	if spot {
+		chargeType = "SPOTPAID"
+	}
+
+	params := map[string]interface{}{
+		"Filters": []map[string]interface{}{
+			{"Name": "instance-type", "Values": []string{instanceType}},
+			{"Name": "instance-charge-type", "Values": []string{chargeType}},
+		},
+	}
+	response, err := c.callAPI(ctx, "DescribeZoneInstanceConfigInfos", region, params)
+	if err != nil {
+		return nil, fmt.Errorf("调用 DescribeZoneInstanceConfigInfos API 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Response struct {
+			InstanceTypeQuotaSet []struct {
+				Zone          string `json:"Zone"`
+				InstanceType  string `json:"InstanceType"`
+				Status        string `json:"Status"`
+				SoldOutReason string `json:"SoldOutReason"`
+				InstanceQuota int    `json:"InstanceQuota"`
+			} `json:"InstanceTypeQuotaSet"`
+		} `json:"Response"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+
+	var result []ZoneAvailability
+	for _, q := range apiResponse.Response.InstanceTypeQuotaSet {
+		if q.InstanceType != instanceType {
+			continue
+		}
+		zone := ZoneAvailability{Zone: q.Zone}
+		switch {
+		case q.Status != "SELL":
+			zone.Reason = fmt.Sprintf("售卖状态为 %s", q.Status)
+			if q.SoldOutReason != "" {
+				zone.Reason += "（" + q.SoldOutReason + "）"
+			}
+		case q.InstanceQuota <= 0:
+			zone.Reason = "实例配额不足"
+		default:
+			zone.Available = true
+		}
+		result = append(result, zone)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Zone < result[j].Zone })
+
+	return result, nil
+}
+
+// describeZones 获取区域内可用的可用区列表（结果按区域缓存）
+func (c *tencentClient) describeZones(ctx context.Context, region string) ([]string, error) {
+	c.cacheMu.Lock()
+	cached, ok := c.zoneCache[region]
+	c.cacheMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	response, err := c.callAPI(ctx, "DescribeZones", region, map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("调用 DescribeZones API 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Response struct {
+			ZoneSet []struct {
+				Zone      string `json:"Zone"`
+				ZoneState string `json:"ZoneState"`
+			} `json:"ZoneSet"`
+		} `json:"Response"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return nil, fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+
+	var zones []string
+	for _, z := range apiResponse.Response.ZoneSet {
+		if z.ZoneState == "AVAILABLE" {
+			zones = append(zones, z.Zone)
+		}
+	}
+
+	c.cacheMu.Lock()
+	c.zoneCache[region] = zones
+	c.cacheMu.Unlock()
+	return zones, nil
+}
+
+// publicImageID 获取区域内一个 Linux 公共镜像 ID，仅用于询价（镜像不影响实例价格）
+func (c *tencentClient) publicImageID(ctx context.Context, region string) (string, error) {
+	c.cacheMu.Lock()
+	cached, ok := c.imageCache[region]
+	c.cacheMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	params := map[string]interface{}{
+		"Filters": []map[string]interface{}{
+			{"Name": "image-type", "Values": []string{"PUBLIC_IMAGE"}},
+			{"Name": "platform", "Values": []string{"Ubuntu"}},
+		},
+		"Limit": 1,
+	}
+	response, err := c.callAPI(ctx, "DescribeImages", region, params)
+	if err != nil {
+		return "", fmt.Errorf("调用 DescribeImages API 失败: %w", err)
+	}
+
+	var apiResponse struct {
+		Response struct {
+			ImageSet []struct {
+				ImageId string `json:"ImageId"`
+			} `json:"ImageSet"`
+		} `json:"Response"`
+	}
+	if err := json.Unmarshal(response, &apiResponse); err != nil {
+		return "", fmt.Errorf("解析 API 响应失败: %w", err)
+	}
+	if len(apiResponse.Response.ImageSet) == 0 {
+		return "", fmt.Errorf("区域 %s 未找到可用的公共镜像", region)
+	}
+
+	imageID := apiResponse.Response.ImageSet[0].ImageId
+	c.cacheMu.Lock()
+	c.imageCache[region] = imageID
+	c.cacheMu.Unlock()
+	return imageID, nil
+}
+
+// VerifyCredentials 验证凭据：通过 STS GetCallerIdentity 查询账号，并检查 CVM 只读权限
+// API 文档: https://cloud.tencent.com/document/api/1312/48210
+func (c *tencentClient) VerifyCredentials(ctx context.Context) (*CredentialVerification, error) {
+	result := &CredentialVerification{Provider: c.Provider()}
+
+	response, err := c.callService(ctx, tencentSTSHost, "sts", "2018-08-13", "GetCallerIdentity", "ap-guangzhou", map[string]interface{}{})
+	if err == nil {
+		var identity struct {
+			Response struct {
+				AccountId string `json:"AccountId"`
+				Arn       string `json:"Arn"`
+			} `json:"Response"`
+		}
+		if err = json.Unmarshal(response, &identity); err == nil {
+			result.AccountID = identity.Response.AccountId
+			result.Identity = identity.Response.Arn
+		}
+	}
+	result.addCheck("sts:GetCallerIdentity", err)
+
+	_, err = c.callAPI(ctx, "DescribeRegions", "ap-guangzhou", map[string]interface{}{})
+	result.addCheck("cvm:DescribeRegions", err)
+
+	return result, nil
+}
+
+// setSessionToken 设置临时凭据的安全令牌
+func (c *tencentClient) setSessionToken(token string) {
+	c.sessionToken = token
+}
+
+// callAPI 调用腾讯云 CVM API（TC3-HMAC-SHA256 签名）
+// API 文档: https://cloud.tencent.com/document/api/213/30654
+func (c *tencentClient) callAPI(ctx context.Context, action, region string, params map[string]interface{}) ([]byte, error) {
+	return c.callService(ctx, tencentCVMHost, "cvm", "2017-03-12", action, region, params)
 }
 
+// callService 调用腾讯云指定服务的 API（TC3-HMAC-SHA256 签名）
+func (c *tencentClient) callService(ctx context.Context, host, service, version, action, region string, params map[string]interface{}) ([]byte, error) {
+	if c.accessKey == "" || c.secretKey == "" {
+		return nil, fmt.Errorf("未配置腾讯云 SecretId 和 SecretKey")
+	}
+
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求参数失败: %w", err)
+	}
+
+	now := time.Now().UTC()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	contentType := "application/json; charset=utf-8"
+	authorization := c.sign(host, service, contentType, payload, now)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://"+host, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Timestamp", timestamp)
+	req.Header.Set("X-TC-Version", version)
+	req.Header.Set("X-TC-Region", region)
+	if c.sessionToken != "" {
+		req.Header.Set("X-TC-Token", c.sessionToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API 返回错误: %d, %s", resp.StatusCode, string(body))
+	}
+
+	// 检查是否有错误
+	var errorResponse struct {
+		Response struct {
+			Error struct {
+				Code    string `json:"Code"`
+				Message string `json:"Message"`
+			} `json:"Error"`
+		} `json:"Response"`
+	}
+	if err := json.Unmarshal(body, &errorResponse); err == nil {
+		if errorResponse.Response.Error.Code != "" {
+			return nil, fmt.Errorf("API 错误: %s - %s", errorResponse.Response.Error.Code, errorResponse.Response.Error.Message)
+		}
+	}
+
+	return body, nil
+}
+
+// sign 计算 TC3-HMAC-SHA256 签名，返回 Authorization 请求头
+// 签名文档: https://cloud.tencent.com/document/api/213/30654
+func (c *tencentClient) sign(host, service, contentType string, payload []byte, now time.Time) string {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	date := now.UTC().Format("2006-01-02")
+
+	// 构建规范请求串
+	canonicalRequest := fmt.Sprintf("POST\n/\n\ncontent-type:%s\nhost:%s\n\ncontent-type;host\n%s",
+		contentType, host, sha256Hex(payload))
+
+	// 构建待签名字符串
+	credentialScope := date + "/" + service + "/tc3_request"
+	stringToSign := fmt.Sprintf("TC3-HMAC-SHA256\n%s\n%s\n%s",
+		timestamp, credentialScope, sha256Hex([]byte(canonicalRequest)))
+
+	// 计算签名
+	secretDate := hmacSHA256([]byte("TC3"+c.secretKey), date)
+	secretService := hmacSHA256(secretDate, service)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
+		c.accessKey, credentialScope, signature)
+}
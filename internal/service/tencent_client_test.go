@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTencentSign(t *testing.T) {
+	// 签名示例取自腾讯云 API 3.0 签名方法 v3 文档: https://cloud.tencent.com/document/api/213/30654
+	tests := []struct {
+		name        string
+		accessKey   string
+		secretKey   string
+		host        string
+		service     string
+		contentType string
+		payload     string
+		timestamp   int64
+		want        string
+	}{
+		{
+			name:        "文档示例 DescribeInstances",
+			accessKey:   "AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE",
+			secretKey:   "Gu5t9xGARNpq86cd98joQYCN3EXAMPLE",
+			host:        "cvm.tencentcloudapi.com",
+			service:     "cvm",
+			contentType: "application/json; charset=utf-8",
+			// 请求体与文档完全一致（中文以 \u 转义），签名按原始字节计算
+			payload:   `{"Limit": 1, "Filters": [{"Values": ["\u672a\u547d\u540d"], "Name": "instance-name"}]}`,
+			timestamp: 1551113065,
+			want: "TC3-HMAC-SHA256 Credential=AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE/2019-02-25/cvm/tc3_request, " +
+				"SignedHeaders=content-type;host, Signature=72e494ea809ad7a8c8f7a4507b9bddcbaa8e581f516e8da2f66e2c5a96525168",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &tencentClient{accessKey: tt.accessKey, secretKey: tt.secretKey}
+			got := c.sign(tt.host, tt.service, tt.contentType, []byte(tt.payload), time.Unix(tt.timestamp, 0))
+			if got != tt.want {
+				t.Errorf("Authorization = %s\n期望 %s", got, tt.want)
+			}
+		})
+	}
+}
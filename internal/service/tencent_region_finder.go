@@ -57,12 +57,15 @@ func GetDomesticRegions() []string {
 func (f *tencentRegionFinder) FindAvailableRegions(ctx context.Context, instanceType string) ([]string, error) {
 	var availableRegions []string
 
+	// 使用 context 中绑定的 profile（项目级凭据）
+	credManager := f.credManager.WithProfile(credentials.ProfileFromContext(ctx))
+
 	// 检查是否有腾讯云凭据
-	if !f.credManager.HasCredentials(credentials.ProviderTencent) {
+	if !credManager.HasCredentials(credentials.ProviderTencent) {
 		return nil, fmt.Errorf("未配置腾讯云凭据，请先运行: credential set tencent")
 	}
 
-	creds, err := f.credManager.GetCredentials(credentials.ProviderTencent)
+	creds, err := credManager.GetCredentials(credentials.ProviderTencent)
 	if err != nil {
 		return nil, fmt.Errorf("获取腾讯云凭据失败: %w", err)
 	}
@@ -250,7 +253,7 @@ func FindBestTencentRegion(ctx context.Context, instanceType string) (string, er
 // QuerySpotInstanceAvailability 查询指定区域的抢占式实例可用性
 // 返回可用区域和实例类型的组合
 func QuerySpotInstanceAvailability(ctx context.Context, regions []string, instanceFamily string) ([]SpotAvailability, error) {
-	credManager := credentials.GetDefaultManager()
+	credManager := credentials.ManagerFromContext(ctx)
 	if !credManager.HasCredentials(credentials.ProviderTencent) {
 		return nil, fmt.Errorf("未配置腾讯云凭据")
 	}
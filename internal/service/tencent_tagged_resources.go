@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// tencentVPCHost 腾讯云 VPC API 地址
+	tencentVPCHost = "vpc.tencentcloudapi.com"
+
+	// tencentPageSize 列表接口每页数量
+	tencentPageSize = 100
+)
+
+// tencentTaggedResourceAPI 腾讯云按标签查询和删除资源使用的接口
+type tencentTaggedResourceAPI struct {
+	kind       string
+	host       string
+	service    string
+	version    string
+	list       string // 列表接口，支持 tag-key 过滤
+	setKey     string // 响应中的列表字段
+	tagKey     string // 列表项中的标签字段（CVM 为 Tags，VPC 为 TagSet）
+	idField    string
+	nameField  string
+	stringPage bool   // 分页参数 Offset/Limit 是否为字符串类型
+	delete     string // 删除接口
+	deleteID   string // 删除接口的资源 ID 参数
+	deleteList bool   // 删除接口的 ID 参数是否为数组
+}
+
+// tencentTaggedResourceAPIs 支持按标签查找的资源类型
+// API 文档: https://cloud.tencent.com/document/api/213/15728（DescribeInstances）等
+var tencentTaggedResourceAPIs = []tencentTaggedResourceAPI{
+	{ResourceInstance, tencentCVMHost, "cvm", "2017-03-12", "DescribeInstances", "InstanceSet", "Tags", "InstanceId", "InstanceName", false, "TerminateInstances", "InstanceIds", true},
+	{ResourceEIP, tencentVPCHost, "vpc", "2017-03-12", "DescribeAddresses", "AddressSet", "TagSet", "AddressId", "AddressIp", false, "ReleaseAddresses", "AddressIds", true},
+	{ResourceSecurityGroup, tencentVPCHost, "vpc", "2017-03-12", "DescribeSecurityGroups", "SecurityGroupSet", "TagSet", "SecurityGroupId", "SecurityGroupName", true, "DeleteSecurityGroup", "SecurityGroupId", false},
+	{ResourceSubnet, tencentVPCHost, "vpc", "2017-03-12", "DescribeSubnets", "SubnetSet", "TagSet", "SubnetId", "SubnetName", true, "DeleteSubnet", "SubnetId", false},
+	{ResourceVPC, tencentVPCHost, "vpc", "2017-03-12", "DescribeVpcs", "VpcSet", "TagSet", "VpcId", "VpcName", true, "DeleteVpc", "VpcId", false},
+}
+
+// DescribeRegions 查询 CVM 可用的全部区域
+func (c *tencentClient) DescribeRegions(ctx context.Context) ([]string, error) {
+	available, err := c.GetAvailableRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var regions []string
+	for _, region := range available {
+		if region.Available {
+			regions = append(regions, region.ID)
+		}
+	}
+	return regions, nil
+}
+
+// ListTaggedResources 列出区域内带有指定标签键的资源
+func (c *tencentClient) ListTaggedResources(ctx context.Context, region, tagKey string) ([]TaggedResource, error) {
+	var resources []TaggedResource
+	for _, api := range tencentTaggedResourceAPIs {
+		for offset := 0; ; offset += tencentPageSize {
+			params := map[string]interface{}{
+				"Filters": []map[string]interface{}{
+					{"Name": "tag-key", "Values": []string{tagKey}},
+				},
+			}
+			if api.stringPage {
+				params["Offset"] = strconv.Itoa(offset)
+				params["Limit"] = strconv.Itoa(tencentPageSize)
+			} else {
+				params["Offset"] = offset
+				params["Limit"] = tencentPageSize
+			}
+
+			response, err := c.callService(ctx, api.host, api.service, api.version, api.list, region, params)
+			if err != nil {
+				return nil, fmt.Errorf("调用 %s API 失败: %w", api.list, err)
+			}
+
+			items, total, err := parseTencentTaggedItems(response, api, region)
+			if err != nil {
+				return nil, fmt.Errorf("解析 %s 响应失败: %w", api.list, err)
+			}
+			resources = append(resources, items...)
+			if len(items) == 0 || offset+len(items) >= total {
+				break
+			}
+		}
+	}
+	return resources, nil
+}
+
+// parseTencentTaggedItems 解析列表接口响应中的资源和标签
+func parseTencentTaggedItems(response []byte, api tencentTaggedResourceAPI, region string) ([]TaggedResource, int, error) {
+	var body struct {
+		Response map[string]json.RawMessage `json:"Response"`
+	}
+	if err := json.Unmarshal(response, &body); err != nil {
+		return nil, 0, err
+	}
+	var total int
+	json.Unmarshal(body.Response["TotalCount"], &total)
+
+	var items []map[string]interface{}
+	if raw, ok := body.Response[api.setKey]; ok {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var resources []TaggedResource
+	for _, item := range items {
+		resource := TaggedResource{
+			Provider: "tencent",
+			Region:   region,
+			Kind:     api.kind,
+		}
+		resource.ID, _ = item[api.idField].(string)
+		resource.Name, _ = item[api.nameField].(string)
+
+		tags, _ := item[api.tagKey].([]interface{})
+		for _, t := range tags {
+			tag, _ := t.(map[string]interface{})
+			key, _ := tag["Key"].(string)
+			value, _ := tag["Value"].(string)
+			resource.setResourceTags(key, value)
+		}
+		resources = append(resources, resource)
+	}
+	return resources, total, nil
+}
+
+// DeleteTaggedResource 删除资源（实例退还）
+func (c *tencentClient) DeleteTaggedResource(ctx context.Context, resource TaggedResource) error {
+	for _, api := range tencentTaggedResourceAPIs {
+		if api.kind != resource.Kind {
+			continue
+		}
+		params := map[string]interface{}{api.deleteID: resource.ID}
+		if api.deleteList {
+			params[api.deleteID] = []string{resource.ID}
+		}
+		if _, err := c.callService(ctx, api.host, api.service, api.version, api.delete, resource.Region, params); err != nil {
+			return fmt.Errorf("调用 %s API 失败: %w", api.delete, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("不支持删除的资源类型: %s", resource.Kind)
+}
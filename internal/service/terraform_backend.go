@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+	"github.com/lucksec/cloudbot/internal/repository"
+)
+
+const (
+	// backendFileName 场景目录中生成的后端配置文件
+	backendFileName = "cloudbot_backend.tf"
+
+	// defaultStateKeyPrefix 远程状态路径的默认前缀
+	defaultStateKeyPrefix = "cloudbot"
+)
+
+// 后端配置中由 cloudbot 处理、不直接写入 backend 块的参数
+const (
+	backendOptKeyPrefix   = "key_prefix"  // 状态路径前缀（s3 / pg）
+	backendOptCredentials = "credentials" // 使用凭据管理器中哪个云服务商的凭据访问存储（s3）
+	backendOptEndpoint    = "endpoint"    // S3 兼容存储的访问地址（OSS / COS / MinIO）
+	backendOptLock        = "lock"        // 是否启用 HTTP 后端的状态锁（http）
+)
+
+var schemaNameSanitizer = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// ValidateBackendConfig 校验后端配置
+func ValidateBackendConfig(backend *domain.BackendConfig) error {
+	required := map[string][]string{
+		domain.BackendS3:   {"bucket", "region"},
+		domain.BackendHTTP: {"address"},
+		domain.BackendPG:   {},
+	}
+	derived := map[string]string{
+		domain.BackendS3:   "key",
+		domain.BackendHTTP: "",
+		domain.BackendPG:   "schema_name",
+	}
+
+	keys, ok := required[backend.Type]
+	if !ok {
+		return fmt.Errorf("不支持的后端类型: %s（支持 s3, http, pg）", backend.Type)
+	}
+	for _, key := range keys {
+		if backend.Options[key] == "" {
+			return fmt.Errorf("%s 后端缺少参数 %s", backend.Type, key)
+		}
+	}
+	if key := derived[backend.Type]; key != "" {
+		if _, ok := backend.Options[key]; ok {
+			return fmt.Errorf("%s 后端的 %s 由项目名和场景 ID 生成，不能手动指定（可使用 %s 设置前缀）", backend.Type, key, backendOptKeyPrefix)
+		}
+	}
+	if provider, ok := backend.Options[backendOptCredentials]; ok {
+		if backend.Type != domain.BackendS3 {
+			return fmt.Errorf("%s 参数只适用于 s3 后端", backendOptCredentials)
+		}
+		if !credentials.Provider(provider).IsValid() {
+			return fmt.Errorf("无效的云服务商: %s", provider)
+		}
+	}
+	return nil
+}
+
+// renderBackend 生成场景的后端配置文件内容
+// 状态路径由项目名和场景 ID 生成，保证团队成员访问同一场景时使用同一份状态
+// 访问存储的凭据不写入配置文件，执行 Terraform 时通过环境变量传递（见 setBackendCredentialsEnv）
+func renderBackend(project *domain.Project, scenario *domain.Scenario) string {
+	backend := project.Backend
+	prefix := backend.Options[backendOptKeyPrefix]
+	if prefix == "" {
+		prefix = defaultStateKeyPrefix
+	}
+
+	attrs := make(map[string]string)
+	for name, value := range backend.Options {
+		switch name {
+		case backendOptKeyPrefix, backendOptCredentials, backendOptEndpoint, backendOptLock:
+			continue
+		}
+		attrs[name] = hclValue(value)
+	}
+
+	switch backend.Type {
+	case domain.BackendS3:
+		attrs["key"] = hclValue(fmt.Sprintf("%s/%s/%s/terraform.tfstate", prefix, project.Name, scenario.ID))
+		if endpoint := backend.Options[backendOptEndpoint]; endpoint != "" {
+			// S3 兼容存储不支持 AWS 账号和区域校验
+			attrs["endpoints"] = fmt.Sprintf("{ s3 = %s }", hclValue(endpoint))
+			for _, skip := range []string{"skip_credentials_validation", "skip_region_validation", "skip_requesting_account_id", "skip_metadata_api_check", "skip_s3_checksum"} {
+				if _, ok := attrs[skip]; !ok {
+					attrs[skip] = "true"
+				}
+			}
+		}
+	case domain.BackendHTTP:
+		address := strings.TrimRight(backend.Options["address"], "/") + "/" + project.Name + "/" + scenario.ID
+		attrs["address"] = hclValue(address)
+		if backend.Options[backendOptLock] == "true" {
+			attrs["lock_address"] = hclValue(address + "/lock")
+			attrs["unlock_address"] = hclValue(address + "/lock")
+		}
+	case domain.BackendPG:
+		schema := strings.ToLower(fmt.Sprintf("%s_%s_%s", prefix, project.Name, scenario.ID))
+		attrs["schema_name"] = hclValue(schemaNameSanitizer.ReplaceAllString(schema, "_"))
+	}
+
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# 由 cloudbot 根据项目后端配置生成，请勿手动修改\n")
+	b.WriteString("terraform {\n")
+	fmt.Fprintf(&b, "  backend %q {\n", backend.Type)
+	for _, name := range names {
+		fmt.Fprintf(&b, "    %s = %s\n", name, attrs[name])
+	}
+	b.WriteString("  }\n}\n")
+
+	return b.String()
+}
+
+// backendCredentialsKey context 中访问 s3 后端存储使用的云服务商凭据
+type backendCredentialsKey struct{}
+
+// contextWithBackendCredentials 在 context 中记录访问 s3 后端存储使用哪个云服务商的凭据（后端的 credentials 参数）
+func contextWithBackendCredentials(ctx context.Context, backend *domain.BackendConfig) context.Context {
+	if backend == nil || backend.Type != domain.BackendS3 || backend.Options[backendOptCredentials] == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, backendCredentialsKey{}, credentials.Provider(backend.Options[backendOptCredentials]))
+}
+
+// setBackendCredentialsEnv 通过 AWS_* 环境变量传递 s3 后端存储的凭据
+// init 之后的 plan、apply 和 state 命令同样需要访问状态存储，因此每次执行 Terraform 都要设置；
+// 不使用 -backend-config，避免凭据出现在命令行参数中并保存到 .terraform 目录
+// 场景本身使用 AWS provider 时两者共用 AWS_* 环境变量，凭据不同则返回错误
+func setBackendCredentialsEnv(ctx context.Context, envMap map[string]string, awsConfigured bool) error {
+	provider, ok := ctx.Value(backendCredentialsKey{}).(credentials.Provider)
+	if !ok {
+		return nil
+	}
+	creds, err := credentials.ManagerFromContext(ctx).GetCredentials(provider)
+	if err != nil {
+		return fmt.Errorf("获取后端存储凭据失败: %w", err)
+	}
+
+	keys := cloudProviderEnv[credentials.ProviderAWS]
+	if awsConfigured && envMap[keys.AccessKey] != creds.AccessKey {
+		return fmt.Errorf("s3 后端使用 %s 的凭据，与场景 AWS provider 的凭据冲突（两者都通过 AWS_* 环境变量传递），请将后端的 credentials 设置为 aws", provider)
+	}
+	envMap[keys.AccessKey] = creds.AccessKey
+	envMap[keys.SecretKey] = creds.SecretKey
+	setSessionTokenEnv(envMap, keys.SessionToken, creds)
+	return nil
+}
+
+// backendHasStoredCredentials 检查工作目录是否保存了之前通过 -backend-config 传递的凭据
+// 这类工作目录需要重新配置后端（状态仍在原存储中，不需要迁移），使保存的凭据失效
+func backendHasStoredCredentials(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, ".terraform", "terraform.tfstate"))
+	if err != nil {
+		return false
+	}
+	var state struct {
+		Backend struct {
+			Config map[string]interface{} `json:"config"`
+		} `json:"backend"`
+	}
+	if json.Unmarshal(data, &state) != nil {
+		return false
+	}
+	for _, key := range []string{"access_key", "secret_key", "token"} {
+		if value, ok := state.Backend.Config[key].(string); ok && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hclValue 将后端参数转换为 HCL 字面量（布尔值和整数原样输出，其他按字符串处理）
+func hclValue(value string) string {
+	if value == "true" || value == "false" {
+		return value
+	}
+	if _, err := strconv.Atoi(value); err == nil {
+		return value
+	}
+	// 后端配置不支持插值，需要转义模板序列
+	value = strings.ReplaceAll(value, "${", "$${")
+	value = strings.ReplaceAll(value, "%{", "%%{")
+	return strconv.Quote(value)
+}
+
+// hasLocalState 场景目录中是否存在本地状态文件
+func hasLocalState(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "terraform.tfstate"))
+	return err == nil && info.Size() > 0
+}
+
+// prepareBackend 按项目后端配置生成场景的后端配置文件，并返回 Init 选项和恢复原配置文件的函数
+// 后端发生变化且已有状态时，只有 migrate 为 true 才会迁移状态，否则返回错误，避免遗留状态导致资源失控
+func (s *projectService) prepareBackend(ctx context.Context, project *domain.Project, scenario *domain.Scenario, migrate bool) (InitOptions, func(), error) {
+	log := logger.GetLogger()
+	path := filepath.Join(scenario.Path, backendFileName)
+
+	existing, err := os.ReadFile(path)
+	hadBackend := err == nil
+	restore := func() {
+		if hadBackend {
+			_ = os.WriteFile(path, existing, 0644)
+		} else {
+			_ = os.Remove(path)
+		}
+	}
+
+	// 项目未配置远程后端：如果之前生成过后端文件，需要把状态迁移回本地
+	if project.Backend == nil {
+		if !hadBackend {
+			return InitOptions{}, restore, nil
+		}
+		if !migrate {
+			return InitOptions{}, nil, fmt.Errorf("项目 %s 已取消远程后端，场景 %s 的状态仍在远程，请运行 'cloudbot project backend %s --migrate' 迁移回本地", project.Name, scenario.ID, project.Name)
+		}
+		if err := os.Remove(path); err != nil {
+			return InitOptions{}, nil, fmt.Errorf("删除后端配置文件失败: %w", err)
+		}
+		log.Info("场景状态迁移回本地: project=%s, scenario=%s", project.Name, scenario.ID)
+		return InitOptions{MigrateState: true}, restore, nil
+	}
+
+	content := renderBackend(project, scenario)
+	var opts InitOptions
+	if hadBackend && string(existing) == content {
+		opts.Reconfigure = backendHasStoredCredentials(scenario.Path)
+		return opts, restore, nil
+	}
+
+	// 后端发生变化：已有状态（本地或旧后端）必须迁移，否则直接重新配置
+	if hasLocalState(scenario.Path) || hadBackend {
+		if !migrate {
+			return InitOptions{}, nil, fmt.Errorf("场景 %s 的状态后端已变化，请运行 'cloudbot project backend %s --migrate' 迁移现有状态", scenario.ID, project.Name)
+		}
+		opts.MigrateState = true
+	} else {
+		opts.Reconfigure = true
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return InitOptions{}, nil, fmt.Errorf("写入后端配置文件失败: %w", err)
+	}
+	log.Info("已生成场景后端配置: project=%s, scenario=%s, backend=%s, migrate=%v", project.Name, scenario.ID, project.Backend.Type, opts.MigrateState)
+	return opts, restore, nil
+}
+
+// requireSharedBackend 元数据在团队内共享的项目必须配置远程状态后端
+// 共享的场景对所有成员可见，而本地状态只在执行部署的机器上，其他成员部署或销毁同一场景时会重复创建或遗漏资源
+func (s *projectService) requireSharedBackend(projectName string) error {
+	if !repository.IsShared(s.projectRepo) {
+		return nil
+	}
+	project, err := s.projectRepo.GetProject(projectName)
+	if err != nil {
+		return err
+	}
+	if project.Backend == nil {
+		return fmt.Errorf("项目 %s 的元数据在团队内共享，必须先配置远程状态后端（cloudbot project backend %s --type ...）", projectName, projectName)
+	}
+	return nil
+}
+
+// initScenario 按项目后端配置初始化场景的 Terraform 工作目录
+func (s *projectService) initScenario(ctx context.Context, projectName string, scenario *domain.Scenario) error {
+	project, err := s.projectRepo.GetProject(projectName)
+	if err != nil {
+		return err
+	}
+
+	opts, _, err := s.prepareBackend(ctx, project, scenario, false)
+	if err != nil {
+		return err
+	}
+	// 下载 provider 插件时的网络错误退避重试
+	return s.retryPolicy.Do(ctx, "Terraform init", func() error {
+		return s.terraformSvc.InitWithOptions(ctx, scenario.Path, opts)
+	})
+}
+
+// SetProjectBackend 设置项目的 Terraform 远程状态后端，backend 为空表示使用本地状态
+// 只保存配置；已有状态需要通过 MigrateProjectState 迁移
+func (s *projectService) SetProjectBackend(ctx context.Context, name string, backend *domain.BackendConfig) error {
+	if backend != nil {
+		if err := ValidateBackendConfig(backend); err != nil {
+			return err
+		}
+	}
+
+	project, err := s.projectRepo.GetProject(name)
+	if err != nil {
+		return err
+	}
+	project.Backend = backend
+	return s.projectRepo.UpdateProject(project)
+}
+
+// MigrateProjectState 将项目下所有场景的状态迁移到当前配置的后端（未配置时迁移回本地）
+func (s *projectService) MigrateProjectState(ctx context.Context, name string) error {
+	ctx, err := s.withProjectProfile(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.projectRepo.GetProject(name)
+	if err != nil {
+		return err
+	}
+	scenarios, err := s.projectRepo.ListScenarios(name)
+	if err != nil {
+		return fmt.Errorf("获取场景列表失败: %w", err)
+	}
+
+	for _, sc := range scenarios {
+		if sc.Path == "" {
+			continue
+		}
+
+		opts, restore, err := s.prepareBackend(ctx, project, sc, true)
+		if err != nil {
+			return fmt.Errorf("场景 %s: %w", sc.ID, err)
+		}
+		if !opts.MigrateState && !opts.Reconfigure {
+			fmt.Printf("场景 %s 的状态后端未变化，跳过\n", sc.ID)
+			continue
+		}
+
+		fmt.Printf("正在迁移场景 %s 的状态 (项目: %s)...\n", sc.ID, name)
+		if err := s.terraformSvc.InitWithOptions(ctx, sc.Path, opts); err != nil {
+			// 恢复原后端配置，便于修复问题后重新迁移
+			restore()
+			return fmt.Errorf("迁移场景 %s 的状态失败: %w", sc.ID, err)
+		}
+	}
+	return nil
+}
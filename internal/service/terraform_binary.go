@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/config"
+)
+
+// Terraform 兼容的发行版
+const (
+	FlavorTerraform = "terraform" // HashiCorp Terraform
+	FlavorOpenTofu  = "tofu"      // OpenTofu
+)
+
+// TerraformBinary Terraform 兼容的可执行文件
+type TerraformBinary struct {
+	Path    string // 可执行文件路径
+	Flavor  string // 发行版（terraform 或 tofu）
+	Version string // 版本号，如 1.9.5
+}
+
+// String 返回发行版和版本，如 "Terraform 1.9.5"
+func (b *TerraformBinary) String() string {
+	name := "Terraform"
+	if b.Flavor == FlavorOpenTofu {
+		name = "OpenTofu"
+	}
+	return fmt.Sprintf("%s %s", name, b.Version)
+}
+
+// versionOutputPattern version 命令输出的第一行，如 "Terraform v1.9.5" 或 "OpenTofu v1.8.3"
+var versionOutputPattern = regexp.MustCompile(`^(Terraform|OpenTofu) v(\S+)`)
+
+// DetectTerraformBinary 执行 version 命令识别可执行文件的发行版和版本
+func DetectTerraformBinary(path string) (*TerraformBinary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, "version").Output()
+	if err != nil {
+		return nil, fmt.Errorf("执行 %s version 失败: %w", path, err)
+	}
+	firstLine := strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0])
+	m := versionOutputPattern.FindStringSubmatch(firstLine)
+	if m == nil {
+		return nil, fmt.Errorf("无法识别 %s 的版本: %s", path, firstLine)
+	}
+
+	flavor := FlavorTerraform
+	if m[1] == "OpenTofu" {
+		flavor = FlavorOpenTofu
+	}
+	return &TerraformBinary{Path: path, Flavor: flavor, Version: m[2]}, nil
+}
+
+// ResolveTerraformExecPath 确定默认使用的可执行文件
+// 配置了 exec_path 时直接使用；否则依次查找安装目录中的 terraform、tofu 和 PATH 中的 terraform、tofu
+func ResolveTerraformExecPath(cfg *config.Config) string {
+	if cfg.Terraform.ExecPath != "" {
+		return cfg.Terraform.ExecPath
+	}
+	for _, flavor := range []string{FlavorTerraform, FlavorOpenTofu} {
+		path := filepath.Join(cfg.Terraform.BinDir, binaryFileName(flavor))
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	for _, flavor := range []string{FlavorTerraform, FlavorOpenTofu} {
+		if path, err := exec.LookPath(flavor); err == nil {
+			return path
+		}
+	}
+	return FlavorTerraform
+}
+
+// ListInstalledTerraform 列出安装目录中的各版本可执行文件（<发行版>_<版本>），按版本从高到低排序
+func ListInstalledTerraform(binDir string) ([]*TerraformBinary, error) {
+	entries, err := os.ReadDir(binDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取安装目录失败: %w", err)
+	}
+
+	var binaries []*TerraformBinary
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".exe")
+		flavor, version, ok := strings.Cut(name, "_")
+		if entry.IsDir() || !ok || (flavor != FlavorTerraform && flavor != FlavorOpenTofu) {
+			continue
+		}
+		if _, err := parseTerraformVersion(version); err != nil {
+			continue
+		}
+		binaries = append(binaries, &TerraformBinary{
+			Path:    filepath.Join(binDir, entry.Name()),
+			Flavor:  flavor,
+			Version: version,
+		})
+	}
+	sort.SliceStable(binaries, func(i, j int) bool {
+		a, _ := parseTerraformVersion(binaries[i].Version)
+		b, _ := parseTerraformVersion(binaries[j].Version)
+		return a.compare(b) > 0
+	})
+	return binaries, nil
+}
+
+// binaryFileName 可执行文件名（Windows 下带 .exe 后缀）
+func binaryFileName(name string) string {
+	if os.PathSeparator == '\\' {
+		return name + ".exe"
+	}
+	return name
+}
+
+// terraformVersion 语义化版本号（主版本、次版本、修订号），忽略预发布后缀
+type terraformVersion [3]int
+
+// parseTerraformVersion 解析版本号，支持 v 前缀和省略的次版本或修订号（如 1.9）
+func parseTerraformVersion(s string) (terraformVersion, error) {
+	var v terraformVersion
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if s == "" || len(parts) > 3 {
+		return v, fmt.Errorf("无效的版本号: %s", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("无效的版本号: %s", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// compare 比较版本号，返回 -1、0 或 1
+func (v terraformVersion) compare(other terraformVersion) int {
+	for i := range v {
+		if v[i] != other[i] {
+			if v[i] < other[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionConstraint 单个版本约束，如 ">= 1.5.0"
+type versionConstraint struct {
+	op      string
+	version terraformVersion
+	parts   int // 约束中写出的版本段数（~> 使用）
+}
+
+// allows 判断版本是否满足约束
+func (c versionConstraint) allows(v terraformVersion) bool {
+	cmp := v.compare(c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "~>":
+		// ~> 1.9 允许 1.x（x >= 9），~> 1.9.5 允许 1.9.x（x >= 5）
+		if cmp < 0 {
+			return false
+		}
+		upper := c.version
+		idx := c.parts - 2
+		if idx < 0 {
+			idx = 0
+		}
+		upper[idx]++
+		for i := idx + 1; i < len(upper); i++ {
+			upper[i] = 0
+		}
+		return v.compare(upper) < 0
+	}
+	return false
+}
+
+// TerraformRequirement 项目要求的 Terraform 版本（project.ini 中的 required_terraform_version）
+// 格式为可选的发行版前缀加逗号分隔的版本约束，如 "1.9.5"、">= 1.5, < 2.0"、"tofu ~> 1.8"
+type TerraformRequirement struct {
+	Raw         string
+	Flavor      string // 为空表示不限发行版
+	constraints []versionConstraint
+}
+
+// constraintPattern 单个版本约束
+var constraintPattern = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~>)?\s*v?([0-9][0-9.]*)$`)
+
+// ParseTerraformRequirement 解析版本要求
+func ParseTerraformRequirement(s string) (*TerraformRequirement, error) {
+	req := &TerraformRequirement{Raw: strings.TrimSpace(s)}
+	rest := req.Raw
+	for _, flavor := range []string{FlavorTerraform, FlavorOpenTofu} {
+		if after, ok := strings.CutPrefix(rest, flavor+" "); ok {
+			req.Flavor = flavor
+			rest = after
+			break
+		}
+	}
+
+	for _, part := range strings.Split(rest, ",") {
+		m := constraintPattern.FindStringSubmatch(strings.TrimSpace(part))
+		if m == nil {
+			return nil, fmt.Errorf("无效的 Terraform 版本要求: %s（示例: 1.9.5、>= 1.5, < 2.0、tofu ~> 1.8）", s)
+		}
+		version, err := parseTerraformVersion(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("无效的 Terraform 版本要求 %s: %w", s, err)
+		}
+		op := m[1]
+		if op == "" {
+			op = "="
+		}
+		req.constraints = append(req.constraints, versionConstraint{op: op, version: version, parts: len(strings.Split(m[2], "."))})
+	}
+	return req, nil
+}
+
+// Allows 判断可执行文件是否满足版本要求
+func (r *TerraformRequirement) Allows(b *TerraformBinary) bool {
+	if r.Flavor != "" && r.Flavor != b.Flavor {
+		return false
+	}
+	v, err := parseTerraformVersion(b.Version)
+	if err != nil {
+		return false
+	}
+	for _, c := range r.constraints {
+		if !c.allows(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// requiredTerraformKey context 中项目要求的 Terraform 版本
+type requiredTerraformKey struct{}
+
+// ContextWithRequiredTerraform 在 context 中记录要求的 Terraform 版本，执行器据此选择可执行文件
+func ContextWithRequiredTerraform(ctx context.Context, req *TerraformRequirement) context.Context {
+	if req == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requiredTerraformKey{}, req)
+}
+
+// requiredTerraformFromContext 获取 context 中要求的 Terraform 版本，未设置时返回 nil
+func requiredTerraformFromContext(ctx context.Context) *TerraformRequirement {
+	req, _ := ctx.Value(requiredTerraformKey{}).(*TerraformRequirement)
+	return req
+}
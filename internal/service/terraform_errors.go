@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// Terraform 失败的错误类型，通过 errors.Is 判断
+var (
+	ErrQuotaExceeded       = errors.New("云资源配额不足")
+	ErrOutOfStock          = errors.New("云资源库存不足")
+	ErrAuthFailed          = errors.New("云服务商认证失败")
+	ErrInsufficientBalance = errors.New("账户余额不足")
+	ErrRateLimited         = errors.New("云服务商 API 限流")
+	ErrTransient           = errors.New("网络或云服务商临时错误")
+	ErrConfig              = errors.New("Terraform 配置错误")
+	ErrStateLocked         = errors.New("Terraform 状态已被锁定")
+	ErrInterrupted         = errors.New("操作已中断")
+)
+
+// terraformErrorRules 各云服务商错误信息到错误类型的匹配规则（不区分大小写，按顺序匹配）
+// 限流需要在配额之前匹配（RequestLimitExceeded 包含 LimitExceeded），余额需要在库存之前匹配
+var terraformErrorRules = []struct {
+	kind     error
+	patterns []string
+}{
+	{ErrStateLocked, []string{
+		"error acquiring the state lock",
+	}},
+	{ErrAuthFailed, []string{
+		"invalidaccesskeyid", "signaturedoesnotmatch", "incompletesignature", "forbidden.ram", "nopermission",
+		"invalidsecuritytoken", "authfailure", "unauthorizedoperation", "invalidclienttokenid", "expiredtoken",
+		"no valid credential sources",
+	}},
+	{ErrInsufficientBalance, []string{
+		"notenoughbalance", "arrearage", "insufficientbalance", "balance.insufficient", "余额不足", "欠费",
+	}},
+	{ErrRateLimited, []string{
+		"throttling", "requestlimitexceeded", "toomanyrequests", "rate exceeded", "flow limit",
+	}},
+	{ErrQuotaExceeded, []string{
+		"limitexceeded", "quotaexceed", "quota.exceed", "quota exceeded", "配额不足",
+	}},
+	{ErrOutOfStock, []string{
+		"nostock", "resourceinsufficient", "resourcessoldout", "soldout", "insufficientinstancecapacity",
+		"zone.notonsale", "库存不足",
+	}},
+	{ErrConfig, []string{
+		"unsupported argument", "missing required argument", "no value for required variable", "invalid reference",
+		"reference to undeclared", "invalid value for variable", "unsupported block type", "argument or block definition required",
+		"invalidparameter", "missingparameter",
+	}},
+	{ErrTransient, []string{
+		"timeout", "connection reset", "connection refused", "no such host", "temporary failure in name resolution",
+		"serviceunavailable", "service unavailable", "internalerror", "internal server error", "unknownerror",
+		"bad gateway", "unexpected eof",
+	}},
+}
+
+// TerraformError Terraform 命令执行失败的错误，包含分类结果和捕获的标准错误输出
+type TerraformError struct {
+	Command string // Terraform 子命令，如 plan、apply
+	Kind    error  // 错误类型（ErrQuotaExceeded 等），无法分类时为 nil
+	Stderr  string // 捕获的标准错误输出
+	Err     error  // 命令执行错误（通常为 *exec.ExitError）
+}
+
+// newTerraformError 根据标准错误输出对命令执行失败进行分类
+func newTerraformError(command, stderr string, err error) *TerraformError {
+	return &TerraformError{
+		Command: command,
+		Kind:    classifyTerraformError(stderr),
+		Stderr:  stderr,
+		Err:     err,
+	}
+}
+
+// Error 返回错误类型和 Terraform 输出中的第一条错误信息
+func (e *TerraformError) Error() string {
+	summary := terraformErrorSummary(e.Stderr)
+	switch {
+	case e.Kind != nil && summary != "":
+		return fmt.Sprintf("%v: %s", e.Kind, summary)
+	case e.Kind != nil:
+		return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+	case summary != "":
+		return fmt.Sprintf("%s (%v)", summary, e.Err)
+	default:
+		return e.Err.Error()
+	}
+}
+
+// Unwrap 同时支持 errors.Is(err, ErrQuotaExceeded) 和 errors.As(err, &exitErr)
+func (e *TerraformError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// newInterruptedError 命令因 context 取消（Ctrl-C）而中断时的错误，不按输出内容分类
+func newInterruptedError(command, stderr string, err error) *TerraformError {
+	return &TerraformError{
+		Command: command,
+		Kind:    ErrInterrupted,
+		Stderr:  stderr,
+		Err:     err,
+	}
+}
+
+// 状态锁错误输出中的锁信息（Lock Info 中的 ID 和 Who）
+var (
+	stateLockIDPattern  = regexp.MustCompile(`(?m)^[\s│]*ID:\s+(\S+)`)
+	stateLockWhoPattern = regexp.MustCompile(`(?m)^[\s│]*Who:\s+(\S+)`)
+)
+
+// stateLockInfo 从状态锁错误中解析锁 ID 和持有者（user@host）
+func stateLockInfo(err error) (id, who string) {
+	var tfErr *TerraformError
+	if !errors.As(err, &tfErr) {
+		return "", ""
+	}
+	if m := stateLockIDPattern.FindStringSubmatch(tfErr.Stderr); m != nil {
+		id = m[1]
+	}
+	if m := stateLockWhoPattern.FindStringSubmatch(tfErr.Stderr); m != nil {
+		who = m[1]
+	}
+	return id, who
+}
+
+// classifyTerraformError 根据标准错误输出判断错误类型，无法判断时返回 nil
+func classifyTerraformError(stderr string) error {
+	text := strings.ToLower(stderr)
+	for _, rule := range terraformErrorRules {
+		for _, pattern := range rule.patterns {
+			if strings.Contains(text, pattern) {
+				return rule.kind
+			}
+		}
+	}
+	return nil
+}
+
+// terraformErrorSummary 提取 Terraform 输出中的第一条错误信息（去掉边框字符和 Error: 前缀）
+func terraformErrorSummary(stderr string) string {
+	const maxLen = 300
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "│╷╵"))
+		if !strings.HasPrefix(line, "Error:") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "Error:"))
+		if runes := []rune(line); len(runes) > maxLen {
+			line = string(runes[:maxLen]) + "..."
+		}
+		return line
+	}
+	return ""
+}
+
+// RetryAction 失败后的处理方式
+type RetryAction int
+
+const (
+	// RetryGiveUp 放弃（认证失败、余额不足、配置错误等重试无法解决的错误）
+	RetryGiveUp RetryAction = iota
+	// RetryBackoff 在原位置等待后重试（限流、网络等临时错误）
+	RetryBackoff
+	// RetryAlternateLocation 换可用区或区域重试（配额或库存不足）
+	RetryAlternateLocation
+)
+
+// RetryPolicy 统一的 Terraform 失败重试策略，根据错误类型决定退避重试、更换位置或放弃
+type RetryPolicy struct {
+	MaxAttempts int           // 同一位置最多执行次数（含首次）
+	BaseDelay   time.Duration // 首次退避等待时间，之后每次翻倍
+	MaxDelay    time.Duration // 最长退避等待时间
+}
+
+// DefaultRetryPolicy 默认重试策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   5 * time.Second,
+	MaxDelay:    time.Minute,
+}
+
+// Action 根据错误类型返回处理方式
+func (p RetryPolicy) Action(err error) RetryAction {
+	switch {
+	case errors.Is(err, ErrRateLimited), errors.Is(err, ErrTransient):
+		return RetryBackoff
+	case errors.Is(err, ErrQuotaExceeded), errors.Is(err, ErrOutOfStock):
+		return RetryAlternateLocation
+	default:
+		return RetryGiveUp
+	}
+}
+
+// Delay 返回第 attempt 次失败后的退避等待时间
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Do 执行 fn，遇到限流或临时错误时退避重试，其他错误直接返回
+func (p RetryPolicy) Do(ctx context.Context, name string, fn func() error) error {
+	log := logger.GetLogger()
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || p.Action(err) != RetryBackoff {
+			return err
+		}
+
+		delay := p.Delay(attempt)
+		log.Warn("%s失败，%v 后重试 (%d/%d): %v", name, delay, attempt, p.MaxAttempts, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,537 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FakeRunner 在内存中模拟 Terraform 的执行器，用于离线测试部署、重试和销毁逻辑
+// 每个工作目录维护一份状态：plan 根据变量计算资源（node_count 个实例和一个网络），apply 写入状态，destroy 清空状态，
+// plan 支持 -destroy、-target 和 -replace，taint 标记的资源在下次 plan 时替换；
+// 通过 FailOn 可以让指定命令按变量（如 region）输出云服务商的错误信息并失败
+type FakeRunner struct {
+	mu       sync.Mutex
+	states   map[string]*FakeState
+	failures []*FakeFailure
+	calls    []FakeCall
+}
+
+// FakeState 模拟的 Terraform 状态
+type FakeState struct {
+	Vars      map[string]string // 最近一次 apply 使用的变量
+	Resources []string          // 状态中的资源地址
+	Tainted   []string          // 标记为需要重建的资源地址
+	Serial    int               // 状态序号，每次修改状态时加 1
+}
+
+// FakeCall 一次命令调用记录
+type FakeCall struct {
+	Dir     string
+	Command string            // 子命令，如 plan、apply、state
+	Args    []string          // 完整参数
+	Vars    map[string]string // 生效的变量（应用计划文件时为计划中的变量）
+}
+
+// FakeFailure 预设的命令失败
+type FakeFailure struct {
+	Command string            // 子命令，如 plan、apply、destroy
+	Vars    map[string]string // 只在变量全部匹配时失败（如 {"region": "ap-shanghai"}），为空表示总是失败
+	Stderr  string            // 失败时 Terraform 输出的错误信息，用于错误分类，如 "LimitExceeded.SpotQuota"
+	Times   int               // 失败次数，0 表示一直失败
+}
+
+// FakeExitError 模拟的命令退出错误
+type FakeExitError struct {
+	Code int
+}
+
+// Error 返回与 exec.ExitError 相同格式的错误信息
+func (e *FakeExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode 返回退出码
+func (e *FakeExitError) ExitCode() int {
+	return e.Code
+}
+
+// fakePlan 模拟的计划文件内容
+type fakePlan struct {
+	RefreshOnly bool              `json:"refresh_only,omitempty"`
+	Vars        map[string]string `json:"vars"`
+	Resources   []string          `json:"resources"`
+	Replace     []string          `json:"replace,omitempty"` // 需要替换的资源（-replace 或已 taint）
+}
+
+// NewFakeRunner 创建模拟执行器
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{states: make(map[string]*FakeState)}
+}
+
+// FailOn 预设命令失败，按添加顺序匹配
+func (f *FakeRunner) FailOn(failure FakeFailure) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, &failure)
+}
+
+// State 返回工作目录的状态副本，从未 apply 过时返回 nil
+func (f *FakeRunner) State(dir string) *FakeState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state, ok := f.states[dir]
+	if !ok {
+		return nil
+	}
+	return &FakeState{
+		Vars:      copyVars(state.Vars),
+		Resources: append([]string(nil), state.Resources...),
+		Tainted:   append([]string(nil), state.Tainted...),
+		Serial:    state.Serial,
+	}
+}
+
+// Calls 返回指定子命令的调用记录，command 为空时返回全部
+func (f *FakeRunner) Calls(command string) []FakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []FakeCall
+	for _, call := range f.calls {
+		if command == "" || call.Command == command {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Run 模拟执行 Terraform 命令
+func (f *FakeRunner) Run(ctx context.Context, req RunRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	command := req.Args[0]
+	vars, flags, positional := parseFakeArgs(req.Args[1:])
+
+	// 应用计划文件时，变量取自计划
+	var plan *fakePlan
+	if command == "apply" && len(positional) > 0 {
+		p, err := readFakePlan(req.Dir, positional[0])
+		if err != nil {
+			fmt.Fprintf(writerOrDiscard(req.Stderr), "Error: Failed to load %q as a plan file: %v\n", positional[0], err)
+			return &FakeExitError{Code: 1}
+		}
+		plan = p
+		vars = p.Vars
+	}
+
+	f.calls = append(f.calls, FakeCall{Dir: req.Dir, Command: command, Args: append([]string(nil), req.Args...), Vars: copyVars(vars)})
+
+	if failure := f.matchFailure(command, vars); failure != nil {
+		fmt.Fprintf(writerOrDiscard(req.Stderr), "╷\n│ Error: %s\n│\n╵\n", failure.Stderr)
+		return &FakeExitError{Code: 1}
+	}
+
+	state := f.states[req.Dir]
+	switch command {
+	case "init", "validate", "providers", "force-unlock":
+		return nil
+
+	case "plan":
+		planFile := flags["-out"]
+		p := &fakePlan{Vars: vars, Resources: fakeResources(vars)}
+		if _, ok := flags["-refresh-only"]; ok {
+			// 模拟的云端资源与状态始终一致
+			p = &fakePlan{RefreshOnly: true}
+			if state != nil {
+				p.Vars, p.Resources = state.Vars, state.Resources
+			}
+		} else {
+			if _, ok := flags["-destroy"]; ok {
+				p.Resources = nil
+			}
+			targets := fakeFlagValues(req.Args[1:], "-target")
+			if len(targets) > 0 {
+				p.Resources = fakeTargetResources(state, p.Resources, targets)
+			}
+			p.Replace = fakeFlagValues(req.Args[1:], "-replace")
+			if state != nil {
+				for _, address := range state.Tainted {
+					if fakeContains(p.Resources, address) && (len(targets) == 0 || fakeTargeted(address, targets)) {
+						p.Replace = append(p.Replace, address)
+					}
+				}
+			}
+		}
+		if planFile == "" {
+			return nil
+		}
+		data, _ := json.Marshal(p)
+		return os.WriteFile(resolveFakePath(req.Dir, planFile), data, 0644)
+
+	case "apply":
+		if plan == nil {
+			plan = &fakePlan{Vars: vars, Resources: fakeResources(vars)}
+		}
+		if state == nil {
+			state = &FakeState{}
+			f.states[req.Dir] = state
+		}
+		if !plan.RefreshOnly {
+			state.Resources = plan.Resources
+			var tainted []string
+			for _, address := range state.Tainted {
+				if fakeContains(state.Resources, address) && !fakeContains(plan.Replace, address) {
+					tainted = append(tainted, address)
+				}
+			}
+			state.Tainted = tainted
+		}
+		state.Vars = copyVars(plan.Vars)
+		state.Serial++
+		return nil
+
+	case "destroy":
+		if state != nil {
+			state.Resources = nil
+			state.Tainted = nil
+			state.Serial++
+		}
+		return nil
+
+	case "taint", "untaint":
+		if len(positional) == 0 || state == nil || !fakeContains(state.Resources, positional[0]) {
+			fmt.Fprintf(writerOrDiscard(req.Stderr), "Error: No such resource instance: %v\n", positional)
+			return &FakeExitError{Code: 1}
+		}
+		address := positional[0]
+		if command == "taint" {
+			if !fakeContains(state.Tainted, address) {
+				state.Tainted = append(state.Tainted, address)
+			}
+		} else {
+			if !fakeContains(state.Tainted, address) {
+				fmt.Fprintf(writerOrDiscard(req.Stderr), "Error: Resource instance is not tainted: %s\n", address)
+				return &FakeExitError{Code: 1}
+			}
+			var tainted []string
+			for _, a := range state.Tainted {
+				if a != address {
+					tainted = append(tainted, a)
+				}
+			}
+			state.Tainted = tainted
+		}
+		state.Serial++
+		return nil
+
+	case "output":
+		outputs := make(map[string]interface{})
+		if state != nil && len(state.Resources) > 0 {
+			outputs["region"] = map[string]string{"value": state.Vars["region"]}
+			outputs["instance_count"] = map[string]int{"value": countFakeInstances(state.Resources)}
+		}
+		return json.NewEncoder(writerOrDiscard(req.Stdout)).Encode(outputs)
+
+	case "state":
+		if len(positional) == 0 {
+			return fmt.Errorf("state 子命令缺少参数")
+		}
+		switch positional[0] {
+		case "list":
+			if state != nil {
+				for _, address := range state.Resources {
+					fmt.Fprintln(writerOrDiscard(req.Stdout), address)
+				}
+			}
+		case "pull":
+			if state != nil {
+				fmt.Fprintf(writerOrDiscard(req.Stdout), `{"version": 4, "serial": %d, "lineage": "fake"}`+"\n", state.Serial)
+			}
+		case "mv":
+			if len(positional) != 3 || state == nil || !fakeContains(state.Resources, positional[1]) || fakeContains(state.Resources, positional[2]) {
+				fmt.Fprintf(writerOrDiscard(req.Stderr), "Error: Invalid state move: %v\n", positional[1:])
+				return &FakeExitError{Code: 1}
+			}
+			for i, address := range state.Resources {
+				if address == positional[1] {
+					state.Resources[i] = positional[2]
+				}
+			}
+			for i, address := range state.Tainted {
+				if address == positional[1] {
+					state.Tainted[i] = positional[2]
+				}
+			}
+			state.Serial++
+		}
+		return nil
+
+	case "show":
+		if len(positional) > 0 {
+			p, err := readFakePlan(req.Dir, positional[0])
+			if err != nil {
+				fmt.Fprintf(writerOrDiscard(req.Stderr), "Error: Failed to read the given file as a state or plan file: %v\n", err)
+				return &FakeExitError{Code: 1}
+			}
+			return json.NewEncoder(writerOrDiscard(req.Stdout)).Encode(fakePlanJSON(p, state))
+		}
+		return json.NewEncoder(writerOrDiscard(req.Stdout)).Encode(fakeStateJSON(state))
+	}
+
+	fmt.Fprintf(writerOrDiscard(req.Stderr), "Error: 模拟执行器不支持的命令: %s\n", command)
+	return &FakeExitError{Code: 1}
+}
+
+// matchFailure 查找匹配的预设失败，并减少剩余失败次数
+func (f *FakeRunner) matchFailure(command string, vars map[string]string) *FakeFailure {
+	for i, failure := range f.failures {
+		if failure.Command != command {
+			continue
+		}
+		matched := true
+		for k, v := range failure.Vars {
+			if vars[k] != v {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		if failure.Times > 0 {
+			failure.Times--
+			if failure.Times == 0 {
+				f.failures = append(f.failures[:i], f.failures[i+1:]...)
+			}
+		}
+		return failure
+	}
+	return nil
+}
+
+// parseFakeArgs 解析命令参数中的 -var、其他标志和位置参数
+func parseFakeArgs(args []string) (map[string]string, map[string]string, []string) {
+	vars := make(map[string]string)
+	flags := make(map[string]string)
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case (arg == "-var" || arg == "-backend-config") && i+1 < len(args):
+			i++
+			if arg == "-var" {
+				if k, v, ok := strings.Cut(args[i], "="); ok {
+					vars[k] = v
+				}
+			}
+		case strings.HasPrefix(arg, "-"):
+			k, v, _ := strings.Cut(arg, "=")
+			flags[k] = v
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	return vars, flags, positional
+}
+
+// fakeFlagValues 可重复标志的所有取值，如多个 -target=<地址>
+func fakeFlagValues(args []string, name string) []string {
+	var values []string
+	for _, arg := range args {
+		if v, ok := strings.CutPrefix(arg, name+"="); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// fakeTargetResources 只处理 -target 指定的资源：其他资源保持状态中的样子，指定的资源按计划增删
+func fakeTargetResources(state *FakeState, planned, targets []string) []string {
+	var current []string
+	if state != nil {
+		current = state.Resources
+	}
+	var resources []string
+	for _, address := range current {
+		if !fakeTargeted(address, targets) || fakeContains(planned, address) {
+			resources = append(resources, address)
+		}
+	}
+	for _, address := range planned {
+		if fakeTargeted(address, targets) && !fakeContains(current, address) {
+			resources = append(resources, address)
+		}
+	}
+	return resources
+}
+
+// fakeTargeted 资源是否属于 -target 指定的资源（地址相同，或为指定资源的实例）
+func fakeTargeted(address string, targets []string) bool {
+	for _, target := range targets {
+		if address == target || strings.HasPrefix(address, target+"[") || strings.HasPrefix(address, target+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeContains 地址列表中是否包含指定地址
+func fakeContains(addresses []string, address string) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeResources 根据变量计算计划中的资源：node_count 个实例（默认 1 个）和一个网络
+func fakeResources(vars map[string]string) []string {
+	count := 1
+	if n, err := strconv.Atoi(vars["node_count"]); err == nil && n >= 0 {
+		count = n
+	}
+	resources := []string{"fake_network.main"}
+	for i := 0; i < count; i++ {
+		resources = append(resources, fmt.Sprintf("fake_instance.node[%d]", i))
+	}
+	return resources
+}
+
+// countFakeInstances 统计资源中的实例数量
+func countFakeInstances(resources []string) int {
+	count := 0
+	for _, address := range resources {
+		if strings.HasPrefix(address, "fake_instance.") {
+			count++
+		}
+	}
+	return count
+}
+
+// fakeResourceType 资源地址中的资源类型
+func fakeResourceType(address string) string {
+	return strings.SplitN(address, ".", 2)[0]
+}
+
+// fakePlanJSON 生成与 terraform show -json 计划格式一致的资源变更
+func fakePlanJSON(p *fakePlan, state *FakeState) map[string]interface{} {
+	if p.RefreshOnly {
+		// 模拟的云端资源与状态始终一致，没有漂移
+		return map[string]interface{}{"resource_drift": []interface{}{}, "resource_changes": []interface{}{}}
+	}
+
+	current := make(map[string]bool)
+	if state != nil {
+		for _, address := range state.Resources {
+			current[address] = true
+		}
+	}
+	planned := make(map[string]bool)
+	var changes []map[string]interface{}
+	for _, address := range p.Resources {
+		planned[address] = true
+		actions := []string{"create"}
+		if current[address] {
+			actions = []string{"no-op"}
+			if fakeContains(p.Replace, address) {
+				actions = []string{"delete", "create"}
+			}
+		}
+		changes = append(changes, fakeResourceChange(address, actions, map[string]interface{}{"instance_type": p.Vars["instance_type"]}))
+	}
+	var deleted []string
+	for address := range current {
+		if !planned[address] {
+			deleted = append(deleted, address)
+		}
+	}
+	sort.Strings(deleted)
+	for _, address := range deleted {
+		changes = append(changes, fakeResourceChange(address, []string{"delete"}, nil))
+	}
+	return map[string]interface{}{"resource_changes": changes}
+}
+
+// fakeResourceChange 单个资源的变更
+func fakeResourceChange(address string, actions []string, after map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"address": address,
+		"mode":    "managed",
+		"type":    fakeResourceType(address),
+		"change":  map[string]interface{}{"actions": actions, "after": after},
+	}
+}
+
+// fakeStateJSON 生成与 terraform show -json 状态格式一致的资源列表
+func fakeStateJSON(state *FakeState) map[string]interface{} {
+	var resources []map[string]interface{}
+	if state != nil {
+		for i, address := range state.Resources {
+			resources = append(resources, map[string]interface{}{
+				"address": address,
+				"type":    fakeResourceType(address),
+				"name":    strings.SplitN(address, ".", 2)[1],
+				"values": map[string]interface{}{
+					"id":            fmt.Sprintf("fake-%d", i),
+					"region":        state.Vars["region"],
+					"instance_type": state.Vars["instance_type"],
+					"status":        "Running",
+				},
+			})
+		}
+	}
+	return map[string]interface{}{"values": map[string]interface{}{"root_module": map[string]interface{}{"resources": resources}}}
+}
+
+// readFakePlan 读取模拟的计划文件
+func readFakePlan(dir, planFile string) (*fakePlan, error) {
+	data, err := os.ReadFile(resolveFakePath(dir, planFile))
+	if err != nil {
+		return nil, err
+	}
+	var p fakePlan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// resolveFakePath 将相对路径解析为工作目录下的路径
+func resolveFakePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// copyVars 复制变量
+func copyVars(vars map[string]string) map[string]string {
+	if vars == nil {
+		return nil
+	}
+	result := make(map[string]string, len(vars))
+	for k, v := range vars {
+		result[k] = v
+	}
+	return result
+}
+
+// writerOrDiscard 输出目标为空时丢弃输出
+func writerOrDiscard(w io.Writer) io.Writer {
+	if w == nil {
+		return io.Discard
+	}
+	return w
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// 官方发行地址
+const (
+	terraformReleaseURL   = "https://releases.hashicorp.com/terraform/%[1]s"
+	terraformCheckpoint   = "https://checkpoint-api.hashicorp.com/v1/check/terraform"
+	openTofuReleaseURL    = "https://github.com/opentofu/opentofu/releases/download/v%[1]s"
+	openTofuLatestRelease = "https://api.github.com/repos/opentofu/opentofu/releases/latest"
+)
+
+// openTofuSigningKeyURL OpenTofu 发布公钥地址，未配置 [terraform] signing_keys 时安装 OpenTofu 前下载
+const openTofuSigningKeyURL = "https://get.opentofu.org/opentofu.asc"
+
+// terraformDownloadTimeout 单个文件的下载超时
+const terraformDownloadTimeout = 10 * time.Minute
+
+// TerraformInstaller Terraform/OpenTofu 安装器
+type TerraformInstaller interface {
+	// Install 下载并安装指定版本，version 为空时安装最新版本
+	// SHA256SUMS 经发布公钥验证签名、发行包经 SHA256SUMS 校验后安装为 <安装目录>/<发行版>_<版本>，
+	// 并设为默认的 <安装目录>/<发行版>
+	Install(ctx context.Context, flavor, version string) (*TerraformBinary, error)
+
+	// List 列出已安装的版本
+	List() ([]*TerraformBinary, error)
+}
+
+// terraformInstaller 安装器实现
+type terraformInstaller struct {
+	binDir      string
+	mirror      string
+	signingKeys string
+	httpClient  *http.Client
+}
+
+// NewTerraformInstaller 创建安装器，mirror 为空时使用配置中的镜像
+func NewTerraformInstaller(cfg *config.Config, mirror string) TerraformInstaller {
+	if mirror == "" {
+		mirror = cfg.Terraform.Mirror
+	}
+	return &terraformInstaller{
+		binDir:      cfg.Terraform.BinDir,
+		mirror:      strings.TrimRight(mirror, "/"),
+		signingKeys: cfg.Terraform.SigningKeys,
+		httpClient:  &http.Client{Timeout: terraformDownloadTimeout},
+	}
+}
+
+// List 列出已安装的版本
+func (i *terraformInstaller) List() ([]*TerraformBinary, error) {
+	return ListInstalledTerraform(i.binDir)
+}
+
+// Install 下载并安装指定版本
+func (i *terraformInstaller) Install(ctx context.Context, flavor, version string) (*TerraformBinary, error) {
+	if flavor != FlavorTerraform && flavor != FlavorOpenTofu {
+		return nil, fmt.Errorf("不支持的发行版: %s（可选 terraform、tofu）", flavor)
+	}
+
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" {
+		latest, err := i.latestVersion(ctx, flavor)
+		if err != nil {
+			return nil, err
+		}
+		version = latest
+	}
+	if _, err := parseTerraformVersion(version); err != nil {
+		return nil, err
+	}
+
+	archive := fmt.Sprintf("%s_%s_%s_%s.zip", flavor, version, runtime.GOOS, runtime.GOARCH)
+	sums := fmt.Sprintf("%s_%s_SHA256SUMS", flavor, version)
+	log := logger.GetLogger()
+	log.Info("获取 %s %s: %s", flavor, version, i.source(flavor, version))
+
+	sumsData, err := i.fetch(ctx, flavor, version, sums)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.verifySignature(ctx, flavor, version, sums, sumsData); err != nil {
+		return nil, err
+	}
+	expected, err := lookupChecksum(sumsData, archive)
+	if err != nil {
+		return nil, err
+	}
+	archiveData, err := i.fetch(ctx, flavor, version, archive)
+	if err != nil {
+		return nil, err
+	}
+	actual := sha256.Sum256(archiveData)
+	if hex.EncodeToString(actual[:]) != expected {
+		return nil, fmt.Errorf("%s 校验失败: 期望 SHA256 %s，实际 %s", archive, expected, hex.EncodeToString(actual[:]))
+	}
+
+	binary, err := extractZipFile(archiveData, binaryFileName(flavor))
+	if err != nil {
+		return nil, fmt.Errorf("解压 %s 失败: %w", archive, err)
+	}
+
+	if err := os.MkdirAll(i.binDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建安装目录失败: %w", err)
+	}
+	versionedPath := filepath.Join(i.binDir, binaryFileName(flavor+"_"+version))
+	if err := writeExecutable(versionedPath, binary); err != nil {
+		return nil, err
+	}
+	if err := writeExecutable(filepath.Join(i.binDir, binaryFileName(flavor)), binary); err != nil {
+		return nil, err
+	}
+	log.Info("已安装 %s %s: %s", flavor, version, versionedPath)
+
+	return &TerraformBinary{Path: versionedPath, Flavor: flavor, Version: version}, nil
+}
+
+// source 返回发行包的下载来源（镜像或官方地址）
+func (i *terraformInstaller) source(flavor, version string) string {
+	if i.mirror != "" {
+		return i.mirror + "/" + flavor + "/" + version
+	}
+	if flavor == FlavorOpenTofu {
+		return fmt.Sprintf(openTofuReleaseURL, version)
+	}
+	return fmt.Sprintf(terraformReleaseURL, version)
+}
+
+// isLocalMirror 镜像是否为本地目录
+func (i *terraformInstaller) isLocalMirror() bool {
+	return i.mirror != "" && !strings.HasPrefix(i.mirror, "http://") && !strings.HasPrefix(i.mirror, "https://")
+}
+
+// fetch 读取发行文件，本地镜像直接读取文件，否则通过 HTTP 下载
+func (i *terraformInstaller) fetch(ctx context.Context, flavor, version, name string) ([]byte, error) {
+	if i.isLocalMirror() {
+		data, err := os.ReadFile(filepath.Join(i.mirror, flavor, version, name))
+		if err != nil {
+			return nil, fmt.Errorf("读取镜像文件失败: %w", err)
+		}
+		return data, nil
+	}
+	return i.get(ctx, i.source(flavor, version)+"/"+name)
+}
+
+// get 通过 HTTP 下载文件
+func (i *terraformInstaller) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建下载请求失败: %w", err)
+	}
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("下载 %s 失败（网络受限时可通过 --mirror 或配置 [terraform] mirror 使用镜像）: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载 %s 失败: HTTP %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("下载 %s 失败: %w", url, err)
+	}
+	return data, nil
+}
+
+// latestVersion 查询最新版本
+// 本地镜像取目录中的最高版本；URL 镜像无法列出版本，需要显式指定
+func (i *terraformInstaller) latestVersion(ctx context.Context, flavor string) (string, error) {
+	if i.isLocalMirror() {
+		return latestMirrorVersion(filepath.Join(i.mirror, flavor))
+	}
+	if i.mirror != "" {
+		return "", fmt.Errorf("使用 URL 镜像时需要指定版本，如 cloudbot terraform install 1.9.5")
+	}
+
+	if flavor == FlavorOpenTofu {
+		data, err := i.get(ctx, openTofuLatestRelease)
+		if err != nil {
+			return "", err
+		}
+		var release struct {
+			TagName string `json:"tag_name"`
+		}
+		if err := json.Unmarshal(data, &release); err != nil || release.TagName == "" {
+			return "", fmt.Errorf("解析 OpenTofu 最新版本失败: %s", strings.TrimSpace(string(data)))
+		}
+		return strings.TrimPrefix(release.TagName, "v"), nil
+	}
+
+	data, err := i.get(ctx, terraformCheckpoint)
+	if err != nil {
+		return "", err
+	}
+	var check struct {
+		CurrentVersion string `json:"current_version"`
+	}
+	if err := json.Unmarshal(data, &check); err != nil || check.CurrentVersion == "" {
+		return "", fmt.Errorf("解析 Terraform 最新版本失败: %s", strings.TrimSpace(string(data)))
+	}
+	return check.CurrentVersion, nil
+}
+
+// latestMirrorVersion 返回本地镜像目录中的最高版本
+func latestMirrorVersion(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("读取镜像目录失败: %w", err)
+	}
+	latest := ""
+	var latestVersion terraformVersion
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		v, err := parseTerraformVersion(entry.Name())
+		if err != nil {
+			continue
+		}
+		if latest == "" || v.compare(latestVersion) > 0 {
+			latest, latestVersion = entry.Name(), v
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("镜像目录 %s 中没有可用版本", dir)
+	}
+	return strings.TrimPrefix(latest, "v"), nil
+}
+
+// verifySignature 使用发布公钥验证 SHA256SUMS 的签名
+// 本地目录镜像中没有签名文件时视为由管理员预先校验，跳过签名验证
+func (i *terraformInstaller) verifySignature(ctx context.Context, flavor, version, sums string, sumsData []byte) error {
+	// Terraform 的签名文件为 <SHA256SUMS>.sig，OpenTofu 为 <SHA256SUMS>.gpgsig
+	sigName := sums + ".sig"
+	if flavor == FlavorOpenTofu {
+		sigName = sums + ".gpgsig"
+	}
+	if i.isLocalMirror() {
+		if _, err := os.Stat(filepath.Join(i.mirror, flavor, version, sigName)); os.IsNotExist(err) {
+			logger.GetLogger().Warn("本地镜像 %s 中没有 %s，不验证 SHA256SUMS 的签名", i.mirror, sigName)
+			return nil
+		}
+	}
+
+	keyring, err := i.releaseKeys(ctx, flavor)
+	if err != nil {
+		return err
+	}
+	sig, err := i.fetch(ctx, flavor, version, sigName)
+	if err != nil {
+		return err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(sig), []byte("-----BEGIN PGP")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(sumsData), bytes.NewReader(sig), nil)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(sumsData), bytes.NewReader(sig), nil)
+	}
+	if err != nil {
+		return fmt.Errorf("%s 签名验证失败: %w", sums, err)
+	}
+	return nil
+}
+
+// releaseKeys 返回验证签名使用的发布公钥
+// 配置了 [terraform] signing_keys 时使用配置的公钥；否则 Terraform 使用内置的 HashiCorp 公钥，
+// OpenTofu 从 get.opentofu.org 下载公钥（与 GitHub 上的发行包来源不同）
+func (i *terraformInstaller) releaseKeys(ctx context.Context, flavor string) (openpgp.EntityList, error) {
+	if i.signingKeys != "" {
+		data, err := os.ReadFile(i.signingKeys)
+		if err != nil {
+			return nil, fmt.Errorf("读取发布公钥失败: %w", err)
+		}
+		return readSigningKeys(i.signingKeys, data)
+	}
+	if flavor == FlavorOpenTofu {
+		data, err := i.get(ctx, openTofuSigningKeyURL)
+		if err != nil {
+			return nil, fmt.Errorf("下载 OpenTofu 发布公钥失败（离线环境可通过 [terraform] signing_keys 指定公钥文件）: %w", err)
+		}
+		return readSigningKeys(openTofuSigningKeyURL, data)
+	}
+	return readSigningKeys("HashiCorp", []byte(hashicorpReleaseKey))
+}
+
+// readSigningKeys 解析发布公钥，支持 ASCII armor 和二进制格式
+func readSigningKeys(source string, data []byte) (openpgp.EntityList, error) {
+	var keyring openpgp.EntityList
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN PGP")) {
+		keyring, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	} else {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析发布公钥 %s 失败: %w", source, err)
+	}
+	return keyring, nil
+}
+
+// lookupChecksum 从 SHA256SUMS 中查找文件的校验值
+func lookupChecksum(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("SHA256SUMS 中没有 %s（当前平台 %s/%s 可能没有发行包）", name, runtime.GOOS, runtime.GOARCH)
+}
+
+// extractZipFile 从 zip 包中取出指定文件
+func extractZipFile(data []byte, name string) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range reader.File {
+		if file.Name != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("发行包中没有 %s", name)
+}
+
+// writeExecutable 写入可执行文件，先写临时文件再重命名，避免覆盖正在使用的文件时出错
+func writeExecutable(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0755); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/lucksec/cloudbot/internal/config"
+)
+
+// writeTerraformMirror 在本地镜像目录中写入 Terraform 发行包、SHA256SUMS 及其签名
+func writeTerraformMirror(t *testing.T, signer *openpgp.Entity, version string) string {
+	t.Helper()
+	mirror := t.TempDir()
+	dir := filepath.Join(mirror, FlavorTerraform, version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create(binaryFileName(FlavorTerraform))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("#!/bin/sh\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archiveName := fmt.Sprintf("terraform_%s_%s_%s.zip", version, runtime.GOOS, runtime.GOARCH)
+	sum := sha256.Sum256(archive.Bytes())
+	sums := []byte(hex.EncodeToString(sum[:]) + "  " + archiveName + "\n")
+
+	var sig bytes.Buffer
+	if err := openpgp.DetachSign(&sig, signer, bytes.NewReader(sums), nil); err != nil {
+		t.Fatal(err)
+	}
+	sumsName := fmt.Sprintf("terraform_%s_SHA256SUMS", version)
+	for name, data := range map[string][]byte{archiveName: archive.Bytes(), sumsName: sums, sumsName + ".sig": sig.Bytes()} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return mirror
+}
+
+func TestTerraformInstallerVerifiesSignature(t *testing.T) {
+	release, err := openpgp.NewEntity("release", "", "release@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := openpgp.NewEntity("other", "", "other@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "release-keys.gpg")
+	var keys bytes.Buffer
+	if err := release.Serialize(&keys); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keys.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	install := func(mirror, signingKeys string) error {
+		cfg := &config.Config{}
+		cfg.Terraform.BinDir = t.TempDir()
+		cfg.Terraform.SigningKeys = signingKeys
+		_, err := NewTerraformInstaller(cfg, mirror).Install(context.Background(), FlavorTerraform, "1.9.5")
+		return err
+	}
+
+	if err := install(writeTerraformMirror(t, release, "1.9.5"), keyFile); err != nil {
+		t.Fatalf("签名正确时安装失败: %v", err)
+	}
+	if err := install(writeTerraformMirror(t, other, "1.9.5"), keyFile); err == nil || !strings.Contains(err.Error(), "签名验证失败") {
+		t.Errorf("签名不是发布公钥签发时应拒绝安装: %v", err)
+	}
+
+	// 本地镜像中没有签名文件时视为已由管理员校验
+	unsigned := writeTerraformMirror(t, other, "1.9.5")
+	if err := os.Remove(filepath.Join(unsigned, FlavorTerraform, "1.9.5", "terraform_1.9.5_SHA256SUMS.sig")); err != nil {
+		t.Fatal(err)
+	}
+	if err := install(unsigned, ""); err != nil {
+		t.Errorf("本地镜像没有签名文件时安装失败: %v", err)
+	}
+
+	// 未配置 signing_keys 时使用内置的 HashiCorp 公钥，其他密钥的签名被拒绝
+	server := httptest.NewServer(http.FileServer(http.Dir(writeTerraformMirror(t, release, "1.9.5"))))
+	defer server.Close()
+	if err := install(server.URL, ""); err == nil || !strings.Contains(err.Error(), "签名验证失败") {
+		t.Errorf("内置公钥应拒绝其他密钥的签名: %v", err)
+	}
+	if err := install(server.URL, keyFile); err != nil {
+		t.Errorf("配置公钥后从 URL 镜像安装失败: %v", err)
+	}
+}
+
+func TestHashicorpReleaseKey(t *testing.T) {
+	keyring, err := readSigningKeys("HashiCorp", []byte(hashicorpReleaseKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keyring) != 1 {
+		t.Fatalf("公钥数量 = %d，期望 1", len(keyring))
+	}
+	if got := fmt.Sprintf("%X", keyring[0].PrimaryKey.Fingerprint); got != "C874011F0AB405110D02105534365D9472D7468F" {
+		t.Errorf("HashiCorp 公钥指纹 = %s", got)
+	}
+}
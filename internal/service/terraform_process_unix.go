@@ -0,0 +1,26 @@
+//go:build !windows
+
+package service
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup 让 Terraform 在单独的进程组中运行
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// interruptProcess 向 Terraform 发送 SIGINT，Terraform 收到后完成正在进行的操作并释放状态锁
+func interruptProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Signal(os.Interrupt)
+}
+
+// killProcessGroup 结束 Terraform 及其启动的 provider 插件进程
+func killProcessGroup(cmd *exec.Cmd) {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package service
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup Windows 下 Terraform 与 cloudbot 共用控制台，不需要单独设置
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// interruptProcess Windows 控制台的 Ctrl-C 会同时发给 Terraform，无需转发
+func interruptProcess(cmd *exec.Cmd) error {
+	return nil
+}
+
+// killProcessGroup 结束 Terraform 进程
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
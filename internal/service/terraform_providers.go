@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/lucksec/cloudbot/internal/config"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// ProviderRequirement 模板 required_providers 中声明的 provider
+type ProviderRequirement struct {
+	Name    string // 本地名称，如 alicloud
+	Source  string // 来源地址，如 aliyun/alicloud
+	Version string // 版本约束，如 ~> 1.200，为空表示不限
+}
+
+// String 返回来源地址和版本约束
+func (p ProviderRequirement) String() string {
+	if p.Version == "" {
+		return p.Source
+	}
+	return fmt.Sprintf("%s (%s)", p.Source, p.Version)
+}
+
+// providerInstallationEnv 返回 provider 安装相关的环境变量
+// 所有场景共享插件缓存目录，配置了镜像时生成 Terraform CLI 配置文件并通过 TF_CLI_CONFIG_FILE 指定
+func providerInstallationEnv(cfg *config.Config) map[string]string {
+	log := logger.GetLogger()
+	env := make(map[string]string)
+
+	if cacheDir, err := filepath.Abs(cfg.Terraform.PluginCacheDir); err == nil && cfg.Terraform.PluginCacheDir != "" {
+		env["TF_PLUGIN_CACHE_DIR"] = cacheDir
+		// 新场景没有依赖锁文件，不设置时 Terraform 1.4+ 会跳过缓存重新下载以记录完整校验值
+		env["TF_PLUGIN_CACHE_MAY_BREAK_DEPENDENCY_LOCK_FILE"] = "true"
+	}
+
+	if cfg.Terraform.ProviderMirror == "" && cfg.Terraform.ProviderNetworkMirror == "" {
+		return env
+	}
+	direct := cfg.Terraform.ProviderNetworkMirror == "" || cfg.Terraform.ProviderDirect
+	content, err := providerInstallationConfig(cfg.Terraform.ProviderMirror, cfg.Terraform.ProviderNetworkMirror, direct)
+	if err != nil {
+		log.Warn("生成 Terraform CLI 配置失败，不使用 provider 镜像: %v", err)
+		return env
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Terraform.CLIConfigFile), 0755); err != nil {
+		log.Warn("生成 Terraform CLI 配置失败，不使用 provider 镜像: %v", err)
+		return env
+	}
+	if err := os.WriteFile(cfg.Terraform.CLIConfigFile, []byte(content), 0644); err != nil {
+		log.Warn("生成 Terraform CLI 配置失败，不使用 provider 镜像: %v", err)
+		return env
+	}
+	if cliConfig, err := filepath.Abs(cfg.Terraform.CLIConfigFile); err == nil {
+		env["TF_CLI_CONFIG_FILE"] = cliConfig
+	}
+	return env
+}
+
+// providerInstallationConfig 生成 Terraform CLI 配置的 provider_installation 块
+// direct 为 true 时镜像中没有的 provider 仍从官方 registry 下载；本地镜像中已有的 provider 从 direct 中排除，
+// 否则 Terraform 会同时查询官方 registry 并可能安装镜像之外的更新版本
+func providerInstallationConfig(mirrorDir, networkMirror string, direct bool) (string, error) {
+	var b strings.Builder
+	var mirrored []string
+	b.WriteString("# 由 cloudbot 根据 [terraform] provider_mirror / provider_network_mirror 生成，请勿手动修改\n")
+	b.WriteString("provider_installation {\n")
+	if mirrorDir != "" {
+		path, err := filepath.Abs(mirrorDir)
+		if err != nil {
+			return "", fmt.Errorf("解析 provider 镜像目录失败: %w", err)
+		}
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("provider 镜像目录 %s 不存在，请先运行 cloudbot providers mirror", path)
+		}
+		fmt.Fprintf(&b, "  filesystem_mirror {\n    path = %q\n  }\n", path)
+		mirrored = mirroredProviders(path)
+	}
+	if networkMirror != "" {
+		url := networkMirror
+		if !strings.HasSuffix(url, "/") {
+			url += "/"
+		}
+		fmt.Fprintf(&b, "  network_mirror {\n    url = %q\n  }\n", url)
+	}
+	switch {
+	case !direct:
+	case len(mirrored) == 0:
+		b.WriteString("  direct {}\n")
+	default:
+		b.WriteString("  direct {\n    exclude = [\n")
+		for _, source := range mirrored {
+			fmt.Fprintf(&b, "      %q,\n", source)
+		}
+		b.WriteString("    ]\n  }\n")
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// mirroredProviders 列出本地镜像目录中的 provider（<主机名>/<命名空间>/<类型> 目录）
+func mirroredProviders(mirrorDir string) []string {
+	dirs, _ := filepath.Glob(filepath.Join(mirrorDir, "*", "*", "*"))
+	var sources []string
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		if rel, err := filepath.Rel(mirrorDir, dir); err == nil {
+			sources = append(sources, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// requiredProvidersPattern required_providers 块的开头
+var requiredProvidersPattern = regexp.MustCompile(`required_providers\s*\{`)
+
+// providerEntryPattern required_providers 中的对象形式声明，如 alicloud = { source = "...", version = "..." }
+var providerEntryPattern = regexp.MustCompile(`(?s)([A-Za-z][\w-]*)\s*=\s*\{([^{}]*)\}`)
+
+// providerShorthandPattern required_providers 中的旧式声明，如 aws = "~> 3.0"（来源默认为 hashicorp/<名称>）
+var providerShorthandPattern = regexp.MustCompile(`(?m)^\s*([A-Za-z][\w-]*)\s*=\s*"([^"]*)"`)
+
+var (
+	providerSourcePattern  = regexp.MustCompile(`source\s*=\s*"([^"]+)"`)
+	providerVersionPattern = regexp.MustCompile(`version\s*=\s*"([^"]+)"`)
+)
+
+// parseRequiredProviders 解析 Terraform 配置中 required_providers 声明的 provider
+func parseRequiredProviders(content string) []ProviderRequirement {
+	var providers []ProviderRequirement
+	for _, loc := range requiredProvidersPattern.FindAllStringIndex(content, -1) {
+		block := matchBraceBlock(content[loc[1]:])
+
+		for _, m := range providerEntryPattern.FindAllStringSubmatch(block, -1) {
+			req := ProviderRequirement{Name: m[1], Source: "hashicorp/" + m[1]}
+			if source := providerSourcePattern.FindStringSubmatch(m[2]); source != nil {
+				req.Source = source[1]
+			}
+			if version := providerVersionPattern.FindStringSubmatch(m[2]); version != nil {
+				req.Version = version[1]
+			}
+			providers = append(providers, req)
+		}
+		for _, m := range providerShorthandPattern.FindAllStringSubmatch(providerEntryPattern.ReplaceAllString(block, ""), -1) {
+			providers = append(providers, ProviderRequirement{Name: m[1], Source: "hashicorp/" + m[1], Version: m[2]})
+		}
+	}
+	return providers
+}
+
+// matchBraceBlock 返回从左花括号之后到匹配的右花括号之前的内容
+func matchBraceBlock(s string) string {
+	depth := 1
+	for i, c := range s {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[:i]
+			}
+		}
+	}
+	return s
+}
+
+// CollectTemplateProviders 收集模板目录下所有模板和动态模板声明的 provider（按来源和版本约束去重）
+func CollectTemplateProviders(templateDir string) ([]ProviderRequirement, error) {
+	providers := parseRequiredProviders(aliyunVersionsTf)
+
+	err := filepath.WalkDir(templateDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// 跳过 init 生成的 .terraform 目录
+			if path != templateDir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".tf" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		providers = append(providers, parseRequiredProviders(string(data))...)
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取模板目录失败: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var unique []ProviderRequirement
+	for _, p := range providers {
+		key := p.Source + "\x00" + p.Version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, p)
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		if unique[i].Source != unique[j].Source {
+			return unique[i].Source < unique[j].Source
+		}
+		return unique[i].Version < unique[j].Version
+	})
+	return unique, nil
+}
+
+// DefaultProviderPlatform 当前平台，如 linux_amd64
+func DefaultProviderPlatform() string {
+	return runtime.GOOS + "_" + runtime.GOARCH
+}
+
+// MirrorProviders 将 provider 下载到本地镜像目录
+// 每个 provider 在单独的临时配置中执行 terraform providers mirror，不同模板对同一 provider 的版本约束互不影响
+func MirrorProviders(ctx context.Context, terraformSvc TerraformService, providers []ProviderRequirement, targetDir string, platforms []string) error {
+	log := logger.GetLogger()
+
+	target, err := filepath.Abs(targetDir)
+	if err != nil {
+		return fmt.Errorf("解析镜像目录失败: %w", err)
+	}
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return fmt.Errorf("创建镜像目录失败: %w", err)
+	}
+
+	for _, p := range providers {
+		workDir, err := os.MkdirTemp("", "cloudbot-mirror-*")
+		if err != nil {
+			return fmt.Errorf("创建临时目录失败: %w", err)
+		}
+
+		content := providerRequirementConfig(p)
+		if err := os.WriteFile(filepath.Join(workDir, "versions.tf"), []byte(content), 0644); err != nil {
+			os.RemoveAll(workDir)
+			return fmt.Errorf("写入 versions.tf 失败: %w", err)
+		}
+
+		log.Info("下载 provider 到镜像目录: %s -> %s", p, target)
+		err = terraformSvc.ProvidersMirror(ctx, workDir, target, platforms)
+		os.RemoveAll(workDir)
+		if err != nil {
+			return fmt.Errorf("镜像 provider %s 失败: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// providerRequirementConfig 生成只声明一个 provider 的 Terraform 配置
+func providerRequirementConfig(p ProviderRequirement) string {
+	var b strings.Builder
+	b.WriteString("terraform {\n  required_providers {\n")
+	fmt.Fprintf(&b, "    %s = {\n      source = %q\n", p.Name, p.Source)
+	if p.Version != "" {
+		fmt.Fprintf(&b, "      version = %q\n", p.Version)
+	}
+	b.WriteString("    }\n  }\n}\n")
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProviderInstallationConfig(t *testing.T) {
+	mirror := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(mirror, "registry.terraform.io", "aliyun", "alicloud"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// 本地镜像中已有的 provider 不再从官方 registry 下载
+	content, err := providerInstallationConfig(mirror, "", true)
+	if err != nil {
+		t.Fatalf("生成配置失败: %v", err)
+	}
+	if !strings.Contains(content, "direct {\n    exclude = [\n      \"registry.terraform.io/aliyun/alicloud\",\n    ]\n  }") {
+		t.Errorf("direct 未排除镜像中的 provider:\n%s", content)
+	}
+
+	// 只配置网络镜像且未开启 provider_direct 时不回退到官方 registry
+	content, err = providerInstallationConfig("", "https://mirrors.example.com/terraform", false)
+	if err != nil {
+		t.Fatalf("生成配置失败: %v", err)
+	}
+	if strings.Contains(content, "direct") {
+		t.Errorf("未开启 provider_direct 时不应包含 direct:\n%s", content)
+	}
+}
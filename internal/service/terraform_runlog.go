@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/domain"
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+const (
+	// runsDirName 场景目录下保存运行日志的子目录
+	runsDirName = ".runs"
+	// runIndexFileName 运行记录索引（每行一条 JSON 记录）
+	runIndexFileName = "index.jsonl"
+	// maxScenarioRuns 每个场景保留的运行记录数量，超出时删除最早的记录和日志
+	maxScenarioRuns = 100
+)
+
+// loggedRunOps 需要记录运行日志的 Terraform 子命令
+var loggedRunOps = map[string]bool{
+	"init":    true,
+	"plan":    true,
+	"apply":   true,
+	"destroy": true,
+	"taint":   true,
+	"untaint": true,
+}
+
+// runLog 一次 Terraform 命令的运行日志
+type runLog struct {
+	dir    string
+	file   *os.File
+	record domain.ScenarioRun
+}
+
+// startRunLog 在场景目录的 .runs 下创建运行日志，不需要记录的命令或创建失败时返回 nil
+func startRunLog(workDir string, args []string) *runLog {
+	if len(args) == 0 || !loggedRunOps[args[0]] {
+		return nil
+	}
+
+	dir := filepath.Join(workDir, runsDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		logger.GetLogger().Warn("创建运行日志目录失败: %v", err)
+		return nil
+	}
+
+	now := time.Now()
+	base := now.Format("20060102-150405") + "-" + args[0]
+	id := base
+	// 重试等场景下同一秒内可能多次执行同一命令
+	for i := 2; ; i++ {
+		file, err := os.OpenFile(filepath.Join(dir, id+".log"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			return &runLog{
+				dir:  dir,
+				file: file,
+				record: domain.ScenarioRun{
+					ID:        id,
+					Op:        args[0],
+					Args:      redactRunArgs(args),
+					StartedAt: now,
+				},
+			}
+		}
+		if !os.IsExist(err) {
+			logger.GetLogger().Warn("创建运行日志失败: %v", err)
+			return nil
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// tee 将输出同时写入运行日志
+func (l *runLog) tee(w io.Writer) io.Writer {
+	if w == nil {
+		return l.file
+	}
+	return io.MultiWriter(w, l.file)
+}
+
+// finish 关闭日志并追加运行记录
+func (l *runLog) finish(err error) {
+	l.file.Close()
+
+	l.record.DurationMs = time.Since(l.record.StartedAt).Milliseconds()
+	if err != nil {
+		l.record.ExitCode = -1
+		var exitErr interface{ ExitCode() int }
+		if errors.As(err, &exitErr) {
+			l.record.ExitCode = exitErr.ExitCode()
+		}
+		l.record.Error = err.Error()
+	}
+
+	if err := appendRunRecord(l.dir, l.record); err != nil {
+		logger.GetLogger().Warn("保存运行记录失败: %v", err)
+	}
+}
+
+// redactRunArgs 隐藏命令参数中的凭据
+// -backend-config 专用于传递凭据等不写入配置文件的值，全部隐藏；-var 隐藏凭据和密码类变量
+func redactRunArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 1; i < len(redacted); i++ {
+		flag := redacted[i-1]
+		if flag != "-var" && flag != "-backend-config" {
+			continue
+		}
+		key, _, ok := strings.Cut(redacted[i], "=")
+		if ok && (flag == "-backend-config" || isSensitiveVar(key)) {
+			redacted[i] = key + "=***"
+		}
+	}
+	return redacted
+}
+
+// isSensitiveVar 判断变量是否包含敏感信息
+func isSensitiveVar(name string) bool {
+	if isCredentialVar(name) {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, word := range []string{"password", "secret", "token"} {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendRunRecord 追加运行记录，超出保留数量时删除最早的记录和日志
+func appendRunRecord(dir string, record domain.ScenarioRun) error {
+	runs, err := readRunRecords(dir)
+	if err != nil {
+		return err
+	}
+	runs = append(runs, &record)
+
+	if len(runs) > maxScenarioRuns {
+		for _, old := range runs[:len(runs)-maxScenarioRuns] {
+			os.Remove(filepath.Join(dir, old.ID+".log"))
+		}
+		runs = runs[len(runs)-maxScenarioRuns:]
+	}
+
+	var b strings.Builder
+	for _, run := range runs {
+		data, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	path := filepath.Join(dir, runIndexFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readRunRecords 读取运行记录（按开始时间排序），没有记录时返回空列表
+func readRunRecords(dir string) ([]*domain.ScenarioRun, error) {
+	file, err := os.Open(filepath.Join(dir, runIndexFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取运行记录失败: %w", err)
+	}
+	defer file.Close()
+
+	var runs []*domain.ScenarioRun
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var run domain.ScenarioRun
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			// 跳过损坏的行（如写入时被中断）
+			continue
+		}
+		runs = append(runs, &run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取运行记录失败: %w", err)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartedAt.Before(runs[j].StartedAt) })
+	return runs, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIsSensitiveVar(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "tencentcloud_secret_id", want: true},
+		{name: "access_key", want: true},
+		{name: "aws_secret_access_key", want: true},
+		{name: "db_password", want: true},
+		{name: "Admin_Password", want: true},
+		{name: "client_secret", want: true},
+		{name: "api_token", want: true},
+		{name: "region", want: false},
+		{name: "node_count", want: false},
+		{name: "access_key_name", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSensitiveVar(tt.name); got != tt.want {
+				t.Errorf("isSensitiveVar(%q) = %v，期望 %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactRunArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "普通变量",
+			args: []string{"apply", "-var", "region=ap-guangzhou", "-var", "node_count=2"},
+			want: []string{"apply", "-var", "region=ap-guangzhou", "-var", "node_count=2"}},
+		{name: "凭证变量",
+			args: []string{"apply", "-var", "tencentcloud_secret_key=abc", "-var", "db_password=p=w"},
+			want: []string{"apply", "-var", "tencentcloud_secret_key=***", "-var", "db_password=***"}},
+		{name: "后端配置全部隐藏",
+			args: []string{"init", "-backend-config", "bucket=state", "-backend-config", "access_key=AK"},
+			want: []string{"init", "-backend-config", "bucket=***", "-backend-config", "access_key=***"}},
+		{name: "非变量参数",
+			args: []string{"apply", "-target", "secret=1", "-auto-approve"},
+			want: []string{"apply", "-target", "secret=1", "-auto-approve"}},
+		{name: "缺少变量值", args: []string{"apply", "-var"}, want: []string{"apply", "-var"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := fmt.Sprint(tt.args)
+			got := redactRunArgs(tt.args)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("redactRunArgs() = %v，期望 %v", got, tt.want)
+			}
+			if fmt.Sprint(tt.args) != original {
+				t.Errorf("redactRunArgs 修改了输入参数: %v", tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/lucksec/cloudbot/internal/logger"
+)
+
+// Runner Terraform 命令执行器
+// 默认实现调用 Terraform 可执行文件，测试中使用 FakeRunner 在内存中模拟状态和失败
+type Runner interface {
+	// Run 执行一次 Terraform 命令，命令失败时返回的错误应实现 ExitCode() int
+	Run(ctx context.Context, req RunRequest) error
+}
+
+// RunRequest Terraform 命令执行请求
+type RunRequest struct {
+	Dir    string    // 工作目录（场景目录）
+	Args   []string  // 命令参数，第一个为子命令，如 plan、apply
+	Env    []string  // 环境变量（包含云服务商凭据）
+	Stdout io.Writer // 标准输出，为空时丢弃
+	Stderr io.Writer // 标准错误，为空时丢弃
+}
+
+// TerraformStopTimeout 中断后等待 Terraform 安全停止的最长时间
+var TerraformStopTimeout = 3 * time.Minute
+
+// terraformWaitDelay Terraform 进程退出后等待输出管道关闭的最长时间
+const terraformWaitDelay = 5 * time.Second
+
+// forceStopKey context 中的强制停止信号
+type forceStopKey struct{}
+
+// ContextWithForceStop 在 context 中记录强制停止信号
+// context 取消后 Terraform 会先安全停止，force 关闭时不再等待，直接结束 Terraform 进程
+func ContextWithForceStop(ctx context.Context, force <-chan struct{}) context.Context {
+	return context.WithValue(ctx, forceStopKey{}, force)
+}
+
+// forceStopFromContext 获取 context 中的强制停止信号，未设置时返回 nil（永不触发）
+func forceStopFromContext(ctx context.Context) <-chan struct{} {
+	force, _ := ctx.Value(forceStopKey{}).(<-chan struct{})
+	return force
+}
+
+// execRunner 调用 Terraform 可执行文件的执行器
+// context 中带有项目要求的版本时，从默认可执行文件和安装目录中选择满足要求的最高版本
+type execRunner struct {
+	execPath string
+	binDir   string
+
+	mu       sync.Mutex
+	detected map[string]*TerraformBinary // 已识别的可执行文件，按路径缓存
+}
+
+// NewExecRunner 创建调用 Terraform 可执行文件的执行器
+// binDir 为 cloudbot terraform install 的安装目录，为空时只使用 execPath
+func NewExecRunner(execPath, binDir string) Runner {
+	return &execRunner{
+		execPath: execPath,
+		binDir:   binDir,
+		detected: make(map[string]*TerraformBinary),
+	}
+}
+
+// Run 执行 Terraform 命令
+// context 取消时先发送中断信号让 Terraform 安全停止（释放状态锁并写入已创建的资源），
+// 收到强制停止信号（见 ContextWithForceStop）或超过 TerraformStopTimeout 后再结束进程
+func (r *execRunner) Run(ctx context.Context, req RunRequest) error {
+	execPath, err := r.selectBinary(ctx)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(execPath, req.Args...)
+	cmd.Dir = req.Dir
+	cmd.Env = req.Env
+	cmd.Stdout = req.Stdout
+	cmd.Stderr = req.Stderr
+	// Terraform 使用单独的进程组，终端的 Ctrl-C 只发给 cloudbot，由 cloudbot 决定何时转发
+	setProcessGroup(cmd)
+	// Terraform 退出后不再等待仍占用输出管道的子进程（如未退出的 provider 插件）
+	cmd.WaitDelay = terraformWaitDelay
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	log := logger.GetLogger()
+	log.Warn("已通知 Terraform 安全停止: %s %s", execPath, req.Args[0])
+	if err := interruptProcess(cmd); err != nil {
+		log.Warn("发送中断信号失败，强制停止 Terraform: %v", err)
+		killProcessGroup(cmd)
+		return <-done
+	}
+
+	timer := time.NewTimer(TerraformStopTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-forceStopFromContext(ctx):
+		log.Warn("强制停止 Terraform，状态锁可能未释放，下次部署或销毁时会自动解锁")
+	case <-timer.C:
+		log.Warn("Terraform 在 %v 内未停止，强制结束", TerraformStopTimeout)
+	}
+	killProcessGroup(cmd)
+	return <-done
+}
+
+// selectBinary 选择满足项目版本要求的可执行文件
+func (r *execRunner) selectBinary(ctx context.Context) (string, error) {
+	req := requiredTerraformFromContext(ctx)
+	if req == nil {
+		return r.execPath, nil
+	}
+
+	if b := r.detect(r.execPath); b != nil && req.Allows(b) {
+		return b.Path, nil
+	}
+	if r.binDir != "" {
+		installed, err := ListInstalledTerraform(r.binDir)
+		if err != nil {
+			return "", err
+		}
+		// 已按版本从高到低排序
+		for _, b := range installed {
+			if req.Allows(b) {
+				return b.Path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("未找到满足版本要求 %q 的 Terraform，请运行 cloudbot terraform install <版本> 安装", req.Raw)
+}
+
+// detect 识别可执行文件的发行版和版本，识别失败时返回 nil
+func (r *execRunner) detect(path string) *TerraformBinary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if b, ok := r.detected[path]; ok {
+		return b
+	}
+	b, err := DetectTerraformBinary(path)
+	if err != nil {
+		b = nil
+	}
+	r.detected[path] = b
+	return b
+}
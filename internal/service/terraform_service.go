@@ -1,11 +1,14 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"regexp"
 	"strings"
 
 	"github.com/lucksec/cloudbot/internal/config"
@@ -18,14 +21,30 @@ type TerraformService interface {
 	// Init 初始化 Terraform
 	Init(ctx context.Context, workDir string) error
 
+	// InitWithOptions 初始化 Terraform（支持远程后端参数和状态迁移）
+	InitWithOptions(ctx context.Context, workDir string, opts InitOptions) error
+
 	// Plan 执行 Terraform plan
 	// 可选传入 vars，在执行时通过 -var 形式传递
 	Plan(ctx context.Context, workDir string, vars map[string]string) error
 
+	// PlanToFile 执行 Terraform plan 并将计划保存到 planFile（plan -out）
+	PlanToFile(ctx context.Context, workDir, planFile string, vars map[string]string) error
+
+	// PlanToFileWithOptions 执行 Terraform plan 并保存计划（支持 -destroy、-target 和 -replace）
+	PlanToFileWithOptions(ctx context.Context, workDir, planFile string, vars map[string]string, opts PlanOptions) error
+
+	// RefreshPlan 执行只刷新的 plan（plan -refresh-only -detailed-exitcode），将结果保存到 planFile
+	// 返回状态与云端实际资源是否存在差异，不会修改状态
+	RefreshPlan(ctx context.Context, workDir, planFile string, vars map[string]string) (bool, error)
+
 	// Apply 执行 Terraform apply
 	// 可选传入 vars，在执行时通过 -var 形式传递
 	Apply(ctx context.Context, workDir string, autoApprove bool, vars map[string]string) error
 
+	// ApplyPlan 应用已保存的计划文件（计划中已包含变量，Terraform 不会再次确认）
+	ApplyPlan(ctx context.Context, workDir, planFile string) error
+
 	// Destroy 执行 Terraform destroy
 	// 可选传入 vars，在执行时通过 -var 形式传递
 	Destroy(ctx context.Context, workDir string, autoApprove bool, vars map[string]string) error
@@ -42,35 +61,106 @@ type TerraformService interface {
 
 	// ShowInstances 获取状态中云主机的详细信息（针对 ECS/EC2 等实例类资源）
 	ShowInstances(ctx context.Context, workDir string) ([]ECSInstanceDetail, error)
+
+	// StatePull 获取当前状态（terraform state pull），没有状态时返回空内容
+	StatePull(ctx context.Context, workDir string) ([]byte, error)
+
+	// ShowPlan 解析已保存的计划文件（terraform show -json），返回资源变更摘要
+	ShowPlan(ctx context.Context, workDir, planFile string) (*PlanSummary, error)
+
+	// ProvidersMirror 将配置中声明的 provider 下载到本地镜像目录（terraform providers mirror）
+	// platforms 为目标平台（如 linux_amd64），为空时只下载当前平台
+	ProvidersMirror(ctx context.Context, workDir, targetDir string, platforms []string) error
+
+	// ForceUnlock 释放被中断的操作遗留的状态锁（terraform force-unlock -force）
+	ForceUnlock(ctx context.Context, workDir, lockID string) error
+
+	// Taint 将资源标记为需要重建（terraform taint），下次部署时替换该资源
+	Taint(ctx context.Context, workDir, address string) error
+
+	// Untaint 取消资源的重建标记（terraform untaint）
+	Untaint(ctx context.Context, workDir, address string) error
+
+	// StateMove 修改状态中资源的地址（terraform state mv），不会变更云资源
+	StateMove(ctx context.Context, workDir, from, to string) error
+}
+
+// InitOptions Terraform 初始化选项
+type InitOptions struct {
+	Reconfigure  bool // 后端配置变化且无需迁移状态时使用 -reconfigure
+	MigrateState bool // 后端变化时将已有状态迁移到新后端（-migrate-state -force-copy）
+}
+
+// PlanOptions Terraform plan 选项
+type PlanOptions struct {
+	Destroy bool     // 生成销毁计划（-destroy）
+	Targets []string // 只处理指定资源及其依赖（-target）
+	Replace []string // 强制重建指定资源（-replace）
+}
+
+// args 转换为 plan 命令参数
+func (o PlanOptions) args() []string {
+	var args []string
+	if o.Destroy {
+		args = append(args, "-destroy")
+	}
+	for _, address := range o.Targets {
+		args = append(args, "-target="+address)
+	}
+	for _, address := range o.Replace {
+		args = append(args, "-replace="+address)
+	}
+	return args
 }
 
 // terraformService Terraform 服务实现
 type terraformService struct {
-	config *config.Config
+	runner      Runner
+	providerEnv map[string]string // provider 插件缓存和镜像相关的环境变量
 }
 
 // NewTerraformService 创建 Terraform 服务实例
 func NewTerraformService(cfg *config.Config) TerraformService {
 	return &terraformService{
-		config: cfg,
+		runner:      NewExecRunner(cfg.Terraform.ExecPath, cfg.Terraform.BinDir),
+		providerEnv: providerInstallationEnv(cfg),
+	}
+}
+
+// NewTerraformServiceWithRunner 使用指定的命令执行器创建 Terraform 服务实例（测试中使用 FakeRunner）
+func NewTerraformServiceWithRunner(runner Runner) TerraformService {
+	return &terraformService{
+		runner: runner,
 	}
 }
 
 // Init 初始化 Terraform
 func (s *terraformService) Init(ctx context.Context, workDir string) error {
+	return s.InitWithOptions(ctx, workDir, InitOptions{})
+}
+
+// InitWithOptions 初始化 Terraform（支持远程后端参数和状态迁移）
+func (s *terraformService) InitWithOptions(ctx context.Context, workDir string, opts InitOptions) error {
 	log := logger.GetLogger()
-	log.Info("开始初始化 Terraform: workDir=%s", workDir)
+	log.Info("开始初始化 Terraform: workDir=%s, reconfigure=%v, migrateState=%v", workDir, opts.Reconfigure, opts.MigrateState)
 
 	// 设置云服务商凭证环境变量（即使 init 可能不需要，也设置以确保一致性）
-	env := s.setupCloudProviderEnv(workDir, make(map[string]string))
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return err
+	}
 
-	cmd := exec.CommandContext(ctx, s.config.Terraform.ExecPath, "init")
-	cmd.Dir = workDir
-	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	args := []string{"init"}
+	switch {
+	case opts.MigrateState:
+		args = append(args, "-input=false", "-migrate-state", "-force-copy")
+	case opts.Reconfigure:
+		args = append(args, "-input=false", "-reconfigure")
+	}
 
-	if err := cmd.Run(); err != nil {
+	req := RunRequest{Dir: workDir, Args: args, Env: env, Stdout: os.Stdout, Stderr: os.Stderr}
+
+	if err := s.run(ctx, req); err != nil {
 		log.Error("Terraform init 失败: workDir=%s, error=%v", workDir, err)
 		return fmt.Errorf("Terraform init 失败: %w", err)
 	}
@@ -85,7 +175,10 @@ func (s *terraformService) Plan(ctx context.Context, workDir string, vars map[st
 	log.Debug("执行 Terraform plan: workDir=%s, vars=%v", workDir, vars)
 
 	// 设置云服务商凭证环境变量
-	env := s.setupCloudProviderEnv(workDir, vars)
+	env, err := s.setupCloudProviderEnv(ctx, workDir, vars)
+	if err != nil {
+		return err
+	}
 
 	args := []string{"plan"}
 	// 只传递非凭证变量（凭证通过环境变量传递）
@@ -97,13 +190,9 @@ func (s *terraformService) Plan(ctx context.Context, workDir string, vars map[st
 		args = append(args, "-var", fmt.Sprintf("%s=%s", k, v))
 	}
 
-	cmd := exec.CommandContext(ctx, s.config.Terraform.ExecPath, args...)
-	cmd.Dir = workDir
-	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	req := RunRequest{Dir: workDir, Args: args, Env: env, Stdout: os.Stdout, Stderr: os.Stderr}
 
-	if err := cmd.Run(); err != nil {
+	if err := s.run(ctx, req); err != nil {
 		log.Error("Terraform plan 失败: workDir=%s, error=%v", workDir, err)
 		return fmt.Errorf("Terraform plan 失败: %w", err)
 	}
@@ -112,13 +201,89 @@ func (s *terraformService) Plan(ctx context.Context, workDir string, vars map[st
 	return nil
 }
 
+// PlanToFile 执行 Terraform plan 并保存计划文件
+func (s *terraformService) PlanToFile(ctx context.Context, workDir, planFile string, vars map[string]string) error {
+	return s.PlanToFileWithOptions(ctx, workDir, planFile, vars, PlanOptions{})
+}
+
+// PlanToFileWithOptions 执行 Terraform plan 并保存计划文件
+func (s *terraformService) PlanToFileWithOptions(ctx context.Context, workDir, planFile string, vars map[string]string, opts PlanOptions) error {
+	log := logger.GetLogger()
+	log.Debug("执行 Terraform plan: workDir=%s, planFile=%s, vars=%v, opts=%+v", workDir, planFile, vars, opts)
+
+	// 设置云服务商凭证环境变量
+	env, err := s.setupCloudProviderEnv(ctx, workDir, vars)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"plan", "-input=false", "-out=" + planFile}
+	args = append(args, opts.args()...)
+	// 只传递非凭证变量（凭证通过环境变量传递）
+	for k, v := range vars {
+		if s.isCredentialVar(k) {
+			continue
+		}
+		args = append(args, "-var", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	req := RunRequest{Dir: workDir, Args: args, Env: env, Stdout: os.Stdout, Stderr: os.Stderr}
+
+	if err := s.run(ctx, req); err != nil {
+		log.Error("Terraform plan 失败: workDir=%s, error=%v", workDir, err)
+		return fmt.Errorf("Terraform plan 失败: %w", err)
+	}
+
+	log.Info("Terraform plan 成功: workDir=%s, planFile=%s", workDir, planFile)
+	return nil
+}
+
+// RefreshPlan 执行 Terraform plan -refresh-only，检测状态与云端资源的差异
+func (s *terraformService) RefreshPlan(ctx context.Context, workDir, planFile string, vars map[string]string) (bool, error) {
+	log := logger.GetLogger()
+	log.Debug("执行 Terraform refresh-only plan: workDir=%s, planFile=%s", workDir, planFile)
+
+	// 设置云服务商凭证环境变量
+	env, err := s.setupCloudProviderEnv(ctx, workDir, vars)
+	if err != nil {
+		return false, err
+	}
+
+	args := []string{"plan", "-refresh-only", "-detailed-exitcode", "-input=false", "-out=" + planFile}
+	// 只传递非凭证变量（凭证通过环境变量传递）
+	for k, v := range vars {
+		if s.isCredentialVar(k) {
+			continue
+		}
+		args = append(args, "-var", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	req := RunRequest{Dir: workDir, Args: args, Env: env, Stdout: os.Stdout, Stderr: os.Stderr}
+
+	// -detailed-exitcode: 0 表示无差异，2 表示存在差异，其他为失败
+	err = s.run(ctx, req)
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+		log.Info("Terraform refresh-only plan 发现差异: workDir=%s", workDir)
+		return true, nil
+	}
+	if err != nil {
+		log.Error("Terraform refresh-only plan 失败: workDir=%s, error=%v", workDir, err)
+		return false, fmt.Errorf("Terraform refresh-only plan 失败: %w", err)
+	}
+	return false, nil
+}
+
 // Apply 执行 Terraform apply
 func (s *terraformService) Apply(ctx context.Context, workDir string, autoApprove bool, vars map[string]string) error {
 	log := logger.GetLogger()
 	log.Info("执行 Terraform apply: workDir=%s, autoApprove=%v, vars=%v", workDir, autoApprove, vars)
 
 	// 设置云服务商凭证环境变量
-	env := s.setupCloudProviderEnv(workDir, vars)
+	env, err := s.setupCloudProviderEnv(ctx, workDir, vars)
+	if err != nil {
+		return err
+	}
 
 	args := []string{"apply"}
 	// 只传递非凭证变量（凭证通过环境变量传递）
@@ -133,13 +298,31 @@ func (s *terraformService) Apply(ctx context.Context, workDir string, autoApprov
 		args = append(args, "-auto-approve")
 	}
 
-	cmd := exec.CommandContext(ctx, s.config.Terraform.ExecPath, args...)
-	cmd.Dir = workDir
-	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	req := RunRequest{Dir: workDir, Args: args, Env: env, Stdout: os.Stdout, Stderr: os.Stderr}
 
-	if err := cmd.Run(); err != nil {
+	if err := s.run(ctx, req); err != nil {
+		log.Error("Terraform apply 失败: workDir=%s, error=%v", workDir, err)
+		return fmt.Errorf("Terraform apply 失败: %w", err)
+	}
+
+	log.Info("Terraform apply 成功: workDir=%s", workDir)
+	return nil
+}
+
+// ApplyPlan 应用已保存的计划文件
+func (s *terraformService) ApplyPlan(ctx context.Context, workDir, planFile string) error {
+	log := logger.GetLogger()
+	log.Info("执行 Terraform apply: workDir=%s, planFile=%s", workDir, planFile)
+
+	// 计划中已包含变量值，只需设置凭证环境变量
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return err
+	}
+
+	req := RunRequest{Dir: workDir, Args: []string{"apply", "-input=false", planFile}, Env: env, Stdout: os.Stdout, Stderr: os.Stderr}
+
+	if err := s.run(ctx, req); err != nil {
 		log.Error("Terraform apply 失败: workDir=%s, error=%v", workDir, err)
 		return fmt.Errorf("Terraform apply 失败: %w", err)
 	}
@@ -154,7 +337,10 @@ func (s *terraformService) Destroy(ctx context.Context, workDir string, autoAppr
 	log.Warn("执行 Terraform destroy: workDir=%s, autoApprove=%v, vars=%v", workDir, autoApprove, vars)
 
 	// 设置云服务商凭证环境变量
-	env := s.setupCloudProviderEnv(workDir, vars)
+	env, err := s.setupCloudProviderEnv(ctx, workDir, vars)
+	if err != nil {
+		return err
+	}
 
 	args := []string{"destroy"}
 	// 只传递非凭证变量（凭证通过环境变量传递）
@@ -169,13 +355,9 @@ func (s *terraformService) Destroy(ctx context.Context, workDir string, autoAppr
 		args = append(args, "-auto-approve")
 	}
 
-	cmd := exec.CommandContext(ctx, s.config.Terraform.ExecPath, args...)
-	cmd.Dir = workDir
-	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	req := RunRequest{Dir: workDir, Args: args, Env: env, Stdout: os.Stdout, Stderr: os.Stderr}
 
-	if err := cmd.Run(); err != nil {
+	if err := s.run(ctx, req); err != nil {
 		log.Error("Terraform destroy 失败: workDir=%s, error=%v", workDir, err)
 		return fmt.Errorf("Terraform destroy 失败: %w", err)
 	}
@@ -187,13 +369,14 @@ func (s *terraformService) Destroy(ctx context.Context, workDir string, autoAppr
 // Output 获取 Terraform output
 func (s *terraformService) Output(ctx context.Context, workDir string) (map[string]string, error) {
 	// 设置云服务商凭证环境变量
-	env := s.setupCloudProviderEnv(workDir, make(map[string]string))
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return nil, err
+	}
 
-	cmd := exec.CommandContext(ctx, s.config.Terraform.ExecPath, "output", "-json")
-	cmd.Dir = workDir
-	cmd.Env = env
+	req := RunRequest{Dir: workDir, Args: []string{"output", "-json"}, Env: env}
 
-	output, err := cmd.Output()
+	output, err := s.output(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("获取 Terraform output 失败: %w", err)
 	}
@@ -209,14 +392,14 @@ func (s *terraformService) Output(ctx context.Context, workDir string) (map[stri
 // Validate 验证 Terraform 配置
 func (s *terraformService) Validate(ctx context.Context, workDir string) error {
 	// 设置云服务商凭证环境变量（validate 可能不需要，但设置以确保一致性）
-	env := s.setupCloudProviderEnv(workDir, make(map[string]string))
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return err
+	}
 
-	cmd := exec.CommandContext(ctx, s.config.Terraform.ExecPath, "validate")
-	cmd.Dir = workDir
-	cmd.Env = env
-	cmd.Stderr = os.Stderr
+	req := RunRequest{Dir: workDir, Args: []string{"validate"}, Env: env, Stderr: os.Stderr}
 
-	if err := cmd.Run(); err != nil {
+	if err := s.run(ctx, req); err != nil {
 		return fmt.Errorf("Terraform 配置验证失败: %w", err)
 	}
 
@@ -227,13 +410,14 @@ func (s *terraformService) Validate(ctx context.Context, workDir string) error {
 // 会调用 `terraform state list`，用于云资源验证
 func (s *terraformService) StateList(ctx context.Context, workDir string) ([]string, error) {
 	// 设置云服务商凭证环境变量
-	env := s.setupCloudProviderEnv(workDir, make(map[string]string))
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return nil, err
+	}
 
-	cmd := exec.CommandContext(ctx, s.config.Terraform.ExecPath, "state", "list")
-	cmd.Dir = workDir
-	cmd.Env = env
+	req := RunRequest{Dir: workDir, Args: []string{"state", "list"}, Env: env}
 
-	output, err := cmd.Output()
+	output, err := s.output(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("获取 Terraform 云资源状态失败: %w", err)
 	}
@@ -251,16 +435,49 @@ func (s *terraformService) StateList(ctx context.Context, workDir string) ([]str
 	return resources, nil
 }
 
+// StatePull 获取当前状态（本地状态或远程后端中的状态）
+func (s *terraformService) StatePull(ctx context.Context, workDir string) ([]byte, error) {
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return nil, err
+	}
+
+	req := RunRequest{Dir: workDir, Args: []string{"state", "pull"}, Env: env, Stderr: os.Stderr}
+
+	output, err := s.output(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Terraform 状态失败: %w", err)
+	}
+	return output, nil
+}
+
+// ShowPlan 通过 terraform show -json 解析计划文件中的资源变更
+func (s *terraformService) ShowPlan(ctx context.Context, workDir, planFile string) (*PlanSummary, error) {
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return nil, err
+	}
+
+	req := RunRequest{Dir: workDir, Args: []string{"show", "-json", planFile}, Env: env, Stderr: os.Stderr}
+
+	output, err := s.output(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("读取 Terraform 计划失败: %w", err)
+	}
+	return parsePlanJSON(output)
+}
+
 // ShowInstances 通过 terraform show -json 解析实例资源详情
 func (s *terraformService) ShowInstances(ctx context.Context, workDir string) ([]ECSInstanceDetail, error) {
 	// 设置云服务商凭证环境变量
-	env := s.setupCloudProviderEnv(workDir, make(map[string]string))
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return nil, err
+	}
 
-	cmd := exec.CommandContext(ctx, s.config.Terraform.ExecPath, "show", "-json")
-	cmd.Dir = workDir
-	cmd.Env = env
+	req := RunRequest{Dir: workDir, Args: []string{"show", "-json"}, Env: env}
 
-	output, err := cmd.Output()
+	output, err := s.output(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("terraform show 失败: %w", err)
 	}
@@ -275,6 +492,120 @@ func (s *terraformService) ShowInstances(ctx context.Context, workDir string) ([
 	return instances, nil
 }
 
+// ProvidersMirror 将配置中声明的 provider 下载到本地镜像目录
+func (s *terraformService) ProvidersMirror(ctx context.Context, workDir, targetDir string, platforms []string) error {
+	log := logger.GetLogger()
+	log.Debug("执行 Terraform providers mirror: workDir=%s, targetDir=%s, platforms=%v", workDir, targetDir, platforms)
+
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return err
+	}
+	args := []string{"providers", "mirror"}
+	for _, platform := range platforms {
+		args = append(args, "-platform="+platform)
+	}
+	args = append(args, targetDir)
+
+	req := RunRequest{Dir: workDir, Args: args, Env: env, Stdout: os.Stdout, Stderr: os.Stderr}
+	if err := s.run(ctx, req); err != nil {
+		return fmt.Errorf("Terraform providers mirror 失败: %w", err)
+	}
+	return nil
+}
+
+// ForceUnlock 释放被中断的操作遗留的状态锁
+func (s *terraformService) ForceUnlock(ctx context.Context, workDir, lockID string) error {
+	log := logger.GetLogger()
+	log.Warn("释放 Terraform 状态锁: workDir=%s, lockID=%s", workDir, lockID)
+
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return err
+	}
+	req := RunRequest{Dir: workDir, Args: []string{"force-unlock", "-force", lockID}, Env: env, Stdout: os.Stdout, Stderr: os.Stderr}
+	if err := s.run(ctx, req); err != nil {
+		return fmt.Errorf("Terraform force-unlock 失败: %w", err)
+	}
+	return nil
+}
+
+// Taint 将资源标记为需要重建
+func (s *terraformService) Taint(ctx context.Context, workDir, address string) error {
+	return s.setTainted(ctx, workDir, "taint", address)
+}
+
+// Untaint 取消资源的重建标记
+func (s *terraformService) Untaint(ctx context.Context, workDir, address string) error {
+	return s.setTainted(ctx, workDir, "untaint", address)
+}
+
+// setTainted 执行 taint 或 untaint（只修改状态，不访问云端资源）
+func (s *terraformService) setTainted(ctx context.Context, workDir, command, address string) error {
+	log := logger.GetLogger()
+	log.Info("执行 Terraform %s: workDir=%s, address=%s", command, workDir, address)
+
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return err
+	}
+	req := RunRequest{Dir: workDir, Args: []string{command, address}, Env: env, Stdout: os.Stdout, Stderr: os.Stderr}
+	if err := s.run(ctx, req); err != nil {
+		return fmt.Errorf("Terraform %s 失败: %w", command, err)
+	}
+	return nil
+}
+
+// StateMove 修改状态中资源的地址
+func (s *terraformService) StateMove(ctx context.Context, workDir, from, to string) error {
+	log := logger.GetLogger()
+	log.Info("执行 Terraform state mv: workDir=%s, %s -> %s", workDir, from, to)
+
+	env, err := s.setupCloudProviderEnv(ctx, workDir, make(map[string]string))
+	if err != nil {
+		return err
+	}
+	req := RunRequest{Dir: workDir, Args: []string{"state", "mv", from, to}, Env: env, Stderr: os.Stderr}
+	if err := s.run(ctx, req); err != nil {
+		return fmt.Errorf("Terraform state mv 失败: %w", err)
+	}
+	return nil
+}
+
+// run 执行 Terraform 命令，标准错误在输出到终端的同时被捕获，失败时返回分类后的 *TerraformError
+// init、plan、apply、destroy、taint、untaint 的输出同时写入场景目录的运行日志（.runs/<运行 ID>.log）
+func (s *terraformService) run(ctx context.Context, req RunRequest) (err error) {
+	if runLog := startRunLog(req.Dir, req.Args); runLog != nil {
+		req.Stdout = runLog.tee(req.Stdout)
+		req.Stderr = runLog.tee(req.Stderr)
+		defer func() { runLog.finish(err) }()
+	}
+
+	var stderr bytes.Buffer
+	if req.Stderr != nil {
+		req.Stderr = io.MultiWriter(req.Stderr, &stderr)
+	} else {
+		req.Stderr = &stderr
+	}
+	if err := s.runner.Run(ctx, req); err != nil {
+		if ctx.Err() != nil {
+			return newInterruptedError(req.Args[0], stderr.String(), err)
+		}
+		return newTerraformError(req.Args[0], stderr.String(), err)
+	}
+	return nil
+}
+
+// output 执行 Terraform 命令并返回标准输出，错误处理与 run 相同
+func (s *terraformService) output(ctx context.Context, req RunRequest) ([]byte, error) {
+	var stdout bytes.Buffer
+	req.Stdout = &stdout
+	if err := s.run(ctx, req); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
 // 解析 terraform show -json 的关键结构
 type terraformState struct {
 	Values struct {
@@ -310,6 +641,7 @@ func collectInstances(out *[]ECSInstanceDetail, m *tfModule) {
 				Region:       getString(r.Values, "region_id"),
 				InstanceType: getString(r.Values, "instance_type"),
 				Status:       getString(r.Values, "status"),
+				Spot:         isSpotInstance(ResourceChange{Type: r.Type, After: r.Values}),
 				PublicIPs:    getStringSlice(r.Values, "public_ip"),
 				PrivateIPs:   getStringSlice(r.Values, "private_ip"),
 			}
@@ -363,13 +695,13 @@ func getStringSlice(m map[string]interface{}, key string) []string {
 	return res
 }
 
-// CheckTerraformInstalled 检查 Terraform 是否已安装
-func CheckTerraformInstalled(execPath string) error {
-	cmd := exec.Command(execPath, "version")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("Terraform 未安装或不在 PATH 中: %w", err)
+// CheckTerraformInstalled 检查 Terraform（或 OpenTofu）是否已安装，返回识别出的发行版和版本
+func CheckTerraformInstalled(execPath string) (*TerraformBinary, error) {
+	binary, err := DetectTerraformBinary(execPath)
+	if err != nil {
+		return nil, fmt.Errorf("Terraform 未安装或不在 PATH 中（可运行 cloudbot terraform install 安装）: %w", err)
 	}
-	return nil
+	return binary, nil
 }
 
 // ECSInstanceDetail 云主机详细信息（用于状态展示）
@@ -379,12 +711,18 @@ type ECSInstanceDetail struct {
 	Region       string   `json:"region"`
 	InstanceType string   `json:"instance_type"`
 	Status       string   `json:"status"`
+	Spot         bool     `json:"spot,omitempty"` // 是否为抢占式实例
 	PublicIPs    []string `json:"public_ips"`
 	PrivateIPs   []string `json:"private_ips"`
 }
 
 // isCredentialVar 检查变量名是否为凭证相关变量
 func (s *terraformService) isCredentialVar(varName string) bool {
+	return isCredentialVar(varName)
+}
+
+// isCredentialVar 检查变量名是否为凭证相关变量（凭证通过环境变量传递，不允许写入变量文件）
+func isCredentialVar(varName string) bool {
 	credentialVars := []string{
 		"tencentcloud_secret_id",
 		"tencentcloud_secret_key",
@@ -405,9 +743,64 @@ func (s *terraformService) isCredentialVar(varName string) bool {
 	return false
 }
 
+// cloudProviderEnvKeys 云服务商 Terraform provider 读取凭据的环境变量
+type cloudProviderEnvKeys struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Region       string
+}
+
+// cloudProviderEnv 各云服务商的凭据环境变量
+var cloudProviderEnv = map[credentials.Provider]cloudProviderEnvKeys{
+	credentials.ProviderTencent:     {"TENCENTCLOUD_SECRET_ID", "TENCENTCLOUD_SECRET_KEY", "TENCENTCLOUD_SECURITY_TOKEN", "TENCENTCLOUD_REGION"},
+	credentials.ProviderAliyun:      {"ALICLOUD_ACCESS_KEY", "ALICLOUD_SECRET_KEY", "ALICLOUD_SECURITY_TOKEN", "ALICLOUD_REGION"},
+	credentials.ProviderHuaweicloud: {"HW_ACCESS_KEY", "HW_SECRET_KEY", "HW_SECURITY_TOKEN", "HW_REGION_NAME"},
+	credentials.ProviderAWS:         {"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_REGION"},
+}
+
+// legacyCredentialVars 向后兼容的 vars 凭证变量（AccessKey 变量名、SecretKey 变量名）
+var legacyCredentialVars = map[credentials.Provider][2]string{
+	credentials.ProviderTencent: {"tencentcloud_secret_id", "tencentcloud_secret_key"},
+	credentials.ProviderAliyun:  {"access_key", "secret_key"},
+}
+
+// terraformProviderPattern 匹配 Terraform 配置中使用的云服务商（provider 块、资源和数据源类型、required_providers 来源）
+var terraformProviderPattern = regexp.MustCompile(`(?m)^\s*(?:provider|resource|data)\s+"(tencentcloud|alicloud|huaweicloud|aws)[_"]|source\s*=\s*"[^"]*/(tencentcloud|alicloud|huaweicloud|aws)"`)
+
+// terraformProviderNames Terraform provider 名称对应的云服务商
+var terraformProviderNames = map[string]credentials.Provider{
+	"tencentcloud": credentials.ProviderTencent,
+	"alicloud":     credentials.ProviderAliyun,
+	"huaweicloud":  credentials.ProviderHuaweicloud,
+	"aws":          credentials.ProviderAWS,
+}
+
+// scenarioCloudProviders 返回工作目录中 Terraform 配置使用的云服务商
+// 未识别出云服务商时（例如配置都在子模块中）返回所有支持的云服务商
+func scenarioCloudProviders(workDir string) []credentials.Provider {
+	found := make(map[credentials.Provider]bool)
+	for _, match := range terraformProviderPattern.FindAllStringSubmatch(readTerraformFiles(workDir), -1) {
+		name := match[1]
+		if name == "" {
+			name = match[2]
+		}
+		found[terraformProviderNames[name]] = true
+	}
+
+	var providers []credentials.Provider
+	for _, provider := range []credentials.Provider{credentials.ProviderTencent, credentials.ProviderAliyun, credentials.ProviderHuaweicloud, credentials.ProviderAWS} {
+		if found[provider] || len(found) == 0 {
+			providers = append(providers, provider)
+		}
+	}
+	return providers
+}
+
 // setupCloudProviderEnv 设置云服务商凭证环境变量
-// 根据工作目录中的 Terraform 配置文件或传入的 vars 判断云服务商类型，并设置相应的环境变量
-func (s *terraformService) setupCloudProviderEnv(workDir string, vars map[string]string) []string {
+// 只获取工作目录中 Terraform 配置使用的云服务商的凭据，避免为无关的云服务商执行 AssumeRole 或 credential_process
+// 凭据取自 ctx 中绑定的 profile（项目级凭据），未绑定时使用当前默认 profile
+func (s *terraformService) setupCloudProviderEnv(ctx context.Context, workDir string, vars map[string]string) ([]string, error) {
 	// 获取当前环境变量
 	env := os.Environ()
 	envMap := make(map[string]string)
@@ -419,67 +812,56 @@ func (s *terraformService) setupCloudProviderEnv(workDir string, vars map[string
 	}
 
 	// 从凭据管理器获取并设置环境变量
-	credManager := credentials.GetDefaultManager()
-
-	// 腾讯云：优先从凭据管理器获取（推荐方式）
-	if credManager.HasCredentials(credentials.ProviderTencent) {
-		creds, err := credManager.GetCredentials(credentials.ProviderTencent)
-		if err == nil && creds != nil && creds.AccessKey != "" && creds.SecretKey != "" {
-			envMap["TENCENTCLOUD_SECRET_ID"] = creds.AccessKey
-			envMap["TENCENTCLOUD_SECRET_KEY"] = creds.SecretKey
-			if creds.Region != "" {
-				envMap["TENCENTCLOUD_REGION"] = creds.Region
-			}
+	credManager := credentials.ManagerFromContext(ctx)
+	awsConfigured := false
+	resolved := make(map[credentials.Provider]bool)
+	for _, provider := range scenarioCloudProviders(workDir) {
+		if !credManager.HasCredentials(provider) {
+			continue
 		}
-	}
-	// 如果 vars 中有腾讯云凭证变量（向后兼容），覆盖凭据管理器的值
-	if secretId, ok := vars["tencentcloud_secret_id"]; ok && secretId != "" {
-		if secretKey, ok := vars["tencentcloud_secret_key"]; ok && secretKey != "" {
-			envMap["TENCENTCLOUD_SECRET_ID"] = secretId
-			envMap["TENCENTCLOUD_SECRET_KEY"] = secretKey
+		creds, err := credManager.GetCredentials(provider)
+		if err != nil {
+			return nil, fmt.Errorf("获取 %s 凭据失败: %w", provider, err)
 		}
-	}
-
-	// 阿里云：优先从凭据管理器获取（推荐方式）
-	if credManager.HasCredentials(credentials.ProviderAliyun) {
-		creds, err := credManager.GetCredentials(credentials.ProviderAliyun)
-		if err == nil && creds != nil && creds.AccessKey != "" && creds.SecretKey != "" {
-			envMap["ALICLOUD_ACCESS_KEY"] = creds.AccessKey
-			envMap["ALICLOUD_SECRET_KEY"] = creds.SecretKey
-			if creds.Region != "" {
-				envMap["ALICLOUD_REGION"] = creds.Region
-			}
+		if creds == nil || creds.AccessKey == "" || creds.SecretKey == "" {
+			continue
 		}
-	}
-	// 如果 vars 中有阿里云凭证变量（向后兼容），覆盖凭据管理器的值
-	if accessKey, ok := vars["access_key"]; ok && accessKey != "" {
-		if secretKey, ok := vars["secret_key"]; ok && secretKey != "" {
-			envMap["ALICLOUD_ACCESS_KEY"] = accessKey
-			envMap["ALICLOUD_SECRET_KEY"] = secretKey
+		keys := cloudProviderEnv[provider]
+		envMap[keys.AccessKey] = creds.AccessKey
+		envMap[keys.SecretKey] = creds.SecretKey
+		setSessionTokenEnv(envMap, keys.SessionToken, creds)
+		if creds.Region != "" {
+			envMap[keys.Region] = creds.Region
 		}
+		awsConfigured = awsConfigured || provider == credentials.ProviderAWS
+		resolved[provider] = true
 	}
 
-	// 检查 vars 中是否有华为云凭证变量
-	if credManager.HasCredentials(credentials.ProviderHuaweicloud) {
-		creds, err := credManager.GetCredentials(credentials.ProviderHuaweicloud)
-		if err == nil && creds != nil {
-			envMap["HUAWEICLOUD_ACCESS_KEY"] = creds.AccessKey
-			envMap["HUAWEICLOUD_SECRET_KEY"] = creds.SecretKey
-			if creds.Region != "" {
-				envMap["HUAWEICLOUD_REGION"] = creds.Region
-			}
+	// s3 后端存储的凭据
+	if err := setBackendCredentialsEnv(ctx, envMap, awsConfigured); err != nil {
+		return nil, err
+	}
+
+	// 凭据管理器未提供凭据时，使用 vars 中的凭证变量（向后兼容）
+	// 凭据管理器每次执行前都会重新获取（临时凭据会自动刷新），vars 只在部署开始时构建一次，不能覆盖
+	for provider, names := range legacyCredentialVars {
+		accessKey, secretKey := vars[names[0]], vars[names[1]]
+		if accessKey == "" || secretKey == "" || resolved[provider] {
+			continue
+		}
+		keys := cloudProviderEnv[provider]
+		if envMap[keys.AccessKey] != accessKey {
+			// 继承自父进程的令牌与 vars 中的 AK/SK 不匹配
+			delete(envMap, keys.SessionToken)
 		}
+		envMap[keys.AccessKey] = accessKey
+		envMap[keys.SecretKey] = secretKey
 	}
 
-	// 检查 vars 中是否有 AWS 凭证变量
-	if credManager.HasCredentials(credentials.ProviderAWS) {
-		creds, err := credManager.GetCredentials(credentials.ProviderAWS)
-		if err == nil && creds != nil {
-			envMap["AWS_ACCESS_KEY_ID"] = creds.AccessKey
-			envMap["AWS_SECRET_ACCESS_KEY"] = creds.SecretKey
-			if creds.Region != "" {
-				envMap["AWS_REGION"] = creds.Region
-			}
+	// provider 插件缓存和镜像，用户环境中已设置的同名变量优先
+	for k, v := range s.providerEnv {
+		if _, ok := envMap[k]; !ok {
+			envMap[k] = v
 		}
 	}
 
@@ -489,5 +871,16 @@ func (s *terraformService) setupCloudProviderEnv(workDir string, vars map[string
 		result = append(result, fmt.Sprintf("%s=%s", k, v))
 	}
 
-	return result
+	return result, nil
+}
+
+// setSessionTokenEnv 设置临时凭据的安全令牌环境变量
+// AssumeRole 类型 profile 的凭据由 GetCredentials 在过期前自动刷新，因此每次执行 Terraform 前都会拿到有效令牌；
+// 使用长期凭据时删除继承自父进程的令牌，避免与 AK/SK 不匹配
+func setSessionTokenEnv(envMap map[string]string, key string, creds *credentials.Credentials) {
+	if creds.SessionToken != "" {
+		envMap[key] = creds.SessionToken
+	} else {
+		delete(envMap, key)
+	}
 }
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucksec/cloudbot/internal/credentials"
+	"github.com/lucksec/cloudbot/internal/domain"
+)
+
+// newTestCredentialManager 创建使用临时凭据文件的凭据管理器，测试之间互不影响
+func newTestCredentialManager(t *testing.T) (credentials.CredentialManager, credentials.ProcessStore) {
+	t.Helper()
+	manager, err := credentials.NewCredentialManager(filepath.Join(t.TempDir(), ".redc.ini"))
+	if err != nil {
+		t.Fatalf("创建凭据管理器失败: %v", err)
+	}
+	store, ok := manager.(credentials.ProcessStore)
+	if !ok {
+		t.Fatal("凭据管理器不支持 credential_process")
+	}
+	return manager, store
+}
+
+// writeCredentialProcess 写入输出固定凭据的 credential_process 辅助程序，执行时创建 marker 文件
+func writeCredentialProcess(t *testing.T, dir, name, output string) (command, marker string) {
+	t.Helper()
+	marker = filepath.Join(dir, name+".ran")
+	script := fmt.Sprintf("#!/bin/sh\ntouch %s\necho '%s'\n", marker, output)
+	command = filepath.Join(dir, name+".sh")
+	if err := os.WriteFile(command, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return command, marker
+}
+
+// writeMainTf 写入只包含指定配置的 main.tf，返回工作目录
+func writeMainTf(t *testing.T, content string) string {
+	t.Helper()
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "main.tf"), []byte(content+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return workDir
+}
+
+func TestSetupCloudProviderEnvCredentialProcess(t *testing.T) {
+	dir := t.TempDir()
+	manager, store := newTestCredentialManager(t)
+	tencent, tencentRan := writeCredentialProcess(t, dir, "tencent",
+		`{"Version":1,"AccessKeyId":"AKIDTMP","SecretAccessKey":"tmp-secret","SessionToken":"tmp-token"}`)
+	aliyun, aliyunRan := writeCredentialProcess(t, dir, "aliyun", `{"AccessKeyId":"LTAI","SecretAccessKey":"secret"}`)
+	if err := store.SetCredentialProcess("process-test", credentials.ProviderTencent, tencent); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetCredentialProcess("process-test", credentials.ProviderAliyun, aliyun); err != nil {
+		t.Fatal(err)
+	}
+
+	workDir := writeMainTf(t, `provider "tencentcloud" {}`)
+	svc := NewTerraformServiceWithRunner(NewFakeRunner()).(*terraformService)
+	ctx := credentials.ContextWithProfile(credentials.ContextWithManager(context.Background(), manager), "process-test")
+
+	// vars 在部署开始时构建，其中的凭证已过期，不能覆盖凭据管理器刷新后的凭据和令牌
+	vars := map[string]string{"tencentcloud_secret_id": "AKIDOLD", "tencentcloud_secret_key": "old-secret"}
+	env, err := svc.setupCloudProviderEnv(ctx, workDir, vars)
+	if err != nil {
+		t.Fatalf("设置环境变量失败: %v", err)
+	}
+
+	for _, want := range []string{"TENCENTCLOUD_SECRET_ID=AKIDTMP", "TENCENTCLOUD_SECRET_KEY=tmp-secret", "TENCENTCLOUD_SECURITY_TOKEN=tmp-token"} {
+		if !fakeContains(env, want) {
+			t.Errorf("环境变量缺少 %s", want)
+		}
+	}
+	if _, err := os.Stat(tencentRan); err != nil {
+		t.Errorf("未执行腾讯云 credential_process: %v", err)
+	}
+	// 场景未使用阿里云，不应执行阿里云的 credential_process
+	if _, err := os.Stat(aliyunRan); err == nil {
+		t.Error("执行了与场景无关的阿里云 credential_process")
+	}
+}
+
+func TestSetupCloudProviderEnvLegacyVars(t *testing.T) {
+	t.Setenv("TENCENTCLOUD_SECURITY_TOKEN", "parent-token")
+	manager, _ := newTestCredentialManager(t)
+	ctx := credentials.ContextWithManager(context.Background(), manager)
+	svc := NewTerraformServiceWithRunner(NewFakeRunner()).(*terraformService)
+
+	// 凭据管理器未提供凭据时使用 vars 中的凭证，继承自父进程的令牌与其不匹配，需要删除
+	workDir := writeMainTf(t, `provider "tencentcloud" {}`)
+	vars := map[string]string{"tencentcloud_secret_id": "AKIDVARS", "tencentcloud_secret_key": "vars-secret"}
+	env, err := svc.setupCloudProviderEnv(ctx, workDir, vars)
+	if err != nil {
+		t.Fatalf("设置环境变量失败: %v", err)
+	}
+	for _, want := range []string{"TENCENTCLOUD_SECRET_ID=AKIDVARS", "TENCENTCLOUD_SECRET_KEY=vars-secret"} {
+		if !fakeContains(env, want) {
+			t.Errorf("环境变量缺少 %s", want)
+		}
+	}
+	if fakeContains(env, "TENCENTCLOUD_SECURITY_TOKEN=parent-token") {
+		t.Error("未删除与 vars 凭证不匹配的令牌")
+	}
+}
+
+func TestSetupCloudProviderEnvHuaweicloud(t *testing.T) {
+	manager, _ := newTestCredentialManager(t)
+	creds := &credentials.Credentials{AccessKey: "HWAK", SecretKey: "hw-secret", Region: "cn-north-4", SessionToken: "hw-token"}
+	if err := manager.SetCredentials(credentials.ProviderHuaweicloud, creds); err != nil {
+		t.Fatal(err)
+	}
+	ctx := credentials.ContextWithManager(context.Background(), manager)
+	svc := NewTerraformServiceWithRunner(NewFakeRunner()).(*terraformService)
+
+	// 华为云 provider 读取 HW_* 环境变量
+	env, err := svc.setupCloudProviderEnv(ctx, writeMainTf(t, `provider "huaweicloud" {}`), map[string]string{})
+	if err != nil {
+		t.Fatalf("设置环境变量失败: %v", err)
+	}
+	for _, want := range []string{"HW_ACCESS_KEY=HWAK", "HW_SECRET_KEY=hw-secret", "HW_SECURITY_TOKEN=hw-token", "HW_REGION_NAME=cn-north-4"} {
+		if !fakeContains(env, want) {
+			t.Errorf("环境变量缺少 %s", want)
+		}
+	}
+}
+
+func TestSetupCloudProviderEnvBackendCredentials(t *testing.T) {
+	dir := t.TempDir()
+	manager, store := newTestCredentialManager(t)
+	aliyun, _ := writeCredentialProcess(t, dir, "aliyun", `{"AccessKeyId":"LTAIBACKEND","SecretAccessKey":"backend-secret","SessionToken":"backend-token"}`)
+	aws, _ := writeCredentialProcess(t, dir, "aws", `{"AccessKeyId":"AKIASCENARIO","SecretAccessKey":"scenario-secret"}`)
+	if err := store.SetCredentialProcess("backend-test", credentials.ProviderAliyun, aliyun); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetCredentialProcess("backend-test", credentials.ProviderAWS, aws); err != nil {
+		t.Fatal(err)
+	}
+	backend := &domain.BackendConfig{Type: domain.BackendS3, Options: map[string]string{"bucket": "state", "region": "cn-hangzhou", "credentials": "aliyun"}}
+	ctx := credentials.ContextWithProfile(credentials.ContextWithManager(context.Background(), manager), "backend-test")
+	ctx = contextWithBackendCredentials(ctx, backend)
+	svc := NewTerraformServiceWithRunner(NewFakeRunner()).(*terraformService)
+
+	// 后端存储的凭据通过 AWS_* 环境变量传递
+	env, err := svc.setupCloudProviderEnv(ctx, writeMainTf(t, `provider "alicloud" {}`), map[string]string{})
+	if err != nil {
+		t.Fatalf("设置环境变量失败: %v", err)
+	}
+	for _, want := range []string{"AWS_ACCESS_KEY_ID=LTAIBACKEND", "AWS_SECRET_ACCESS_KEY=backend-secret", "AWS_SESSION_TOKEN=backend-token"} {
+		if !fakeContains(env, want) {
+			t.Errorf("环境变量缺少 %s", want)
+		}
+	}
+
+	// 场景使用 AWS provider 且凭据不同时无法同时通过 AWS_* 环境变量传递
+	if _, err := svc.setupCloudProviderEnv(ctx, writeMainTf(t, `provider "aws" {}`), map[string]string{}); err == nil {
+		t.Error("后端凭据与 AWS provider 凭据冲突时应返回错误")
+	}
+}